@@ -38,8 +38,9 @@ type GetAndSaveUserOp struct {
 // 2. Ensure that the user is associated with the external account information. This means
 //    creating the external account if it does not already exist or updating it if it
 //    already does.
-// 3. Update any user props that have changed.
-// 4. Return the user ID.
+// 3. Return an error if an existing user is suspended.
+// 4. Update any user props that have changed.
+// 5. Return the user ID.
 //
 // 🚨 SECURITY: It is the caller's responsibility to ensure the veracity of the information that
 // op contains (e.g., by receiving it from the appropriate authentication mechanism). It must
@@ -129,6 +130,10 @@ func GetAndSaveUser(ctx context.Context, op GetAndSaveUserOp) (userID int32, saf
 		if err != nil {
 			return 0, "Unexpected error getting the Sourcegraph user account. Ask a site admin for help.", err
 		}
+		// 🚨 SECURITY: Suspended users may not sign in.
+		if user.SuspendedAt != nil {
+			return 0, "Your Sourcegraph user account is suspended. Ask a site admin to unsuspend it.", fmt.Errorf("user %d is suspended", user.ID)
+		}
 		var userUpdate db.UserUpdate
 		if user.DisplayName != op.UserProps.DisplayName {
 			userUpdate.DisplayName = &op.UserProps.DisplayName
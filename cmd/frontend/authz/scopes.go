@@ -2,12 +2,47 @@ package authz
 
 const (
 	// Access token scopes.
-	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
-	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeUserAll         = "user:all"         // Full control of all resources accessible to the user account.
+	ScopeSiteAdminSudo   = "site-admin:sudo"  // Ability to perform any action as any other user.
+	ScopeSearchRead      = "search:read"      // Ability to run searches.
+	ScopeRepoRead        = "repo:read"        // Ability to read repositories and their contents.
+	ScopeCampaignsWrite  = "campaigns:write"  // Ability to read, create, update and delete campaigns.
+	ScopeCodeIntelUpload = "codeintel:upload" // Ability to upload LSIF data.
+	ScopeSettingsWrite   = "settings:write"   // Ability to read and update settings.
+	ScopeAdminRead       = "admin:read"       // Ability to read site administration data (the user must be a site admin).
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeCodeIntelUpload,
+	ScopeSettingsWrite,
+	ScopeAdminRead,
+}
+
+// UserScopes is a list of all access token scopes that grant access to the resources of the
+// token's subject user, i.e. all scopes except ScopeSiteAdminSudo.
+var UserScopes = []string{
+	ScopeUserAll,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeCodeIntelUpload,
+	ScopeSettingsWrite,
+	ScopeAdminRead,
+}
+
+// HasScope reports whether the given access token scopes grant the scope. ScopeUserAll grants
+// every scope in UserScopes.
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || (s == ScopeUserAll && scope != ScopeSiteAdminSudo) {
+			return true
+		}
+	}
+	return false
 }
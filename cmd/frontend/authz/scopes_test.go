@@ -0,0 +1,24 @@
+package authz
+
+import "testing"
+
+func TestHasScope(t *testing.T) {
+	for _, tc := range []struct {
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{scopes: nil, scope: ScopeRepoRead, want: false},
+		{scopes: []string{ScopeRepoRead}, scope: ScopeRepoRead, want: true},
+		{scopes: []string{ScopeSearchRead}, scope: ScopeRepoRead, want: false},
+		{scopes: []string{ScopeUserAll}, scope: ScopeRepoRead, want: true},
+		{scopes: []string{ScopeUserAll}, scope: ScopeUserAll, want: true},
+		{scopes: []string{ScopeUserAll}, scope: ScopeSiteAdminSudo, want: false},
+		{scopes: []string{ScopeSiteAdminSudo}, scope: ScopeRepoRead, want: false},
+		{scopes: []string{ScopeSearchRead}, scope: ScopeUserAll, want: false},
+	} {
+		if have := HasScope(tc.scopes, tc.scope); have != tc.want {
+			t.Errorf("HasScope(%q, %q): have %v, want %v", tc.scopes, tc.scope, have, tc.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package authz
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// SubRepoPermsProvider is implemented by authz providers whose code host restricts
+// access to paths within repositories (e.g. Perforce protections). It is optional:
+// repositories of providers that do not implement it are readable in full by every
+// user who has read access to them.
+type SubRepoPermsProvider interface {
+	// FetchUserSubRepoPerms returns the sub-repository permissions of the user for the
+	// repositories/projects on the code host that the user may only read partially.
+	// Repositories that are not in the returned map are readable in full (provided that
+	// the user has read access to them).
+	//
+	// Unlike FetchUserPerms, the implementation must not return partial results in
+	// case of error, because a missing entry grants access to the whole repository.
+	FetchUserSubRepoPerms(ctx context.Context, account *extsvc.Account) (map[extsvc.RepoID]*SubRepoPermissions, error)
+}
+
+// SubRepoPermissions declares which paths of a repository a user may read. The paths
+// are matched against globs relative to the repository root, where "*" matches any
+// sequence of characters within a path segment and "**" also matches across segments
+// (e.g. "docs/**" matches every file in the "docs" directory).
+//
+// A path is readable if it matches at least one of PathIncludes and none of
+// PathExcludes. In particular, no path is readable if PathIncludes is empty.
+type SubRepoPermissions struct {
+	PathIncludes []string
+	PathExcludes []string
+}
+
+// Compile compiles the globs into a matcher. A nil SubRepoPermissions compiles into
+// a nil matcher, which matches every path.
+func (p *SubRepoPermissions) Compile() (*SubRepoPathMatcher, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	m := &SubRepoPathMatcher{perms: p}
+	for _, pattern := range p.PathIncludes {
+		g, err := compileSubRepoGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.includes = append(m.includes, g)
+		m.includePrefixes = append(m.includePrefixes, globLiteralPrefix(pattern))
+	}
+	for _, pattern := range p.PathExcludes {
+		g, err := compileSubRepoGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		m.excludes = append(m.excludes, g)
+	}
+	return m, nil
+}
+
+func compileSubRepoGlob(pattern string) (glob.Glob, error) {
+	g, err := glob.Compile(strings.TrimPrefix(pattern, "/"), '/')
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid sub-repository permissions path %q", pattern)
+	}
+	return g, nil
+}
+
+// globLiteralPrefix returns the part of the glob before its first special character.
+func globLiteralPrefix(pattern string) string {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if i := strings.IndexAny(pattern, `*?[{\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// SubRepoPathMatcher reports whether paths of a repository are readable according to
+// SubRepoPermissions. A nil *SubRepoPathMatcher matches every path.
+//
+// It implements the pathmatch.PathMatcher interface.
+type SubRepoPathMatcher struct {
+	perms           *SubRepoPermissions
+	includes        []glob.Glob
+	includePrefixes []string
+	excludes        []glob.Glob
+}
+
+// MatchPath reports whether the file at the given path (relative to the repository
+// root, with or without a leading slash) is readable.
+func (m *SubRepoPathMatcher) MatchPath(name string) bool {
+	if m == nil {
+		return true
+	}
+
+	name = cleanSubRepoPath(name)
+	return matchAny(m.includes, name) && !matchAny(m.excludes, name)
+}
+
+// MatchDir reports whether the directory at the given path may contain readable
+// files, i.e. whether it should be listed. It errs on the side of listing
+// directories: the names of directories that contain no readable files may be
+// listed, but never their contents.
+func (m *SubRepoPathMatcher) MatchDir(name string) bool {
+	if m == nil {
+		return true
+	}
+
+	name = cleanSubRepoPath(name)
+	if name == "" {
+		return len(m.includes) > 0
+	}
+
+	// A glob such as "dir/**" matches "dir/" when the whole directory is excluded.
+	if matchAny(m.excludes, name+"/") {
+		return false
+	}
+	for i, include := range m.includes {
+		prefix := m.includePrefixes[i]
+		if include.Match(name+"/") ||
+			strings.HasPrefix(prefix, name+"/") ||
+			strings.HasPrefix(name+"/", prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFileOrDir reports whether the path, which may be a file or a directory, is readable
+// (e.g. to show its history). Unlike MatchDir, it errs on the side of not matching: a
+// directory only matches if the includes match the directory itself (e.g. "docs/**"
+// matches "docs"), and neither a file nor a directory matches if it is excluded.
+func (m *SubRepoPathMatcher) MatchFileOrDir(name string) bool {
+	if m == nil {
+		return true
+	}
+
+	name = cleanSubRepoPath(name)
+	if name == "" {
+		return len(m.includes) > 0
+	}
+	if matchAny(m.excludes, name) || matchAny(m.excludes, name+"/") {
+		return false
+	}
+	return matchAny(m.includes, name) || matchAny(m.includes, name+"/")
+}
+
+// String implements the pathmatch.PathMatcher interface.
+func (m *SubRepoPathMatcher) String() string {
+	if m == nil {
+		return "sub-repo:*"
+	}
+	return "sub-repo:" + strings.Join(m.perms.PathIncludes, ",") + " !" + strings.Join(m.perms.PathExcludes, ",")
+}
+
+func cleanSubRepoPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func matchAny(globs []glob.Glob, name string) bool {
+	for _, g := range globs {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
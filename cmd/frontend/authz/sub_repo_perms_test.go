@@ -0,0 +1,101 @@
+package authz
+
+import (
+	"testing"
+)
+
+func TestSubRepoPathMatcher(t *testing.T) {
+	perms := &SubRepoPermissions{
+		PathIncludes: []string{"/src/**", "README.md", "docs/*.md"},
+		PathExcludes: []string{"src/secret/**", "**/*.key"},
+	}
+	m, err := perms.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"README.md", true},
+		{"/README.md", true},
+		{"LICENSE", false},
+		{"src/main.go", true},
+		{"src/a/b/c.go", true},
+		{"src/a/b/c.key", false},
+		{"src/secret/main.go", false},
+		{"src/../src/secret/main.go", false},
+		{"docs/index.md", true},
+		{"docs/api/index.md", false},
+	} {
+		if got := m.MatchPath(tc.path); got != tc.want {
+			t.Errorf("MatchPath(%q): got %v, want %v", tc.path, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		dir  string
+		want bool
+	}{
+		{"", true},
+		{"/", true},
+		{"src", true},
+		{"src/a", true},
+		{"src/secret", false},
+		{"src/secret/a", false},
+		{"docs", true},
+		{"vendor", false},
+	} {
+		if got := m.MatchDir(tc.dir); got != tc.want {
+			t.Errorf("MatchDir(%q): got %v, want %v", tc.dir, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		path string
+		want bool
+	}{
+		{"", true},
+		{"README.md", true},
+		{"LICENSE", false},
+		{"src", true},
+		{"src/a", true},
+		{"src/a/b/c.key", false},
+		{"src/secret", false},
+		{"docs/index.md", true},
+		{"docs/api/index.md", false},
+		{"docs", false},
+	} {
+		if got := m.MatchFileOrDir(tc.path); got != tc.want {
+			t.Errorf("MatchFileOrDir(%q): got %v, want %v", tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestSubRepoPathMatcher_nil(t *testing.T) {
+	var perms *SubRepoPermissions
+	m, err := perms.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.MatchPath("a/b") || !m.MatchDir("a") {
+		t.Error("nil matcher should match every path")
+	}
+}
+
+func TestSubRepoPermissions_noIncludes(t *testing.T) {
+	m, err := (&SubRepoPermissions{}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MatchPath("a") || m.MatchDir("") {
+		t.Error("matcher without includes should match no path")
+	}
+}
+
+func TestSubRepoPermissions_invalidGlob(t *testing.T) {
+	if _, err := (&SubRepoPermissions{PathIncludes: []string{"src/[a"}}).Compile(); err == nil {
+		t.Error("got nil error for invalid glob")
+	}
+}
@@ -0,0 +1,126 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditSiteConfigUpdate      = "site_config.update"
+	AuditCriticalConfigUpdate  = "critical_config.update"
+	AuditUserSetSiteAdmin      = "user.set_site_admin"
+	AuditUserSetActive         = "user.set_active"
+	AuditUserSetSuspended      = "user.set_suspended"
+	AuditAccessTokenCreate     = "access_token.create"
+	AuditAccessTokenDelete     = "access_token.delete"
+	AuditAccessTokenSudo       = "access_token.sudo"
+	AuditExternalServiceAdd    = "external_service.add"
+	AuditExternalServiceUpdate = "external_service.update"
+	AuditExternalServiceDelete = "external_service.delete"
+)
+
+// AuditEvent describes a change to record in the audit log.
+type AuditEvent struct {
+	Action     string
+	TargetType string // e.g. "user" or "external_service"
+	Target     string // e.g. the ID of the user
+
+	// Before and After are the target before and after the change. Only the
+	// lines which differ are recorded, with secret-looking JSON values
+	// redacted.
+	Before, After string
+
+	// ActorUserID is the user who made the change. If zero, it is the actor
+	// in the context.
+	ActorUserID int32
+}
+
+// LogAuditEvent records the change described by e in the audit log, with the
+// actor and client IP address of ctx.
+//
+// Failing to record a change does not fail the change: the error is logged
+// instead.
+func LogAuditEvent(ctx context.Context, e AuditEvent) {
+	entry := &types.AuditLogEntry{
+		ActorIP:    ClientIPFromContext(ctx),
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		Target:     e.Target,
+		Diff:       auditDiff(e.Before, e.After),
+	}
+	uid := e.ActorUserID
+	if uid == 0 {
+		uid = actor.FromContext(ctx).UID
+	}
+	if uid != 0 {
+		entry.ActorUserID = &uid
+	}
+
+	// Record the change even if the request that made it was canceled.
+	if err := db.AuditLog.Insert(context.Background(), entry); err != nil {
+		log15.Error("Failed to record change in the audit log.", "action", e.Action, "targetType", e.TargetType, "target", e.Target, "actorUserID", uid, "error", err)
+	}
+}
+
+// ExternalServiceAuditDescription describes an external service for the audit
+// log. Secrets in its config are redacted by LogAuditEvent.
+func ExternalServiceAuditDescription(svc *types.ExternalService) string {
+	return fmt.Sprintf("kind: %s\ndisplayName: %q\n%s", svc.Kind, svc.DisplayName, svc.Config)
+}
+
+// auditDiff returns the lines removed from before (prefixed with "-") and
+// added in after (prefixed with "+").
+func auditDiff(before, after string) string {
+	dmp := diffmatchpatch.New()
+	a, b, lines := dmp.DiffLinesToChars(before, after)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lines)
+
+	var out []string
+	for _, d := range diffs {
+		var prefix string
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		default:
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(d.Text, "\n"), "\n") {
+			out = append(out, prefix+redactSecrets(line))
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// secretValueRegexp matches a JSON string property whose name suggests that
+// its value is a secret, e.g. "token": "abc".
+var secretValueRegexp = lazyregexp.New(`(?i)("[^"]*(?:token|password|secret|key|credentials)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+func redactSecrets(s string) string {
+	return secretValueRegexp.ReplaceAllString(s, `$1"REDACTED"`)
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx with the IP address of the client which
+// sent the request.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP address set by WithClientIP, or
+// "" if there is none.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
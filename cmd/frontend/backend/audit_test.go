@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestAuditDiff(t *testing.T) {
+	tests := map[string]struct {
+		before, after, want string
+	}{
+		"unchanged": {
+			before: "{\n  \"a\": 1\n}",
+			after:  "{\n  \"a\": 1\n}",
+			want:   "",
+		},
+		"created": {
+			before: "",
+			after:  "{\n  \"a\": 1\n}",
+			want:   "+{\n+  \"a\": 1\n+}",
+		},
+		"changed line": {
+			before: "{\n  \"a\": 1,\n  \"b\": 2\n}",
+			after:  "{\n  \"a\": 1,\n  \"b\": 3\n}",
+			want:   "-  \"b\": 2\n+  \"b\": 3",
+		},
+		"secrets": {
+			before: "{\n  \"url\": \"https://github.com\",\n  \"token\": \"abc\"\n}",
+			after:  "{\n  \"url\": \"https://github.com\",\n  \"token\": \"d\\\"ef\", \"clientSecret\": \"s\"\n}",
+			want:   "-  \"token\": \"REDACTED\"\n+  \"token\": \"REDACTED\", \"clientSecret\": \"REDACTED\"",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := auditDiff(test.before, test.after); got != test.want {
+				t.Errorf("got diff\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLogAuditEvent(t *testing.T) {
+	ctx := testContext()
+	ctx = WithClientIP(ctx, "10.0.0.1")
+
+	var got []*types.AuditLogEntry
+	db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+		got = append(got, e)
+		return nil
+	}
+
+	LogAuditEvent(ctx, AuditEvent{Action: AuditUserSetSiteAdmin, TargetType: "user", Target: "2", Before: "false", After: "true"})
+	LogAuditEvent(ctx, AuditEvent{Action: AuditAccessTokenSudo, TargetType: "user", Target: "3", ActorUserID: 4})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if e := got[0]; e.ActorUserID == nil || *e.ActorUserID != 1 || e.ActorIP != "10.0.0.1" || e.Action != AuditUserSetSiteAdmin || e.Target != "2" || e.Diff != "-false\n+true" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := got[1]; e.ActorUserID == nil || *e.ActorUserID != 4 {
+		t.Errorf("got actor %v, want 4", e.ActorUserID)
+	}
+}
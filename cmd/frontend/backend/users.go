@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/randstring"
@@ -47,3 +48,14 @@ func CheckActorHasTag(ctx context.Context, tag string) error {
 	}
 	return fmt.Errorf("actor lacks required tag %q", tag)
 }
+
+// CheckActorHasScope returns an error if the context actor was authenticated with an access token
+// that does not grant the given scope. Actors that were not authenticated with an access token
+// (e.g., with a session cookie) are not restricted by scopes.
+func CheckActorHasScope(ctx context.Context, scope string) error {
+	a := actor.FromContext(ctx)
+	if a.AccessTokenScopes == nil || authz.HasScope(a.AccessTokenScopes, scope) {
+		return nil
+	}
+	return &InsufficientAuthorizationError{fmt.Sprintf("access token lacks required scope %q", scope)}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 )
@@ -34,7 +35,7 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,13 +54,16 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Errorf("got %q, want %q", got.Note, want)
 	}
 
-	gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, "a")
+	gotSubjectUserID, gotScopes, err := AccessTokens.Lookup(ctx, tv0, []string{"a"})
 	if err != nil {
 		t.Fatal(err)
 	}
 	if want := subject.ID; gotSubjectUserID != want {
 		t.Errorf("got %v, want %v", gotSubjectUserID, want)
 	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(gotScopes, want) {
+		t.Errorf("got scopes %q, want %q", gotScopes, want)
+	}
 
 	ts, err := AccessTokens.List(ctx, AccessTokensListOptions{SubjectUserID: subject.ID})
 	if err != nil {
@@ -108,11 +112,11 @@ func TestAccessTokens_List(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,13 +191,13 @@ func TestAccessTokens_Lookup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	for _, scope := range []string{"a", "b"} {
-		gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, scope)
+	for _, scopes := range [][]string{{"a"}, {"b"}, {"x", "b"}} {
+		gotSubjectUserID, _, err := AccessTokens.Lookup(ctx, tv0, scopes)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -203,12 +207,12 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	}
 
 	// Lookup with a nonexistent scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, "x"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"x"}); err == nil {
 		t.Fatal(err)
 	}
 
-	// Lookup with an empty scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, ""); err == nil {
+	// Lookup with no scopes and ensure it fails.
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, nil); err == nil {
 		t.Fatal(err)
 	}
 
@@ -216,12 +220,43 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	if err := AccessTokens.DeleteByID(ctx, tid0, subject.ID); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 		t.Fatal(err)
 	}
 
 	// Try to Lookup a token that was never created.
-	if _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, "a"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, []string{"a"}); err == nil {
+		t.Fatal(err)
+	}
+
+	// Create tokens that expire in the future and in the past, and ensure Lookup fails on the
+	// expired one only.
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	_, tv1, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n1", creator.ID, &future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv1, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	_, tv2, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n2", creator.ID, &past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv2, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Fatalf("got error %v, want %v", err, ErrAccessTokenNotFound)
+	}
+
+	// Delete all of the subject's tokens and ensure Lookup fails on them.
+	if err := AccessTokens.DeleteBySubjectUserID(ctx, subject.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv1, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Fatalf("got error %v, want %v", err, ErrAccessTokenNotFound)
+	}
+
+	// Deleting the tokens of a user without any tokens is not an error.
+	if err := AccessTokens.DeleteBySubjectUserID(ctx, subject.ID); err != nil {
 		t.Fatal(err)
 	}
 }
@@ -255,18 +290,18 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, subject.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted subject user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted subject user")
 		}
 	})
@@ -291,19 +326,56 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, creator.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted creator user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted creator user")
 		}
 	})
 }
+
+func TestAccessTokens_Lookup_suspendedUser(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	subject, err := Users.Create(ctx, NewUser{
+		Email:                 "u1@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", subject.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.SetIsSuspended(ctx, subject.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Fatalf("Lookup: got error %v, want %v for suspended subject user", err, ErrAccessTokenNotFound)
+	}
+
+	// The token works again after the user is unsuspended.
+	if err := Users.SetIsSuspended(ctx, subject.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err != nil {
+		t.Fatalf("Lookup: got error %v, want nil for unsuspended subject user", err)
+	}
+}
@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// auditLog provides access to the audit_log table, an append-only record of
+// security-relevant changes.
+//
+// Each entry is chained to the entry before it by its hash (see
+// auditLogEntryHash), so that changes to the table that don't go through
+// Insert can be detected.
+type auditLog struct{}
+
+// Insert appends e to the audit log. Its ID, CreatedAt, PrevHash and Hash
+// fields are set by Insert.
+func (*auditLog) Insert(ctx context.Context, e *types.AuditLogEntry) error {
+	if Mocks.AuditLog.Insert != nil {
+		return Mocks.AuditLog.Insert(e)
+	}
+
+	// Each entry must be chained to the entry inserted right before it. The
+	// unique index on prev_hash makes concurrent inserts which read the same
+	// previous entry conflict, so the losers retry with the new last entry.
+	for attempt := 0; ; attempt++ {
+		err := insertAuditLogEntry(ctx, e)
+		if pqErr, ok := errors.Cause(err).(*pq.Error); ok && pqErr.Constraint == "audit_log_prev_hash" && attempt+1 < maxAuditLogInsertAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// maxAuditLogInsertAttempts bounds the attempts of an audit log insert which
+// conflicts with concurrent inserts.
+const maxAuditLogInsertAttempts = 10
+
+func insertAuditLogEntry(ctx context.Context, e *types.AuditLogEntry) error {
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&e.PrevHash)
+	if err == sql.ErrNoRows {
+		e.PrevHash, err = "", nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Postgres stores microseconds, so truncate to get the same hash when
+	// the entry is read back.
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.Hash = auditLogEntryHash(e)
+
+	err = dbconn.Global.QueryRowContext(ctx, `
+INSERT INTO audit_log(created_at, actor_user_id, actor_ip, action, target_type, target, diff, prev_hash, hash)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id`,
+		e.CreatedAt, e.ActorUserID, e.ActorIP, e.Action, e.TargetType, e.Target, e.Diff, e.PrevHash, e.Hash,
+	).Scan(&e.ID)
+	if err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	return nil
+}
+
+// auditLogEntryHash returns the hex-encoded SHA-256 hash of the JSON object
+// with the fields of e, in this order:
+//
+//	{"prevHash":"…","createdAt":"2006-01-02T15:04:05.999999Z","actorUserID":1,"actorIP":"…","action":"…","targetType":"…","target":"…","diff":"…"}
+//
+// The ID is not included, because it is only known after the insert.
+func auditLogEntryHash(e *types.AuditLogEntry) string {
+	b, _ := json.Marshal(struct {
+		PrevHash    string `json:"prevHash"`
+		CreatedAt   string `json:"createdAt"`
+		ActorUserID *int32 `json:"actorUserID"`
+		ActorIP     string `json:"actorIP"`
+		Action      string `json:"action"`
+		TargetType  string `json:"targetType"`
+		Target      string `json:"target"`
+		Diff        string `json:"diff"`
+	}{
+		PrevHash:    e.PrevHash,
+		CreatedAt:   e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ActorUserID: e.ActorUserID,
+		ActorIP:     e.ActorIP,
+		Action:      e.Action,
+		TargetType:  e.TargetType,
+		Target:      e.Target,
+		Diff:        e.Diff,
+	})
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLogListOptions contains options for listing audit log entries.
+type AuditLogListOptions struct {
+	ActorUserID int32  // only list entries of changes made by this user
+	Action      string // only list entries with this action
+	TargetType  string // only list entries with this target type
+	Target      string // only list entries with this target
+	Since       *time.Time
+	Until       *time.Time
+
+	// AfterID only lists entries with a greater ID. It is used to page through
+	// the entries in OldestFirst order.
+	AfterID int64
+
+	// OldestFirst lists the entries in the order they were inserted, instead
+	// of newest first.
+	OldestFirst bool
+
+	*LimitOffset
+}
+
+func (o AuditLogListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.ActorUserID != 0 {
+		conds = append(conds, sqlf.Sprintf("actor_user_id=%d", o.ActorUserID))
+	}
+	if o.Action != "" {
+		conds = append(conds, sqlf.Sprintf("action=%s", o.Action))
+	}
+	if o.TargetType != "" {
+		conds = append(conds, sqlf.Sprintf("target_type=%s", o.TargetType))
+	}
+	if o.Target != "" {
+		conds = append(conds, sqlf.Sprintf("target=%s", o.Target))
+	}
+	if o.Since != nil {
+		conds = append(conds, sqlf.Sprintf("created_at>=%s", *o.Since))
+	}
+	if o.Until != nil {
+		conds = append(conds, sqlf.Sprintf("created_at<%s", *o.Until))
+	}
+	if o.AfterID != 0 {
+		conds = append(conds, sqlf.Sprintf("id>%d", o.AfterID))
+	}
+	return conds
+}
+
+// List lists the audit log entries that satisfy the options.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) List(ctx context.Context, opt AuditLogListOptions) ([]*types.AuditLogEntry, error) {
+	if Mocks.AuditLog.List != nil {
+		return Mocks.AuditLog.List(opt)
+	}
+
+	order := sqlf.Sprintf("id DESC")
+	if opt.OldestFirst {
+		order = sqlf.Sprintf("id ASC")
+	}
+	q := sqlf.Sprintf(`
+SELECT id, created_at, actor_user_id, actor_ip, action, target_type, target, diff, prev_hash, hash FROM audit_log
+WHERE (%s)
+ORDER BY %s
+%s`,
+		sqlf.Join(opt.sqlConditions(), ") AND ("),
+		order,
+		opt.LimitOffset.SQL(),
+	)
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*types.AuditLogEntry
+	for rows.Next() {
+		var e types.AuditLogEntry
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.ActorUserID, &e.ActorIP, &e.Action, &e.TargetType, &e.Target, &e.Diff, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// Count counts the audit log entries that satisfy the options (ignoring
+// limit and offset).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) Count(ctx context.Context, opt AuditLogListOptions) (int, error) {
+	if Mocks.AuditLog.Count != nil {
+		return Mocks.AuditLog.Count(opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM audit_log WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+type MockAuditLog struct {
+	Insert func(e *types.AuditLogEntry) error
+	List   func(opt AuditLogListOptions) ([]*types.AuditLogEntry, error)
+	Count  func(opt AuditLogListOptions) (int, error)
+}
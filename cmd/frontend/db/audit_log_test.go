@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	uid := int32(1)
+	entries := []*types.AuditLogEntry{
+		{ActorUserID: &uid, ActorIP: "10.0.0.1", Action: "user.set_site_admin", TargetType: "user", Target: "2", Diff: "-false\n+true"},
+		{Action: "site_config.update", TargetType: "site_config", Diff: "+{}"},
+		{ActorUserID: &uid, Action: "access_token.create", TargetType: "access_token", Target: "3"},
+	}
+	for _, e := range entries {
+		if err := AuditLog.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := AuditLog.List(ctx, AuditLogListOptions{OldestFirst: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != len(entries) {
+		t.Fatalf("got %d entries, want %d", len(all), len(entries))
+	}
+	prevHash := ""
+	for i, e := range all {
+		if e.ID != entries[i].ID || e.Action != entries[i].Action {
+			t.Errorf("entry %d: got %+v, want %+v", i, e, entries[i])
+		}
+		if e.PrevHash != prevHash {
+			t.Errorf("entry %d: got prev hash %q, want %q", i, e.PrevHash, prevHash)
+		}
+		if want := auditLogEntryHash(e); e.Hash != want {
+			t.Errorf("entry %d: got hash %q, want %q", i, e.Hash, want)
+		}
+		prevHash = e.Hash
+	}
+
+	byActor, err := AuditLog.List(ctx, AuditLogListOptions{ActorUserID: uid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byActor) != 2 || byActor[0].Action != "access_token.create" {
+		t.Errorf("got %+v, want the 2 entries of user %d, newest first", byActor, uid)
+	}
+	if count, err := AuditLog.Count(ctx, AuditLogListOptions{Action: "site_config.update"}); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Errorf("got count %d, want 1", count)
+	}
+
+	if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET diff='' WHERE id=$1", all[0].ID); err == nil {
+		t.Error("expected an error when updating an audit log entry")
+	}
+	if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+		t.Error("expected an error when deleting audit log entries")
+	}
+}
+
+func TestAuditLog_concurrentInserts(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- AuditLog.Insert(ctx, &types.AuditLogEntry{Action: "site_config.update"})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The entries form a single chain.
+	all, err := AuditLog.List(ctx, AuditLogListOptions{OldestFirst: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != n {
+		t.Fatalf("got %d entries, want %d", len(all), n)
+	}
+	prevHash := ""
+	for i, e := range all {
+		if e.PrevHash != prevHash {
+			t.Fatalf("entry %d: got prev hash %q, want %q", i, e.PrevHash, prevHash)
+		}
+		prevHash = e.Hash
+	}
+}
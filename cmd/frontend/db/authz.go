@@ -5,6 +5,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
@@ -42,6 +43,14 @@ type RevokeUserPermissionsArgs struct {
 	Accounts []*extsvc.Accounts
 }
 
+// SubRepoPermissionsArgs contains required arguments to load the sub-repository permissions of a user.
+type SubRepoPermissionsArgs struct {
+	// The user whose sub-repository permissions are loaded.
+	UserID int32
+	// The repositories to load the sub-repository permissions for.
+	RepoIDs []api.RepoID
+}
+
 // AuthzStore contains methods for manipulating user permissions.
 type AuthzStore interface {
 	// GrantPendingPermissions grants pending permissions for a user. It is a no-op in the OSS version.
@@ -53,6 +62,9 @@ type AuthzStore interface {
 	// RevokeUserPermissions deletes both effective and pending permissions that could be related to a user.
 	// It is a no-op in the OSS version.
 	RevokeUserPermissions(ctx context.Context, args *RevokeUserPermissionsArgs) error
+	// SubRepoPermissions returns the sub-repository permissions of a user for the given repositories.
+	// Repositories that are not in the returned map are readable in full. It is a no-op in the OSS version.
+	SubRepoPermissions(ctx context.Context, args *SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error)
 }
 
 // authzStore is a no-op placeholder for the OSS version.
@@ -78,3 +90,10 @@ func (*authzStore) RevokeUserPermissions(ctx context.Context, args *RevokeUserPe
 	}
 	return nil
 }
+
+func (*authzStore) SubRepoPermissions(ctx context.Context, args *SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error) {
+	if Mocks.Authz.SubRepoPermissions != nil {
+		return Mocks.Authz.SubRepoPermissions(ctx, args)
+	}
+	return nil, nil
+}
@@ -3,11 +3,14 @@ package db
 import (
 	"context"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 )
 
 type MockAuthz struct {
 	GrantPendingPermissions func(ctx context.Context, args *GrantPendingPermissionsArgs) error
 	AuthorizedRepos         func(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error)
 	RevokeUserPermissions   func(ctx context.Context, args *RevokeUserPermissionsArgs) error
+	SubRepoPermissions      func(ctx context.Context, args *SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error)
 }
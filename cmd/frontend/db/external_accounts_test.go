@@ -72,6 +72,16 @@ func TestExternalAccounts_AssociateUserAndSave(t *testing.T) {
 	if want := (extsvc.Account{UserID: user.ID, AccountSpec: spec}); !reflect.DeepEqual(account, want) {
 		t.Errorf("got %+v, want %+v", account, want)
 	}
+
+	for accountID, wantCount := range map[string]int{"xd": 1, "xe": 0} {
+		accounts, err := ExternalAccounts.List(ctx, ExternalAccountsListOptions{AccountID: accountID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(accounts) != wantCount {
+			t.Errorf("got len(accounts) == %d for account ID %q, want %d", len(accounts), accountID, wantCount)
+		}
+	}
 }
 
 func TestExternalAccounts_CreateUserAndSave(t *testing.T) {
@@ -30,6 +30,7 @@ type ExternalServicesStore struct {
 	GitHubValidators          []func(*schema.GitHubConnection) error
 	GitLabValidators          []func(*schema.GitLabConnection, []schema.AuthProviders) error
 	BitbucketServerValidators []func(*schema.BitbucketServerConnection) error
+	BitbucketCloudValidators  []func(*schema.BitbucketCloudConnection) error
 }
 
 // ExternalServiceKinds contains a map of all supported kinds of
@@ -38,6 +39,7 @@ var ExternalServiceKinds = map[string]ExternalServiceKind{
 	"AWSCODECOMMIT":   {CodeHost: true, JSONSchema: schema.AWSCodeCommitSchemaJSON},
 	"BITBUCKETCLOUD":  {CodeHost: true, JSONSchema: schema.BitbucketCloudSchemaJSON},
 	"BITBUCKETSERVER": {CodeHost: true, JSONSchema: schema.BitbucketServerSchemaJSON},
+	"GITEA":           {CodeHost: true, JSONSchema: schema.GiteaSchemaJSON},
 	"GITHUB":          {CodeHost: true, JSONSchema: schema.GitHubSchemaJSON},
 	"GITLAB":          {CodeHost: true, JSONSchema: schema.GitLabSchemaJSON},
 	"GITOLITE":        {CodeHost: true, JSONSchema: schema.GitoliteSchemaJSON},
@@ -130,6 +132,13 @@ func (e *ExternalServicesStore) ValidateConfig(kind, config string, ps []schema.
 		}
 		err = e.validateBitbucketServerConnection(&c)
 
+	case "BITBUCKETCLOUD":
+		var c schema.BitbucketCloudConnection
+		if err = json.Unmarshal(normalized, &c); err != nil {
+			return err
+		}
+		err = e.validateBitbucketCloudConnection(&c)
+
 	case "OTHER":
 		var c schema.OtherExternalServiceConnection
 		if err = json.Unmarshal(normalized, &c); err != nil {
@@ -204,6 +213,14 @@ func (e *ExternalServicesStore) validateBitbucketServerConnection(c *schema.Bitb
 	return err.ErrorOrNil()
 }
 
+func (e *ExternalServicesStore) validateBitbucketCloudConnection(c *schema.BitbucketCloudConnection) error {
+	err := new(multierror.Error)
+	for _, validate := range e.BitbucketCloudValidators {
+		err = multierror.Append(err, validate(c))
+	}
+	return err.ErrorOrNil()
+}
+
 // Create creates a external service.
 //
 // Since this method is used before the configuration server has started
@@ -414,6 +431,17 @@ func (c *ExternalServicesStore) ListGitHubConnections(ctx context.Context) ([]*s
 	return connections, nil
 }
 
+// ListGiteaConnections returns a list of GiteaConnection configs.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (c *ExternalServicesStore) ListGiteaConnections(ctx context.Context) ([]*schema.GiteaConnection, error) {
+	var connections []*schema.GiteaConnection
+	if err := c.listConfigs(ctx, "GITEA", &connections); err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
+
 // ListGitLabConnections returns a list of GitLabConnection configs.
 //
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin.
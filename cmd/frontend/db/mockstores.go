@@ -27,4 +27,6 @@ type MockStores struct {
 	ExternalServices MockExternalServices
 
 	Authz MockAuthz
+
+	AuditLog MockAuditLog
 }
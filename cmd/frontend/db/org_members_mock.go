@@ -8,6 +8,9 @@ import (
 )
 
 type MockOrgMembers struct {
+	Create              func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error)
+	Remove              func(ctx context.Context, orgID, userID int32) error
+	GetByOrgID          func(ctx context.Context, orgID int32) ([]*types.OrgMembership, error)
 	GetByOrgIDAndUserID func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error)
 }
 
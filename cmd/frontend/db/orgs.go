@@ -25,6 +25,11 @@ func (e *OrgNotFoundError) Error() string {
 
 var errOrgNameAlreadyExists = errors.New("organization name is already taken (by a user or another organization)")
 
+// IsOrgNameAlreadyExists reports whether err indicates that the organization name is already taken.
+func IsOrgNameAlreadyExists(err error) bool {
+	return err == errOrgNameAlreadyExists
+}
+
 type orgs struct{}
 
 // GetByUserID returns a list of all organizations for the user. An empty slice is
@@ -148,6 +153,10 @@ func (*orgs) getBySQL(ctx context.Context, query string, args ...interface{}) ([
 }
 
 func (*orgs) Create(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Create != nil {
+		return Mocks.Orgs.Create(ctx, name, displayName)
+	}
+
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
@@ -197,6 +206,10 @@ func (*orgs) Create(ctx context.Context, name string, displayName *string) (*typ
 }
 
 func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+	if Mocks.Orgs.Update != nil {
+		return Mocks.Orgs.Update(ctx, id, displayName)
+	}
+
 	org, err := o.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -221,6 +234,10 @@ func (o *orgs) Update(ctx context.Context, id int32, displayName *string) (*type
 }
 
 func (o *orgs) Delete(ctx context.Context, id int32) error {
+	if Mocks.Orgs.Delete != nil {
+		return Mocks.Orgs.Delete(ctx, id)
+	}
+
 	// Wrap in transaction because we delete from multiple tables.
 	tx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 )
@@ -12,10 +13,11 @@ import (
 type queryRunnerState struct{}
 
 type SavedQueryInfo struct {
-	Query        string
-	LastExecuted time.Time
-	LatestResult time.Time
-	ExecDuration time.Duration
+	Query              string
+	LastExecuted       time.Time
+	LatestResult       time.Time
+	ExecDuration       time.Duration
+	ResultFingerprints []string
 }
 
 // Get gets the saved query information for the given query. nil
@@ -27,9 +29,9 @@ func (s *queryRunnerState) Get(ctx context.Context, query string) (*SavedQueryIn
 	var execDurationNs int64
 	err := dbconn.Global.QueryRowContext(
 		ctx,
-		"SELECT last_executed, latest_result, exec_duration_ns FROM query_runner_state WHERE query=$1",
+		"SELECT last_executed, latest_result, exec_duration_ns, result_fingerprints FROM query_runner_state WHERE query=$1",
 		query,
-	).Scan(&info.LastExecuted, &info.LatestResult, &execDurationNs)
+	).Scan(&info.LastExecuted, &info.LatestResult, &execDurationNs, pq.Array(&info.ResultFingerprints))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -47,10 +49,11 @@ func (s *queryRunnerState) Get(ctx context.Context, query string) (*SavedQueryIn
 func (s *queryRunnerState) Set(ctx context.Context, info *SavedQueryInfo) error {
 	res, err := dbconn.Global.ExecContext(
 		ctx,
-		"UPDATE query_runner_state SET last_executed=$1, latest_result=$2, exec_duration_ns=$3 WHERE query=$4",
+		"UPDATE query_runner_state SET last_executed=$1, latest_result=$2, exec_duration_ns=$3, result_fingerprints=$4 WHERE query=$5",
 		info.LastExecuted,
 		info.LatestResult,
 		int64(info.ExecDuration),
+		pq.Array(info.ResultFingerprints),
 		info.Query,
 	)
 	if err != nil {
@@ -64,11 +67,12 @@ func (s *queryRunnerState) Set(ctx context.Context, info *SavedQueryInfo) error
 		// Didn't update any row, so insert a new one.
 		_, err := dbconn.Global.ExecContext(
 			ctx,
-			"INSERT INTO query_runner_state(query, last_executed, latest_result, exec_duration_ns) VALUES($1, $2, $3, $4)",
+			"INSERT INTO query_runner_state(query, last_executed, latest_result, exec_duration_ns, result_fingerprints) VALUES($1, $2, $3, $4, $5)",
 			info.Query,
 			info.LastExecuted,
 			info.LatestResult,
 			int64(info.ExecDuration),
+			pq.Array(info.ResultFingerprints),
 		)
 		if err != nil {
 			return errors.Wrap(err, "INSERT")
@@ -56,7 +56,10 @@ func (s *savedSearches) ListAll(ctx context.Context) (savedSearches []api.SavedQ
 		notify_slack,
 		user_id,
 		org_id,
-		slack_webhook_url FROM saved_searches
+		slack_webhook_url,
+		notify_webhook,
+		webhook_url,
+		webhook_secret FROM saved_searches
 	`)
 	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar))
 	if err != nil {
@@ -73,7 +76,10 @@ func (s *savedSearches) ListAll(ctx context.Context) (savedSearches []api.SavedQ
 			&sq.Config.NotifySlack,
 			&sq.Config.UserID,
 			&sq.Config.OrgID,
-			&sq.Config.SlackWebhookURL); err != nil {
+			&sq.Config.SlackWebhookURL,
+			&sq.Config.NotifyWebhook,
+			&sq.Config.WebhookURL,
+			&sq.Config.WebhookSecret); err != nil {
 			return nil, errors.Wrap(err, "Scan")
 		}
 		sq.Spec.Key = sq.Config.Key
@@ -106,7 +112,10 @@ func (s *savedSearches) GetByID(ctx context.Context, id int32) (*api.SavedQueryS
 		notify_slack,
 		user_id,
 		org_id,
-		slack_webhook_url
+		slack_webhook_url,
+		notify_webhook,
+		webhook_url,
+		webhook_secret
 		FROM saved_searches WHERE id=$1`, id).Scan(
 		&sq.Config.Key,
 		&sq.Config.Description,
@@ -115,7 +124,10 @@ func (s *savedSearches) GetByID(ctx context.Context, id int32) (*api.SavedQueryS
 		&sq.Config.NotifySlack,
 		&sq.Config.UserID,
 		&sq.Config.OrgID,
-		&sq.Config.SlackWebhookURL)
+		&sq.Config.SlackWebhookURL,
+		&sq.Config.NotifyWebhook,
+		&sq.Config.WebhookURL,
+		&sq.Config.WebhookSecret)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +178,10 @@ func (s *savedSearches) ListSavedSearchesByUserID(ctx context.Context, userID in
 		notify_slack,
 		user_id,
 		org_id,
-		slack_webhook_url
+		slack_webhook_url,
+		notify_webhook,
+		webhook_url,
+		webhook_secret
 		FROM saved_searches %v`, conds)
 
 	rows, err := dbconn.Global.QueryContext(ctx, query.Query(sqlf.PostgresBindVar), query.Args()...)
@@ -175,7 +190,7 @@ func (s *savedSearches) ListSavedSearchesByUserID(ctx context.Context, userID in
 	}
 	for rows.Next() {
 		var ss types.SavedSearch
-		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.UserID, &ss.OrgID, &ss.SlackWebhookURL); err != nil {
+		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.UserID, &ss.OrgID, &ss.SlackWebhookURL, &ss.NotifyWebhook, &ss.WebhookURL, &ss.WebhookSecret); err != nil {
 			return nil, errors.Wrap(err, "Scan(2)")
 		}
 		savedSearches = append(savedSearches, &ss)
@@ -201,7 +216,10 @@ func (s *savedSearches) ListSavedSearchesByOrgID(ctx context.Context, orgID int3
 		notify_slack,
 		user_id,
 		org_id,
-		slack_webhook_url
+		slack_webhook_url,
+		notify_webhook,
+		webhook_url,
+		webhook_secret
 		FROM saved_searches %v`, conds)
 
 	rows, err := dbconn.Global.QueryContext(ctx, query.Query(sqlf.PostgresBindVar), query.Args()...)
@@ -210,7 +228,7 @@ func (s *savedSearches) ListSavedSearchesByOrgID(ctx context.Context, orgID int3
 	}
 	for rows.Next() {
 		var ss types.SavedSearch
-		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.UserID, &ss.OrgID, &ss.SlackWebhookURL); err != nil {
+		if err := rows.Scan(&ss.ID, &ss.Description, &ss.Query, &ss.Notify, &ss.NotifySlack, &ss.UserID, &ss.OrgID, &ss.SlackWebhookURL, &ss.NotifyWebhook, &ss.WebhookURL, &ss.WebhookSecret); err != nil {
 			return nil, errors.Wrap(err, "Scan")
 		}
 		savedSearches = append(savedSearches, &ss)
@@ -240,12 +258,15 @@ func (s *savedSearches) Create(ctx context.Context, newSavedSearch *types.SavedS
 	}()
 
 	savedQuery = &types.SavedSearch{
-		Description: newSavedSearch.Description,
-		Query:       newSavedSearch.Query,
-		Notify:      newSavedSearch.Notify,
-		NotifySlack: newSavedSearch.NotifySlack,
-		UserID:      newSavedSearch.UserID,
-		OrgID:       newSavedSearch.OrgID,
+		Description:   newSavedSearch.Description,
+		Query:         newSavedSearch.Query,
+		Notify:        newSavedSearch.Notify,
+		NotifySlack:   newSavedSearch.NotifySlack,
+		UserID:        newSavedSearch.UserID,
+		OrgID:         newSavedSearch.OrgID,
+		NotifyWebhook: newSavedSearch.NotifyWebhook,
+		WebhookURL:    newSavedSearch.WebhookURL,
+		WebhookSecret: newSavedSearch.WebhookSecret,
 	}
 
 	err = dbconn.Global.QueryRowContext(ctx, `INSERT INTO saved_searches(
@@ -254,14 +275,20 @@ func (s *savedSearches) Create(ctx context.Context, newSavedSearch *types.SavedS
 			notify_owner,
 			notify_slack,
 			user_id,
-			org_id
-		) VALUES($1, $2, $3, $4, $5, $6) RETURNING id`,
+			org_id,
+			notify_webhook,
+			webhook_url,
+			webhook_secret
+		) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
 		newSavedSearch.Description,
 		newSavedSearch.Query,
 		newSavedSearch.Notify,
 		newSavedSearch.NotifySlack,
 		newSavedSearch.UserID,
 		newSavedSearch.OrgID,
+		newSavedSearch.NotifyWebhook,
+		newSavedSearch.WebhookURL,
+		newSavedSearch.WebhookSecret,
 	).Scan(&savedQuery.ID)
 	if err != nil {
 		return nil, err
@@ -293,6 +320,9 @@ func (s *savedSearches) Update(ctx context.Context, savedSearch *types.SavedSear
 		UserID:          savedSearch.UserID,
 		OrgID:           savedSearch.OrgID,
 		SlackWebhookURL: savedSearch.SlackWebhookURL,
+		NotifyWebhook:   savedSearch.NotifyWebhook,
+		WebhookURL:      savedSearch.WebhookURL,
+		WebhookSecret:   savedSearch.WebhookSecret,
 	}
 
 	fieldUpdates := []*sqlf.Query{
@@ -304,6 +334,9 @@ func (s *savedSearches) Update(ctx context.Context, savedSearch *types.SavedSear
 		sqlf.Sprintf("user_id=%v", savedSearch.UserID),
 		sqlf.Sprintf("org_id=%v", savedSearch.OrgID),
 		sqlf.Sprintf("slack_webhook_url=%v", savedSearch.SlackWebhookURL),
+		sqlf.Sprintf("notify_webhook=%t", savedSearch.NotifyWebhook),
+		sqlf.Sprintf("webhook_url=%v", savedSearch.WebhookURL),
+		sqlf.Sprintf("webhook_secret=%v", savedSearch.WebhookSecret),
 	}
 
 	updateQuery := sqlf.Sprintf(`UPDATE saved_searches SET %s WHERE ID=%v RETURNING id`, sqlf.Join(fieldUpdates, ", "), savedSearch.ID)
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+var MockSubRepoPathMatchers func(ctx context.Context, repoIDs []api.RepoID) (map[api.RepoID]*authz.SubRepoPathMatcher, error)
+
+// SubRepoPathMatchers is the enforcement mechanism for sub-repository permissions. It
+// returns matchers for the paths that the currently authenticated user may read in each of
+// the given repositories. Repositories without a matcher in the returned map are readable in
+// full, so callers can pass the (possibly nil) matcher of a repository to code that enforces
+// it without checking whether it exists.
+//
+// 🚨 SECURITY: Repositories on a code host with sub-repository permissions for which the user
+// has no stored permissions get a matcher that matches no path. Repository permissions can be
+// granted before the sub-repository permissions of the user are synced (e.g. by a
+// repository-centric sync), so a missing entry must not grant access to the whole repository.
+//
+// It complements authzFilter, which must have been used to check that the user has read
+// access to the repositories in the first place.
+func SubRepoPathMatchers(ctx context.Context, repoIDs ...api.RepoID) (map[api.RepoID]*authz.SubRepoPathMatcher, error) {
+	if MockSubRepoPathMatchers != nil {
+		return MockSubRepoPathMatchers(ctx, repoIDs)
+	}
+
+	if len(repoIDs) == 0 || isInternalActor(ctx) {
+		return nil, nil
+	}
+	serviceIDs := subRepoPermsServiceIDs()
+	if len(serviceIDs) == 0 {
+		return nil, nil
+	}
+
+	// 🚨 SECURITY: Anonymous users can only access repositories that are readable by
+	// everyone, which aren't subject to sub-repository permissions.
+	if !actor.FromContext(ctx).IsAuthenticated() {
+		return nil, nil
+	}
+	currentUser, err := Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.SiteAdmin {
+		return nil, nil
+	}
+
+	perms, err := Authz.SubRepoPermissions(ctx, &SubRepoPermissionsArgs{
+		UserID:  currentUser.ID,
+		RepoIDs: repoIDs,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "load sub-repository permissions")
+	}
+
+	matchers := make(map[api.RepoID]*authz.SubRepoPathMatcher, len(perms))
+	for repoID, p := range perms {
+		m, err := p.Compile()
+		if err != nil {
+			return nil, err
+		}
+		matchers[repoID] = m
+	}
+
+	var missing []api.RepoID
+	for _, id := range repoIDs {
+		if _, ok := perms[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return matchers, nil
+	}
+	repos, err := Repos.GetByIDs(ctx, missing...)
+	if err != nil {
+		return nil, errors.Wrap(err, "get repositories")
+	}
+	readable := make(map[api.RepoID]bool, len(repos))
+	for _, r := range repos {
+		readable[r.ID] = !serviceIDs[r.ExternalRepo.ServiceID]
+	}
+	for _, id := range missing {
+		if !readable[id] {
+			matchers[id] = noSubRepoPathMatcher
+		}
+	}
+	return matchers, nil
+}
+
+// noSubRepoPathMatcher matches no path.
+var noSubRepoPathMatcher, _ = (&authz.SubRepoPermissions{}).Compile()
+
+// SubRepoPathMatcher returns the matcher for the paths that the currently authenticated
+// user may read in the given repository, or nil if the repository is readable in full.
+func SubRepoPathMatcher(ctx context.Context, repoID api.RepoID) (*authz.SubRepoPathMatcher, error) {
+	matchers, err := SubRepoPathMatchers(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	return matchers[repoID], nil
+}
+
+// subRepoPermsServiceIDs returns the set of service IDs of the authz providers that support
+// sub-repository permissions. Without one, there are no sub-repository permissions to load.
+func subRepoPermsServiceIDs() map[string]bool {
+	_, providers := authz.GetProviders()
+	serviceIDs := make(map[string]bool)
+	for _, p := range providers {
+		if _, ok := p.(authz.SubRepoPermsProvider); ok {
+			serviceIDs[p.ServiceID()] = true
+		}
+	}
+	return serviceIDs
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+type mockSubRepoPermsProvider struct {
+	MockAuthzProvider
+}
+
+func (*mockSubRepoPermsProvider) FetchUserSubRepoPerms(context.Context, *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error) {
+	return nil, nil
+}
+
+func TestSubRepoPathMatchers(t *testing.T) {
+	defer func() {
+		Mocks = MockStores{}
+		authz.SetProviders(true, nil)
+	}()
+
+	var siteAdmin bool
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID, SiteAdmin: siteAdmin}, nil
+	}
+	Mocks.Authz.SubRepoPermissions = func(ctx context.Context, args *SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error) {
+		if args.UserID != 1 {
+			t.Errorf("got user ID %d, want 1", args.UserID)
+		}
+		return map[api.RepoID]*authz.SubRepoPermissions{
+			2: {PathIncludes: []string{"docs/**"}},
+		}, nil
+	}
+
+	Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error) {
+		// Repository 4 is not readable by the user.
+		repos := map[api.RepoID]*types.Repo{
+			1: {ID: 1, ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://gitlab.com/"}},
+			3: {ID: 3, ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://perforce.mine/"}},
+		}
+		var rs []*types.Repo
+		for _, id := range ids {
+			if r, ok := repos[id]; ok {
+				rs = append(rs, r)
+			}
+		}
+		return rs, nil
+	}
+
+	userCtx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+	t.Run("no sub-repository permissions provider", func(t *testing.T) {
+		authz.SetProviders(true, []authz.Provider{&MockAuthzProvider{}})
+		matchers, err := SubRepoPathMatchers(userCtx, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matchers) != 0 {
+			t.Errorf("got %d matchers, want none", len(matchers))
+		}
+	})
+
+	authz.SetProviders(true, []authz.Provider{
+		&MockAuthzProvider{serviceID: "https://gitlab.com/"},
+		&mockSubRepoPermsProvider{MockAuthzProvider{serviceID: "https://perforce.mine/"}},
+	})
+
+	t.Run("user", func(t *testing.T) {
+		matchers, err := SubRepoPathMatchers(userCtx, 1, 2, 3, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := matchers[1]; ok {
+			t.Error("got matcher for repo 1, want none")
+		}
+		if m := matchers[2]; m == nil || m.MatchPath("README.md") || !m.MatchPath("docs/index.md") {
+			t.Errorf("got matcher %v for repo 2, want one that only matches docs", m)
+		}
+		// Repositories on a code host with sub-repository permissions must not be readable
+		// before the sub-repository permissions of the user are synced.
+		for _, id := range []api.RepoID{3, 4} {
+			if m, ok := matchers[id]; !ok || m.MatchPath("README.md") || m.MatchDir("") {
+				t.Errorf("got matcher %v for repo %d, want one that matches nothing", m, id)
+			}
+		}
+	})
+
+	for name, ctx := range map[string]context.Context{
+		"anonymous user": context.Background(),
+		"internal actor": actor.WithActor(context.Background(), &actor.Actor{Internal: true}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			m, err := SubRepoPathMatcher(ctx, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m != nil {
+				t.Errorf("got matcher %v, want nil", m)
+			}
+		})
+	}
+
+	t.Run("site admin", func(t *testing.T) {
+		siteAdmin = true
+		defer func() { siteAdmin = false }()
+		m, err := SubRepoPathMatcher(userCtx, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m != nil {
+			t.Errorf("got matcher %v, want nil", m)
+		}
+	})
+}
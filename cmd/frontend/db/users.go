@@ -550,6 +550,77 @@ func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool)
 	return err
 }
 
+// InvalidateSessionsByID invalidates all of the user's existing sessions, so that they must sign in
+// again.
+func (u *users) InvalidateSessionsByID(ctx context.Context, id int32) error {
+	if Mocks.Users.InvalidateSessionsByID != nil {
+		return Mocks.Users.InvalidateSessionsByID(ctx, id)
+	}
+	res, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET invalidated_sessions_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
+// SetIsSuspended suspends or unsuspends the user. Suspending a user who is already suspended
+// leaves the time of their suspension unchanged.
+func (u *users) SetIsSuspended(ctx context.Context, id int32, suspended bool) error {
+	if Mocks.Users.SetIsSuspended != nil {
+		return Mocks.Users.SetIsSuspended(ctx, id, suspended)
+	}
+	res, err := dbconn.Global.ExecContext(ctx, `
+UPDATE users SET suspended_at=(CASE WHEN $2 THEN COALESCE(suspended_at, now()) ELSE NULL END), updated_at=now()
+WHERE id=$1 AND deleted_at IS NULL`, id, suspended)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
+// SuspendInactive suspends all users (other than site admins) who have been inactive since the
+// given time, invalidates their sessions, and returns the IDs of the users it suspended. A user is
+// inactive if they have no events in event_logs since then, none of their access tokens was used
+// since then (e.g., by a service account which only uses the API), and their account was not
+// updated (e.g., by being unsuspended) since then.
+func (u *users) SuspendInactive(ctx context.Context, since time.Time) (ids []int32, err error) {
+	if Mocks.Users.SuspendInactive != nil {
+		return Mocks.Users.SuspendInactive(ctx, since)
+	}
+	rows, err := dbconn.Global.QueryContext(ctx, `
+UPDATE users u SET suspended_at=now(), invalidated_sessions_at=now(), updated_at=now()
+WHERE u.deleted_at IS NULL AND u.suspended_at IS NULL AND NOT u.site_admin AND u.updated_at < $1
+AND NOT EXISTS (SELECT 1 FROM event_logs e WHERE e.user_id=u.id AND e.timestamp >= $1)
+AND NOT EXISTS (SELECT 1 FROM access_tokens t WHERE t.subject_user_id=u.id AND t.last_used_at >= $1)
+RETURNING u.id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // CheckAndDecrementInviteQuota should be called before the user (identified
 // by userID) is allowed to invite any other user. If ok is false, then the
 // user is not allowed to invite any other user (either because they've
@@ -665,6 +736,8 @@ type UsersListOptions struct {
 
 	Tag string // only include users with this tag
 
+	ExcludeSuspended bool // exclude suspended users
+
 	*LimitOffset
 }
 
@@ -710,6 +783,9 @@ func (*users) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	if opt.Tag != "" {
 		conds = append(conds, sqlf.Sprintf("%s::text = ANY(u.tags)", opt.Tag))
 	}
+	if opt.ExcludeSuspended {
+		conds = append(conds, sqlf.Sprintf("u.suspended_at IS NULL"))
+	}
 	return conds
 }
 
@@ -726,7 +802,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.invalidated_sessions_at, u.suspended_at FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +812,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.InvalidatedSessionsAt, &u.SuspendedAt)
 		if err != nil {
 			return nil, err
 		}
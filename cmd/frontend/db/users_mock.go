@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 )
@@ -13,6 +14,9 @@ type MockUsers struct {
 	Delete                       func(ctx context.Context, id int32) error
 	HardDelete                   func(ctx context.Context, id int32) error
 	SetIsSiteAdmin               func(id int32, isSiteAdmin bool) error
+	InvalidateSessionsByID       func(ctx context.Context, id int32) error
+	SetIsSuspended               func(ctx context.Context, id int32, suspended bool) error
+	SuspendInactive              func(ctx context.Context, since time.Time) ([]int32, error)
 	CheckAndDecrementInviteQuota func(ctx context.Context, userID int32) (bool, error)
 	GetByID                      func(ctx context.Context, id int32) (*types.User, error)
 	GetByUsername                func(ctx context.Context, username string) (*types.User, error)
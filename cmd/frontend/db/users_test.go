@@ -436,6 +436,161 @@ func TestUsers_Update(t *testing.T) {
 	}
 }
 
+func TestUsers_InvalidateSessionsByID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.InvalidatedSessionsAt != nil {
+		t.Errorf("got invalidated sessions at %v, want nil", user.InvalidatedSessionsAt)
+	}
+
+	if err := Users.InvalidateSessionsByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.InvalidatedSessionsAt == nil {
+		t.Error("got invalidated sessions at nil, want non-nil")
+	}
+
+	// Can't invalidate sessions of nonexistent user.
+	if err := Users.InvalidateSessionsByID(ctx, 12345); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
+func TestUsers_SetIsSuspended(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.SuspendedAt != nil {
+		t.Errorf("got suspended at %v, want nil", user.SuspendedAt)
+	}
+
+	if err := Users.SetIsSuspended(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.SuspendedAt == nil {
+		t.Fatal("got suspended at nil, want non-nil")
+	}
+	suspendedAt := *user.SuspendedAt
+
+	// Suspended users don't count toward the licensed user count.
+	if count, err := Users.Count(ctx, &UsersListOptions{ExcludeSuspended: true}); err != nil {
+		t.Fatal(err)
+	} else if count != 0 {
+		t.Errorf("got %d unsuspended users, want 0", count)
+	}
+	if count, err := Users.Count(ctx, nil); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Errorf("got %d users, want 1", count)
+	}
+
+	// Suspending again doesn't change the time of the suspension.
+	if err := Users.SetIsSuspended(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.SuspendedAt == nil || !user.SuspendedAt.Equal(suspendedAt) {
+		t.Errorf("got suspended at %v, want %v", user.SuspendedAt, suspendedAt)
+	}
+
+	if err := Users.SetIsSuspended(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	user, err = Users.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.SuspendedAt != nil {
+		t.Errorf("got suspended at %v, want nil", user.SuspendedAt)
+	}
+
+	// Can't suspend nonexistent user.
+	if err := Users.SetIsSuspended(ctx, 12345, true); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
+func TestUsers_SuspendInactive(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	var ids []int32
+	for _, username := range []string{"active", "inactive", "admin", "recent", "tokenuser"} {
+		user, err := Users.Create(ctx, NewUser{Username: username})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, user.ID)
+	}
+	active, inactive, admin, recent, tokenUser := ids[0], ids[1], ids[2], ids[3], ids[4]
+	if _, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET updated_at=now()-interval '30 days' WHERE id <> $1", recent); err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.SetIsSiteAdmin(ctx, admin, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := EventLogs.Insert(ctx, &Event{Name: "ViewRepository", URL: "http://example.com", UserID: uint32(active), Source: "WEB", Timestamp: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	// A user who only uses the API (e.g., a service account) has no events, but is active.
+	_, token, err := AccessTokens.Create(ctx, tokenUser, []string{"a"}, "n", tokenUser, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, token, []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	suspended, err := Users.SuspendInactive(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{inactive}; !reflect.DeepEqual(suspended, want) {
+		t.Errorf("got suspended users %v, want %v", suspended, want)
+	}
+	for _, id := range ids {
+		user, err := Users.GetByID(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := user.SuspendedAt != nil, id == inactive; got != want {
+			t.Errorf("user %s: got suspended %v, want %v", user.Username, got, want)
+		}
+		if got, want := user.InvalidatedSessionsAt != nil, id == inactive; got != want {
+			t.Errorf("user %s: got invalidated sessions %v, want %v", user.Username, got, want)
+		}
+	}
+}
+
 func TestUsers_GetByVerifiedEmail(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -17,9 +20,10 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User      graphql.ID
+	Scopes    []string
+	Note      string
+	ExpiresAt *DateTime
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,13 +50,14 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasUserScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
-		case authz.ScopeUserAll:
-			hasUserAllScope = true
+		case authz.ScopeUserAll, authz.ScopeSearchRead, authz.ScopeRepoRead, authz.ScopeCampaignsWrite,
+			authz.ScopeCodeIntelUpload, authz.ScopeSettingsWrite, authz.ScopeAdminRead:
+			hasUserScope = true
 		case authz.ScopeSiteAdminSudo:
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
@@ -67,12 +72,39 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasUserScope {
+		return nil, fmt.Errorf("all access tokens must have at least one scope other than %q (valid scopes: %q)", authz.ScopeSiteAdminSudo, authz.UserScopes)
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
-	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
+	var expiresAt *time.Time
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.Time.After(time.Now()) {
+			return nil, errors.New("access token expiration date must be in the future")
+		}
+		expiresAt = &args.ExpiresAt.Time
+	}
+
+	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditAccessTokenCreate,
+		TargetType: "access_token",
+		Target:     strconv.FormatInt(id, 10),
+		After:      accessTokenAuditDescription(userID, args.Scopes, args.Note, expiresAt),
+	})
+	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
+}
+
+// accessTokenAuditDescription describes an access token (without its secret
+// value) for the audit log.
+func accessTokenAuditDescription(subjectUserID int32, scopes []string, note string, expiresAt *time.Time) string {
+	desc := fmt.Sprintf("subjectUserID: %d\nscopes: %s\nnote: %q", subjectUserID, strings.Join(scopes, ", "), note)
+	if expiresAt != nil {
+		desc += fmt.Sprintf("\nexpiresAt: %s", expiresAt.UTC().Format(time.RFC3339))
+	}
+	return desc
 }
 
 type createAccessTokenResult struct {
@@ -115,6 +147,12 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := db.AccessTokens.DeleteByID(ctx, token.ID, token.SubjectUserID); err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditAccessTokenDelete,
+			TargetType: "access_token",
+			Target:     strconv.FormatInt(token.ID, 10),
+			Before:     accessTokenAuditDescription(token.SubjectUserID, token.Scopes, token.Note, token.ExpiresAt),
+		})
 
 	case args.ByToken != nil:
 		// 🚨 SECURITY: This is easier than the ByID case because anyone holding the access token's
@@ -122,6 +160,10 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 		if err := db.AccessTokens.DeleteByToken(ctx, *args.ByToken); err != nil {
 			return nil, err
 		}
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditAccessTokenDelete,
+			TargetType: "access_token",
+		})
 	}
 
 	return &EmptyResponse{}, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,7 +18,7 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
-		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
 			}
@@ -32,6 +33,12 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			}
 			return 1, "t", nil
 		}
+		db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+			if want := backend.AuditAccessTokenCreate; e.Action != want {
+				t.Errorf("got action %q, want %q", e.Action, want)
+			}
+			return nil
+		}
 	}
 
 	const uid1GQLID = "VXNlcjox"
@@ -76,6 +83,64 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using fine-grained scopes and expiration", func(t *testing.T) {
+		resetMocks()
+		mockAccessTokensCreate(t, 1, []string{authz.ScopeRepoRead, authz.ScopeSearchRead})
+		expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+		createAccessToken := db.Mocks.AccessTokens.Create
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, gotExpiresAt *time.Time) (int64, string, error) {
+			if gotExpiresAt == nil || !gotExpiresAt.Equal(expiresAt) {
+				t.Errorf("got expiresAt %v, want %v", gotExpiresAt, expiresAt)
+			}
+			return createAccessToken(subjectUserID, scopes, note, creatorUserID, gotExpiresAt)
+		}
+
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t),
+				Query: `
+				mutation {
+					createAccessToken(user: "` + uid1GQLID + `", scopes: ["search:read", "repo:read"], note: "n", expiresAt: "` + expiresAt.Format(time.RFC3339) + `") {
+						id
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"createAccessToken": {
+						"id": "QWNjZXNzVG9rZW46MQ=="
+					}
+				}
+			`,
+			},
+		})
+	})
+
+	for name, args := range map[string]*createAccessTokenInput{
+		"no scopes":              {User: uid1GQLID, Note: "n"},
+		"unknown scope":          {User: uid1GQLID, Scopes: []string{"repo:write"}, Note: "n"},
+		"only site-admin scope":  {User: uid1GQLID, Scopes: []string{authz.ScopeSiteAdminSudo}, Note: "n"},
+		"expiration in the past": {User: uid1GQLID, Scopes: []string{authz.ScopeRepoRead}, Note: "n", ExpiresAt: &DateTime{Time: time.Now().Add(-time.Hour)}},
+	} {
+		t.Run("authenticated as site admin, using invalid arguments: "+name, func(t *testing.T) {
+			resetMocks()
+			db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+				return &types.User{ID: 1, SiteAdmin: true}, nil
+			}
+			defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+			result, err := (&schemaResolver{}).CreateAccessToken(ctx, args)
+			if err == nil {
+				t.Error("err == nil")
+			}
+			if result != nil {
+				t.Errorf("got result %v, want nil", result)
+			}
+		})
+	}
+
 	t.Run("authenticated as user, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
@@ -220,6 +285,12 @@ func TestMutation_DeleteAccessToken(t *testing.T) {
 			}
 			return &db.AccessToken{ID: 1, SubjectUserID: 2}, nil
 		}
+		db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+			if want := backend.AuditAccessTokenDelete; e.Action != want {
+				t.Errorf("got action %q, want %q", e.Action, want)
+			}
+			return nil
+		}
 	}
 
 	token1GQLID := graphql.ID("QWNjZXNzVG9rZW46MQ==")
@@ -0,0 +1,136 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func (r *siteResolver) AuditLog(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+	Actor      *graphql.ID
+	Action     *string
+	TargetType *string
+	Since      *DateTime
+	Until      *DateTime
+}) (*auditLogConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can read the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var opt db.AuditLogListOptions
+	if args.Actor != nil {
+		var err error
+		opt.ActorUserID, err = UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Action != nil {
+		opt.Action = *args.Action
+	}
+	if args.TargetType != nil {
+		opt.TargetType = *args.TargetType
+	}
+	if args.Since != nil {
+		opt.Since = &args.Since.Time
+	}
+	if args.Until != nil {
+		opt.Until = &args.Until.Time
+	}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &auditLogConnectionResolver{opt: opt}, nil
+}
+
+// auditLogConnectionResolver resolves a list of audit log entries.
+//
+// 🚨 SECURITY: When instantiating an auditLogConnectionResolver value, the caller MUST check
+// permissions.
+type auditLogConnectionResolver struct {
+	opt db.AuditLogListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*types.AuditLogEntry
+	err     error
+}
+
+func (r *auditLogConnectionResolver) compute(ctx context.Context) ([]*types.AuditLogEntry, error) {
+	r.once.Do(func() {
+		opt2 := r.opt
+		if opt2.LimitOffset != nil {
+			tmp := *opt2.LimitOffset
+			opt2.LimitOffset = &tmp
+			opt2.Limit++ // so we can detect if there is a next page
+		}
+
+		r.entries, r.err = db.AuditLog.List(ctx, opt2)
+	})
+	return r.entries, r.err
+}
+
+func (r *auditLogConnectionResolver) Nodes(ctx context.Context) ([]*auditLogEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.LimitOffset.Limit {
+		entries = entries[:r.opt.LimitOffset.Limit]
+	}
+
+	l := make([]*auditLogEntryResolver, 0, len(entries))
+	for _, entry := range entries {
+		l = append(l, &auditLogEntryResolver{entry: entry})
+	}
+	return l, nil
+}
+
+func (r *auditLogConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.AuditLog.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *auditLogConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(entries) > r.opt.Limit), nil
+}
+
+type auditLogEntryResolver struct {
+	entry *types.AuditLogEntry
+}
+
+func (r *auditLogEntryResolver) CreatedAt() DateTime { return DateTime{Time: r.entry.CreatedAt} }
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (*UserResolver, error) {
+	if r.entry.ActorUserID == nil {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, *r.entry.ActorUserID)
+	if err != nil && errcode.IsNotFound(err) {
+		// Don't throw an error if a user has been deleted.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *auditLogEntryResolver) ActorIP() string { return r.entry.ActorIP }
+
+func (r *auditLogEntryResolver) Action() string { return r.entry.Action }
+
+func (r *auditLogEntryResolver) TargetType() string { return r.entry.TargetType }
+
+func (r *auditLogEntryResolver) Target() string { return r.entry.Target }
+
+func (r *auditLogEntryResolver) Diff() string { return r.entry.Diff }
+
+func (r *auditLogEntryResolver) Hash() string { return r.entry.Hash }
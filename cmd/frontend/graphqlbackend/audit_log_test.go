@@ -0,0 +1,112 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestSiteAuditLog(t *testing.T) {
+	t.Run("non-admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&siteResolver{}).AuditLog(ctx, &struct {
+			graphqlutil.ConnectionArgs
+			Actor      *graphql.ID
+			Action     *string
+			TargetType *string
+			Since      *DateTime
+			Until      *DateTime
+		}{})
+		if err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+		}
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+			return &types.User{ID: id, Username: "alice"}, nil
+		}
+		since := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+		checkOpt := func(opt db.AuditLogListOptions) {
+			if opt.ActorUserID != 1 || opt.Action != backend.AuditUserSetSiteAdmin || opt.TargetType != "" || opt.Since == nil || !opt.Since.Equal(since) || opt.Until != nil {
+				t.Errorf("unexpected options %+v", opt)
+			}
+		}
+		db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*types.AuditLogEntry, error) {
+			checkOpt(opt)
+			uid := int32(1)
+			return []*types.AuditLogEntry{
+				{ActorUserID: &uid, ActorIP: "10.0.0.1", Action: backend.AuditUserSetSiteAdmin, TargetType: "user", Target: "bob", Diff: "-siteAdmin: false\n+siteAdmin: true", Hash: "h2"},
+				{ActorUserID: &uid, ActorIP: "10.0.0.1", Action: backend.AuditUserSetSiteAdmin, TargetType: "user", Target: "carol", Hash: "h1"},
+			}, nil
+		}
+		db.Mocks.AuditLog.Count = func(opt db.AuditLogListOptions) (int, error) {
+			checkOpt(opt)
+			return 2, nil
+		}
+
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t),
+				Query: `
+				{
+					site {
+						auditLog(first: 1, actor: "VXNlcjox", action: "user.set_site_admin", since: "2020-04-01T00:00:00Z") {
+							nodes {
+								actor { username }
+								actorIP
+								action
+								targetType
+								target
+								diff
+								hash
+							}
+							totalCount
+							pageInfo { hasNextPage }
+						}
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"site": {
+						"auditLog": {
+							"nodes": [
+								{
+									"actor": { "username": "alice" },
+									"actorIP": "10.0.0.1",
+									"action": "user.set_site_admin",
+									"targetType": "user",
+									"target": "bob",
+									"diff": "-siteAdmin: false\n+siteAdmin: true",
+									"hash": "h2"
+								}
+							],
+							"totalCount": 2,
+							"pageInfo": { "hasNextPage": true }
+						}
+					}
+				}
+			`,
+			},
+		})
+	})
+}
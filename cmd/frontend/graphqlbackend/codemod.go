@@ -16,7 +16,9 @@ import (
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
@@ -197,9 +199,22 @@ func performCodemod(ctx context.Context, args *search.TextParameters) ([]SearchR
 		return nil, nil, err
 	}
 
+	repoIDs := make([]api.RepoID, len(args.Repos))
+	for i, repoRev := range args.Repos {
+		repoIDs[i] = repoRev.Repo.ID
+	}
+	matchers, err := db.SubRepoPathMatchers(ctx, repoIDs...)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var results []SearchResultResolver
 	for _, ur := range unflattened {
 		for _, resolver := range ur {
+			// 🚨 SECURITY: Remove the changes to paths that the user may not read.
+			if !matchers[resolver.commit.repo.repo.ID].MatchPath(resolver.path) {
+				continue
+			}
 			v := resolver
 			results = append(results, &v)
 		}
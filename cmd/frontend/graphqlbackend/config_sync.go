@@ -0,0 +1,90 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/configsync"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func (r *siteResolver) ConfigSync(ctx context.Context) (*configSyncStatusResolver, error) {
+	// 🚨 SECURITY: Only site admins can view the config sync status, because
+	// its errors may contain parts of the site configuration.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	status, err := configsync.CurrentStatus(ctx)
+	if err != nil || status == nil {
+		return nil, err
+	}
+	return &configSyncStatusResolver{status: status}, nil
+}
+
+type configSyncStatusResolver struct {
+	status *configsync.Status
+}
+
+func (r *configSyncStatusResolver) RepositoryName() string { return string(r.status.Repo) }
+
+func (r *configSyncStatusResolver) Path() string { return r.status.Path }
+
+func (r *configSyncStatusResolver) Commit() *string {
+	if r.status.Commit == "" {
+		return nil
+	}
+	return strptr(string(r.status.Commit))
+}
+
+func (r *configSyncStatusResolver) SyncedAt() *DateTime {
+	if r.status.SyncedAt.IsZero() {
+		return nil
+	}
+	return &DateTime{Time: r.status.SyncedAt}
+}
+
+func (r *configSyncStatusResolver) Error() *string {
+	if r.status.Err == nil {
+		return nil
+	}
+	return strptr(r.status.Err.Error())
+}
+
+func (r *configSyncStatusResolver) Changes() []string {
+	if r.status.Changes == nil {
+		return []string{}
+	}
+	return r.status.Changes
+}
+
+func (r *configSyncStatusResolver) Drift() []*configSyncDriftResolver {
+	l := make([]*configSyncDriftResolver, 0, len(r.status.Drift))
+	for _, d := range r.status.Drift {
+		l = append(l, &configSyncDriftResolver{drift: d})
+	}
+	return l
+}
+
+type configSyncDriftResolver struct {
+	drift configsync.Drift
+}
+
+func (r *configSyncDriftResolver) Kind() string { return r.drift.Kind }
+
+func (r *configSyncDriftResolver) Name() *string {
+	if r.drift.Name == "" {
+		return nil
+	}
+	return &r.drift.Name
+}
+
+func (r *configSyncDriftResolver) Author(ctx context.Context) (*UserResolver, error) {
+	user, err := UserByIDInt32(ctx, r.drift.AuthorUserID)
+	if err != nil && errcode.IsNotFound(err) {
+		// Don't throw an error if a user has been deleted.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *configSyncDriftResolver) EditedAt() DateTime { return DateTime{Time: r.drift.EditedAt} }
@@ -47,6 +47,12 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Create(ctx, conf.Get, externalService); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditExternalServiceAdd,
+		TargetType: "external_service",
+		Target:     strconv.FormatInt(externalService.ID, 10),
+		After:      backend.ExternalServiceAuditDescription(externalService),
+	})
 
 	res := &externalServiceResolver{externalService: externalService}
 	if err := syncExternalService(ctx, externalService); err != nil {
@@ -80,6 +86,11 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 		return nil, fmt.Errorf("blank external service configuration is invalid (must be valid JSONC)")
 	}
 
+	prev, err := db.ExternalServices.GetByID(ctx, externalServiceID)
+	if err != nil {
+		return nil, err
+	}
+
 	ps := conf.Get().AuthProviders
 	update := &db.ExternalServiceUpdate{
 		DisplayName: args.Input.DisplayName,
@@ -93,6 +104,13 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	if err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditExternalServiceUpdate,
+		TargetType: "external_service",
+		Target:     strconv.FormatInt(externalServiceID, 10),
+		Before:     backend.ExternalServiceAuditDescription(prev),
+		After:      backend.ExternalServiceAuditDescription(externalService),
+	})
 
 	res := &externalServiceResolver{externalService: externalService}
 	if err = syncExternalService(ctx, externalService); err != nil {
@@ -149,6 +167,12 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 	if err := db.ExternalServices.Delete(ctx, id); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditExternalServiceDelete,
+		TargetType: "external_service",
+		Target:     strconv.FormatInt(id, 10),
+		Before:     backend.ExternalServiceAuditDescription(externalService),
+	})
 	now := time.Now()
 	externalService.DeletedAt = &now
 
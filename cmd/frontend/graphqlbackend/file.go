@@ -18,6 +18,12 @@ func (r *GitTreeEntryResolver) Content(ctx context.Context) (string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	// 🚨 SECURITY: Tree entries can be resolved from other places than GitCommitResolver.Blob
+	// (such as locations), so check sub-repository permissions before reading the file.
+	if err := checkSubRepoPath(ctx, r.commit.repo.repo, r.Path(), false); err != nil {
+		return "", err
+	}
+
 	cachedRepo, err := backend.CachedGitRepo(ctx, r.commit.repo.repo)
 	if err != nil {
 		return "", err
@@ -101,6 +107,11 @@ func (r *GitTreeEntryResolver) Highlight(ctx context.Context, args *struct {
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
+	// 🚨 SECURITY: See Content.
+	if err := checkSubRepoPath(ctx, r.commit.repo.repo, r.Path(), false); err != nil {
+		return nil, err
+	}
+
 	cachedRepo, err := backend.CachedGitRepo(ctx, r.commit.repo.repo)
 	if err != nil {
 		return nil, err
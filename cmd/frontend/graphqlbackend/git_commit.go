@@ -186,6 +186,11 @@ func (r *GitCommitResolver) Tree(ctx context.Context, args *struct {
 	Path      string
 	Recursive bool
 }) (*GitTreeEntryResolver, error) {
+	// 🚨 SECURITY: Check sub-repository permissions before stat'ing the path, so that the
+	// existence of paths the user may not read isn't leaked.
+	if err := checkSubRepoPath(ctx, r.repo.repo, args.Path, true); err != nil {
+		return nil, err
+	}
 	cachedRepo, err := backend.CachedGitRepo(ctx, r.repo.repo)
 	if err != nil {
 		return nil, err
@@ -207,6 +212,10 @@ func (r *GitCommitResolver) Tree(ctx context.Context, args *struct {
 func (r *GitCommitResolver) Blob(ctx context.Context, args *struct {
 	Path string
 }) (*GitTreeEntryResolver, error) {
+	// 🚨 SECURITY: See Tree.
+	if err := checkSubRepoPath(ctx, r.repo.repo, args.Path, false); err != nil {
+		return nil, err
+	}
 	cachedRepo, err := backend.CachedGitRepo(ctx, r.repo.repo)
 	if err != nil {
 		return nil, err
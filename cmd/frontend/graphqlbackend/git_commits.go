@@ -40,6 +40,9 @@ func (r *gitCommitConnectionResolver) compute(ctx context.Context) ([]*git.Commi
 		var path string
 		if r.path != nil {
 			path = *r.path
+			if err := checkSubRepoHistoryPath(ctx, r.repo.repo, path); err != nil {
+				return nil, err
+			}
 		}
 		var author string
 		if r.author != nil {
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
@@ -52,6 +53,22 @@ func (r *GitTreeEntryResolver) entries(ctx context.Context, args *gitTreeEntryCo
 		}
 	}
 
+	// 🚨 SECURITY: Hide the entries that the user may not read due to sub-repository
+	// permissions, before limiting them.
+	matcher, err := db.SubRepoPathMatcher(ctx, r.commit.repo.repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if matcher != nil {
+		allowed := entries[:0]
+		for _, entry := range entries {
+			if entry.IsDir() && matcher.MatchDir(entry.Name()) || !entry.IsDir() && matcher.MatchPath(entry.Name()) {
+				allowed = append(allowed, entry)
+			}
+		}
+		entries = allowed
+	}
+
 	sort.Sort(byDirectory(entries))
 
 	if args.First != nil && len(entries) > int(*args.First) {
@@ -98,6 +98,12 @@ func (prometheusTracer) TraceField(ctx context.Context, label, typeName, fieldNa
 		ctx, finish = trace.OpenTracingTracer{}.TraceField(ctx, label, typeName, fieldName, trivial, args)
 	}
 
+	// 🚨 SECURITY: Access tokens with fine-grained scopes may only resolve the fields that their
+	// scopes grant.
+	if err := checkFieldScope(ctx, typeName, fieldName); err != nil {
+		ctx = scopeErrorContext{Context: ctx, err: err}
+	}
+
 	start := time.Now()
 	return ctx, func(err *gqlerrors.QueryError) {
 		isErrStr := strconv.FormatBool(err != nil)
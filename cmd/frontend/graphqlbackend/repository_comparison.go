@@ -10,7 +10,9 @@ import (
 	"sync"
 
 	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
@@ -176,6 +178,12 @@ func (r *fileDiffConnectionResolver) compute(ctx context.Context) ([]*diff.FileD
 			// flags or refer to a file.
 			return nil, fmt.Errorf("invalid diff range argument: %q", rangeSpec)
 		}
+		// 🚨 SECURITY: The diff hunks contain the contents of the changed files, so file diffs in
+		// paths that the current user may not read must be omitted.
+		matcher, err := db.SubRepoPathMatcher(ctx, r.cmp.repo.repo.ID)
+		if err != nil {
+			return nil, err
+		}
 		cachedRepo, err := backend.CachedGitRepo(ctx, r.cmp.repo.repo)
 		if err != nil {
 			return nil, err
@@ -195,37 +203,39 @@ func (r *fileDiffConnectionResolver) compute(ctx context.Context) ([]*diff.FileD
 		}
 		defer rdr.Close()
 
-		var fileDiffs []*diff.FileDiff
-		if r.first != nil {
-			fileDiffs = make([]*diff.FileDiff, 0, int(*r.first)) // preallocate
-		}
-		dr := diff.NewMultiFileDiffReader(rdr)
-		for {
-			fileDiff, err := dr.ReadFile()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				return nil, err
-			}
-			fileDiffs = append(fileDiffs, fileDiff)
-			if r.first != nil && len(fileDiffs) == int(*r.first) {
-				// Check for hasNextPage.
-				_, err := dr.ReadFile()
-				if err != nil && err != io.EOF {
-					return nil, err
-				}
-				r.hasNextPage = err != io.EOF
-				break
-			}
-		}
-		return fileDiffs, nil
+		fileDiffs, hasNextPage, err := readFileDiffs(diff.NewMultiFileDiffReader(rdr), matcher, r.first)
+		r.hasNextPage = hasNextPage
+		return fileDiffs, err
 	}
 
 	r.once.Do(func() { r.fileDiffs, r.err = do() })
 	return r.fileDiffs, r.err
 }
 
+// readFileDiffs reads the file diffs from dr (at most first, if non-nil), omitting the file diffs
+// that matcher does not allow, and reports whether dr has more file diffs that matcher allows.
+func readFileDiffs(dr *diff.MultiFileDiffReader, matcher *authz.SubRepoPathMatcher, first *int32) (fileDiffs []*diff.FileDiff, hasNextPage bool, err error) {
+	if first != nil {
+		fileDiffs = make([]*diff.FileDiff, 0, int(*first)) // preallocate
+	}
+	for {
+		fileDiff, err := dr.ReadFile()
+		if err == io.EOF {
+			return fileDiffs, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if !matchFileDiffBySubRepoPerms(matcher, fileDiff) {
+			continue
+		}
+		if first != nil && len(fileDiffs) == int(*first) {
+			return fileDiffs, true, nil
+		}
+		fileDiffs = append(fileDiffs, fileDiff)
+	}
+}
+
 func (r *fileDiffConnectionResolver) Nodes(ctx context.Context) ([]*fileDiffResolver, error) {
 	fileDiffs, err := r.compute(ctx)
 	if err != nil {
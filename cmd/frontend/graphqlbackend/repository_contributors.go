@@ -45,6 +45,9 @@ func (r *repositoryContributorConnectionResolver) compute(ctx context.Context) (
 			opt.Range = *r.args.RevisionRange
 		}
 		if r.args.Path != nil {
+			if r.err = checkSubRepoHistoryPath(ctx, r.repo.repo, *r.args.Path); r.err != nil {
+				return
+			}
 			opt.Path = *r.args.Path
 		}
 		if r.args.After != nil {
@@ -3,6 +3,7 @@ package graphqlbackend
 import (
 	"context"
 	"errors"
+	"net/url"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
@@ -59,6 +60,9 @@ func savedSearchByID(ctx context.Context, id graphql.ID) (*savedSearchResolver,
 			UserID:          ss.Config.UserID,
 			OrgID:           ss.Config.OrgID,
 			SlackWebhookURL: ss.Config.SlackWebhookURL,
+			NotifyWebhook:   ss.Config.NotifyWebhook,
+			WebhookURL:      ss.Config.WebhookURL,
+			WebhookSecret:   ss.Config.WebhookSecret,
 		},
 	}
 	return savedSearch, nil
@@ -97,6 +101,14 @@ func (r savedSearchResolver) OrgID() *graphql.ID {
 }
 func (r savedSearchResolver) SlackWebhookURL() *string { return r.s.SlackWebhookURL }
 
+func (r savedSearchResolver) NotifyWebhook() bool { return r.s.NotifyWebhook }
+
+func (r savedSearchResolver) WebhookURL() *string { return r.s.WebhookURL }
+
+func (r savedSearchResolver) HasWebhookSecret() bool {
+	return r.s.WebhookSecret != nil && *r.s.WebhookSecret != ""
+}
+
 func toSavedSearchResolver(entry types.SavedSearch) *savedSearchResolver {
 	return &savedSearchResolver{entry}
 }
@@ -142,12 +154,15 @@ func (r *schemaResolver) SendSavedSearchTestNotification(ctx context.Context, ar
 }
 
 func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	Description   string
+	Query         string
+	NotifyOwner   bool
+	NotifySlack   bool
+	OrgID         *graphql.ID
+	UserID        *graphql.ID
+	NotifyWebhook bool
+	WebhookURL    *string
+	WebhookSecret *string
 }) (*savedSearchResolver, error) {
 	var userID, orgID *int32
 	// 🚨 SECURITY: Make sure the current user has permission to create a saved search for the specified user or org.
@@ -176,14 +191,20 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 	if !queryHasPatternType(args.Query) {
 		return nil, errMissingPatternType
 	}
+	if err := validateSavedSearchWebhook(args.NotifyWebhook, args.WebhookURL); err != nil {
+		return nil, err
+	}
 
 	ss, err := db.SavedSearches.Create(ctx, &types.SavedSearch{
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      userID,
-		OrgID:       orgID,
+		Description:   args.Description,
+		Query:         args.Query,
+		Notify:        args.NotifyOwner,
+		NotifySlack:   args.NotifySlack,
+		UserID:        userID,
+		OrgID:         orgID,
+		NotifyWebhook: args.NotifyWebhook,
+		WebhookURL:    nonEmptyOrNil(args.WebhookURL),
+		WebhookSecret: nonEmptyOrNil(args.WebhookSecret),
 	})
 	if err != nil {
 		return nil, err
@@ -193,13 +214,16 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 }
 
 func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
-	ID          graphql.ID
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	ID            graphql.ID
+	Description   string
+	Query         string
+	NotifyOwner   bool
+	NotifySlack   bool
+	OrgID         *graphql.ID
+	UserID        *graphql.ID
+	NotifyWebhook bool
+	WebhookURL    *string
+	WebhookSecret *string
 }) (*savedSearchResolver, error) {
 	var userID, orgID *int32
 	// 🚨 SECURITY: Make sure the current user has permission to update a saved search for the specified user or org.
@@ -233,15 +257,35 @@ func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
 	if !queryHasPatternType(args.Query) {
 		return nil, errMissingPatternType
 	}
+	if err := validateSavedSearchWebhook(args.NotifyWebhook, args.WebhookURL); err != nil {
+		return nil, err
+	}
+
+	// The webhook secret is never returned to clients, so a client that
+	// doesn't change it omits it. It is only kept for the same webhook URL,
+	// so that the signed payloads can't be redirected elsewhere.
+	webhookSecret := nonEmptyOrNil(args.WebhookSecret)
+	if args.WebhookSecret == nil && args.WebhookURL != nil {
+		old, err := db.SavedSearches.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if old.Config.WebhookURL != nil && args.WebhookURL != nil && *old.Config.WebhookURL == *args.WebhookURL {
+			webhookSecret = old.Config.WebhookSecret
+		}
+	}
 
 	ss, err := db.SavedSearches.Update(ctx, &types.SavedSearch{
-		ID:          id,
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      userID,
-		OrgID:       orgID,
+		ID:            id,
+		Description:   args.Description,
+		Query:         args.Query,
+		Notify:        args.NotifyOwner,
+		NotifySlack:   args.NotifySlack,
+		UserID:        userID,
+		OrgID:         orgID,
+		NotifyWebhook: args.NotifyWebhook,
+		WebhookURL:    nonEmptyOrNil(args.WebhookURL),
+		WebhookSecret: webhookSecret,
 	})
 	if err != nil {
 		return nil, err
@@ -287,3 +331,26 @@ func queryHasPatternType(query string) bool {
 }
 
 var errMissingPatternType error = errors.New("a `patternType:` filter is required in the query for all saved searches. `patternType` can be \"literal\" or \"regexp\"")
+
+// validateSavedSearchWebhook returns an error if notifyWebhook is set without
+// a valid http(s) webhook URL.
+func validateSavedSearchWebhook(notifyWebhook bool, webhookURL *string) error {
+	if webhookURL == nil || *webhookURL == "" {
+		if notifyWebhook {
+			return errors.New("a webhook URL is required to send webhook notifications")
+		}
+		return nil
+	}
+	u, err := url.Parse(*webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("the webhook URL must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func nonEmptyOrNil(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
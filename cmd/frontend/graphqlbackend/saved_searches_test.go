@@ -61,12 +61,15 @@ func TestCreateSavedSearch(t *testing.T) {
 	}
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
 	}{Description: "test query", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -91,12 +94,15 @@ func TestCreateSavedSearch(t *testing.T) {
 
 	// Ensure create saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
 	}{Description: "test query", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for createSavedSearch when query does not provide a patternType: field.")
@@ -119,13 +125,16 @@ func TestUpdateSavedSearch(t *testing.T) {
 	}
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID            graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -151,19 +160,89 @@ func TestUpdateSavedSearch(t *testing.T) {
 
 	// Ensure update saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID            graphql.ID
+		Description   string
+		Query         string
+		NotifyOwner   bool
+		NotifySlack   bool
+		OrgID         *graphql.ID
+		UserID        *graphql.ID
+		NotifyWebhook bool
+		WebhookURL    *string
+		WebhookSecret *string
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for updateSavedSearch when query does not provide a patternType: field.")
 	}
 }
 
+func TestUpdateSavedSearch_webhook(t *testing.T) {
+	ctx := context.Background()
+	defer resetMocks()
+
+	key := int32(1)
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true, ID: key}, nil
+	}
+	oldURL, oldSecret := "https://example.com/hook", "s3cr3t"
+	db.Mocks.SavedSearches.GetByID = func(ctx context.Context, id int32) (*api.SavedQuerySpecAndConfig, error) {
+		return &api.SavedQuerySpecAndConfig{Config: api.ConfigSavedQuery{UserID: &key, NotifyWebhook: true, WebhookURL: &oldURL, WebhookSecret: &oldSecret}}, nil
+	}
+	var updated *types.SavedSearch
+	db.Mocks.SavedSearches.Update = func(ctx context.Context, savedSearch *types.SavedSearch) (*types.SavedSearch, error) {
+		updated = savedSearch
+		return savedSearch, nil
+	}
+
+	update := func(webhookURL, webhookSecret *string) error {
+		userID := MarshalUserID(key)
+		_, err := (&schemaResolver{}).UpdateSavedSearch(ctx, &struct {
+			ID            graphql.ID
+			Description   string
+			Query         string
+			NotifyOwner   bool
+			NotifySlack   bool
+			OrgID         *graphql.ID
+			UserID        *graphql.ID
+			NotifyWebhook bool
+			WebhookURL    *string
+			WebhookSecret *string
+		}{ID: marshalSavedSearchID(key), Description: "d", Query: "test type:diff patternType:regexp", UserID: &userID, NotifyWebhook: true, WebhookURL: webhookURL, WebhookSecret: webhookSecret})
+		return err
+	}
+	strPtr := func(s string) *string { return &s }
+
+	// An omitted secret is kept for the same URL.
+	if err := update(strPtr(oldURL), nil); err != nil {
+		t.Fatal(err)
+	}
+	if updated.WebhookSecret == nil || *updated.WebhookSecret != oldSecret {
+		t.Errorf("got webhook secret %v, want %q", updated.WebhookSecret, oldSecret)
+	}
+
+	// But not when the URL changes.
+	if err := update(strPtr("https://example.org/hook"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if updated.WebhookSecret != nil {
+		t.Errorf("got webhook secret %q for a new URL, want none", *updated.WebhookSecret)
+	}
+
+	// An empty secret removes it.
+	if err := update(strPtr(oldURL), strPtr("")); err != nil {
+		t.Fatal(err)
+	}
+	if updated.WebhookSecret != nil {
+		t.Errorf("got webhook secret %q, want none", *updated.WebhookSecret)
+	}
+
+	for _, webhookURL := range []*string{nil, strPtr(""), strPtr("ftp://example.com"), strPtr("/relative")} {
+		if err := update(webhookURL, nil); err == nil {
+			t.Errorf("expected an error for webhook URL %v", webhookURL)
+		}
+	}
+}
+
 func TestDeleteSavedSearch(t *testing.T) {
 	ctx := context.Background()
 	defer resetMocks()
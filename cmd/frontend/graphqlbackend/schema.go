@@ -204,9 +204,20 @@ type Mutation {
     # - "user:all": Full control of all resources accessible to the user account.
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
     #   with this scope.)
+    # - "search:read": Ability to run searches.
+    # - "repo:read": Ability to read repositories and their contents.
+    # - "campaigns:write": Ability to read, create, update and delete campaigns.
+    # - "codeintel:upload": Ability to upload LSIF data.
+    # - "settings:write": Ability to read and update settings.
+    # - "admin:read": Ability to read site administration data (the user must be a site admin).
+    #
+    # An access token must have at least one scope other than "site-admin:sudo". The "user:all" scope grants all
+    # other scopes except "site-admin:sudo".
+    #
+    # If expiresAt is set, the access token can't be used after that date.
     #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(user: ID!, scopes: [String!]!, note: String!, expiresAt: DateTime): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
@@ -321,7 +332,8 @@ type Mutation {
         argument: String
     ): EmptyResponse
     # Sends a test notification for the saved search. Be careful: this will send a notifcation (email and other
-    # types of notifications, if configured) to all subscribers of the saved search, which could be bothersome.
+    # types of notifications, such as Slack or webhook, if configured) to all subscribers of the saved search,
+    # which could be bothersome.
     #
     # Only subscribers to this saved search may perform this action.
     sendSavedSearchTestNotification(
@@ -352,12 +364,31 @@ type Mutation {
         # with this new value.
         input: String!
     ): Boolean!
+    # Restores the site configuration to an earlier version, by saving that version's contents as a new version.
+    # Returns whether or not a restart is required for the rollback to be applied.
+    #
+    # Only site admins may perform this mutation.
+    rollbackSiteConfiguration(
+        # The ID of the site configuration version to restore (see SiteConfiguration.history).
+        toID: Int!
+    ): Boolean!
     # Manages discussions.
     discussions: DiscussionsMutation
     # Sets whether the user with the specified user ID is a site admin.
     #
     # Only site admins may perform this mutation.
     setUserIsSiteAdmin(userID: ID!, siteAdmin: Boolean!): EmptyResponse
+    # Suspends a user. Suspended users can't sign in or use their access tokens, and they don't
+    # count toward the licensed user count. Suspending a user also signs them out. Unlike deleting
+    # a user, suspending a user keeps all of their data, and it can be undone with unsuspendUser.
+    #
+    # Only site admins may perform this mutation.
+    suspendUser(user: ID!): EmptyResponse
+    # Unsuspends a user who was suspended with suspendUser or automatically (because they were
+    # inactive), so that they can sign in and use their access tokens again.
+    #
+    # Only site admins may perform this mutation.
+    unsuspendUser(user: ID!): EmptyResponse
     # Reloads the site by restarting the server. This is not supported for all deployment
     # types. This may cause downtime.
     #
@@ -381,6 +412,13 @@ type Mutation {
         notifySlack: Boolean!
         orgID: ID
         userID: ID
+        # Whether to POST new results of the saved search to webhookURL.
+        notifyWebhook: Boolean = false
+        # The URL to POST new results to.
+        webhookURL: String
+        # The key to sign the webhook payloads with. On update, null keeps the existing key and
+        # an empty string removes it.
+        webhookSecret: String
     ): SavedSearch!
     # Updates a saved search
     updateSavedSearch(
@@ -391,6 +429,13 @@ type Mutation {
         notifySlack: Boolean!
         orgID: ID
         userID: ID
+        # Whether to POST new results of the saved search to webhookURL.
+        notifyWebhook: Boolean = false
+        # The URL to POST new results to.
+        webhookURL: String
+        # The key to sign the webhook payloads with. On update, null keeps the existing key and
+        # an empty string removes it.
+        webhookSecret: String
     ): SavedSearch!
     # Deletes a saved search
     deleteSavedSearch(id: ID!): EmptyResponse
@@ -1619,6 +1664,12 @@ type SavedSearch implements Node {
     orgID: ID
     # The Slack webhook URL associated with this saved search, if any.
     slackWebhookURL: String
+    # Whether or not new results are POSTed to the webhook URL.
+    notifyWebhook: Boolean!
+    # The URL new results are POSTed to, if any.
+    webhookURL: String
+    # Whether the webhook payloads are signed. The key itself is never returned.
+    hasWebhookSecret: Boolean!
 }
 
 # A search query description.
@@ -1730,6 +1781,7 @@ enum ExternalServiceKind {
     AWSCODECOMMIT
     BITBUCKETCLOUD
     BITBUCKETSERVER
+    GITEA
     GITHUB
     GITLAB
     GITOLITE
@@ -2977,6 +3029,11 @@ type User implements Node & SettingsSubject & Namespace {
     #
     # Only the user and site admins can access this field.
     siteAdmin: Boolean!
+    # The date when the user was suspended, or null if the user is not suspended. Suspended users
+    # can't sign in or use their access tokens.
+    #
+    # Only site admins can access this field.
+    suspendedAt: DateTime
     # Whether the user account uses built in auth.
     builtinAuth: Boolean!
     # The latest settings for the user.
@@ -3074,6 +3131,8 @@ type AccessToken implements Node {
     createdAt: DateTime!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: DateTime
+    # The date after which the access token can't be used, or null if it never expires.
+    expiresAt: DateTime
 }
 
 # A list of access tokens.
@@ -3126,6 +3185,72 @@ type ExternalAccountConnection {
     pageInfo: PageInfo!
 }
 
+# A list of audit log entries.
+type AuditLogEntryConnection {
+    # A list of audit log entries.
+    nodes: [AuditLogEntry!]!
+    # The total count of audit log entries in the connection. This total count may be larger than the number of
+    # nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# An entry in the audit log, which records a security-relevant change such as a site configuration update or
+# the creation of an access token.
+type AuditLogEntry {
+    # The date when the change was made.
+    createdAt: DateTime!
+    # The user who made the change, or null if it was not made by a user (or the user was deleted).
+    actor: User
+    # The IP address of the client that made the change.
+    actorIP: String!
+    # The kind of change (e.g., "site_config.update" or "access_token.create").
+    action: String!
+    # The type of the changed object (e.g., "site_config", "user" or "external_service").
+    targetType: String!
+    # The changed object (e.g., the username of a user, or the ID of an external service).
+    target: String!
+    # The lines removed ("-") and added ("+") by the change. Secret values are redacted.
+    diff: String!
+    # The hash of this entry, which covers the hash of the previous entry so that changes to the audit log
+    # can be detected.
+    hash: String!
+}
+
+# The status of syncing the site configuration, external services and global settings from a git repository.
+type ConfigSyncStatus {
+    # The name of the repository that contains the configuration files.
+    repositoryName: String!
+    # The directory in the repository that contains the configuration files.
+    path: String!
+    # The commit that was most recently applied (or failed to apply), or null if it could not be resolved.
+    commit: String
+    # The date when the configuration was most recently synced by any frontend replica, or null if it was not
+    # synced yet.
+    syncedAt: DateTime
+    # The reason why the configuration files could not be applied (e.g., because they are invalid), or
+    # null if they were applied.
+    error: String
+    # Descriptions of the changes made when the commit was applied. Frontend replicas which sync the
+    # same commit afterwards find nothing to change and keep these.
+    changes: [String!]!
+    # The configuration that was edited outside of the repository and overwritten when the commit was applied.
+    drift: [ConfigSyncDrift!]!
+}
+
+# Configuration that was edited outside of the config sync repository.
+type ConfigSyncDrift {
+    # The kind of configuration: "site_config", "global_settings" or "external_service".
+    kind: String!
+    # The display name of the external service, or null for other kinds of configuration.
+    name: String
+    # The user who last edited the configuration, or null if the user was deleted.
+    author: User
+    # The date when the configuration was last edited.
+    editedAt: DateTime!
+}
+
 # An external account associated with a user.
 type ExternalAccount implements Node {
     # The unique ID for the external account.
@@ -3610,6 +3735,24 @@ type Site implements SettingsSubject {
         # Include only external accounts with this client ID.
         clientID: String
     ): ExternalAccountConnection!
+    # The audit log of security-relevant changes, newest first. Only visible to site admins.
+    auditLog(
+        # Returns the first n entries from the list.
+        first: Int
+        # Include only changes made by this user.
+        actor: ID
+        # Include only entries with this action (e.g., "site_config.update").
+        action: String
+        # Include only entries with this target type (e.g., "user" or "external_service").
+        targetType: String
+        # Include only entries recorded at or after this time.
+        since: DateTime
+        # Include only entries recorded before this time.
+        until: DateTime
+    ): AuditLogEntryConnection!
+    # The status of syncing the site configuration, external services and global settings from a git
+    # repository, or null if config sync is not enabled (see CONFIG_SYNC_REPO). Only visible to site admins.
+    configSync: ConfigSyncStatus
     # The build version of the Sourcegraph software that is running on this site (of the form
     # NNNNN_YYYY-MM-DD_XXXXX, like 12345_2018-01-01_abcdef).
     buildVersion: String!
@@ -3674,6 +3817,52 @@ type SiteConfiguration {
     # This includes both JSON Schema validation problems and other messages that perform more advanced checks
     # on the configuration (that can't be expressed in the JSON Schema).
     validationMessages: [String!]!
+    # The saved versions of the site configuration, newest first.
+    history(
+        # Returns the first n versions from the list.
+        first: Int
+    ): SiteConfigurationVersionConnection!
+    # The properties whose values differ between two versions of the site configuration.
+    diff(
+        # The ID of the older version.
+        from: Int!
+        # The ID of the newer version.
+        to: Int!
+    ): [SiteConfigurationFieldDiff!]!
+}
+
+# A list of site configuration versions.
+type SiteConfigurationVersionConnection {
+    # A list of site configuration versions.
+    nodes: [SiteConfigurationVersion!]!
+    # The total count of site configuration versions in the connection.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A saved version of the site configuration.
+type SiteConfigurationVersion {
+    # The unique identifier of this site configuration version.
+    id: Int!
+    # The user who saved this version, or null if it was not saved by a user (e.g., the default site
+    # configuration or one loaded from SITE_CONFIG_FILE) or the user was deleted.
+    author: User
+    # The date when this version was saved.
+    createdAt: DateTime!
+    # The configuration JSON of this version.
+    contents: JSONCString!
+}
+
+# A site configuration property whose value differs between two versions of the site configuration.
+type SiteConfigurationFieldDiff {
+    # The name of the property (e.g., "externalURL"). Experimental features are named
+    # "experimentalFeatures::" followed by the name of the feature.
+    field: String!
+    # The value of the property in the older version.
+    before: JSONValue
+    # The value of the property in the newer version.
+    after: JSONValue
 }
 
 # The critical configuration for a site.
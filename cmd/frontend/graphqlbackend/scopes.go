@@ -0,0 +1,126 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+)
+
+// anyScope is the scope of fields that access tokens with any scope may resolve.
+const anyScope = ""
+
+// fieldScopes are the access token scopes that grant access to the Query and Mutation fields.
+// The fields that are not listed require the "user:all" scope, so that new fields are not
+// accessible to access tokens with fine-grained scopes by default. The fields of other types are
+// accessible if the Query or Mutation field through which they are reached is.
+var fieldScopes = map[string]string{
+	"Query.__schema":            anyScope,
+	"Query.__type":              anyScope,
+	"Query.currentUser":         anyScope,
+	"Query.clientConfiguration": anyScope,
+	"Query.renderMarkdown":      anyScope,
+
+	"Query.search":                  authz.ScopeSearchRead,
+	"Query.searchFilterSuggestions": authz.ScopeSearchRead,
+	"Query.savedSearches":           authz.ScopeSearchRead,
+	"Query.repoGroups":              authz.ScopeSearchRead,
+
+	"Query.repository":         authz.ScopeRepoRead,
+	"Query.repositoryRedirect": authz.ScopeRepoRead,
+	"Query.repositories":       authz.ScopeRepoRead,
+	"Query.phabricatorRepo":    authz.ScopeRepoRead,
+	"Query.highlightCode":      authz.ScopeRepoRead,
+
+	"Query.campaigns":                    authz.ScopeCampaignsWrite,
+	"Mutation.createChangesets":          authz.ScopeCampaignsWrite,
+	"Mutation.addChangesetsToCampaign":   authz.ScopeCampaignsWrite,
+	"Mutation.createCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.createPatchSetFromPatches": authz.ScopeCampaignsWrite,
+	"Mutation.updateCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.retryCampaign":             authz.ScopeCampaignsWrite,
+	"Mutation.deleteCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.closeCampaign":             authz.ScopeCampaignsWrite,
+	"Mutation.publishCampaign":           authz.ScopeCampaignsWrite,
+	"Mutation.publishChangeset":          authz.ScopeCampaignsWrite,
+	"Mutation.syncChangeset":             authz.ScopeCampaignsWrite,
+
+	"Query.settingsSubject":          authz.ScopeSettingsWrite,
+	"Query.viewerSettings":           authz.ScopeSettingsWrite,
+	"Query.viewerConfiguration":      authz.ScopeSettingsWrite,
+	"Mutation.settingsMutation":      authz.ScopeSettingsWrite,
+	"Mutation.configurationMutation": authz.ScopeSettingsWrite,
+
+	"Query.site":                        authz.ScopeAdminRead,
+	"Query.users":                       authz.ScopeAdminRead,
+	"Query.organizations":               authz.ScopeAdminRead,
+	"Query.externalServices":            authz.ScopeAdminRead,
+	"Query.surveyResponses":             authz.ScopeAdminRead,
+	"Query.statusMessages":              authz.ScopeAdminRead,
+	"Query.usersWithPendingPermissions": authz.ScopeAdminRead,
+	"Query.authorizedUserRepositories":  authz.ScopeAdminRead,
+}
+
+// userFieldScopes are the access token scopes that grant access to the User fields. Users are
+// reachable through fields that access tokens with any scope may resolve (e.g. Query.currentUser
+// or the authors of search results), so only the fields that identify a user are accessible to
+// every access token. The fields that are not listed contain private data of the user and require
+// the "user:all" scope, or the "admin:read" scope of a site admin.
+var userFieldScopes = map[string]string{
+	"id":                      anyScope,
+	"databaseID":              anyScope,
+	"username":                anyScope,
+	"displayName":             anyScope,
+	"avatarURL":               anyScope,
+	"url":                     anyScope,
+	"settingsURL":             anyScope,
+	"namespaceName":           anyScope,
+	"createdAt":               anyScope,
+	"updatedAt":               anyScope,
+	"siteAdmin":               anyScope,
+	"builtinAuth":             anyScope,
+	"viewerCanAdminister":     anyScope,
+	"viewerCanChangeUsername": anyScope,
+
+	"latestSettings":       authz.ScopeSettingsWrite,
+	"settingsCascade":      authz.ScopeSettingsWrite,
+	"configurationCascade": authz.ScopeSettingsWrite,
+}
+
+// checkFieldScope returns an error if the current actor was authenticated with an access token
+// whose scopes don't grant access to the given field.
+func checkFieldScope(ctx context.Context, typeName, fieldName string) error {
+	var scope string
+	switch typeName {
+	case "Query", "Mutation":
+		var ok bool
+		if scope, ok = fieldScopes[typeName+"."+fieldName]; !ok {
+			scope = authz.ScopeUserAll
+		}
+	case "User":
+		var ok bool
+		if scope, ok = userFieldScopes[fieldName]; !ok {
+			if backend.CheckActorHasScope(ctx, authz.ScopeAdminRead) == nil {
+				return nil
+			}
+			scope = authz.ScopeUserAll
+		}
+	default:
+		return nil
+	}
+	if scope == anyScope {
+		return nil
+	}
+	return backend.CheckActorHasScope(ctx, scope)
+}
+
+// scopeErrorContext is a context whose Err method returns the error of an access token scope
+// check. graphql-go does not call the resolver of a field when the context returned by the
+// tracer has an error, and reports the error for the field instead. TestFieldScopes checks that
+// the resolvers of denied fields are never called, in case graphql-go changes this behavior.
+type scopeErrorContext struct {
+	context.Context
+	err error
+}
+
+func (c scopeErrorContext) Err() error { return c.err }
@@ -0,0 +1,123 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// 🚨 SECURITY: This tests that access tokens with fine-grained scopes can only resolve the fields
+// that their scopes grant.
+func TestFieldScopes(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1, Username: "alice", SiteAdmin: true}, nil
+	}
+	db.Mocks.AccessTokens.GetByID = func(id int64) (*db.AccessToken, error) {
+		return &db.AccessToken{ID: id, SubjectUserID: 1}, nil
+	}
+	// called records the resolvers that were called, to check that the resolvers of the fields
+	// that an access token may not resolve are never called.
+	var called map[string]bool
+	db.Mocks.AccessTokens.DeleteByID = func(id int64, subjectUserID int32) error {
+		called["deleteAccessToken"] = true
+		return nil
+	}
+	db.Mocks.Users.List = func(ctx context.Context, opt *db.UsersListOptions) ([]*types.User, error) {
+		called["users"] = true
+		return []*types.User{{ID: 2, Username: "bob"}}, nil
+	}
+	db.Mocks.UserEmails.ListByUser = func(ctx context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+		called["emails"] = true
+		return []*db.UserEmail{{UserID: 1, Email: "alice@example.com"}}, nil
+	}
+	db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error { return nil }
+	defer resetMocks()
+
+	const (
+		currentUserQuery = `query { currentUser { username } }`
+		emailsQuery      = `query { currentUser { emails { email } } }`
+		usersQuery       = `query { users { nodes { username } } }`
+		deleteTokenQuery = `mutation { deleteAccessToken(byID: "QWNjZXNzVG9rZW46MQ==") { alwaysNil } }`
+	)
+
+	for _, tc := range []struct {
+		name       string
+		scopes     []string
+		query      string
+		wantErr    string
+		wantCalled string
+	}{
+		{name: "session", query: deleteTokenQuery, wantCalled: "deleteAccessToken"},
+		{name: "user:all", scopes: []string{authz.ScopeUserAll}, query: deleteTokenQuery, wantCalled: "deleteAccessToken"},
+		{name: "any scope", scopes: []string{authz.ScopeSearchRead}, query: currentUserQuery},
+		{
+			name:    "missing scope",
+			scopes:  []string{authz.ScopeSearchRead, authz.ScopeRepoRead},
+			query:   deleteTokenQuery,
+			wantErr: `access token lacks required scope "user:all"`,
+		},
+		{
+			name:    "site-admin:sudo only",
+			scopes:  []string{authz.ScopeSiteAdminSudo},
+			query:   `query { search(query: "foo") { __typename } }`,
+			wantErr: `access token lacks required scope "search:read"`,
+		},
+		{
+			name:    "missing scope for query field",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   usersQuery,
+			wantErr: `access token lacks required scope "admin:read"`,
+		},
+		{name: "admin:read", scopes: []string{authz.ScopeAdminRead}, query: usersQuery, wantCalled: "users"},
+		{
+			name:    "private user field",
+			scopes:  []string{authz.ScopeSearchRead, authz.ScopeSettingsWrite},
+			query:   emailsQuery,
+			wantErr: `access token lacks required scope "user:all"`,
+		},
+		{name: "private user field with user:all", scopes: []string{authz.ScopeUserAll}, query: emailsQuery, wantCalled: "emails"},
+		{name: "private user field with admin:read", scopes: []string{authz.ScopeAdminRead}, query: emailsQuery, wantCalled: "emails"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			called = map[string]bool{}
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1, AccessTokenScopes: tc.scopes})
+			resp := mustParseGraphQLSchema(t).Exec(ctx, tc.query, "", nil)
+
+			var errs []string
+			for _, err := range resp.Errors {
+				errs = append(errs, err.Message)
+			}
+			switch {
+			case tc.wantErr == "" && len(errs) > 0:
+				t.Fatalf("got errors %q, want none", errs)
+			case tc.wantErr != "" && (len(errs) != 1 || errs[0] != tc.wantErr):
+				t.Fatalf("got errors %q, want %q", errs, tc.wantErr)
+			}
+			want := map[string]bool{}
+			if tc.wantCalled != "" {
+				want[tc.wantCalled] = true
+			}
+			if !reflect.DeepEqual(called, want) {
+				t.Errorf("got called resolvers %v, want %v", called, want)
+			}
+			if tc.wantErr != "" {
+				var data map[string]interface{}
+				if err := json.Unmarshal(resp.Data, &data); err != nil {
+					t.Fatal(err)
+				}
+				for field, value := range data {
+					if value != nil {
+						t.Errorf("got %s %v, want null", field, value)
+					}
+				}
+			}
+		})
+	}
+}
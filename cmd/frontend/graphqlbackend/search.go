@@ -328,6 +328,15 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 
 	commitAfter, _ := r.query.StringValue(query.FieldRepoHasCommitAfter)
 
+	var revs []search.RevisionSpecifier
+	if revStr, _ := r.query.StringValue(query.FieldRev); revStr != "" {
+		scope, err := query.ParseRevScope(revStr)
+		if err != nil {
+			return nil, nil, false, &badRequestError{err}
+		}
+		revs = []search.RevisionSpecifier{{RevSpec: scope.Head}}
+	}
+
 	tr.LazyPrintf("resolveRepositories - start")
 	repoRevs, missingRepoRevs, overLimit, err = resolveRepositories(ctx, resolveRepoOp{
 		repoFilters:      repoFilters,
@@ -340,6 +349,7 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 		onlyPrivate:      visibility == query.Private,
 		onlyPublic:       visibility == query.Public,
 		commitAfter:      commitAfter,
+		revs:             revs,
 	})
 	tr.LazyPrintf("resolveRepositories - done")
 	if effectiveRepoFieldValues == nil {
@@ -455,6 +465,10 @@ type resolveRepoOp struct {
 	commitAfter      string
 	onlyPrivate      bool
 	onlyPublic       bool
+
+	// revs, if set, are the revisions to search in every repository
+	// (rev:). They can't be combined with repo:pattern@rev.
+	revs []search.RevisionSpecifier
 }
 
 func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions, missingRepoRevisions []*search.RepositoryRevisions, overLimit bool, err error) {
@@ -502,6 +516,9 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 	if err != nil {
 		return nil, nil, false, err
 	}
+	if len(op.revs) > 0 && len(includePatternRevs) > 0 {
+		return nil, nil, false, &badRequestError{errors.New("rev: can't be combined with repo:pattern@rev")}
+	}
 
 	var defaultRepos []*types.Repo
 	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 {
@@ -546,6 +563,10 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 	tr.LazyPrintf("Associate/validate revs - start")
 	for _, repo := range repos {
 		revs, clashingRevs := getRevsForMatchedRepo(repo.Name, includePatternRevs)
+		if len(op.revs) > 0 {
+			// Copy, since revs is filtered in place below.
+			revs = append([]search.RevisionSpecifier(nil), op.revs...)
+		}
 		repoRev := &search.RepositoryRevisions{Repo: repo}
 
 		// We do in place filtering to reduce allocations. Common path is no
@@ -214,6 +214,13 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 		IsRegExp:        op.PatternInfo.IsRegExp,
 		IsCaseSensitive: op.PatternInfo.IsCaseSensitive,
 	}
+	// 🚨 SECURITY: Only search the changes to paths that the user may read due to sub-repository
+	// permissions. The matcher is only set if there is one, because a nil
+	// *authz.SubRepoPathMatcher would be a non-nil pathmatch.PathMatcher.
+	subRepoMatcher, err := db.SubRepoPathMatcher(ctx, op.RepoRevs.Repo.ID)
+	if err != nil {
+		return nil, false, false, err
+	}
 	diffParameters := search.DiffParameters{
 		Repo: op.RepoRevs.GitserverRepo(),
 		Options: git.RawLogDiffSearchOptions{
@@ -229,6 +236,9 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 			Args:              args,
 		},
 	}
+	if subRepoMatcher != nil {
+		diffParameters.Options.Paths.Matcher = subRepoMatcher
+	}
 
 	rawResults, complete, err := git.RawLogDiffSearch(ctx, diffParameters.Repo, diffParameters.Options)
 	if err != nil {
@@ -0,0 +1,135 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	zoektquery "github.com/google/zoekt/query"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// maxDiffScopeChangedPaths is the maximum number of changed files per
+// repository that a diff scoped search (rev:base...head) searches.
+const maxDiffScopeChangedPaths = 10000
+
+// resolveDiffScope returns the paths changed on the searched revision of each
+// repository since it diverged from base. For the repositories searched with
+// zoekt, the changes of the indexed commit are returned.
+//
+// Repositories without changes are left out. Repositories that don't have
+// base are reported as missing in common.
+func resolveDiffScope(ctx context.Context, common *searchResultsCommon, base string, zoektRepos, searcherRepos []*search.RepositoryRevisions) (map[api.RepoName][]string, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		sem     = make(chan struct{}, 16)
+		changed = make(map[api.RepoName][]string, len(zoektRepos)+len(searcherRepos))
+		diffErr error
+	)
+	for i, repoRev := range append(zoektRepos[:len(zoektRepos):len(zoektRepos)], searcherRepos...) {
+		if len(repoRev.Revs) == 0 {
+			continue
+		}
+
+		// rev: sets exactly one revspec per repository.
+		head := repoRev.Revs[0].RevSpec
+		if i < len(zoektRepos) && repoRev.IndexedHEADCommit() != "" {
+			head = string(repoRev.IndexedHEADCommit())
+		} else if head == "" {
+			head = "HEAD"
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(repoRev *search.RepositoryRevisions, head string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			paths, err := git.ChangedPaths(ctx, repoRev.GitserverRepo(), base, head)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if gitserver.IsRevisionNotFound(err) {
+				common.missing = append(common.missing, repoRev.Repo)
+				return
+			}
+			if fatalErr := handleRepoSearchResult(common, repoRev, false, false, err); fatalErr != nil {
+				if diffErr == nil {
+					diffErr = errors.Wrapf(fatalErr, "failed to list the files changed in %s since %s", repoRev.String(), base)
+				}
+				return
+			}
+			if len(paths) > maxDiffScopeChangedPaths {
+				if diffErr == nil {
+					diffErr = &badRequestError{fmt.Errorf("more than %d files changed in %s since %s (narrow the diff scope of rev:)", maxDiffScopeChangedPaths, repoRev.Repo.Name, base)}
+				}
+				return
+			}
+			if len(paths) > 0 {
+				changed[repoRev.Repo.Name] = paths
+			}
+		}(repoRev, head)
+	}
+	wg.Wait()
+	if diffErr != nil {
+		return nil, diffErr
+	}
+	return changed, nil
+}
+
+// filterRepoRevsWithChangedPaths returns the repositories of repos that have
+// changed paths.
+func filterRepoRevsWithChangedPaths(repos []*search.RepositoryRevisions, changed map[api.RepoName][]string) []*search.RepositoryRevisions {
+	filtered := repos[:0:0]
+	for _, repoRev := range repos {
+		if _, ok := changed[repoRev.Repo.Name]; ok {
+			filtered = append(filtered, repoRev)
+		}
+	}
+	return filtered
+}
+
+// filterChangedPaths returns the paths in paths which are in changed.
+func filterChangedPaths(paths, changed []string) []string {
+	isChanged := make(map[string]bool, len(changed))
+	for _, p := range changed {
+		isChanged[p] = true
+	}
+	filtered := paths[:0:0]
+	for _, p := range paths {
+		if isChanged[p] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// changedPathsPattern returns a regexp which matches exactly the given paths.
+func changedPathsPattern(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	return "^(?:" + strings.Join(quoted, "|") + ")$"
+}
+
+// changedPathsQuery returns a zoekt query which matches the changed paths of
+// each repository.
+func changedPathsQuery(changed map[api.RepoName][]string) (zoektquery.Q, error) {
+	or := make([]zoektquery.Q, 0, len(changed))
+	for repo, paths := range changed {
+		q, err := fileRe(changedPathsPattern(paths), true)
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, zoektquery.NewAnd(&zoektquery.RepoSet{Set: map[string]bool{string(repo): true}}, q))
+	}
+	return zoektquery.NewOr(or...), nil
+}
@@ -1109,7 +1109,11 @@ func (r *searchResolver) determineResultTypes(args search.TextParameters, forceO
 	} else {
 		resultTypes, _ = r.query.StringValues(query.FieldType)
 		if len(resultTypes) == 0 {
-			resultTypes = []string{"file", "path", "repo"}
+			if sel, _ := r.query.StringValue(query.FieldSelect); strings.HasPrefix(strings.ToLower(sel), "symbol") {
+				resultTypes = []string{"symbol"}
+			} else {
+				resultTypes = []string{"file", "path", "repo"}
+			}
 		}
 	}
 	for _, resultType := range resultTypes {
@@ -3,8 +3,6 @@ package graphqlbackend
 import (
 	"context"
 	"errors"
-	"regexp"
-	"regexp/syntax"
 	"strings"
 	"time"
 
@@ -33,43 +31,36 @@ func splitOnHolesPattern() string {
 
 var matchHoleRegexp = lazyregexp.New(splitOnHolesPattern())
 
-// StructuralPatToRegexpQuery converts a comby pattern to a Zoekt regular
-// expression query. It converts whitespace in the pattern so that content
-// across newlines can be matched in the index. As an incomplete approximation,
-// we use the regex pattern .*? to scan ahead. A shortcircuit option returns a
-// regexp query that may find true matches faster, but may miss all possible
-// matches.
+// StructuralPatToIndexedQuery converts a comby pattern to a Zoekt query that
+// matches the files that may contain a match of the pattern. Comby matches the
+// text outside of holes literally (modulo whitespace), so a file can only
+// match if it contains every literal fragment of the pattern. The query is the
+// conjunction of these fragments as case-sensitive substrings, which Zoekt can
+// answer from its trigram index without scanning file contents with a regular
+// expression.
 //
 // Example:
-// "ParseInt(:[args]) if err != nil" -> "ParseInt(.*)\s+if\s+err!=\s+nil"
-func StructuralPatToRegexpQuery(pattern string, shortcircuit bool) (zoektquery.Q, error) {
-	substrings := matchHoleRegexp.Split(pattern, -1)
+// "ParseInt(:[args]) if err != nil" -> (and "ParseInt(" ")" "if" "err" "!=" "nil")
+func StructuralPatToIndexedQuery(pattern string) zoektquery.Q {
 	var children []zoektquery.Q
-	var pieces []string
-	for _, s := range substrings {
-		piece := regexp.QuoteMeta(s)
-		onMatchWhitespace := lazyregexp.New(`[\s]+`)
-		piece = onMatchWhitespace.ReplaceAllLiteralString(piece, `[\s]+`)
-		pieces = append(pieces, piece)
-	}
-
-	if len(pieces) == 0 {
-		return &zoektquery.Const{Value: true}, nil
-	}
-	var rs string
-	if shortcircuit {
-		// As a shortcircuit, do not match across newlines of structural search pieces.
-		rs = "(" + strings.Join(pieces, ").*?(") + ")"
-	} else {
-		rs = "(" + strings.Join(pieces, ")(.|\\s)*?(") + ")"
-	}
-	re, _ := syntax.Parse(rs, syntax.ClassNL|syntax.PerlX|syntax.UnicodeGroups)
-	children = append(children, &zoektquery.Regexp{
-		Regexp:        re,
-		CaseSensitive: true,
-		Content:       true,
-	})
-	return &zoektquery.And{Children: children}, nil
+	seen := map[string]bool{}
+	for _, s := range matchHoleRegexp.Split(pattern, -1) {
+		for _, fragment := range strings.Fields(s) {
+			if seen[fragment] {
+				continue
+			}
+			seen[fragment] = true
+			children = append(children, &zoektquery.Substring{
+				Pattern:       fragment,
+				CaseSensitive: true,
+				Content:       true,
+			})
+		}
+	}
+	if len(children) == 0 {
+		return &zoektquery.Const{Value: true}
+	}
+	return zoektquery.NewAnd(children...)
 }
 
 func HandleFilePathPatterns(query *search.TextPatternInfo) (zoektquery.Q, error) {
@@ -98,18 +89,14 @@ func HandleFilePathPatterns(query *search.TextPatternInfo) (zoektquery.Q, error)
 	return zoektquery.NewAnd(and...), nil
 }
 
-func buildQuery(args *search.TextParameters, newRepoSet *zoektquery.RepoSet, filePathPatterns zoektquery.Q, shortcircuit bool) (zoektquery.Q, error) {
-	q, err := StructuralPatToRegexpQuery(args.PatternInfo.Pattern, shortcircuit)
-	if err != nil {
-		return nil, err
-	}
-	q = zoektquery.NewAnd(newRepoSet, filePathPatterns, q)
-	q = zoektquery.Simplify(q)
-	return q, nil
+func buildQuery(args *search.TextParameters, newRepoSet *zoektquery.RepoSet, filePathPatterns zoektquery.Q) zoektquery.Q {
+	q := zoektquery.NewAnd(newRepoSet, filePathPatterns, StructuralPatToIndexedQuery(args.PatternInfo.Pattern))
+	return zoektquery.Simplify(q)
 }
 
-// zoektSearchHEADOnlyFiles searches repositories using zoekt, returning only the file paths containing
-// content matching the given pattern.
+// zoektSearchHEADOnlyFiles searches repositories using zoekt, returning only the paths of the files
+// containing all literal fragments of the given structural pattern. These files are candidates that
+// searcher then runs comby on.
 //
 // Timeouts are reported through the context, and as a special case errNoResultsInTimeout
 // is returned if no results are found in the given timeout (instead of the more common
@@ -156,6 +143,13 @@ func zoektSearchHEADOnlyFiles(ctx context.Context, args *search.TextParameters,
 	if err != nil {
 		return nil, false, nil, err
 	}
+	if args.ChangedPaths != nil {
+		changed, err := changedPathsQuery(args.ChangedPaths)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		filePathPatterns = zoektquery.NewAnd(filePathPatterns, changed)
+	}
 
 	// Handle `repohasfile` or `-repohasfile`
 	newRepoSet, err := createNewRepoSetWithRepoHasFileInputs(ctx, args.PatternInfo, args.Zoekt.Client, repoSet)
@@ -164,10 +158,7 @@ func zoektSearchHEADOnlyFiles(ctx context.Context, args *search.TextParameters,
 	}
 
 	t0 := time.Now()
-	q, err := buildQuery(args, newRepoSet, filePathPatterns, true)
-	if err != nil {
-		return nil, false, nil, err
-	}
+	q := buildQuery(args, newRepoSet, filePathPatterns)
 	resp, err := args.Zoekt.Client.Search(ctx, q, &searchOpts)
 	if err != nil {
 		return nil, false, nil, err
@@ -175,23 +166,7 @@ func zoektSearchHEADOnlyFiles(ctx context.Context, args *search.TextParameters,
 	if since(t0) >= searchOpts.MaxWallTime {
 		return nil, false, nil, errNoResultsInTimeout
 	}
-
-	// We always return approximate results (limitHit true) unless we run the branch to perform a more complete search.
-	limitHit = true
-	// If the previous indexed search did not return a substantial number of matching file candidates or count was
-	// manually specified, run a more complete and expensive search.
-	if resp.FileCount < 10 || args.PatternInfo.FileMatchLimit != defaultMaxSearchResults {
-		q, err = buildQuery(args, newRepoSet, filePathPatterns, false)
-		resp, err = args.Zoekt.Client.Search(ctx, q, &searchOpts)
-		if err != nil {
-			return nil, false, nil, err
-		}
-		if since(t0) >= searchOpts.MaxWallTime {
-			return nil, false, nil, errNoResultsInTimeout
-		}
-		// This is the only place limitHit can be set false, meaning we covered everything.
-		limitHit = resp.FilesSkipped+resp.ShardsSkipped > 0
-	}
+	limitHit = resp.FilesSkipped+resp.ShardsSkipped > 0
 
 	if len(resp.Files) == 0 {
 		return nil, false, nil, nil
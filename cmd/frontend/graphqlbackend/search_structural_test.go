@@ -2,83 +2,74 @@ package graphqlbackend
 
 import (
 	"testing"
-
-	zoektquery "github.com/google/zoekt/query"
 )
 
-func TestStructuralPatToZoektQuery(t *testing.T) {
+func TestStructuralPatToIndexedQuery(t *testing.T) {
 	cases := []struct {
-		Name     string
-		Pattern  string
-		Function func(string, bool) (zoektquery.Q, error)
-		Want     string
+		Name    string
+		Pattern string
+		Want    string
 	}{
 		{
-			Name:     "Just a hole",
-			Pattern:  ":[1]",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"()((?s:.))*?()")`,
+			Name:    "Just a hole",
+			Pattern: ":[1]",
+			Want:    `TRUE`,
 		},
 		{
-			Name:     "Adjacent holes",
-			Pattern:  ":[1]:[2]:[3]",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"()((?s:.))*?()((?s:.))*?()((?s:.))*?()")`,
+			Name:    "Adjacent holes",
+			Pattern: ":[1]:[2]:[3]",
+			Want:    `TRUE`,
 		},
 		{
-			Name:     "Substring between holes",
-			Pattern:  ":[1] substring :[2]",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"()((?s:.))*?([\\t-\\n\\f-\\r ]+substring[\\t-\\n\\f-\\r ]+)((?s:.))*?()")`,
+			Name:    "Substring between holes",
+			Pattern: ":[1] substring :[2]",
+			Want:    `(and case_content_substr:"substring")`,
 		},
 		{
-			Name:     "Substring before and after different hole kinds",
-			Pattern:  "prefix :[[1]] :[2.] suffix",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"(prefix[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+suffix)")`,
+			Name:    "Substring before and after different hole kinds",
+			Pattern: "prefix :[[1]] :[2.] suffix",
+			Want:    `(and case_content_substr:"prefix" case_content_substr:"suffix")`,
 		},
 		{
-			Name:     "Substrings covering all hole kinds.",
-			Pattern:  `1. :[1] 2. :[[2]] 3. :[3.] 4. :[4\n] 5. :[ ] 6. :[ 6] done.`,
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"(1\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+2\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+3\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+4\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+5\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+6\\.[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+done\\.)")`,
+			Name:    "Substrings covering all hole kinds.",
+			Pattern: `1. :[1] 2. :[[2]] 3. :[3.] 4. :[4\n] 5. :[ ] 6. :[ 6] done.`,
+			Want:    `(and case_content_substr:"1." case_content_substr:"2." case_content_substr:"3." case_content_substr:"4." case_content_substr:"5." case_content_substr:"6." case_content_substr:"done.")`,
 		},
 		{
-			Name:     "Substrings across multiple lines.",
-			Pattern:  ``,
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"()")`,
+			Name:    "Empty pattern",
+			Pattern: ``,
+			Want:    `TRUE`,
 		},
 		{
-			Name:     "Allow alphanumeric identifiers in holes",
-			Pattern:  "sub :[alphanum_ident_123] string",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"(sub[\\t-\\n\\f-\\r ]+)((?s:.))*?([\\t-\\n\\f-\\r ]+string)")`,
+			Name:    "Allow alphanumeric identifiers in holes",
+			Pattern: "sub :[alphanum_ident_123] string",
+			Want:    `(and case_content_substr:"sub" case_content_substr:"string")`,
 		},
-
 		{
-			Name:     "Whitespace separated holes",
-			Pattern:  ":[1] :[2]",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"()((?s:.))*?([\\t-\\n\\f-\\r ]+)((?s:.))*?()")`,
+			Name:    "Whitespace separated holes",
+			Pattern: ":[1] :[2]",
+			Want:    `TRUE`,
 		},
 		{
-			Name:     "Expect newline separated pattern",
-			Pattern:  "ParseInt(:[stuff], :[x]) if err ",
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"(ParseInt\\()((?s:.))*?(,[\\t-\\n\\f-\\r ]+)((?s:.))*?(\\)[\\t-\\n\\f-\\r ]+if[\\t-\\n\\f-\\r ]+err[\\t-\\n\\f-\\r ]+)")`,
+			Name:    "Expect newline separated pattern",
+			Pattern: "ParseInt(:[stuff], :[x]) if err ",
+			Want:    `(and case_content_substr:"ParseInt(" case_content_substr:"," case_content_substr:")" case_content_substr:"if" case_content_substr:"err")`,
 		},
 		{
-			Name: "Contiguous whitespace is replaced by regex",
+			Name: "Contiguous whitespace separates fragments",
 			Pattern: `ParseInt(:[stuff],    :[x])
              if err `,
-			Function: StructuralPatToRegexpQuery,
-			Want:     `(and case_regex:"(ParseInt\\()((?s:.))*?(,[\\t-\\n\\f-\\r ]+)((?s:.))*?(\\)[\\t-\\n\\f-\\r ]+if[\\t-\\n\\f-\\r ]+err[\\t-\\n\\f-\\r ]+)")`,
+			Want: `(and case_content_substr:"ParseInt(" case_content_substr:"," case_content_substr:")" case_content_substr:"if" case_content_substr:"err")`,
+		},
+		{
+			Name:    "Repeated fragments are only matched once",
+			Pattern: "foo(:[a]) + foo(:[b])",
+			Want:    `(and case_content_substr:"foo(" case_content_substr:")" case_content_substr:"+")`,
 		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.Name, func(t *testing.T) {
-			got, _ := tt.Function(tt.Pattern, false)
+			got := StructuralPatToIndexedQuery(tt.Pattern)
 			if got.String() != tt.Want {
 				t.Fatalf("mismatched queries\ngot  %s\nwant %s", got.String(), tt.Want)
 			}
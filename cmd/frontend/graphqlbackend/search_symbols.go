@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 	lsp "github.com/sourcegraph/go-lsp"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/api"
@@ -64,6 +66,11 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 		return nil, nil, nil
 	}
 
+	filters, err := symbolFiltersFromQuery(args.Query, args.PatternInfo.IsCaseSensitive)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ctx, cancelAll := context.WithCancel(ctx)
 	defer cancelAll()
 
@@ -160,7 +167,7 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 	run.Acquire()
 	goroutine.Go(func() {
 		defer run.Release()
-		matches, limitHit, reposLimitHit, searchErr := zoektSearchHEAD(ctx, args, zoektRepos, true, time.Since)
+		matches, limitHit, reposLimitHit, searchErr := zoektSearchHEAD(ctx, filters.zoektArgs(args), zoektRepos, true, time.Since)
 		mu.Lock()
 		defer mu.Unlock()
 		if ctx.Err() == nil {
@@ -180,7 +187,9 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 			err = searchErr
 			tr.LazyPrintf("cancel indexed symbol search due to error: %v", err)
 		}
-		addMatches(matches)
+		// Zoekt doesn't know about symbol filters, so we apply them to its
+		// results here. If zoekt hit its limit, limitHit is already set.
+		addMatches(filters.filterFileMatches(matches))
 	})
 
 	for _, repoRevs := range searcherRepos {
@@ -194,7 +203,7 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 		run.Acquire()
 		goroutine.Go(func() {
 			defer run.Release()
-			repoSymbols, repoErr := searchSymbolsInRepo(ctx, repoRevs, args.PatternInfo, args.Query, limit)
+			repoSymbols, repoErr := searchSymbolsInRepo(ctx, repoRevs, args.PatternInfo, filters, limit)
 			if repoErr != nil {
 				tr.LogFields(otlog.String("repo", string(repoRevs.Repo.Name)), otlog.String("repoErr", repoErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(repoErr)), otlog.Bool("temporary", errcode.IsTemporary(repoErr)))
 			}
@@ -218,10 +227,174 @@ func searchSymbols(ctx context.Context, args *search.TextParameters, limit int)
 	err = run.Wait()
 	flattened := flattenFileMatches(unflattened, int(args.PatternInfo.FileMatchLimit))
 	res2 := limitSymbolResults(flattened, limit)
-	common.limitHit = symbolCount(res2) < symbolCount(res)
+	if symbolCount(res2) < symbolCount(flattened) {
+		common.limitHit = true
+	}
 	return res2, common, err
 }
 
+// symbolFilters restrict symbol search results by the kind and parent of
+// symbols. They are given by the select:, symbolkind: and symbolparent: fields
+// of a query.
+type symbolFilters struct {
+	// kinds are lowercase ctags kinds. Symbols of any of these kinds match. An
+	// empty list matches symbols of all kinds.
+	kinds []string
+
+	// parentPatterns are regular expressions that must all match the parent
+	// of a symbol. parentRegexps are their compiled form.
+	parentPatterns []string
+	parentRegexps  []*regexp.Regexp
+}
+
+// symbolFiltersFromQuery returns the symbol filters given by q.
+//
+// select:symbol.KIND restricts results to symbols of the LSP symbol kind KIND
+// (e.g. select:symbol.function), which covers all ctags kinds that map to it.
+// symbolkind: restricts results to the given ctags kinds (e.g.
+// symbolkind:struct). symbolparent: restricts results to symbols whose parent
+// (the enclosing class, struct, namespace, etc.) matches all given patterns.
+func symbolFiltersFromQuery(q query.QueryInfo, isCaseSensitive bool) (*symbolFilters, error) {
+	filters := &symbolFilters{}
+
+	var selected map[string]bool
+	if sel, _ := q.StringValue(query.FieldSelect); sel != "" {
+		kind, err := parseSelectSymbolKind(sel)
+		if err != nil {
+			return nil, err
+		}
+		if kind != 0 {
+			selected = make(map[string]bool)
+			for ctagsKind, lspKind := range ctagsKinds {
+				if lspKind == kind {
+					selected[ctagsKind] = true
+				}
+			}
+		}
+	}
+
+	kinds, _ := q.StringValues(query.FieldSymbolKind)
+	switch {
+	case len(kinds) > 0:
+		for _, kind := range kinds {
+			kind = strings.ToLower(kind)
+			if selected == nil || selected[kind] {
+				filters.kinds = append(filters.kinds, kind)
+			}
+		}
+		if len(filters.kinds) == 0 {
+			return nil, fmt.Errorf("none of the symbolkind: values %q are of the selected symbol kind", kinds)
+		}
+	case selected != nil:
+		for kind := range selected {
+			filters.kinds = append(filters.kinds, kind)
+		}
+		sort.Strings(filters.kinds)
+	}
+
+	filters.parentPatterns, _ = q.RegexpPatterns(query.FieldSymbolParent)
+	for _, pattern := range filters.parentPatterns {
+		expr := pattern
+		if !isCaseSensitive {
+			expr = "(?i:" + expr + ")"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid symbolparent: value %q", pattern)
+		}
+		filters.parentRegexps = append(filters.parentRegexps, re)
+	}
+
+	return filters, nil
+}
+
+// parseSelectSymbolKind parses the value of the select: field. It returns the
+// LSP symbol kind for values of the form "symbol.KIND" and 0 for "symbol".
+func parseSelectSymbolKind(value string) (lsp.SymbolKind, error) {
+	v := strings.ToLower(value)
+	if v == "symbol" {
+		return 0, nil
+	}
+	if name := strings.TrimPrefix(v, "symbol."); name != v {
+		for _, kind := range ctagsKinds {
+			if strings.ToLower(kind.String()) == name {
+				return kind, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid select:%q (valid values are symbol and symbol.KIND, e.g. symbol.function)", value)
+}
+
+// match reports whether the symbol passes the filters.
+func (f *symbolFilters) match(symbol *protocol.Symbol) bool {
+	if len(f.kinds) > 0 {
+		kind := strings.ToLower(symbol.Kind)
+		found := false
+		for _, k := range f.kinds {
+			if k == kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, re := range f.parentRegexps {
+		if !re.MatchString(symbol.Parent) {
+			return false
+		}
+	}
+	return true
+}
+
+// empty reports whether f doesn't filter out any symbols.
+func (f *symbolFilters) empty() bool {
+	return len(f.kinds) == 0 && len(f.parentRegexps) == 0
+}
+
+// symbolFiltersOverFetchFactor is how many more file matches we ask zoekt for
+// when symbol filters are used.
+const symbolFiltersOverFetchFactor = 10
+
+// zoektArgs returns the arguments for the zoekt part of a symbol search. Zoekt
+// can't apply symbol filters, so they remove symbols from its results only
+// after zoekt applied its limits. To still return enough results, we ask
+// zoekt for more file matches when filtering.
+func (f *symbolFilters) zoektArgs(args *search.TextParameters) *search.TextParameters {
+	if f.empty() {
+		return args
+	}
+	patternInfo := *args.PatternInfo
+	patternInfo.FileMatchLimit *= symbolFiltersOverFetchFactor
+	argsCopy := *args
+	argsCopy.PatternInfo = &patternInfo
+	return &argsCopy
+}
+
+// filterFileMatches removes the symbols that don't pass the filters from the
+// given file matches, dropping file matches that are left without symbols.
+func (f *symbolFilters) filterFileMatches(matches []*FileMatchResolver) []*FileMatchResolver {
+	if f.empty() {
+		return matches
+	}
+
+	filtered := matches[:0]
+	for _, fm := range matches {
+		symbols := fm.symbols[:0]
+		for _, s := range fm.symbols {
+			if f.match(&s.symbol) {
+				symbols = append(symbols, s)
+			}
+		}
+		if len(symbols) > 0 {
+			fm.symbols = symbols
+			filtered = append(filtered, fm)
+		}
+	}
+	return filtered
+}
+
 // limitSymbolResults returns a new version of res containing no more than limit symbol matches.
 func limitSymbolResults(res []*FileMatchResolver, limit int) []*FileMatchResolver {
 	res2 := make([]*FileMatchResolver, 0, len(res))
@@ -251,7 +424,7 @@ func symbolCount(fmrs []*FileMatchResolver) int {
 	return nsym
 }
 
-func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, patternInfo *search.TextPatternInfo, query query.QueryInfo, limit int) (res []*FileMatchResolver, err error) {
+func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions, patternInfo *search.TextPatternInfo, filters *symbolFilters, limit int) (res []*FileMatchResolver, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Search symbols in repo")
 	defer func() {
 		if err != nil {
@@ -278,6 +451,18 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 		return nil, err
 	}
 
+	// 🚨 SECURITY: The symbols service lists the symbols of the whole repository, so the symbols
+	// in paths that the user may not read are removed before the limit is applied. The symbols
+	// service can't filter by the matcher, so over-fetch to make up for the removed symbols.
+	subRepoMatcher, err := db.SubRepoPathMatcher(ctx, repoRevs.Repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	first := limit + 1 // Ask for limit + 1 so we can detect whether there are more results than the limit.
+	if subRepoMatcher != nil {
+		first *= subRepoPermsOverFetchFactor
+	}
+
 	symbols, err := backend.Symbols.ListTags(ctx, search.SymbolsParameters{
 		Repo:            repoRevs.Repo.Name,
 		CommitID:        commitID,
@@ -286,9 +471,19 @@ func searchSymbolsInRepo(ctx context.Context, repoRevs *search.RepositoryRevisio
 		IsRegExp:        patternInfo.IsRegExp,
 		IncludePatterns: patternInfo.IncludePatterns,
 		ExcludePattern:  patternInfo.ExcludePattern,
-		// Ask for limit + 1 so we can detect whether there are more results than the limit.
-		First: limit + 1,
+		Kinds:           filters.kinds,
+		ParentPatterns:  filters.parentPatterns,
+		First:           first,
 	})
+	if subRepoMatcher != nil {
+		filtered := symbols[:0]
+		for _, symbol := range symbols {
+			if subRepoMatcher.MatchPath(symbol.Path) && len(filtered) <= limit {
+				filtered = append(filtered, symbol)
+			}
+		}
+		symbols = filtered
+	}
 	fileMatchesByURI := make(map[string]*FileMatchResolver)
 	fileMatches := make([]*FileMatchResolver, 0)
 	for _, symbol := range symbols {
@@ -357,61 +552,79 @@ func ctagsSymbolCharacter(s protocol.Symbol) int {
 	return 0
 }
 
+// ctagsKinds maps ctags kinds to LSP symbol kinds. Ctags kinds are determined
+// by the parser and do not (in general) match LSP symbol kinds.
+var ctagsKinds = map[string]lsp.SymbolKind{
+	"file":            lsp.SKFile,
+	"module":          lsp.SKModule,
+	"namespace":       lsp.SKNamespace,
+	"package":         lsp.SKPackage,
+	"packagename":     lsp.SKPackage,
+	"subprogspec":     lsp.SKPackage,
+	"class":           lsp.SKClass,
+	"type":            lsp.SKClass,
+	"service":         lsp.SKClass,
+	"typedef":         lsp.SKClass,
+	"union":           lsp.SKClass,
+	"section":         lsp.SKClass,
+	"subtype":         lsp.SKClass,
+	"component":       lsp.SKClass,
+	"method":          lsp.SKMethod,
+	"methodspec":      lsp.SKMethod,
+	"property":        lsp.SKProperty,
+	"field":           lsp.SKField,
+	"member":          lsp.SKField,
+	"anonmember":      lsp.SKField,
+	"recordfield":     lsp.SKField,
+	"constructor":     lsp.SKConstructor,
+	"enum":            lsp.SKEnum,
+	"enumerator":      lsp.SKEnum,
+	"interface":       lsp.SKInterface,
+	"function":        lsp.SKFunction,
+	"func":            lsp.SKFunction,
+	"subroutine":      lsp.SKFunction,
+	"macro":           lsp.SKFunction,
+	"subprogram":      lsp.SKFunction,
+	"procedure":       lsp.SKFunction,
+	"command":         lsp.SKFunction,
+	"singletonmethod": lsp.SKFunction,
+	"variable":        lsp.SKVariable,
+	"var":             lsp.SKVariable,
+	"functionvar":     lsp.SKVariable,
+	"define":          lsp.SKVariable,
+	"alias":           lsp.SKVariable,
+	"val":             lsp.SKVariable,
+	"constant":        lsp.SKConstant,
+	"const":           lsp.SKConstant,
+	"string":          lsp.SKString,
+	"message":         lsp.SKString,
+	"heredoc":         lsp.SKString,
+	"number":          lsp.SKNumber,
+	"bool":            lsp.SKBoolean,
+	"boolean":         lsp.SKBoolean,
+	"array":           lsp.SKArray,
+	"object":          lsp.SKObject,
+	"literal":         lsp.SKObject,
+	"map":             lsp.SKObject,
+	"key":             lsp.SKKey,
+	"label":           lsp.SKKey,
+	"target":          lsp.SKKey,
+	"selector":        lsp.SKKey,
+	"id":              lsp.SKKey,
+	"tag":             lsp.SKKey,
+	"null":            lsp.SKNull,
+	"enum member":     lsp.SKEnumMember,
+	"enumconstant":    lsp.SKEnumMember,
+	"struct":          lsp.SKStruct,
+	"event":           lsp.SKEvent,
+	"operator":        lsp.SKOperator,
+	"type parameter":  lsp.SKTypeParameter,
+	"annotation":      lsp.SKTypeParameter,
+}
+
 func ctagsKindToLSPSymbolKind(kind string) lsp.SymbolKind {
-	// Ctags kinds are determined by the parser and do not (in general) match LSP symbol kinds.
-	switch strings.ToLower(kind) {
-	case "file":
-		return lsp.SKFile
-	case "module":
-		return lsp.SKModule
-	case "namespace":
-		return lsp.SKNamespace
-	case "package", "packagename", "subprogspec":
-		return lsp.SKPackage
-	case "class", "type", "service", "typedef", "union", "section", "subtype", "component":
-		return lsp.SKClass
-	case "method", "methodspec":
-		return lsp.SKMethod
-	case "property":
-		return lsp.SKProperty
-	case "field", "member", "anonmember", "recordfield":
-		return lsp.SKField
-	case "constructor":
-		return lsp.SKConstructor
-	case "enum", "enumerator":
-		return lsp.SKEnum
-	case "interface":
-		return lsp.SKInterface
-	case "function", "func", "subroutine", "macro", "subprogram", "procedure", "command", "singletonmethod":
-		return lsp.SKFunction
-	case "variable", "var", "functionvar", "define", "alias", "val":
-		return lsp.SKVariable
-	case "constant", "const":
-		return lsp.SKConstant
-	case "string", "message", "heredoc":
-		return lsp.SKString
-	case "number":
-		return lsp.SKNumber
-	case "bool", "boolean":
-		return lsp.SKBoolean
-	case "array":
-		return lsp.SKArray
-	case "object", "literal", "map":
-		return lsp.SKObject
-	case "key", "label", "target", "selector", "id", "tag":
-		return lsp.SKKey
-	case "null":
-		return lsp.SKNull
-	case "enum member", "enumconstant":
-		return lsp.SKEnumMember
-	case "struct":
-		return lsp.SKStruct
-	case "event":
-		return lsp.SKEvent
-	case "operator":
-		return lsp.SKOperator
-	case "type parameter", "annotation":
-		return lsp.SKTypeParameter
+	if k, ok := ctagsKinds[strings.ToLower(kind)]; ok {
+		return k
 	}
 	log15.Debug("Unknown ctags kind", "kind", kind)
 	return 0
@@ -9,6 +9,8 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/gituri"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
@@ -257,3 +259,99 @@ func Test_limitingSymbolResults(t *testing.T) {
 		}
 	})
 }
+
+func TestSymbolFiltersFromQuery(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantKinds []string
+		wantErr   bool
+	}{
+		{query: "foo"},
+		{query: "foo select:symbol"},
+		{query: "foo select:symbol.struct", wantKinds: []string{"struct"}},
+		{query: "foo select:symbol.enummember", wantKinds: []string{"enum member", "enumconstant"}},
+		{query: "foo select:symbol.nope", wantErr: true},
+		{query: "foo select:file", wantErr: true},
+		{query: "foo symbolkind:Func symbolkind:method", wantKinds: []string{"func", "method"}},
+		{query: "foo select:symbol.function symbolkind:func symbolkind:method", wantKinds: []string{"func"}},
+		{query: "foo select:symbol.function symbolkind:method", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			q, err := query.ParseAndCheck(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filters, err := symbolFiltersFromQuery(q, false)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, test.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(filters.kinds, test.wantKinds) {
+				t.Errorf("got kinds %q, want %q", filters.kinds, test.wantKinds)
+			}
+		})
+	}
+}
+
+func TestSymbolFilters_filterFileMatches(t *testing.T) {
+	q, err := query.ParseAndCheck("foo symbolkind:method symbolparent:^server$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filters, err := symbolFiltersFromQuery(q, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches := []*FileMatchResolver{
+		{
+			JPath: "a.go",
+			symbols: []*searchSymbolResult{
+				{symbol: protocol.Symbol{Name: "Serve", Kind: "method", Parent: "Server"}},
+				{symbol: protocol.Symbol{Name: "Server", Kind: "struct"}},
+				{symbol: protocol.Symbol{Name: "Close", Kind: "method", Parent: "Client"}},
+			},
+		},
+		{
+			JPath: "b.go",
+			symbols: []*searchSymbolResult{
+				{symbol: protocol.Symbol{Name: "main", Kind: "func"}},
+			},
+		},
+	}
+
+	got := filters.filterFileMatches(matches)
+	if len(got) != 1 || got[0].JPath != "a.go" {
+		t.Fatalf("got %d file matches, want only a.go", len(got))
+	}
+	if len(got[0].symbols) != 1 || got[0].symbols[0].symbol.Name != "Serve" {
+		t.Errorf("got symbols %+v, want only Serve", got[0].symbols)
+	}
+}
+
+func TestSymbolFilters_zoektArgs(t *testing.T) {
+	for _, tc := range []struct {
+		query     string
+		wantLimit int32
+	}{
+		{query: "foo", wantLimit: 30},
+		{query: "foo symbolkind:method", wantLimit: 30 * symbolFiltersOverFetchFactor},
+	} {
+		q, err := query.ParseAndCheck(tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filters, err := symbolFiltersFromQuery(q, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		args := &search.TextParameters{PatternInfo: &search.TextPatternInfo{FileMatchLimit: 30}}
+		if got := filters.zoektArgs(args).PatternInfo.FileMatchLimit; got != tc.wantLimit {
+			t.Errorf("%q: got zoekt file match limit %d, want %d", tc.query, got, tc.wantLimit)
+		}
+		if args.PatternInfo.FileMatchLimit != 30 {
+			t.Errorf("%q: the arguments of the search were modified", tc.query)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
@@ -137,8 +138,72 @@ func (*schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 		return nil, err
 	}
 
+	target, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
 		return nil, err
 	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditUserSetSiteAdmin,
+		TargetType: "user",
+		Target:     target.Username,
+		Before:     fmt.Sprintf("siteAdmin: %t", target.SiteAdmin),
+		After:      fmt.Sprintf("siteAdmin: %t", args.SiteAdmin),
+	})
+	return &EmptyResponse{}, nil
+}
+
+func (*schemaResolver) SuspendUser(ctx context.Context, args *struct {
+	User graphql.ID
+}) (*EmptyResponse, error) {
+	return setUserIsSuspended(ctx, args.User, true)
+}
+
+func (*schemaResolver) UnsuspendUser(ctx context.Context, args *struct {
+	User graphql.ID
+}) (*EmptyResponse, error) {
+	return setUserIsSuspended(ctx, args.User, false)
+}
+
+func setUserIsSuspended(ctx context.Context, id graphql.ID, suspended bool) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins can suspend and unsuspend users.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	currentUser, err := CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if currentUser.ID() == id {
+		return nil, errors.New("unable to suspend or unsuspend current user")
+	}
+
+	userID, err := UnmarshalUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Users.SetIsSuspended(ctx, userID, suspended); err != nil {
+		return nil, err
+	}
+	if suspended {
+		// Sign the user out, so that they must sign in again after they are unsuspended.
+		if err := db.Users.InvalidateSessionsByID(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditUserSetSuspended,
+		TargetType: "user",
+		Target:     target.Username,
+		Before:     fmt.Sprintf("suspended: %t", target.SuspendedAt != nil),
+		After:      fmt.Sprintf("suspended: %t", suspended),
+	})
 	return &EmptyResponse{}, nil
 }
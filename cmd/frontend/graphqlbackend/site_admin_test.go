@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/graph-gophers/graphql-go"
@@ -165,3 +166,126 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestSuspendUser(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{}, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{}).SuspendUser(ctx, &struct{ User graphql.ID }{User: MarshalUserID(2)})
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("err: want %q but got %v", want, err)
+		}
+		if result != nil {
+			t.Errorf("result: want nil but got %v", result)
+		}
+	})
+
+	t.Run("suspend current user", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&schemaResolver{}).SuspendUser(ctx, &struct{ User graphql.ID }{User: MarshalUserID(1)})
+		want := "unable to suspend or unsuspend current user"
+		if err == nil || err.Error() != want {
+			t.Fatalf("err: want %q but got %v", want, err)
+		}
+	})
+
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	var suspended, invalidatedSessions bool
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		user := &types.User{ID: id, Username: "alice"}
+		if suspended {
+			suspendedAt := time.Now()
+			user.SuspendedAt = &suspendedAt
+		}
+		return user, nil
+	}
+	db.Mocks.Users.SetIsSuspended = func(_ context.Context, id int32, s bool) error {
+		if id != 2 {
+			t.Errorf("got user ID %d, want 2", id)
+		}
+		suspended = s
+		return nil
+	}
+	db.Mocks.Users.InvalidateSessionsByID = func(_ context.Context, id int32) error {
+		invalidatedSessions = true
+		return nil
+	}
+	var audited []string
+	db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+		audited = append(audited, e.Action+" "+e.Target+" "+e.Diff)
+		return nil
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: mustParseGraphQLSchema(t),
+			Query: `
+				mutation {
+					suspendUser(user: "VXNlcjoy") {
+						alwaysNil
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"suspendUser": {
+						"alwaysNil": null
+					}
+				}
+			`,
+		},
+	})
+	if !suspended {
+		t.Error("want user to be suspended")
+	}
+	if !invalidatedSessions {
+		t.Error("want sessions of suspended user to be invalidated")
+	}
+
+	invalidatedSessions = false
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: mustParseGraphQLSchema(t),
+			Query: `
+				mutation {
+					unsuspendUser(user: "VXNlcjoy") {
+						alwaysNil
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"unsuspendUser": {
+						"alwaysNil": null
+					}
+				}
+			`,
+		},
+	})
+	if suspended {
+		t.Error("want user to be unsuspended")
+	}
+	if invalidatedSessions {
+		t.Error("want sessions of unsuspended user to be left unchanged")
+	}
+
+	want := []string{
+		"user.set_suspended alice -suspended: false\n+suspended: true",
+		"user.set_suspended alice -suspended: true\n+suspended: false",
+	}
+	if diff := cmp.Diff(want, audited); diff != "" {
+		t.Errorf("audit log: %s", diff)
+	}
+}
@@ -0,0 +1,172 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func (r *siteConfigurationResolver) History(ctx context.Context, args *graphqlutil.ConnectionArgs) (*siteConfigurationVersionConnectionResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return &siteConfigurationVersionConnectionResolver{first: int(args.GetFirst())}, nil
+}
+
+func (r *siteConfigurationResolver) Diff(ctx context.Context, args *struct {
+	From int32
+	To   int32
+}) ([]*siteConfigurationFieldDiffResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	from, err := confdb.SiteGetByID(ctx, args.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := confdb.SiteGetByID(ctx, args.To)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := conf.DiffSiteConfiguration(from.Contents, to.Contents)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]*siteConfigurationFieldDiffResolver, 0, len(diffs))
+	for _, diff := range diffs {
+		l = append(l, &siteConfigurationFieldDiffResolver{diff: diff})
+	}
+	return l, nil
+}
+
+func (r *schemaResolver) RollbackSiteConfiguration(ctx context.Context, args *struct {
+	ToID int32
+}) (bool, error) {
+	// 🚨 SECURITY: Rolling back overwrites the site configuration with a previous
+	// version, so only admins may do it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return false, err
+	}
+	if os.Getenv("SITE_CONFIG_FILE") != "" && !siteConfigAllowEdits {
+		return false, errors.New("rolling back site configuration not allowed when using SITE_CONFIG_FILE")
+	}
+
+	version, err := confdb.SiteGetByID(ctx, args.ToID)
+	if err != nil {
+		return false, err
+	}
+	prev := globals.ConfigurationServerFrontendOnly.Raw()
+	prev.Site = version.Contents
+	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
+		return false, err
+	}
+	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
+}
+
+// siteConfigurationVersionConnectionResolver resolves a list of site
+// configuration versions.
+//
+// 🚨 SECURITY: When instantiating a siteConfigurationVersionConnectionResolver
+// value, the caller MUST check permissions.
+type siteConfigurationVersionConnectionResolver struct {
+	first int // the maximum number of versions to return, or 0 for all
+
+	// cache results because they are used by multiple fields
+	once     sync.Once
+	versions []*confdb.SiteConfig
+	err      error
+}
+
+func (r *siteConfigurationVersionConnectionResolver) compute(ctx context.Context) ([]*confdb.SiteConfig, error) {
+	r.once.Do(func() {
+		limit := r.first
+		if limit > 0 {
+			limit++ // so we can detect if there is a next page
+		}
+		r.versions, r.err = confdb.SiteList(ctx, limit, 0)
+	})
+	return r.versions, r.err
+}
+
+func (r *siteConfigurationVersionConnectionResolver) Nodes(ctx context.Context) ([]*siteConfigurationVersionResolver, error) {
+	versions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.first > 0 && len(versions) > r.first {
+		versions = versions[:r.first]
+	}
+
+	l := make([]*siteConfigurationVersionResolver, 0, len(versions))
+	for _, version := range versions {
+		l = append(l, &siteConfigurationVersionResolver{version: version})
+	}
+	return l, nil
+}
+
+func (r *siteConfigurationVersionConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := confdb.SiteCount(ctx)
+	return int32(count), err
+}
+
+func (r *siteConfigurationVersionConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	versions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.first > 0 && len(versions) > r.first), nil
+}
+
+type siteConfigurationVersionResolver struct {
+	version *confdb.SiteConfig
+}
+
+func (r *siteConfigurationVersionResolver) ID() int32 { return r.version.ID }
+
+func (r *siteConfigurationVersionResolver) Author(ctx context.Context) (*UserResolver, error) {
+	if r.version.AuthorUserID == nil {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, *r.version.AuthorUserID)
+	if err != nil && errcode.IsNotFound(err) {
+		// Don't throw an error if a user has been deleted.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *siteConfigurationVersionResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.version.CreatedAt}
+}
+
+func (r *siteConfigurationVersionResolver) Contents() JSONCString {
+	return JSONCString(r.version.Contents)
+}
+
+type siteConfigurationFieldDiffResolver struct {
+	diff conf.FieldDiff
+}
+
+func (r *siteConfigurationFieldDiffResolver) Field() string { return r.diff.Field }
+
+func (r *siteConfigurationFieldDiffResolver) Before() *JSONValue {
+	return &JSONValue{Value: r.diff.Before}
+}
+
+func (r *siteConfigurationFieldDiffResolver) After() *JSONValue {
+	return &JSONValue{Value: r.diff.After}
+}
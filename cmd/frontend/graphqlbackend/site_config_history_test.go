@@ -0,0 +1,35 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestSiteConfigurationHistory_NonAdmin(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+
+	if _, err := (&siteConfigurationResolver{}).History(ctx, &graphqlutil.ConnectionArgs{}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("History: got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+	if _, err := (&siteConfigurationResolver{}).Diff(ctx, &struct {
+		From int32
+		To   int32
+	}{From: 1, To: 2}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("Diff: got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+	if _, err := (&schemaResolver{}).RollbackSiteConfiguration(ctx, &struct {
+		ToID int32
+	}{ToID: 1}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("RollbackSiteConfiguration: got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+}
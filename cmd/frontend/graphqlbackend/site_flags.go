@@ -69,7 +69,8 @@ func (r *siteResolver) FreeUsersExceeded(ctx context.Context) (bool, error) {
 		return false, nil
 	}
 
-	userCount, err := db.Users.Count(ctx, nil)
+	// Suspended users don't count toward the licensed user count.
+	userCount, err := db.Users.Count(ctx, &db.UsersListOptions{ExcludeSuspended: true})
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,110 @@
+package graphqlbackend
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+)
+
+// checkSubRepoPath returns an error if the current user may not read the file or directory at
+// the given path of repo. The error is the same as for a path that does not exist, so that the
+// existence of the path isn't leaked.
+func checkSubRepoPath(ctx context.Context, repo *types.Repo, name string, isDir bool) error {
+	m, err := db.SubRepoPathMatcher(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	allowed := m.MatchPath(name)
+	if isDir {
+		allowed = m.MatchDir(name)
+	}
+	if !allowed {
+		return &os.PathError{Op: "ls-tree", Path: name, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+// checkSubRepoHistoryPath is like checkSubRepoPath, but for a path that scopes the commit history
+// of repo and may be a file or a directory. Git interprets the path as a pathspec, so paths with
+// wildcards or magic signatures are rejected when the user may only read parts of repo.
+func checkSubRepoHistoryPath(ctx context.Context, repo *types.Repo, name string) error {
+	m, err := db.SubRepoPathMatcher(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return nil
+	}
+	if strings.ContainsAny(name, `*?[\`) || strings.HasPrefix(name, ":") || !m.MatchFileOrDir(name) {
+		return &os.PathError{Op: "log", Path: name, Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+// subRepoPathMatchersForRepos returns the sub-repository permissions matchers of the current
+// user for the given repositories (see db.SubRepoPathMatchers).
+func subRepoPathMatchersForRepos(ctx context.Context, repos []*search.RepositoryRevisions) (map[api.RepoID]*authz.SubRepoPathMatcher, error) {
+	repoIDs := make([]api.RepoID, 0, len(repos))
+	seen := make(map[api.RepoID]bool, len(repos))
+	for _, repoRevs := range repos {
+		if !seen[repoRevs.Repo.ID] {
+			seen[repoRevs.Repo.ID] = true
+			repoIDs = append(repoIDs, repoRevs.Repo.ID)
+		}
+	}
+	return db.SubRepoPathMatchers(ctx, repoIDs...)
+}
+
+// filterFileMatchesBySubRepoPerms removes the file matches in paths that matchers do not match.
+// The matches slice is filtered in place.
+//
+// Searches must filter their matches before they count them towards the result limit, so that
+// the removed matches don't take the place of matches that the user may read.
+func filterFileMatchesBySubRepoPerms(matchers map[api.RepoID]*authz.SubRepoPathMatcher, matches []*FileMatchResolver) []*FileMatchResolver {
+	if len(matchers) == 0 {
+		return matches
+	}
+
+	filtered := matches[:0]
+	for _, fm := range matches {
+		if fm.Repo != nil && matchers[fm.Repo.ID].MatchPath(fm.JPath) {
+			filtered = append(filtered, fm)
+		}
+	}
+	return filtered
+}
+
+// subRepoPermsOverFetchFactor is how many more symbols we ask the symbols service for in
+// repositories that the user may only read partially.
+const subRepoPermsOverFetchFactor = 10
+
+// filterSymbolsBySubRepoPerms removes the symbols in files that matcher does not match. The
+// symbols slice is filtered in place.
+func filterSymbolsBySubRepoPerms(matcher *authz.SubRepoPathMatcher, symbols []*symbolResolver) []*symbolResolver {
+	filtered := symbols[:0]
+	for _, sym := range symbols {
+		if matcher.MatchPath(sym.symbol.Path) {
+			filtered = append(filtered, sym)
+		}
+	}
+	return filtered
+}
+
+// matchFileDiffBySubRepoPerms reports whether matcher allows both the old and the new path of the
+// file diff. Renames and copies out of or into a disallowed path are not allowed, because their
+// hunks reveal the contents of the disallowed file.
+func matchFileDiffBySubRepoPerms(matcher *authz.SubRepoPathMatcher, fileDiff *diff.FileDiff) bool {
+	for _, name := range []string{fileDiff.OrigName, fileDiff.NewName} {
+		if path := diffPathOrNull(name); path != nil && !matcher.MatchPath(*path) {
+			return false
+		}
+	}
+	return true
+}
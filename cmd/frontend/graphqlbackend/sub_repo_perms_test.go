@@ -0,0 +1,250 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/zoekt"
+	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	searchbackend "github.com/sourcegraph/sourcegraph/internal/search/backend"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+func TestFilterFileMatchesBySubRepoPerms(t *testing.T) {
+	m, err := (&authz.SubRepoPermissions{PathIncludes: []string{"docs/**"}}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchers := map[api.RepoID]*authz.SubRepoPathMatcher{1: m}
+
+	restricted := &types.Repo{ID: 1, Name: "a"}
+	unrestricted := &types.Repo{ID: 2, Name: "b"}
+	matches := []*FileMatchResolver{
+		{JPath: "docs/index.md", Repo: restricted},
+		{JPath: "main.go", Repo: restricted},
+		{JPath: "main.go", Repo: unrestricted},
+	}
+
+	var paths []string
+	for _, fm := range filterFileMatchesBySubRepoPerms(matchers, matches) {
+		paths = append(paths, string(fm.Repo.Name)+"/"+fm.JPath)
+	}
+	if want := []string{"a/docs/index.md", "b/main.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+}
+
+// fakeSubRepoPermsProvider is an authz provider for a code host with sub-repository
+// permissions.
+type fakeSubRepoPermsProvider struct {
+	serviceID string
+
+	// Default all unimplemented authz.Provider methods to panic.
+	authz.Provider
+}
+
+func (p *fakeSubRepoPermsProvider) ServiceID() string { return p.serviceID }
+
+func (p *fakeSubRepoPermsProvider) FetchUserSubRepoPerms(context.Context, *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error) {
+	return nil, nil
+}
+
+func TestSearchFilesInRepos_subRepoPerms(t *testing.T) {
+	authz.SetProviders(false, []authz.Provider{&fakeSubRepoPermsProvider{serviceID: "https://perforce.mine/"}})
+	defer authz.SetProviders(true, nil)
+
+	repos := map[api.RepoID]*types.Repo{
+		// The user may only read docs.
+		1: {ID: 1, Name: "partial", ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://perforce.mine/"}},
+		// The sub-repository permissions of the user haven't been synced yet.
+		2: {ID: 2, Name: "unsynced", ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://perforce.mine/"}},
+		// The code host has no sub-repository permissions.
+		3: {ID: 3, Name: "full", ExternalRepo: api.ExternalRepoSpec{ServiceID: "https://github.com/"}},
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	db.Mocks.Authz.SubRepoPermissions = func(ctx context.Context, args *db.SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error) {
+		return map[api.RepoID]*authz.SubRepoPermissions{
+			1: {PathIncludes: []string{"docs/**"}},
+		}, nil
+	}
+	db.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error) {
+		var rs []*types.Repo
+		for _, id := range ids {
+			rs = append(rs, repos[id])
+		}
+		return rs, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	mockSearchFilesInRepo = func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+		paths := []string{"main.go"}
+		if repo.Name == "partial" {
+			// The matches that the user may not read come first, so they would use up the
+			// result limit if they were counted.
+			paths = []string{"secret1.go", "secret2.go", "secret3.go", "docs/index.md"}
+		}
+		for _, p := range paths {
+			matches = append(matches, &FileMatchResolver{
+				JPath: p,
+				uri:   "git://" + string(repo.Name) + "?" + rev + "#" + p,
+				Repo:  repo,
+			})
+		}
+		return matches, false, nil
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	q, err := query.ParseAndCheck("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.TextParameters{
+		PatternInfo: &search.TextPatternInfo{
+			FileMatchLimit: 2,
+			Pattern:        "foo",
+		},
+		Query:        q,
+		Zoekt:        &searchbackend.Zoekt{Client: &fakeSearcher{repos: &zoekt.RepoList{}}},
+		SearcherURLs: endpoint.Static("test"),
+	}
+	for _, id := range []api.RepoID{1, 2, 3} {
+		args.Repos = append(args.Repos, &search.RepositoryRevisions{Repo: repos[id], Revs: []search.RevisionSpecifier{{RevSpec: ""}}})
+	}
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	results, _, err := searchFilesInRepos(ctx, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, fm := range results {
+		paths = append(paths, string(fm.Repo.Name)+"/"+fm.JPath)
+	}
+	sort.Strings(paths)
+	if want := []string{"full/main.go", "partial/docs/index.md"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+
+	// Zoekt applies the result limit after removing the matches.
+	args.PatternInfo.FileMatchLimit = 1
+	args.PatternInfo.PathPatternsAreRegExps = true
+	args.Zoekt = &searchbackend.Zoekt{Client: &fakeSearcher{result: &zoekt.SearchResult{Files: []zoekt.FileMatch{
+		{Repository: "partial", FileName: "secret1.go"},
+		{Repository: "unsynced", FileName: "main.go"},
+		{Repository: "partial", FileName: "docs/index.md"},
+	}}}}
+	results, _, _, err = zoektSearchHEAD(ctx, args, args.Repos, false, time.Since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].JPath != "docs/index.md" {
+		t.Errorf("got %v, want partial/docs/index.md", results)
+	}
+}
+
+func TestReadFileDiffs_subRepoPerms(t *testing.T) {
+	m, err := (&authz.SubRepoPermissions{PathIncludes: []string{"docs/**"}}).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const rawDiff = `diff --git docs/a.md docs/a.md
+index 1111111..2222222 100644
+--- docs/a.md
++++ docs/a.md
+@@ -1 +1 @@
+-a
++b
+diff --git secret.txt secret.txt
+index 1111111..2222222 100644
+--- secret.txt
++++ secret.txt
+@@ -1 +1 @@
+-password1
++password2
+diff --git secret.key docs/key.md
+similarity index 90%
+rename from secret.key
+rename to docs/key.md
+index 1111111..2222222 100644
+--- secret.key
++++ docs/key.md
+@@ -1 +1 @@
+-key1
++key2
+diff --git docs/new.md docs/new.md
+new file mode 100644
+index 0000000..2222222
+--- /dev/null
++++ docs/new.md
+@@ -0,0 +1 @@
++new
+`
+	one, two := int32(1), int32(2)
+	paths := func(fileDiffs []*diff.FileDiff) (names []string) {
+		for _, fd := range fileDiffs {
+			names = append(names, fd.OrigName+" "+fd.NewName)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name            string
+		matcher         *authz.SubRepoPathMatcher
+		first           *int32
+		wantPaths       []string
+		wantHasNextPage bool
+	}{
+		{
+			name:      "unrestricted",
+			wantPaths: []string{"docs/a.md docs/a.md", "secret.txt secret.txt", "secret.key docs/key.md", "/dev/null docs/new.md"},
+		},
+		{
+			name:      "restricted",
+			matcher:   m,
+			wantPaths: []string{"docs/a.md docs/a.md", "/dev/null docs/new.md"},
+		},
+		{
+			name:            "restricted, first page",
+			matcher:         m,
+			first:           &one,
+			wantPaths:       []string{"docs/a.md docs/a.md"},
+			wantHasNextPage: true,
+		},
+		{
+			name:      "restricted, omitted diffs don't count as next page",
+			matcher:   m,
+			first:     &two,
+			wantPaths: []string{"docs/a.md docs/a.md", "/dev/null docs/new.md"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileDiffs, hasNextPage, err := readFileDiffs(diff.NewMultiFileDiffReader(strings.NewReader(rawDiff)), test.matcher, test.first)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(fileDiffs); !reflect.DeepEqual(got, test.wantPaths) {
+				t.Errorf("got file diffs %q, want %q", got, test.wantPaths)
+			}
+			if hasNextPage != test.wantHasNextPage {
+				t.Errorf("got hasNextPage %v, want %v", hasNextPage, test.wantHasNextPage)
+			}
+		})
+	}
+}
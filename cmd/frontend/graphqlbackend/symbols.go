@@ -10,6 +10,7 @@ import (
 	"github.com/google/zoekt"
 	zoektquery "github.com/google/zoekt/query"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gituri"
@@ -164,6 +165,18 @@ func searchZoektSymbols(ctx context.Context, commit *GitCommitResolver, queryStr
 }
 
 func computeSymbols(ctx context.Context, commit *GitCommitResolver, query *string, first *int32, includePatterns *[]string) (res []*symbolResolver, err error) {
+	// 🚨 SECURITY: Symbols reveal file contents, so only return the symbols in files that the
+	// user may read due to sub-repository permissions.
+	matcher, err := db.SubRepoPathMatcher(ctx, commit.repo.repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if matcher != nil {
+			res = filterSymbolsBySubRepoPerms(matcher, res)
+		}
+	}()
+
 	if indexedSymbols(string(commit.repo.repo.Name), string(commit.oid)) {
 		return searchZoektSymbols(ctx, commit, query, first, includePatterns)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -188,8 +189,23 @@ var mockTextSearch func(ctx context.Context, repo gitserver.Repo, commit api.Com
 // textSearch searches repo@commit with p.
 // Note: the returned matches do not set fileMatch.uri
 func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+	limitHit, err = textSearchStream(ctx, searcherURLs, repo, commit, p, fetchTimeout, func(fm *FileMatchResolver) {
+		matches = append(matches, fm)
+	})
+	return matches, limitHit, err
+}
+
+// textSearchStream searches repo@commit with p and calls onMatch with each
+// match as soon as searcher sends it. The matches passed to onMatch before an
+// error is returned are partial results.
+// Note: the matches do not set fileMatch.uri
+func textSearchStream(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatch func(*FileMatchResolver)) (limitHit bool, err error) {
 	if mockTextSearch != nil {
-		return mockTextSearch(ctx, repo, commit, p, fetchTimeout)
+		matches, limitHit, err := mockTextSearch(ctx, repo, commit, p, fetchTimeout)
+		for _, fm := range matches {
+			onMatch(fm)
+		}
+		return limitHit, err
 	}
 
 	tr, ctx := trace.New(ctx, "searcher.client", fmt.Sprintf("%s@%s", repo.Name, commit))
@@ -199,20 +215,19 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	}()
 
 	q := url.Values{
-		"Repo":            []string{string(repo.Name)},
-		"URL":             []string{repo.URL},
-		"Commit":          []string{string(commit)},
-		"Pattern":         []string{p.Pattern},
-		"ExcludePattern":  []string{p.ExcludePattern},
-		"IncludePatterns": p.IncludePatterns,
-		"FetchTimeout":    []string{fetchTimeout.String()},
-		"Languages":       p.Languages,
-		"CombyRule":       []string{p.CombyRule},
+		"Repo":           []string{string(repo.Name)},
+		"URL":            []string{repo.URL},
+		"Commit":         []string{string(commit)},
+		"Pattern":        []string{p.Pattern},
+		"ExcludePattern": []string{p.ExcludePattern},
+		"FetchTimeout":   []string{fetchTimeout.String()},
+		"Languages":      p.Languages,
+		"CombyRule":      []string{p.CombyRule},
 	}
 	if deadline, ok := ctx.Deadline(); ok {
 		t, err := deadline.MarshalText()
 		if err != nil {
-			return nil, false, err
+			return false, err
 		}
 		q.Set("Deadline", string(t))
 	}
@@ -239,8 +254,20 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	// these fields from old frontends that do not (and provide a default in the latter case).
 	q.Set("PatternMatchesContent", strconv.FormatBool(p.PatternMatchesContent))
 	q.Set("PatternMatchesPath", strconv.FormatBool(p.PatternMatchesPath))
+	// Ask searcher to stream matches so that we keep the matches found so
+	// far if our deadline is hit while reading the response.
+	q.Set("Stream", "true")
 	rawQuery := q.Encode()
 
+	// The lists of file paths and include patterns are unbounded (e.g. the
+	// candidate files of a structural search, or the changed files of a diff
+	// scoped search), so they are sent in the request body rather than the
+	// URL, which would hit URL length limits.
+	body := url.Values{
+		"FilePaths":       p.FilePaths,
+		"IncludePatterns": p.IncludePatterns,
+	}.Encode()
+
 	// Searcher caches the file contents for repo@commit since it is
 	// relatively expensive to fetch from gitserver. So we use consistent
 	// hashing to increase cache hits.
@@ -252,13 +279,21 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 		excludedSearchURLs = map[string]bool{}
 		attempt            = 0
 		maxAttempts        = 2
+
+		// Matches passed on to onMatch can't be taken back, so we only retry
+		// if the failed attempt did not stream any.
+		streamed = 0
 	)
+	onStreamedMatch := func(fm *FileMatchResolver) {
+		streamed++
+		onMatch(fm)
+	}
 	for {
 		attempt++
 
 		searcherURL, err := searcherURLs.Get(consistentHashKey, excludedSearchURLs)
 		if err != nil {
-			return nil, false, err
+			return false, err
 		}
 
 		// Fallback to a bad host if nothing is left
@@ -266,25 +301,25 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 			tr.LazyPrintf("failed to find endpoint, trying again without excludes")
 			searcherURL, err = searcherURLs.Get(consistentHashKey, nil)
 			if err != nil {
-				return nil, false, err
+				return false, err
 			}
 		}
 
 		url := searcherURL + "?" + rawQuery
 		tr.LazyPrintf("attempt %d: %s", attempt, url)
-		matches, limitHit, err = textSearchURL(ctx, url)
+		limitHit, err = textSearchURL(ctx, url, body, onStreamedMatch)
 		if err == nil || errcode.IsTimeout(err) {
-			return matches, limitHit, err
+			return limitHit, err
 		}
 
 		// If we are canceled, return that error.
 		if err := ctx.Err(); err != nil {
-			return nil, false, err
+			return false, err
 		}
 
 		// If not temporary or our last attempt then don't try again.
-		if !errcode.IsTemporary(err) || attempt == maxAttempts {
-			return nil, false, err
+		if !errcode.IsTemporary(err) || attempt == maxAttempts || streamed > 0 {
+			return false, err
 		}
 
 		tr.LazyPrintf("transient error %s", err.Error())
@@ -293,11 +328,15 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	}
 }
 
-func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// textSearchURL sends a search request to the searcher at url and calls
+// onMatch with each match in the response. body contains the form-encoded
+// parameters that are too large for the URL.
+func textSearchURL(ctx context.Context, url, body string, onMatch func(*FileMatchResolver)) (bool, error) {
+	req, err := http.NewRequest("POST", url, strings.NewReader(body))
 	if err != nil {
-		return nil, false, err
+		return false, err
 	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req = req.WithContext(ctx)
 
 	req, ht := nethttp.TraceRequest(ot.GetTracer(ctx), req,
@@ -315,17 +354,23 @@ func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool,
 		if ctx.Err() != nil {
 			err = ctx.Err()
 		}
-		return nil, false, errors.Wrap(err, "searcher request failed")
+		return false, errors.Wrap(err, "searcher request failed")
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
-			return nil, false, err
+			return false, err
 		}
-		return nil, false, errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
+		return false, errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
+	}
+
+	if resp.Header.Get("Content-Type") == searcherStreamContentType {
+		return decodeSearcherStream(ctx, resp.Body, onMatch)
 	}
 
+	// BACKCOMPAT: searchers which do not support streaming ignore the
+	// Stream parameter and respond with all matches at once.
 	r := struct {
 		Matches     []*FileMatchResolver
 		LimitHit    bool
@@ -333,12 +378,58 @@ func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool,
 	}{}
 	err = json.NewDecoder(resp.Body).Decode(&r)
 	if err != nil {
-		return nil, false, errors.Wrap(err, "searcher response invalid")
+		return false, errors.Wrap(err, "searcher response invalid")
+	}
+	for _, fm := range r.Matches {
+		onMatch(fm)
 	}
 	if r.DeadlineHit {
 		err = context.DeadlineExceeded
 	}
-	return r.Matches, r.LimitHit, err
+	return r.LimitHit, err
+}
+
+// searcherStreamContentType is the Content-Type of a streaming searcher
+// response. It must match protocol.StreamContentType in cmd/searcher.
+const searcherStreamContentType = "application/x-ndjson"
+
+// decodeSearcherStream reads newline delimited events from a streaming
+// searcher response and calls onMatch with each match as it arrives. If the
+// stream is cut short because ctx is done, ctx.Err() is returned.
+func decodeSearcherStream(ctx context.Context, body io.Reader, onMatch func(*FileMatchResolver)) (limitHit bool, err error) {
+	dec := json.NewDecoder(body)
+	for {
+		var ev struct {
+			Match *FileMatchResolver
+			Done  *struct {
+				LimitHit    bool
+				DeadlineHit bool
+				Error       string
+			}
+		}
+		if err := dec.Decode(&ev); err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return false, errors.Wrap(err, "searcher response invalid")
+		}
+
+		if ev.Done != nil {
+			if ev.Done.Error != "" {
+				return false, errors.WithStack(&searcherError{StatusCode: http.StatusInternalServerError, Message: ev.Done.Error})
+			}
+			if ev.Done.DeadlineHit {
+				err = context.DeadlineExceeded
+			}
+			return ev.Done.LimitHit, err
+		}
+		if ev.Match != nil {
+			onMatch(ev.Match)
+		}
+	}
 }
 
 type searcherError struct {
@@ -360,9 +451,15 @@ func (e *searcherError) Error() string {
 
 var mockSearchFilesInRepo func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error)
 
-func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+// searchFilesInRepo searches repo at rev and calls onMatch with each match as
+// soon as searcher finds it.
+func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration, onMatch func(*FileMatchResolver)) (limitHit bool, err error) {
 	if mockSearchFilesInRepo != nil {
-		return mockSearchFilesInRepo(ctx, repo, gitserverRepo, rev, info, fetchTimeout)
+		matches, limitHit, err := mockSearchFilesInRepo(ctx, repo, gitserverRepo, rev, info, fetchTimeout)
+		for _, fm := range matches {
+			onMatch(fm)
+		}
+		return limitHit, err
 	}
 
 	// Do not trigger a repo-updater lookup (e.g.,
@@ -371,31 +468,25 @@ func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo *ty
 	// repo is not on gitserver.
 	commit, err := git.ResolveRevision(ctx, gitserverRepo, nil, rev, &git.ResolveRevisionOptions{NoEnsureRevision: true})
 	if err != nil {
-		return nil, false, err
+		return false, err
 	}
 
 	shouldBeSearched, err := repoShouldBeSearched(ctx, searcherURLs, info, gitserverRepo, commit, fetchTimeout)
 	if err != nil {
-		return nil, false, err
+		return false, err
 	}
 	if !shouldBeSearched {
-		return nil, false, err
-	}
-
-	matches, limitHit, err = textSearch(ctx, searcherURLs, gitserverRepo, commit, info, fetchTimeout)
-	if err != nil {
-		return nil, false, err
+		return false, err
 	}
 
 	workspace := fileMatchURI(repo.Name, rev, "")
-	for _, fm := range matches {
+	return textSearchStream(ctx, searcherURLs, gitserverRepo, commit, info, fetchTimeout, func(fm *FileMatchResolver) {
 		fm.uri = workspace + fm.JPath
 		fm.Repo = repo
 		fm.CommitID = commit
 		fm.InputRev = &rev
-	}
-
-	return matches, limitHit, err
+		onMatch(fm)
+	})
 }
 
 // repoShouldBeSearched determines whether a repository should be searched in, based on whether the repository
@@ -511,6 +602,13 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 		return nil, common, nil
 	}
 
+	// 🚨 SECURITY: Searcher and Zoekt search whole repositories, so the matches in paths that the
+	// user may not read are removed before they count towards the result limit.
+	subRepoMatchers, err := subRepoPathMatchersForRepos(ctx, args.Repos)
+	if err != nil {
+		return nil, common, err
+	}
+
 	// Support index:yes (default), index:only, and index:no in search query.
 	index, _ := args.Query.StringValues(query.FieldIndex)
 	if len(index) > 0 {
@@ -540,6 +638,28 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 		}
 	}
 
+	// Support rev:base...head, which restricts the search to the files
+	// changed on the searched revision since it diverged from base.
+	if rev, _ := args.Query.StringValue(query.FieldRev); rev != "" {
+		scope, err := query.ParseRevScope(rev)
+		if err != nil {
+			return nil, common, &badRequestError{err}
+		}
+		if scope.IsDiff() {
+			changed, err := resolveDiffScope(ctx, common, scope.Base, zoektRepos, searcherRepos)
+			if err != nil {
+				return nil, common, err
+			}
+			zoektRepos = filterRepoRevsWithChangedPaths(zoektRepos, changed)
+			searcherRepos = filterRepoRevsWithChangedPaths(searcherRepos, changed)
+			tr.LazyPrintf("rev:%s, %d repos with changes", rev, len(changed))
+
+			argsCopy := *args
+			argsCopy.ChangedPaths = changed
+			args = &argsCopy
+		}
+	}
+
 	var (
 		// TODO: convert wg to an errgroup
 		wg                sync.WaitGroup
@@ -550,29 +670,40 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 		overLimitCanceled bool // canceled because we were over the limit
 	)
 
-	// addMatches assumes the caller holds mu.
-	addMatches := func(matches []*FileMatchResolver) {
+	// countMatches assumes the caller holds mu.
+	countMatches := func(n int) {
+		common.resultCount += int32(n)
+		flattenedSize += n
+
+		// Stop searching once we have found enough matches. This does
+		// lead to potentially unstable result ordering, but is worth
+		// it for the performance benefit.
+		if flattenedSize > int(args.PatternInfo.FileMatchLimit) && !overLimitCanceled {
+			tr.LazyPrintf("cancel due to result size: %d > %d", flattenedSize, args.PatternInfo.FileMatchLimit)
+			overLimitCanceled = true
+			common.limitHit = true
+			cancel()
+		}
+	}
+
+	// appendMatches adds the matches of one repository, which have already
+	// been counted with countMatches. It assumes the caller holds mu.
+	appendMatches := func(matches []*FileMatchResolver) {
 		if len(matches) > 0 {
-			common.resultCount += int32(len(matches))
 			sort.Slice(matches, func(i, j int) bool {
 				a, b := matches[i].uri, matches[j].uri
 				return a > b
 			})
 			unflattened = append(unflattened, matches)
-			flattenedSize += len(matches)
-
-			// Stop searching once we have found enough matches. This does
-			// lead to potentially unstable result ordering, but is worth
-			// it for the performance benefit.
-			if flattenedSize > int(args.PatternInfo.FileMatchLimit) {
-				tr.LazyPrintf("cancel due to result size: %d > %d", flattenedSize, args.PatternInfo.FileMatchLimit)
-				overLimitCanceled = true
-				common.limitHit = true
-				cancel()
-			}
 		}
 	}
 
+	// addMatches assumes the caller holds mu.
+	addMatches := func(matches []*FileMatchResolver) {
+		countMatches(len(matches))
+		appendMatches(matches)
+	}
+
 	// callSearcherOverRepos calls searcher on a set of repos.
 	// searcherReposFilteredFiles is an optional map of {repo name => file list}
 	// that forces the searcher to only include the file list in the
@@ -631,32 +762,78 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 					break outer
 				}
 
-				// Make a new repoRev for just the operation of searching this revspec.
-				repoRev := &search.RepositoryRevisions{Repo: repoAllRevs.Repo, Revs: []search.RevisionSpecifier{{RevSpec: rev}}}
-
 				args := *args
+				indexedCommit := false
 				if args.PatternInfo.IsStructuralPat && searcherReposFilteredFiles != nil {
 					// Modify the search query to only run for the filtered files
-					if v, ok := searcherReposFilteredFiles[string(repoRev.Repo.Name)]; ok {
+					if v, ok := searcherReposFilteredFiles[string(repoAllRevs.Repo.Name)]; ok {
+						filePaths := append([]string{}, v...)
+						if paths, ok := args.ChangedPaths[repoAllRevs.Repo.Name]; ok {
+							// Only search the files changed in the diff scope.
+							filePaths = filterChangedPaths(filePaths, paths)
+						} else if args.ChangedPaths != nil {
+							filePaths = nil
+						}
+						if len(filePaths) == 0 {
+							limitDone()
+							continue
+						}
 						patternCopy := *args.PatternInfo
 						args.PatternInfo = &patternCopy
-						includePatternsCopy := []string{}
-						args.PatternInfo.IncludePatterns = append(includePatternsCopy, v...)
+						args.PatternInfo.FilePaths = filePaths
+						// The files were found in the indexed commit, which
+						// may lag behind HEAD, so search that commit.
+						if commit := repoAllRevs.IndexedHEADCommit(); commit != "" {
+							rev = string(commit)
+							indexedCommit = true
+						}
 					}
+				} else if paths, ok := args.ChangedPaths[repoAllRevs.Repo.Name]; ok {
+					// Only search the files changed in the diff scope.
+					patternCopy := *args.PatternInfo
+					args.PatternInfo = &patternCopy
+					includePatternsCopy := append([]string{}, patternCopy.IncludePatterns...)
+					args.PatternInfo.IncludePatterns = append(includePatternsCopy, changedPathsPattern(paths))
 				}
 
+				// Make a new repoRev for just the operation of searching this revspec.
+				repoRev := &search.RepositoryRevisions{Repo: repoAllRevs.Repo, Revs: []search.RevisionSpecifier{{RevSpec: rev}}}
+
 				wg.Add(1)
 				go func(ctx context.Context, done context.CancelFunc) {
 					defer wg.Done()
 					defer done()
 
-					matches, repoLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], args.PatternInfo, fetchTimeout)
+					// Count the matches as searcher streams them, so that we
+					// stop searching as soon as we have found enough.
+					var matches []*FileMatchResolver
+					subRepoMatcher := subRepoMatchers[repoRev.Repo.ID]
+					repoLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], args.PatternInfo, fetchTimeout, func(fm *FileMatchResolver) {
+						if !subRepoMatcher.MatchPath(fm.JPath) {
+							return
+						}
+						if indexedCommit {
+							// Link to HEAD like other indexed search results.
+							fm.uri = fileMatchURI(repoRev.Repo.Name, "", fm.JPath)
+							fm.InputRev = nil
+						}
+						matches = append(matches, fm)
+						mu.Lock()
+						countMatches(1)
+						mu.Unlock()
+					})
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil && overLimitCanceled && len(matches) > 0 && ctx.Err() == context.Canceled {
+						// We stopped this repository's stream ourselves because
+						// enough matches were found, so keep what it sent.
+						err = nil
+						repoLimitHit = true
+					}
 					if err != nil {
 						tr.LogFields(otlog.String("repo", string(repoRev.Repo.Name)), otlog.Error(err), otlog.Bool("timeout", errcode.IsTimeout(err)), otlog.Bool("temporary", errcode.IsTemporary(err)))
 						log15.Warn("searchFilesInRepo failed", "error", err, "repo", repoRev.Repo.Name)
 					}
-					mu.Lock()
-					defer mu.Unlock()
 					if ctx.Err() == nil {
 						common.searched = append(common.searched, repoRev.Repo)
 					}
@@ -680,7 +857,7 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 							cancel()
 						}
 					}
-					addMatches(matches)
+					appendMatches(matches)
 				}(limitCtx, limitDone) // ends the Go routine for a call to searcher for a repo
 			} // ends the for loop iterating over repo's revs
 		} // ends the for loop iterating over repos
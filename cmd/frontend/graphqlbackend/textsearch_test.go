@@ -3,10 +3,13 @@ package graphqlbackend
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -434,6 +437,137 @@ func TestSearchFilesInRepos_multipleRevsPerRepo(t *testing.T) {
 	}
 }
 
+func TestSearchFilesInRepos_diffScope(t *testing.T) {
+	var mu sync.Mutex
+	searched := map[api.RepoName][]string{}
+	mockSearchFilesInRepo = func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		searched[repo.Name] = info.IncludePatterns
+		return []*FileMatchResolver{{uri: "git://" + string(repo.Name) + "?" + rev + "#" + "main.go"}}, false, nil
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	git.Mocks.ChangedPaths = func(base, head string) ([]string, error) {
+		if base != "main" {
+			t.Errorf("got base %q, want main", base)
+		}
+		switch head {
+		case "b1":
+			return []string{"main.go", "cmd/a+b.go"}, nil
+		case "b2":
+			return nil, nil
+		default:
+			return nil, &gitserver.RevisionNotFoundError{Spec: base + "..." + head}
+		}
+	}
+	defer git.ResetMocks()
+
+	zoekt := &searchbackend.Zoekt{Client: &fakeSearcher{repos: &zoekt.RepoList{}}}
+
+	q, err := query.ParseAndCheck("foo rev:main...b1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.TextParameters{
+		PatternInfo: &search.TextPatternInfo{
+			FileMatchLimit:  defaultMaxSearchResults,
+			Pattern:         "foo",
+			IncludePatterns: []string{`\.go$`},
+		},
+		// The heads differ from rev: so that the mock can tell the repos
+		// apart.
+		Repos:        makeRepositoryRevisions("foo/changed@b1", "foo/unchanged@b2", "foo/no-base@b3"),
+		Query:        q,
+		Zoekt:        zoekt,
+		SearcherURLs: endpoint.Static("test"),
+	}
+	results, common, err := searchFilesInRepos(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected one result, got %d", len(results))
+	}
+
+	want := map[api.RepoName][]string{
+		"foo/changed": {`\.go$`, `^(?:main\.go|cmd/a\+b\.go)$`},
+	}
+	if !reflect.DeepEqual(searched, want) {
+		t.Errorf("got include patterns %v, want %v", searched, want)
+	}
+	if v := toRepoNames(common.missing); !reflect.DeepEqual(v, []api.RepoName{"foo/no-base"}) {
+		t.Errorf("unexpected missing: %v", v)
+	}
+	if len(args.PatternInfo.IncludePatterns) != 1 {
+		t.Errorf("expected the include patterns of args to be unchanged, got %v", args.PatternInfo.IncludePatterns)
+	}
+}
+
+func TestSearchFilesInRepos_structuralDiffScope(t *testing.T) {
+	var mu sync.Mutex
+	searched := map[api.RepoName][]string{}
+	mockSearchFilesInRepo = func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		searched[repo.Name] = info.FilePaths
+		return []*FileMatchResolver{{uri: "git://" + string(repo.Name) + "?" + rev + "#" + "main.go"}}, false, nil
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	git.Mocks.ChangedPaths = func(base, head string) ([]string, error) {
+		return []string{"main.go"}, nil
+	}
+	defer git.ResetMocks()
+
+	// The fake zoekt ignores the query, so it also returns a file which
+	// didn't change.
+	zoekt := &searchbackend.Zoekt{Client: &fakeSearcher{
+		repos: &zoekt.RepoList{Repos: []*zoekt.RepoListEntry{{
+			Repository: zoekt.Repository{
+				Name:     "foo/indexed",
+				Branches: []zoekt.RepositoryBranch{{Name: "HEAD", Version: "deadbeef"}},
+			},
+		}}},
+		result: &zoekt.SearchResult{Files: []zoekt.FileMatch{
+			{Repository: "foo/indexed", FileName: "main.go"},
+			{Repository: "foo/indexed", FileName: "unchanged.go"},
+		}},
+	}}
+
+	q, err := query.ParseAndCheck("foo rev:main...HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.TextParameters{
+		PatternInfo: &search.TextPatternInfo{
+			FileMatchLimit:         defaultMaxSearchResults,
+			Pattern:                "foo",
+			IsStructuralPat:        true,
+			PathPatternsAreRegExps: true,
+		},
+		Repos:        makeRepositoryRevisions("foo/indexed@"),
+		Query:        q,
+		Zoekt:        zoekt,
+		SearcherURLs: endpoint.Static("test"),
+	}
+	if _, _, err := searchFilesInRepos(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[api.RepoName][]string{"foo/indexed": {"main.go"}}
+	if !reflect.DeepEqual(searched, want) {
+		t.Errorf("got file paths %v, want %v", searched, want)
+	}
+}
+
+func TestFilterChangedPaths(t *testing.T) {
+	got := filterChangedPaths([]string{"a.go", "b.go", "c.go"}, []string{"c.go", "a.go", "d.go"})
+	if want := []string{"a.go", "c.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func TestRepoShouldBeSearched(t *testing.T) {
 	mockTextSearch = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
 		repoName := repo.Name
@@ -954,3 +1088,110 @@ func Benchmark_zoektIndexedRepos(b *testing.B) {
 		_, _, _ = zoektIndexedRepos(ctx, z, repos, nil)
 	}
 }
+
+func TestDecodeSearcherStream(t *testing.T) {
+	decode := func(body string) (paths []string, limitHit bool, err error) {
+		limitHit, err = decodeSearcherStream(context.Background(), strings.NewReader(body), func(fm *FileMatchResolver) {
+			paths = append(paths, fm.JPath)
+		})
+		return paths, limitHit, err
+	}
+
+	body := `{"Match":{"Path":"a.go","LineMatches":[{"Preview":"foo","LineNumber":1,"OffsetAndLengths":[[0,3]]}]}}
+{"Match":{"Path":"b.go"}}
+{"Done":{"LimitHit":true}}
+`
+	paths, limitHit, err := decode(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !limitHit {
+		t.Error("expected limitHit")
+	}
+	if want := []string{"a.go", "b.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+
+	// A stream without a trailer is invalid.
+	_, _, err = decode(`{"Match":{"Path":"a.go"}}`)
+	if err == nil {
+		t.Error("expected error for truncated stream")
+	}
+
+	// Errors reported in the trailer are returned.
+	_, _, err = decode(`{"Done":{"Error":"boom"}}`)
+	if err == nil || err.Error() != "boom" {
+		t.Errorf("got error %v, want boom", err)
+	}
+}
+
+func TestTextSearchStream_matchesBeforeResponseEnds(t *testing.T) {
+	received := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", searcherStreamContentType)
+		_, _ = w.Write([]byte(`{"Match":{"Path":"a.go"}}` + "\n"))
+		w.(http.Flusher).Flush()
+
+		// Only finish the response once the client has seen the first match.
+		select {
+		case <-received:
+		case <-time.After(10 * time.Second):
+			t.Error("match was not passed on before the response ended")
+		}
+		_, _ = w.Write([]byte(`{"Match":{"Path":"b.go"}}` + "\n" + `{"Done":{}}` + "\n"))
+	}))
+	defer srv.Close()
+
+	var paths []string
+	_, err := textSearchStream(context.Background(), endpoint.Static(srv.URL), gitserver.Repo{Name: "r"}, "c", &search.TextPatternInfo{Pattern: "foo"}, time.Minute, func(fm *FileMatchResolver) {
+		if len(paths) == 0 {
+			close(received)
+		}
+		paths = append(paths, fm.JPath)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.go", "b.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
+
+func TestTextSearch_requestBody(t *testing.T) {
+	filePaths := []string{"a.go", "dir/b c.go"}
+	includePatterns := []string{changedPathsPattern(filePaths)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("got method %s, want POST", r.Method)
+		}
+		for _, key := range []string{"FilePaths", "IncludePatterns"} {
+			if got := r.URL.Query()[key]; len(got) != 0 {
+				t.Errorf("got %s %q in the URL, want them in the body", key, got)
+			}
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Form["FilePaths"]; !reflect.DeepEqual(got, filePaths) {
+			t.Errorf("got FilePaths %q, want %q", got, filePaths)
+		}
+		if got := r.Form["IncludePatterns"]; !reflect.DeepEqual(got, includePatterns) {
+			t.Errorf("got IncludePatterns %q, want %q", got, includePatterns)
+		}
+		if got, want := r.Form.Get("Pattern"), "foo"; got != want {
+			t.Errorf("got Pattern %q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", searcherStreamContentType)
+		_, _ = w.Write([]byte(`{"Match":{"Path":"a.go"}}` + "\n" + `{"Done":{}}` + "\n"))
+	}))
+	defer srv.Close()
+
+	p := &search.TextPatternInfo{Pattern: "foo", IsStructuralPat: true, FilePaths: filePaths, IncludePatterns: includePatterns}
+	matches, _, err := textSearch(context.Background(), endpoint.Static(srv.URL), gitserver.Repo{Name: "r"}, "c", p, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].JPath != "a.go" {
+		t.Errorf("got matches %+v, want a.go", matches)
+	}
+}
@@ -177,6 +177,18 @@ func (r *UserResolver) SiteAdmin(ctx context.Context) (bool, error) {
 	return r.user.SiteAdmin, nil
 }
 
+func (r *UserResolver) SuspendedAt(ctx context.Context) (*DateTime, error) {
+	// 🚨 SECURITY: Only site admins are allowed to determine if the user is suspended.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if r.user.SuspendedAt == nil {
+		return nil, nil
+	}
+	return &DateTime{Time: *r.user.SuspendedAt}, nil
+}
+
 func (*schemaResolver) UpdateUser(ctx context.Context, args *struct {
 	User        graphql.ID
 	Username    *string
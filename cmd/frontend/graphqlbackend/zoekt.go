@@ -96,6 +96,13 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 	if err != nil {
 		return nil, false, nil, err
 	}
+	if args.ChangedPaths != nil {
+		changed, err := changedPathsQuery(args.ChangedPaths)
+		if err != nil {
+			return nil, false, nil, err
+		}
+		queryExceptRepos = zoektquery.NewAnd(queryExceptRepos, changed)
+	}
 	finalQuery := zoektquery.NewAnd(repoSet, queryExceptRepos)
 
 	tr, ctx := trace.New(ctx, "zoekt.Search", fmt.Sprintf("%d %+v", len(repoSet.Set), finalQuery.String()))
@@ -107,6 +114,14 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 		tr.Finish()
 	}()
 
+	// 🚨 SECURITY: Zoekt searches whole repositories, so the matches in paths that the user may
+	// not read are removed before the file match limit is applied. The matchers are loaded
+	// before ctx may be replaced by one without the actor below.
+	subRepoMatchers, err := subRepoPathMatchersForRepos(ctx, repos)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
 	k := zoektResultCountFactor(len(repos), args.PatternInfo)
 	searchOpts := zoektSearchOpts(k, args.PatternInfo)
 
@@ -164,6 +179,17 @@ func zoektSearchHEAD(ctx context.Context, args *search.TextParameters, repos []*
 		}
 	}
 
+	if len(subRepoMatchers) > 0 {
+		files := resp.Files[:0]
+		for _, file := range resp.Files {
+			repoRev := repoMap[api.RepoName(strings.ToLower(file.Repository))]
+			if repoRev != nil && subRepoMatchers[repoRev.Repo.ID].MatchPath(file.FileName) {
+				files = append(files, file)
+			}
+		}
+		resp.Files = files
+	}
+
 	if len(resp.Files) == 0 {
 		return nil, false, nil, nil
 	}
@@ -0,0 +1,10 @@
+package httpapi
+
+import (
+	"net/http"
+)
+
+// NewSCIMHandler returns the handler for the SCIM 2.0 user and group provisioning API.
+//
+// Set by enterprise frontend
+var NewSCIMHandler func() http.Handler
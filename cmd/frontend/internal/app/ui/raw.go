@@ -16,8 +16,12 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 
 	"github.com/golang/gddo/httputil"
 	"github.com/gorilla/mux"
@@ -88,6 +92,17 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 		requestedPath = "/" + requestedPath
 	}
 
+	// 🚨 SECURITY: Only serve the paths that the user may read due to sub-repository
+	// permissions. A nil matcher matches every path.
+	matcher, err := db.SubRepoPathMatcher(r.Context(), common.Repo.ID)
+	if err != nil {
+		return err
+	}
+	notFound := func() {
+		err := &os.PathError{Op: "open", Path: requestedPath, Err: os.ErrNotExist}
+		http.Error(w, html.EscapeString(err.Error()), http.StatusNotFound)
+	}
+
 	if requestedPath == "/" && r.Method == "HEAD" {
 		_, err = gitserver.DefaultClient.RepoInfo(r.Context(), common.Repo.Name)
 		if err != nil {
@@ -170,6 +185,29 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 			requestType = "patharchive"
 		}
 
+		var paths []string
+		if matcher != nil {
+			paths, err = subRepoArchivePaths(r.Context(), gitserver.Repo{Name: common.Repo.Name}, common.CommitID, relativePath, matcher)
+			if err != nil {
+				if os.IsNotExist(err) {
+					requestType = "404"
+					notFound()
+					return nil // request handled
+				}
+				return err
+			}
+			if len(paths) == 0 {
+				requestType = "404"
+				notFound()
+				return nil // request handled
+			}
+			if len(paths) > maxSubRepoArchivePaths {
+				requestType = "400"
+				http.Error(w, fmt.Sprintf("Too many files to archive (%d, limit %d). Download a subdirectory instead.", len(paths), maxSubRepoArchivePaths), http.StatusBadRequest)
+				return nil // request handled
+			}
+		}
+
 		metricRunning := metricRawArchiveRunning.WithLabelValues(string(format))
 		metricRunning.Inc()
 		defer metricRunning.Dec()
@@ -179,7 +217,7 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 			Commit:       common.CommitID,
 			Format:       format,
 			RelativePath: relativePath,
-		})
+		}, paths)
 		if err != nil {
 			return err
 		}
@@ -241,6 +279,11 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 			}
 			return err
 		}
+		if fi.IsDir() && !matcher.MatchDir(requestedPath) || !fi.IsDir() && !matcher.MatchPath(requestedPath) {
+			requestType = "404"
+			notFound()
+			return nil // request handled
+		}
 		if fi.IsDir() {
 			requestType = "dir"
 			infos, err := archiveFS.ReadDir(r.Context(), requestedPath)
@@ -251,6 +294,10 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 			var names []string
 			for _, info := range infos {
 				name := info.Name()
+				entryPath := path.Join(requestedPath, name)
+				if info.IsDir() && !matcher.MatchDir(entryPath) || !info.IsDir() && !matcher.MatchPath(entryPath) {
+					continue
+				}
 				if info.IsDir() {
 					name = name + "/"
 				}
@@ -278,12 +325,69 @@ func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
 // use vfsutil since most archives are just streamed once so caching locally
 // is not useful. Additionally we transfer the output over the internet, so we
 // use default compression levels on zips (instead of no compression).
-func openArchiveReader(ctx context.Context, opts vfsutil.ArchiveOpts) (io.ReadCloser, error) {
-	cmd := gitserver.DefaultClient.Command("git", "archive", "--format="+string(opts.Format), string(opts.Commit), opts.RelativePath)
+//
+// If paths is non-empty, only those paths are archived instead of
+// opts.RelativePath.
+func openArchiveReader(ctx context.Context, opts vfsutil.ArchiveOpts, paths []string) (io.ReadCloser, error) {
+	cmd := gitserver.DefaultClient.Command("git", archiveArgs(opts, paths)...)
 	cmd.Repo = gitserver.Repo{Name: opts.Repo}
 	return gitserver.StdoutReader(ctx, cmd)
 }
 
+// maxSubRepoArchivePaths is the maximum number of paths passed to git archive
+// when archiving for a user with sub-repository permissions. Each path is a
+// command line argument, so an unbounded list could exceed ARG_MAX.
+const maxSubRepoArchivePaths = 10000
+
+// archiveArgs returns the git arguments for openArchiveReader.
+//
+// 🚨 SECURITY: Paths come from the repository, so they may look like options
+// (e.g. "--output=/x") or pathspec magic (e.g. "*" or ":(glob)**"). They are
+// passed after "--" with --literal-pathspecs so that git neither parses them
+// as options nor expands them to files the user may not be allowed to read.
+func archiveArgs(opts vfsutil.ArchiveOpts, paths []string) []string {
+	args := []string{"--literal-pathspecs", "archive", "--format=" + string(opts.Format), string(opts.Commit), "--"}
+	if len(paths) > 0 {
+		args = append(args, paths...)
+	} else {
+		args = append(args, opts.RelativePath)
+	}
+	return args
+}
+
+// subRepoArchivePaths returns the paths of the files under relativePath that
+// matcher matches, which are the files that may be archived for a user with
+// sub-repository permissions.
+func subRepoArchivePaths(ctx context.Context, repo gitserver.Repo, commit api.CommitID, relativePath string, matcher *authz.SubRepoPathMatcher) ([]string, error) {
+	dir := relativePath
+	if dir == "." {
+		dir = ""
+	} else {
+		fi, err := git.Stat(ctx, repo, commit, relativePath)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			if matcher.MatchPath(relativePath) {
+				return []string{relativePath}, nil
+			}
+			return nil, nil
+		}
+	}
+
+	entries, err := git.ReadDir(ctx, repo, commit, dir, true)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && matcher.MatchPath(e.Name()) {
+			paths = append(paths, e.Name())
+		}
+	}
+	return paths, nil
+}
+
 var metricRawDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Name:    "src_frontend_http_raw_duration_seconds",
 	Help:    "A histogram of latencies for the raw endpoint.",
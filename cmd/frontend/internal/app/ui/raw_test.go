@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/vfsutil"
+)
+
+func TestArchiveArgs(t *testing.T) {
+	opts := vfsutil.ArchiveOpts{
+		Repo:         "r",
+		Commit:       "c",
+		Format:       vfsutil.ArchiveFormatZip,
+		RelativePath: "dir",
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name: "relative path",
+			want: []string{"--literal-pathspecs", "archive", "--format=zip", "c", "--", "dir"},
+		},
+		{
+			name:  "paths that look like options or pathspec magic",
+			paths: []string{"dir/--output=/x", "dir/*", ":(glob)**"},
+			want:  []string{"--literal-pathspecs", "archive", "--format=zip", "c", "--", "dir/--output=/x", "dir/*", ":(glob)**"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := archiveArgs(opts, test.paths); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -229,6 +229,12 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
+	// 🚨 SECURITY: Suspended users may not sign in. This is only revealed to users who provided the
+	// correct password.
+	if usr.SuspendedAt != nil {
+		httpLogAndError(w, "Your user account is suspended. Ask a site admin to unsuspend it.", http.StatusForbidden, "userID", usr.ID)
+		return
+	}
 	actor := &actor.Actor{UID: usr.ID}
 
 	// Write the session cookie
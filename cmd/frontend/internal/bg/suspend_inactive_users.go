@@ -0,0 +1,46 @@
+package bg
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// SuspendInactiveUsers periodically suspends the users who have been inactive for longer than the
+// number of days in the "auth.suspendInactiveUsersAfterDays" site configuration property.
+func SuspendInactiveUsers(ctx context.Context) {
+	for {
+		if days := conf.Get().AuthSuspendInactiveUsersAfterDays; days > 0 {
+			if err := suspendInactiveUsers(ctx, time.Now().Add(-time.Duration(days)*24*time.Hour)); err != nil {
+				log15.Error("suspending inactive users", "error", err)
+			}
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+func suspendInactiveUsers(ctx context.Context, since time.Time) error {
+	ids, err := db.Users.SuspendInactive(ctx, since)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		user, err := db.Users.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		log15.Info("Suspended inactive user.", "user", user.Username, "inactiveSince", since)
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditUserSetSuspended,
+			TargetType: "user",
+			Target:     user.Username,
+			Before:     "suspended: false",
+			After:      "suspended: true",
+		})
+	}
+	return nil
+}
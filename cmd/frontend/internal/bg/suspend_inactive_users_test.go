@@ -0,0 +1,45 @@
+package bg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestSuspendInactiveUsers(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	since := time.Now().Add(-30 * 24 * time.Hour)
+	db.Mocks.Users.SuspendInactive = func(ctx context.Context, gotSince time.Time) ([]int32, error) {
+		if !gotSince.Equal(since) {
+			t.Errorf("got since %v, want %v", gotSince, since)
+		}
+		return []int32{2, 3}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: map[int32]string{2: "alice", 3: "bob"}[id]}, nil
+	}
+	var audited []string
+	db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+		if e.ActorUserID != nil {
+			t.Errorf("got actor %d, want none", *e.ActorUserID)
+		}
+		audited = append(audited, e.Action+" "+e.Target+" "+e.Diff)
+		return nil
+	}
+
+	if err := suspendInactiveUsers(context.Background(), since); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"user.set_suspended alice -suspended: false\n+suspended: true",
+		"user.set_suspended bob -suspended: false\n+suspended: true",
+	}
+	if !reflect.DeepEqual(audited, want) {
+		t.Errorf("got audit log %q, want %q", audited, want)
+	}
+}
@@ -2,26 +2,26 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/user"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
-	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/configsync"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
 	"github.com/sourcegraph/sourcegraph/internal/db/confdb"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
-	"github.com/sourcegraph/sourcegraph/internal/jsonc"
 )
 
 func printConfigValidation() {
@@ -120,11 +120,11 @@ func handleConfigOverrides() error {
 			if err != nil {
 				return errors.Wrap(err, "reading EXTSVC_CONFIG_FILE")
 			}
-			var rawConfigs map[string][]*json.RawMessage
-			if err := jsonc.Unmarshal(string(extsvc), &rawConfigs); err != nil {
+			desired, err := configsync.ParseExternalServices(string(extsvc))
+			if err != nil {
 				return errors.Wrap(err, "parsing EXTSVC_CONFIG_FILE")
 			}
-			if len(rawConfigs) == 0 {
+			if len(desired) == 0 {
 				log15.Warn("EXTSVC_CONFIG_FILE contains zero external service configurations")
 			}
 
@@ -138,59 +138,17 @@ func handleConfigOverrides() error {
 			// because that would cause repo-updater to need to update
 			// repositories and reassociate them with external services each
 			// time the frontend restarts.
-			//
-			// Start out by assuming we will remove all and re-add all.
-			var (
-				toAdd    = make(map[*types.ExternalService]bool)
-				toRemove = make(map[*types.ExternalService]bool)
-				toUpdate = make(map[int64]*types.ExternalService)
-			)
-			for _, existing := range existing {
-				toRemove[existing] = true
-			}
-			for key, cfgs := range rawConfigs {
-				for i, cfg := range cfgs {
-					marshaledCfg, err := json.MarshalIndent(cfg, "", "  ")
-					if err != nil {
-						return errors.Wrap(err, fmt.Sprintf("marshaling extsvc config ([%v][%v])", key, i))
-					}
-					toAdd[&types.ExternalService{
-						Kind:        key,
-						DisplayName: fmt.Sprintf("%s #%d", key, i+1),
-						Config:      string(marshaledCfg),
-					}] = true
-				}
-			}
-			// Now eliminate operations from toAdd/toRemove where the config
-			// file and DB describe an equivalent external service.
-			isEquiv := func(a, b *types.ExternalService) bool {
-				return a.Kind == b.Kind && a.DisplayName == b.DisplayName && a.Config == b.Config
-			}
-			shouldUpdate := func(a, b *types.ExternalService) bool {
-				return a.Kind == b.Kind && a.DisplayName == b.DisplayName && a.Config != b.Config
-			}
-			for a := range toAdd {
-				for b := range toRemove {
-					if isEquiv(a, b) { // Nothing changed
-						delete(toAdd, a)
-						delete(toRemove, b)
-					} else if shouldUpdate(a, b) {
-						delete(toAdd, a)
-						delete(toRemove, b)
-						toUpdate[b.ID] = a
-					}
-				}
-			}
+			toAdd, toUpdate, toRemove := configsync.DiffExternalServices(existing, desired)
 
 			// Apply the delta update.
-			for extSvc := range toRemove {
+			for _, extSvc := range toRemove {
 				log15.Debug("Deleting external service", "id", extSvc.ID, "displayName", extSvc.DisplayName)
 				err := db.ExternalServices.Delete(ctx, extSvc.ID)
 				if err != nil {
 					return errors.Wrap(err, "ExternalServices.Delete")
 				}
 			}
-			for extSvc := range toAdd {
+			for _, extSvc := range toAdd {
 				log15.Debug("Adding external service", "displayName", extSvc.DisplayName)
 				if err := db.ExternalServices.Create(ctx, confGet, extSvc); err != nil {
 					return errors.Wrap(err, "ExternalServices.Create")
@@ -198,11 +156,11 @@ func handleConfigOverrides() error {
 			}
 
 			ps := confGet().AuthProviders
-			for id, extSvc := range toUpdate {
-				log15.Debug("Updating external service", "id", id, "displayName", extSvc.DisplayName)
+			for _, extSvc := range toUpdate {
+				log15.Debug("Updating external service", "id", extSvc.ID, "displayName", extSvc.DisplayName)
 
 				update := &db.ExternalServiceUpdate{DisplayName: &extSvc.DisplayName, Config: &extSvc.Config}
-				if err := db.ExternalServices.Update(ctx, ps, id, update); err != nil {
+				if err := db.ExternalServices.Update(ctx, ps, extSvc.ID, update); err != nil {
 					return errors.Wrap(err, "ExternalServices.Update")
 				}
 			}
@@ -241,14 +199,37 @@ func (c configurationSource) Write(ctx context.Context, input conftypes.RawUnifi
 		return errors.Wrap(err, "confdb.SiteGetLatest")
 	}
 
-	_, err = confdb.CriticalCreateIfUpToDate(ctx, &critical.ID, input.Critical)
+	var authorUserID *int32
+	if a := actor.FromContext(ctx); a.IsAuthenticated() {
+		authorUserID = &a.UID
+	}
+
+	newCritical, err := confdb.CriticalCreateIfUpToDate(ctx, &critical.ID, authorUserID, input.Critical)
 	if err != nil {
 		return errors.Wrap(err, "confdb.CriticalCreateIfUpToDate")
 	}
-	_, err = confdb.SiteCreateIfUpToDate(ctx, &site.ID, input.Site)
+	if newCritical.Contents != critical.Contents {
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditCriticalConfigUpdate,
+			TargetType: "critical_config",
+			Target:     strconv.Itoa(int(newCritical.ID)),
+			Before:     critical.Contents,
+			After:      newCritical.Contents,
+		})
+	}
+	newSite, err := confdb.SiteCreateIfUpToDate(ctx, &site.ID, authorUserID, input.Site)
 	if err != nil {
 		return errors.Wrap(err, "confdb.SiteCreateIfUpToDate")
 	}
+	if newSite.Contents != site.Contents {
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditSiteConfigUpdate,
+			TargetType: "site_config",
+			Target:     strconv.Itoa(int(newSite.ID)),
+			Before:     site.Contents,
+			After:      newSite.Contents,
+		})
+	}
 	return nil
 }
 
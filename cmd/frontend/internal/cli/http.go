@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/NYTimes/gziphandler"
@@ -9,6 +12,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/httpapi"
@@ -22,6 +26,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/env"
 	tracepkg "github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/version"
@@ -29,14 +34,14 @@ import (
 
 // newExternalHTTPHandler creates and returns the HTTP handler that serves the app and API pages to
 // external clients.
-func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServerWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) (http.Handler, error) {
+func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, gitlabWebhook, bitbucketServerWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) (http.Handler, error) {
 	// Each auth middleware determines on a per-request basis whether it should be enabled (if not, it
 	// immediately delegates the request to the next middleware in the chain).
 	authMiddlewares := auth.AuthMiddleware()
 
 	// HTTP API handler.
 	r := router.New(mux.NewRouter().PathPrefix("/.api/").Subrouter())
-	apiHandler := internalhttpapi.NewHandler(r, schema, githubWebhook, bitbucketServerWebhook, lsifServerProxy)
+	apiHandler := internalhttpapi.NewHandler(r, schema, githubWebhook, gitlabWebhook, bitbucketServerWebhook, lsifServerProxy)
 	apiHandler = authMiddlewares.API(apiHandler) // 🚨 SECURITY: auth middleware
 	// 🚨 SECURITY: The HTTP API should not accept cookies as authentication (except those with the
 	// X-Requested-With header). Doing so would open it up to CSRF attacks.
@@ -80,6 +85,7 @@ func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServ
 	h = middleware.BlackHole(h)
 	h = secureHeadersMiddleware(h)
 	h = healthCheckMiddleware(h)
+	h = clientIPMiddleware(h)
 	h = gcontext.ClearHandler(h)
 	h = middleware.Trace(h)
 	return h, nil
@@ -96,6 +102,60 @@ func healthCheckMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// trustedProxyHops is the number of reverse proxies in front of the frontend
+// that append the address of their peer to the X-Forwarded-For header.
+var trustedProxyHops = func() int {
+	v := env.Get("SRC_TRUSTED_PROXY_HOPS", "0", "number of trusted reverse proxies in front of the frontend that append to X-Forwarded-For (used to determine the client IP recorded in the audit log)")
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		log.Fatalf("invalid SRC_TRUSTED_PROXY_HOPS %q: must be a non-negative integer", v)
+	}
+	return n
+}()
+
+// clientIPMiddleware adds the IP address of the client to the request context,
+// so that it can be recorded in the audit log.
+func clientIPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(backend.WithClientIP(r.Context(), clientIP(r, trustedProxyHops))))
+	})
+}
+
+// clientIP returns the IP address of the client that sent r, given the number
+// of trusted reverse proxies in front of the frontend.
+//
+// 🚨 SECURITY: The client controls the X-Forwarded-For header it sends, so only
+// the addresses appended by trusted proxies (the right-most trustedProxyHops
+// entries) may be used. The address of the connection is used if there are no
+// trusted proxies or the header is absent.
+func clientIP(r *http.Request, trustedProxyHops int) string {
+	if trustedProxyHops > 0 {
+		var addrs []string
+		for _, v := range r.Header["X-Forwarded-For"] {
+			for _, addr := range strings.Split(v, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+		if len(addrs) > 0 {
+			// If there are fewer entries than trusted proxies, the request
+			// bypassed the outer proxies and every entry was appended by a
+			// trusted one.
+			i := len(addrs) - trustedProxyHops
+			if i < 0 {
+				i = 0
+			}
+			return addrs[i]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 // newInternalHTTPHandler creates and returns the HTTP handler for the internal API (accessible to
 // other internal services).
 func newInternalHTTPHandler(schema *graphql.Schema) http.Handler {
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name             string
+		xForwardedFor    []string
+		trustedProxyHops int
+		want             string
+	}{
+		{name: "no header", trustedProxyHops: 1, want: "10.0.0.1"},
+		{name: "no trusted proxies", xForwardedFor: []string{"1.1.1.1"}, trustedProxyHops: 0, want: "10.0.0.1"},
+		{name: "one proxy", xForwardedFor: []string{"1.1.1.1"}, trustedProxyHops: 1, want: "1.1.1.1"},
+		{name: "forged entry", xForwardedFor: []string{"6.6.6.6, 1.1.1.1"}, trustedProxyHops: 1, want: "1.1.1.1"},
+		{name: "two proxies", xForwardedFor: []string{"6.6.6.6, 1.1.1.1, 2.2.2.2"}, trustedProxyHops: 2, want: "1.1.1.1"},
+		{name: "multiple headers", xForwardedFor: []string{"6.6.6.6", "1.1.1.1"}, trustedProxyHops: 1, want: "1.1.1.1"},
+		{name: "fewer entries than proxies", xForwardedFor: []string{"1.1.1.1"}, trustedProxyHops: 2, want: "1.1.1.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "10.0.0.1:1234"
+			for _, v := range test.xForwardedFor {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := clientIP(r, test.trustedProxyHops); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/pkg/updatecheck"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/bg"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cli/loghandlers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/configsync"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/discussions/mailreply"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/siteid"
@@ -113,7 +114,7 @@ func InitDB() error {
 }
 
 // Main is the main entrypoint for the frontend server program.
-func Main(githubWebhook, bitbucketServerWebhook http.Handler) error {
+func Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook http.Handler) error {
 	log.SetFlags(0)
 	log.SetPrefix("")
 
@@ -194,7 +195,9 @@ func Main(githubWebhook, bitbucketServerWebhook http.Handler) error {
 	goroutine.Go(func() { bg.CheckRedisCacheEvictionPolicy() })
 	goroutine.Go(func() { bg.DeleteOldCacheDataInRedis() })
 	goroutine.Go(func() { bg.DeleteOldEventLogsInPostgres(context.Background()) })
+	goroutine.Go(func() { bg.SuspendInactiveUsers(context.Background()) })
 	goroutine.Go(mailreply.StartWorker)
+	goroutine.Go(configsync.Start)
 	go updatecheck.Start()
 
 	// Parse GraphQL schema and set up resolvers that depend on dbconn.Global
@@ -236,7 +239,7 @@ func Main(githubWebhook, bitbucketServerWebhook http.Handler) error {
 	}
 
 	// Create the external HTTP handler.
-	externalHandler, err := newExternalHTTPHandler(schema, githubWebhook, bitbucketServerWebhook, lsifServerProxy)
+	externalHandler, err := newExternalHTTPHandler(schema, githubWebhook, gitlabWebhook, bitbucketServerWebhook, lsifServerProxy)
 	if err != nil {
 		return err
 	}
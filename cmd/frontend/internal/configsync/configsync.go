@@ -0,0 +1,203 @@
+// Package configsync keeps the site configuration, external services and
+// global settings in sync with configuration files in a git repository.
+package configsync
+
+import (
+	"context"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/segmentio/fasthash/fnv1"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+var (
+	repoName    = env.Get("CONFIG_SYNC_REPO", "", "name of the repository (e.g. github.com/acme/sourcegraph-config) whose site configuration, external services and global settings files are applied")
+	repoPath    = env.Get("CONFIG_SYNC_PATH", "", "directory in CONFIG_SYNC_REPO that contains the configuration files")
+	repoRev     = env.Get("CONFIG_SYNC_REV", "HEAD", "revision of CONFIG_SYNC_REPO to apply")
+	rawInterval = env.Get("CONFIG_SYNC_INTERVAL", defaultInterval.String(), "how often to apply the configuration files in CONFIG_SYNC_REPO")
+
+	allowNoExternalServices, _ = strconv.ParseBool(env.Get("CONFIG_SYNC_ALLOW_NO_EXTERNAL_SERVICES", "false", "allow an external_services.json in CONFIG_SYNC_REPO without external services to delete all external services"))
+)
+
+const defaultInterval = time.Minute
+
+// syncInterval returns how often to apply the configuration files. An invalid
+// CONFIG_SYNC_INTERVAL falls back to the default, so that we never sync
+// without a pause.
+func syncInterval() time.Duration {
+	d, err := time.ParseDuration(rawInterval)
+	if err != nil || d <= 0 {
+		log15.Error("Invalid CONFIG_SYNC_INTERVAL, using the default.", "value", rawInterval, "default", defaultInterval, "error", err)
+		return defaultInterval
+	}
+	return d
+}
+
+// The names of the configuration files in the repository. A file that does
+// not exist leaves the corresponding configuration alone.
+const (
+	siteConfigFile       = "site.json"
+	externalServicesFile = "external_services.json" // in the format of EXTSVC_CONFIG_FILE
+	globalSettingsFile   = "global_settings.json"
+)
+
+// maxFileSize is the maximum size of a configuration file.
+const maxFileSize = 1 << 20
+
+// Status describes the outcome of the most recent sync.
+type Status struct {
+	Repo     api.RepoName
+	Path     string
+	Commit   api.CommitID // the commit that was applied, if it could be resolved
+	SyncedAt time.Time    // zero if no sync has completed yet
+	Err      error        // why the configuration was not applied, if it wasn't
+
+	// Changes describes the changes that were applied.
+	Changes []string
+
+	// Drift lists the configuration that was edited outside of the
+	// repository. These edits were overwritten.
+	Drift []Drift
+}
+
+// Drift describes configuration that was edited outside of the repository.
+type Drift struct {
+	Kind         string    `json:"kind"`         // "site_config", "global_settings" or "external_service"
+	Name         string    `json:"name"`         // the display name of the external service (empty for other kinds)
+	AuthorUserID int32     `json:"authorUserID"` // the user who last edited the configuration
+	EditedAt     time.Time `json:"editedAt"`
+}
+
+// Enabled reports whether the configuration is synced from a repository (i.e.,
+// whether CONFIG_SYNC_REPO is set).
+func Enabled() bool { return repoName != "" }
+
+// CurrentStatus returns the status of the most recent sync by any frontend
+// replica, or nil if config sync is not enabled.
+func CurrentStatus(ctx context.Context) (*Status, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+	status, err := loadStatus(ctx, api.RepoName(repoName))
+	if err != nil {
+		return nil, errors.Wrap(err, "loading config sync status")
+	}
+	if status == nil {
+		return &Status{Repo: api.RepoName(repoName), Path: repoPath}, nil
+	}
+	return status, nil
+}
+
+// Start periodically applies the configuration files in CONFIG_SYNC_REPO. It
+// returns immediately if config sync is not enabled.
+func Start() {
+	if !Enabled() {
+		return
+	}
+
+	interval := syncInterval()
+	ctx := actor.WithActor(context.Background(), &actor.Actor{Internal: true})
+	for {
+		status := syncOnceLocked(ctx)
+		if status.Err != nil {
+			log15.Error("Failed to apply configuration from config sync repository.", "repo", status.Repo, "commit", status.Commit, "error", status.Err)
+		}
+		for _, d := range status.Drift {
+			log15.Warn("Overwrote configuration that was edited outside of the config sync repository.", "kind", d.Kind, "name", d.Name, "author", d.AuthorUserID, "editedAt", d.EditedAt)
+		}
+
+		time.Sleep(interval)
+	}
+}
+
+// lockNamespace and lockID identify the advisory lock that is held while
+// syncing.
+var (
+	lockNamespace = int32(fnv1.HashString32("configsync"))
+	lockID        = int32(fnv1.HashString32(repoName))
+)
+
+// syncOnceLocked runs syncOnce while holding an advisory lock. Every frontend
+// replica runs config sync, and replicas that apply the files at the same time
+// would e.g. both add a new external service. With the lock, replicas apply the
+// files one after another, and all but the first one find nothing to change.
+func syncOnceLocked(ctx context.Context) *Status {
+	// Session-level advisory locks belong to a connection, so we lock and
+	// unlock on the same one.
+	conn, err := dbconn.Global.Conn(ctx)
+	if err != nil {
+		return &Status{Repo: api.RepoName(repoName), Path: repoPath, SyncedAt: time.Now(), Err: errors.Wrap(err, "getting database connection")}
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1, $2)", lockNamespace, lockID); err != nil {
+		return &Status{Repo: api.RepoName(repoName), Path: repoPath, SyncedAt: time.Now(), Err: errors.Wrap(err, "acquiring config sync lock")}
+	}
+	defer func() {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1, $2)", lockNamespace, lockID); err != nil {
+			log15.Error("Failed to release config sync lock.", "error", err)
+		}
+	}()
+
+	// The status is saved while holding the lock, so that the saved status
+	// is the one of the most recent sync.
+	status := syncOnce(ctx)
+	if err := saveStatus(ctx, status); err != nil {
+		log15.Error("Failed to save config sync status.", "error", err)
+	}
+	return status
+}
+
+func syncOnce(ctx context.Context) *Status {
+	status := &Status{Repo: api.RepoName(repoName), Path: repoPath}
+	defer func() { status.SyncedAt = time.Now() }()
+
+	files, commit, err := readFiles(ctx, status.Repo, repoRev, repoPath)
+	status.Commit = commit
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	status.Changes, status.Drift, status.Err = reconcile(ctx, files)
+	return status
+}
+
+// readFiles returns the contents of the configuration files in dir at the
+// given revision of the repository. Files that don't exist are omitted.
+func readFiles(ctx context.Context, name api.RepoName, rev, dir string) (map[string][]byte, api.CommitID, error) {
+	repo, err := backend.Repos.GetByName(ctx, name)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "Repos.GetByName")
+	}
+	gitRepo, err := backend.CachedGitRepo(ctx, repo)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "CachedGitRepo")
+	}
+	commit, err := git.ResolveRevision(ctx, *gitRepo, nil, rev, nil)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "resolving revision %q", rev)
+	}
+
+	files := make(map[string][]byte)
+	for _, file := range []string{siteConfigFile, externalServicesFile, globalSettingsFile} {
+		data, err := git.ReadFile(ctx, *gitRepo, commit, path.Join(dir, file), maxFileSize)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, commit, errors.Wrapf(err, "reading %s", file)
+		}
+		files[file] = data
+	}
+	return files, commit, nil
+}
@@ -0,0 +1,308 @@
+package configsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
+	"github.com/sourcegraph/sourcegraph/internal/db/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+)
+
+// ParseExternalServices parses external service configurations in the format
+// of EXTSVC_CONFIG_FILE: a JSON object that maps each external service kind to
+// a list of configurations. The external services are named after their kind
+// and position (e.g. "GITHUB #1").
+func ParseExternalServices(data string) ([]*types.ExternalService, error) {
+	var rawConfigs map[string][]*json.RawMessage
+	if err := jsonc.Unmarshal(data, &rawConfigs); err != nil {
+		return nil, err
+	}
+
+	kinds := make([]string, 0, len(rawConfigs))
+	for kind := range rawConfigs {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	svcs := []*types.ExternalService{}
+	for _, kind := range kinds {
+		for i, cfg := range rawConfigs[kind] {
+			marshaledCfg, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("marshaling extsvc config ([%v][%v])", kind, i))
+			}
+			svcs = append(svcs, &types.ExternalService{
+				Kind:        kind,
+				DisplayName: fmt.Sprintf("%s #%d", kind, i+1),
+				Config:      string(marshaledCfg),
+			})
+		}
+	}
+	return svcs, nil
+}
+
+// DiffExternalServices returns the external services that must be added,
+// updated and removed so that the existing external services match the desired
+// ones. External services are matched by kind and display name. The returned
+// external services to update are the existing ones with the desired config.
+func DiffExternalServices(existing, desired []*types.ExternalService) (toAdd, toUpdate, toRemove []*types.ExternalService) {
+	matched := make(map[*types.ExternalService]bool, len(existing))
+	for _, d := range desired {
+		var match *types.ExternalService
+		for _, e := range existing {
+			if !matched[e] && e.Kind == d.Kind && e.DisplayName == d.DisplayName {
+				match = e
+				break
+			}
+		}
+		if match == nil {
+			toAdd = append(toAdd, d)
+			continue
+		}
+		matched[match] = true
+		if match.Config != d.Config {
+			update := *match
+			update.Config = d.Config
+			toUpdate = append(toUpdate, &update)
+		}
+	}
+	for _, e := range existing {
+		if !matched[e] {
+			toRemove = append(toRemove, e)
+		}
+	}
+	return toAdd, toUpdate, toRemove
+}
+
+// desiredState is the configuration in the repository. A nil field means that
+// the corresponding file does not exist, so that configuration is left alone.
+type desiredState struct {
+	site             *string
+	globalSettings   *string
+	externalServices []*types.ExternalService
+}
+
+// parseDesiredState parses and validates the configuration files. If any file
+// is invalid, an error describing all problems is returned so that none of the
+// configuration is applied. The current site configuration is used to validate
+// the external services if the files don't contain a site configuration.
+func parseDesiredState(files map[string][]byte, currentSite string) (*desiredState, error) {
+	var (
+		state desiredState
+		errs  *multierror.Error
+	)
+
+	site := currentSite
+	if data, ok := files[siteConfigFile]; ok {
+		site = string(data)
+		state.site = &site
+		messages, err := conf.ValidateSite(site)
+		if err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, siteConfigFile))
+		}
+		for _, m := range messages {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %s", siteConfigFile, m))
+		}
+	}
+
+	if data, ok := files[globalSettingsFile]; ok {
+		settings := string(data)
+		state.globalSettings = &settings
+		messages, err := conf.ValidateSettings(settings)
+		if err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, globalSettingsFile))
+		}
+		for _, m := range messages {
+			errs = multierror.Append(errs, fmt.Errorf("%s: %s", globalSettingsFile, m))
+		}
+	}
+
+	if data, ok := files[externalServicesFile]; ok {
+		svcs, err := ParseExternalServices(string(data))
+		if err != nil {
+			return nil, multierror.Append(errs, errors.Wrap(err, externalServicesFile))
+		}
+		state.externalServices = svcs
+
+		// GitLab connections are validated against the auth providers.
+		parsed, err := conf.ParseConfig(conftypes.RawUnified{Site: site})
+		if err != nil {
+			return nil, multierror.Append(errs, errors.Wrap(err, siteConfigFile))
+		}
+		for _, svc := range svcs {
+			if err := db.ExternalServices.ValidateConfig(svc.Kind, svc.Config, parsed.AuthProviders); err != nil {
+				errs = multierror.Append(errs, errors.Wrapf(err, "%s: %s", externalServicesFile, svc.DisplayName))
+			}
+		}
+	}
+
+	return &state, errs.ErrorOrNil()
+}
+
+// reconcile applies the configuration files, and returns the changes it made
+// and the configuration that was edited outside of the repository (and that
+// was overwritten). Applying the same files again makes no changes.
+//
+// Configuration that was last edited by a user differs from the files only if
+// the user edited it outside of the repository, because reconcile edits
+// configuration without a user. Configuration that was last edited without a
+// user (e.g. by an earlier commit) is just out of date.
+func reconcile(ctx context.Context, files map[string][]byte) (changes []string, drift []Drift, err error) {
+	raw := globals.ConfigurationServerFrontendOnly.Raw()
+	state, err := parseDesiredState(files, raw.Site)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if state.site != nil {
+		latest, err := confdb.SiteGetLatest(ctx)
+		if err != nil {
+			return changes, drift, err
+		}
+		if latest.Contents != *state.site {
+			if latest.AuthorUserID != nil {
+				drift = append(drift, Drift{Kind: "site_config", AuthorUserID: *latest.AuthorUserID, EditedAt: latest.CreatedAt})
+			}
+			raw.Site = *state.site
+			if err := globals.ConfigurationServerFrontendOnly.Write(ctx, raw); err != nil {
+				return changes, drift, errors.Wrap(err, "writing site configuration")
+			}
+			changes = append(changes, "Updated the site configuration.")
+		}
+	}
+
+	if state.globalSettings != nil {
+		subject := api.SettingsSubject{Site: true}
+		latest, err := db.Settings.GetLatest(ctx, subject)
+		if err != nil {
+			return changes, drift, err
+		}
+		if latest == nil || latest.Contents != *state.globalSettings {
+			var lastID *int32
+			if latest != nil {
+				lastID = &latest.ID
+				if latest.AuthorUserID != nil {
+					drift = append(drift, Drift{Kind: "global_settings", AuthorUserID: *latest.AuthorUserID, EditedAt: latest.CreatedAt})
+				}
+			}
+			if _, err := db.Settings.CreateIfUpToDate(ctx, subject, lastID, nil, *state.globalSettings); err != nil {
+				return changes, drift, errors.Wrap(err, "writing global settings")
+			}
+			changes = append(changes, "Updated the global settings.")
+		}
+	}
+
+	if state.externalServices != nil {
+		extsvcChanges, extsvcDrift, err := reconcileExternalServices(ctx, state.externalServices)
+		changes = append(changes, extsvcChanges...)
+		drift = append(drift, extsvcDrift...)
+		if err != nil {
+			return changes, drift, err
+		}
+	}
+
+	return changes, drift, nil
+}
+
+func reconcileExternalServices(ctx context.Context, desired []*types.ExternalService) (changes []string, drift []Drift, err error) {
+	existing, err := db.ExternalServices.List(ctx, db.ExternalServicesListOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "ExternalServices.List")
+	}
+	// An empty external_services.json (e.g. because of a bad commit) would delete
+	// every external service and with them all repositories, so that must be explicitly allowed.
+	if len(desired) == 0 && len(existing) > 0 && !allowNoExternalServices {
+		return nil, nil, fmt.Errorf("%s contains no external services, refusing to delete all %d external services (set CONFIG_SYNC_ALLOW_NO_EXTERNAL_SERVICES=true to allow it)", externalServicesFile, len(existing))
+	}
+	toAdd, toUpdate, toRemove := DiffExternalServices(existing, desired)
+
+	for _, svc := range append(toUpdate, toRemove...) {
+		d, err := externalServiceDrift(ctx, svc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if d != nil {
+			drift = append(drift, *d)
+		}
+	}
+
+	for _, svc := range toRemove {
+		if err := db.ExternalServices.Delete(ctx, svc.ID); err != nil {
+			return changes, drift, errors.Wrap(err, "ExternalServices.Delete")
+		}
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditExternalServiceDelete,
+			TargetType: "external_service",
+			Target:     strconv.FormatInt(svc.ID, 10),
+			Before:     backend.ExternalServiceAuditDescription(svc),
+		})
+		changes = append(changes, fmt.Sprintf("Deleted external service %q.", svc.DisplayName))
+	}
+	for _, svc := range toAdd {
+		if err := db.ExternalServices.Create(ctx, conf.Get, svc); err != nil {
+			return changes, drift, errors.Wrap(err, "ExternalServices.Create")
+		}
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditExternalServiceAdd,
+			TargetType: "external_service",
+			Target:     strconv.FormatInt(svc.ID, 10),
+			After:      backend.ExternalServiceAuditDescription(svc),
+		})
+		changes = append(changes, fmt.Sprintf("Added external service %q.", svc.DisplayName))
+	}
+	for _, svc := range toUpdate {
+		prev, err := db.ExternalServices.GetByID(ctx, svc.ID)
+		if err != nil {
+			return changes, drift, err
+		}
+		update := &db.ExternalServiceUpdate{Config: &svc.Config}
+		if err := db.ExternalServices.Update(ctx, conf.Get().AuthProviders, svc.ID, update); err != nil {
+			return changes, drift, errors.Wrap(err, "ExternalServices.Update")
+		}
+		backend.LogAuditEvent(ctx, backend.AuditEvent{
+			Action:     backend.AuditExternalServiceUpdate,
+			TargetType: "external_service",
+			Target:     strconv.FormatInt(svc.ID, 10),
+			Before:     backend.ExternalServiceAuditDescription(prev),
+			After:      backend.ExternalServiceAuditDescription(svc),
+		})
+		changes = append(changes, fmt.Sprintf("Updated external service %q.", svc.DisplayName))
+	}
+
+	return changes, drift, nil
+}
+
+// externalServiceDrift returns the drift of an external service that differs
+// from the repository if a user made the last recorded change to it, and nil
+// otherwise.
+func externalServiceDrift(ctx context.Context, svc *types.ExternalService) (*Drift, error) {
+	entries, err := db.AuditLog.List(ctx, db.AuditLogListOptions{
+		TargetType:  "external_service",
+		Target:      strconv.FormatInt(svc.ID, 10),
+		LimitOffset: &db.LimitOffset{Limit: 1},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "AuditLog.List")
+	}
+	if len(entries) == 0 || entries[0].ActorUserID == nil {
+		return nil, nil
+	}
+	return &Drift{
+		Kind:         "external_service",
+		Name:         svc.DisplayName,
+		AuthorUserID: *entries[0].ActorUserID,
+		EditedAt:     entries[0].CreatedAt,
+	}, nil
+}
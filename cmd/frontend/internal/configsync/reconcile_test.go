@@ -0,0 +1,165 @@
+package configsync
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func TestParseExternalServices(t *testing.T) {
+	got, err := ParseExternalServices(`{
+		// comments are allowed
+		"OTHER": [{"url": "https://b.example.com"}],
+		"GITHUB": [{"url": "https://github.com"}, {"url": "https://ghe.example.com"}],
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, svc := range got {
+		names = append(names, svc.Kind+"/"+svc.DisplayName)
+	}
+	if want := []string{"GITHUB/GITHUB #1", "GITHUB/GITHUB #2", "OTHER/OTHER #1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+	if want := "{\n  \"url\": \"https://ghe.example.com\"\n}"; got[1].Config != want {
+		t.Errorf("got config %q, want %q", got[1].Config, want)
+	}
+}
+
+func TestDiffExternalServices(t *testing.T) {
+	existing := []*types.ExternalService{
+		{ID: 1, Kind: "GITHUB", DisplayName: "GITHUB #1", Config: "a"},
+		{ID: 2, Kind: "GITHUB", DisplayName: "GITHUB #2", Config: "b"},
+		{ID: 3, Kind: "GITLAB", DisplayName: "GITLAB #1", Config: "c"},
+	}
+	desired := []*types.ExternalService{
+		{Kind: "GITHUB", DisplayName: "GITHUB #1", Config: "a"},
+		{Kind: "GITHUB", DisplayName: "GITHUB #2", Config: "b2"},
+		{Kind: "OTHER", DisplayName: "OTHER #1", Config: "d"},
+	}
+
+	toAdd, toUpdate, toRemove := DiffExternalServices(existing, desired)
+	if want := []*types.ExternalService{desired[2]}; !reflect.DeepEqual(toAdd, want) {
+		t.Errorf("got toAdd %+v, want %+v", toAdd, want)
+	}
+	if want := []*types.ExternalService{{ID: 2, Kind: "GITHUB", DisplayName: "GITHUB #2", Config: "b2"}}; !reflect.DeepEqual(toUpdate, want) {
+		t.Errorf("got toUpdate %+v, want %+v", toUpdate, want)
+	}
+	if want := []*types.ExternalService{existing[2]}; !reflect.DeepEqual(toRemove, want) {
+		t.Errorf("got toRemove %+v, want %+v", toRemove, want)
+	}
+	if existing[1].Config != "b" {
+		t.Error("existing external service was modified")
+	}
+
+	// Applying the desired external services again makes no changes.
+	toAdd, toUpdate, toRemove = DiffExternalServices(desired, desired)
+	if len(toAdd) != 0 || len(toUpdate) != 0 || len(toRemove) != 0 {
+		t.Errorf("got changes %+v, %+v, %+v, want none", toAdd, toUpdate, toRemove)
+	}
+}
+
+func TestParseDesiredState(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		state, err := parseDesiredState(map[string][]byte{
+			globalSettingsFile:   []byte(`{"search.defaultPatternType": "literal"}`),
+			externalServicesFile: []byte(`{"OTHER": [{"url": "https://git.mycompany.com", "repos": ["a"]}]}`),
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.site != nil {
+			t.Errorf("got site config %q, want nil", *state.site)
+		}
+		if state.globalSettings == nil || len(state.externalServices) != 1 {
+			t.Errorf("got %+v, want global settings and 1 external service", state)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseDesiredState(map[string][]byte{
+			globalSettingsFile:   []byte(`{"search.defaultPatternType": 1}`),
+			externalServicesFile: []byte(`{"OTHER": [{"url": "https://git.mycompany.com", "repos": ["a"]}], "NOPE": [{}]}`),
+		}, "")
+		if err == nil {
+			t.Fatal("got nil error")
+		}
+		for _, want := range []string{globalSettingsFile + ": search.defaultPatternType", externalServicesFile + ": NOPE #1"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("got error %q, want it to contain %q", err, want)
+			}
+		}
+	})
+}
+
+func TestExternalServiceDrift(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	editedAt := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	svc := &types.ExternalService{ID: 7, Kind: "GITHUB", DisplayName: "GITHUB #1"}
+	for _, test := range []struct {
+		name    string
+		entries []*types.AuditLogEntry
+		want    *Drift
+	}{
+		{name: "never changed"},
+		{name: "changed by config sync", entries: []*types.AuditLogEntry{{CreatedAt: editedAt}}},
+		{
+			name:    "changed by a user",
+			entries: []*types.AuditLogEntry{{ActorUserID: int32Ptr(3), CreatedAt: editedAt}},
+			want:    &Drift{Kind: "external_service", Name: "GITHUB #1", AuthorUserID: 3, EditedAt: editedAt},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*types.AuditLogEntry, error) {
+				if opt.TargetType != "external_service" || opt.Target != "7" {
+					t.Errorf("unexpected options %+v", opt)
+				}
+				return test.entries, nil
+			}
+			got, err := externalServiceDrift(context.Background(), svc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReconcileExternalServices_noExternalServices(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	db.Mocks.ExternalServices.List = func(opt db.ExternalServicesListOptions) ([]*types.ExternalService, error) {
+		return []*types.ExternalService{{ID: 7, Kind: "GITHUB", DisplayName: "GITHUB #1"}}, nil
+	}
+	_, _, err := reconcileExternalServices(context.Background(), []*types.ExternalService{})
+	if want := "refusing to delete all 1 external services"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %v, want it to contain %q", err, want)
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	defer func(v string) { rawInterval = v }(rawInterval)
+
+	for value, want := range map[string]time.Duration{
+		"5m":      5 * time.Minute,
+		"invalid": defaultInterval,
+		"0s":      defaultInterval,
+		"-1m":     defaultInterval,
+	} {
+		rawInterval = value
+		if got := syncInterval(); got != want {
+			t.Errorf("%q: got %v, want %v", value, got, want)
+		}
+	}
+}
+
+func int32Ptr(v int32) *int32 { return &v }
@@ -0,0 +1,92 @@
+package configsync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// The status of the most recent sync is stored in the database, so that every
+// frontend replica reports the same status.
+
+// saveStatus stores status as the status of the most recent sync.
+//
+// Every replica syncs, and the replicas which sync after the one that applied
+// a commit find nothing to change. Such a sync keeps the changes and drift of
+// the sync that applied the commit, so that they are still reported.
+func saveStatus(ctx context.Context, status *Status) error {
+	changes, err := json.Marshal(nonNilStrings(status.Changes))
+	if err != nil {
+		return err
+	}
+	drift, err := json.Marshal(nonNilDrift(status.Drift))
+	if err != nil {
+		return err
+	}
+	var errMsg *string
+	if status.Err != nil {
+		msg := status.Err.Error()
+		errMsg = &msg
+	}
+	unchanged := status.Err == nil && len(status.Changes) == 0 && len(status.Drift) == 0
+
+	_, err = dbconn.Global.ExecContext(ctx, `
+INSERT INTO config_sync_status(repo_name, path, commit_id, synced_at, error, changes, drift)
+VALUES($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (repo_name) DO UPDATE SET
+	path = excluded.path,
+	commit_id = excluded.commit_id,
+	synced_at = excluded.synced_at,
+	error = excluded.error,
+	changes = CASE WHEN $8 AND config_sync_status.commit_id = excluded.commit_id THEN config_sync_status.changes ELSE excluded.changes END,
+	drift = CASE WHEN $8 AND config_sync_status.commit_id = excluded.commit_id THEN config_sync_status.drift ELSE excluded.drift END
+`, status.Repo, status.Path, status.Commit, status.SyncedAt, errMsg, changes, drift, unchanged)
+	return err
+}
+
+// loadStatus returns the status of the most recent sync of the configuration
+// in repo.
+func loadStatus(ctx context.Context, repo api.RepoName) (*Status, error) {
+	status := &Status{Repo: repo}
+	var (
+		errMsg         sql.NullString
+		changes, drift []byte
+	)
+	err := dbconn.Global.QueryRowContext(ctx, `
+SELECT path, commit_id, synced_at, error, changes, drift FROM config_sync_status WHERE repo_name=$1
+`, repo).Scan(&status.Path, &status.Commit, &status.SyncedAt, &errMsg, &changes, &drift)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if errMsg.Valid {
+		status.Err = errors.New(errMsg.String)
+	}
+	if err := json.Unmarshal(changes, &status.Changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(drift, &status.Drift); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func nonNilStrings(l []string) []string {
+	if l == nil {
+		return []string{}
+	}
+	return l
+}
+
+func nonNilDrift(l []Drift) []Drift {
+	if l == nil {
+		return []Drift{}
+	}
+	return l
+}
@@ -0,0 +1,67 @@
+package configsync
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestStatus(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	const repo = "github.com/acme/config"
+	if status, err := loadStatus(ctx, repo); err != nil || status != nil {
+		t.Fatalf("got status %+v (error %v) before the first sync, want none", status, err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	applied := &Status{
+		Repo:     repo,
+		Path:     "config",
+		Commit:   "a",
+		SyncedAt: now,
+		Changes:  []string{"updated the site configuration"},
+		Drift:    []Drift{{Kind: "site_config", AuthorUserID: 1, EditedAt: now}},
+	}
+	if err := saveStatus(ctx, applied); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another replica syncs the same commit and finds nothing to change. The
+	// changes made by the first sync are kept.
+	unchanged := &Status{Repo: repo, Path: "config", Commit: "a", SyncedAt: now.Add(time.Minute)}
+	if err := saveStatus(ctx, unchanged); err != nil {
+		t.Fatal(err)
+	}
+	status, err := loadStatus(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.SyncedAt.Equal(unchanged.SyncedAt) {
+		t.Errorf("got synced at %s, want %s", status.SyncedAt, unchanged.SyncedAt)
+	}
+	if !reflect.DeepEqual(status.Changes, applied.Changes) || len(status.Drift) != 1 || status.Drift[0].Kind != "site_config" {
+		t.Errorf("got changes %q and drift %+v, want those of the first sync", status.Changes, status.Drift)
+	}
+
+	// A failed sync of a new commit replaces the status.
+	failed := &Status{Repo: repo, Path: "config", Commit: "b", SyncedAt: now.Add(2 * time.Minute), Err: errors.New("invalid site.json")}
+	if err := saveStatus(ctx, failed); err != nil {
+		t.Fatal(err)
+	}
+	status, err = loadStatus(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Commit != "b" || status.Err == nil || status.Err.Error() != "invalid site.json" || len(status.Changes) != 0 || len(status.Drift) != 0 {
+		t.Errorf("got status %+v, want the failed sync", status)
+	}
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// auditLogExportPageSize is the number of audit log entries read from the
+// database at a time when exporting.
+const auditLogExportPageSize = 1000
+
+// auditLogExportEntry is the JSON representation of an audit log entry in
+// exports. It contains all the fields covered by the hash, so that the hash
+// chain can be verified.
+type auditLogExportEntry struct {
+	ID          int64     `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ActorUserID *int32    `json:"actorUserID"`
+	ActorIP     string    `json:"actorIP"`
+	Action      string    `json:"action"`
+	TargetType  string    `json:"targetType"`
+	Target      string    `json:"target"`
+	Diff        string    `json:"diff"`
+	PrevHash    string    `json:"prevHash"`
+	Hash        string    `json:"hash"`
+}
+
+// serveAuditLogExport writes the audit log as newline-delimited JSON, oldest
+// entry first. The entries can be filtered with the actor (a username),
+// action, targetType, since and until (RFC 3339 timestamps) query parameters.
+func serveAuditLogExport(w http.ResponseWriter, r *http.Request) error {
+	// 🚨 SECURITY: Only site admins can export the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		http.Error(w, "Only site admins can export the audit log.", http.StatusForbidden)
+		return nil
+	}
+
+	q := r.URL.Query()
+	opt := db.AuditLogListOptions{
+		Action:      q.Get("action"),
+		TargetType:  q.Get("targetType"),
+		OldestFirst: true,
+		LimitOffset: &db.LimitOffset{Limit: auditLogExportPageSize},
+	}
+	if username := q.Get("actor"); username != "" {
+		user, err := db.Users.GetByUsername(r.Context(), username)
+		if err != nil {
+			if errcode.IsNotFound(err) {
+				http.Error(w, "No such actor.", http.StatusBadRequest)
+				return nil
+			}
+			return err
+		}
+		opt.ActorUserID = user.ID
+	}
+	for _, p := range []struct {
+		name string
+		t    **time.Time
+	}{{"since", &opt.Since}, {"until", &opt.Until}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "Invalid "+p.name+" parameter (must be an RFC 3339 timestamp).", http.StatusBadRequest)
+				return nil
+			}
+			*p.t = &t
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-log.ndjson"`)
+	enc := json.NewEncoder(w)
+	for {
+		entries, err := db.AuditLog.List(r.Context(), opt)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := enc.Encode(auditLogExportEntry{
+				ID:          e.ID,
+				CreatedAt:   e.CreatedAt.UTC(),
+				ActorUserID: e.ActorUserID,
+				ActorIP:     e.ActorIP,
+				Action:      e.Action,
+				TargetType:  e.TargetType,
+				Target:      e.Target,
+				Diff:        e.Diff,
+				PrevHash:    e.PrevHash,
+				Hash:        e.Hash,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(entries) < auditLogExportPageSize {
+			return nil
+		}
+		opt.AfterID = entries[len(entries)-1].ID
+	}
+}
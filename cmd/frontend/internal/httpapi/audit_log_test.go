@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestServeAuditLogExport(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	serve := func(siteAdmin bool, url string) *httptest.ResponseRecorder {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+		}
+		req, _ := http.NewRequest("GET", url, nil)
+		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 1}))
+		rec := httptest.NewRecorder()
+		if err := serveAuditLogExport(rec, req); err != nil {
+			t.Fatal(err)
+		}
+		return rec
+	}
+
+	t.Run("non-admin", func(t *testing.T) {
+		db.Mocks.AuditLog.List = func(db.AuditLogListOptions) ([]*types.AuditLogEntry, error) {
+			t.Fatal("unexpected call to List")
+			return nil, nil
+		}
+		if rec := serve(false, "/audit-log/export"); rec.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		if rec := serve(true, "/audit-log/export?since=yesterday"); rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		db.Mocks.AuditLog.List = func(opt db.AuditLogListOptions) ([]*types.AuditLogEntry, error) {
+			if !opt.OldestFirst || opt.Action != "site_config.update" || opt.Since == nil {
+				t.Errorf("unexpected options %+v", opt)
+			}
+			// Return a full page, and then the rest.
+			n := auditLogExportPageSize
+			if opt.AfterID != 0 {
+				n = 2
+			}
+			entries := make([]*types.AuditLogEntry, n)
+			for i := range entries {
+				entries[i] = &types.AuditLogEntry{ID: opt.AfterID + int64(i) + 1, Action: opt.Action}
+			}
+			return entries, nil
+		}
+
+		rec := serve(true, "/audit-log/export?action=site_config.update&since=2020-04-01T00:00:00Z")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+		}
+		if got, want := rec.Header().Get("Content-Type"), "application/x-ndjson"; got != want {
+			t.Errorf("got Content-Type %q, want %q", got, want)
+		}
+		var ids []int64
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var e auditLogExportEntry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, e.ID)
+		}
+		if want := auditLogExportPageSize + 2; len(ids) != want || ids[len(ids)-1] != int64(want) {
+			t.Errorf("got %d entries ending with ID %d, want %d", len(ids), ids[len(ids)-1], want)
+		}
+	})
+}
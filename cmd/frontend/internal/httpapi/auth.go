@@ -1,7 +1,9 @@
 package httpapi
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -10,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 )
 
 // AccessTokenAuthMiddleware authenticates the user based on the
@@ -36,7 +39,10 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			if err != nil {
 				if authz.IsUnrecognizedScheme(err) {
 					// Ignore Authorization headers that we don't handle.
-					log15.Warn("Ignoring unrecognized Authorization header.", "err", err, "value", headerValue)
+					//
+					// 🚨 SECURITY: Don't log the credentials of other schemes (e.g. the SCIM
+					// bearer token), only the scheme itself.
+					log15.Warn("Ignoring unrecognized Authorization header.", "err", err, "scheme", authorizationScheme(headerValue))
 					next.ServeHTTP(w, r)
 					return
 				}
@@ -61,19 +67,25 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
 			// is allowed to do.
-			var requiredScope string
-			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
-			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+			requiredScopes := authz.UserScopes
+			if sudoUser != "" {
+				requiredScopes = []string{authz.ScopeSiteAdminSudo}
 			}
-			subjectUserID, err := db.AccessTokens.Lookup(r.Context(), token, requiredScope)
+			subjectUserID, scopes, err := db.AccessTokens.Lookup(r.Context(), token, requiredScopes)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
 				return
 			}
 
+			// 🚨 SECURITY: Tokens with fine-grained scopes may only be used for the requests their
+			// scopes grant. Requests that aren't listed in requestScopes require the "user:all"
+			// scope. The GraphQL API checks the scope of each field it resolves.
+			if scope := requiredScopeForRequest(r); scope != "" && !authz.HasScope(scopes, scope) {
+				http.Error(w, fmt.Sprintf("Access token lacks the required scope %q for this request.", scope), http.StatusForbidden)
+				return
+			}
+
 			// Determine the actor's user ID.
 			var actorUserID int32
 			if sudoUser == "" {
@@ -101,13 +113,62 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 					http.Error(w, message, http.StatusForbidden)
 					return
 				}
+				// 🚨 SECURITY: Suspended users may not be impersonated, because they may not
+				// authenticate in any other way.
+				if user.SuspendedAt != nil {
+					log15.Error("Suspended username used with sudo access token.", "sudoUser", sudoUser)
+					http.Error(w, "Unable to sudo to suspended user.", http.StatusForbidden)
+					return
+				}
 				actorUserID = user.ID
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
+				backend.LogAuditEvent(r.Context(), backend.AuditEvent{
+					Action:      backend.AuditAccessTokenSudo,
+					TargetType:  "user",
+					Target:      user.Username,
+					After:       r.Method + " " + r.URL.RequestURI(),
+					ActorUserID: subjectUserID,
+				})
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID, AccessTokenScopes: scopes}))
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// requestScopes are the access token scopes that grant access to requests, by the path pattern of
+// the request.
+var requestScopes = []struct {
+	pattern *lazyregexp.Regexp
+	scope   string // the empty string means that any scope grants access
+}{
+	{lazyregexp.New(`^/\.api/graphql$`), ""},
+	{lazyregexp.New(`^/\.api/src-cli/`), ""},
+	{lazyregexp.New(`^/\.api/lsif/upload$`), authz.ScopeCodeIntelUpload},
+	{lazyregexp.New(`^/\.api/repos/.+/-/shield$`), authz.ScopeRepoRead},
+	{lazyregexp.New(`^/\.api/audit-log/export$`), authz.ScopeAdminRead},
+	{lazyregexp.New(`/-/raw(/|$)`), authz.ScopeRepoRead},
+}
+
+// requiredScopeForRequest returns the access token scope that grants access to the request.
+func requiredScopeForRequest(r *http.Request) string {
+	for _, s := range requestScopes {
+		if s.pattern.MatchString(r.URL.Path) {
+			return s.scope
+		}
+	}
+	return authz.ScopeUserAll
+}
+
+// authorizationScheme returns the scheme of an Authorization header value, or
+// the empty string if the value has no credentials after its scheme (in which
+// case the value may itself be a credential).
+func authorizationScheme(headerValue string) string {
+	i := strings.IndexAny(headerValue, " \t")
+	if i == -1 {
+		return ""
+	}
+	return headerValue[:i]
+}
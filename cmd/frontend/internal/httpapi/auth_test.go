@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -69,9 +72,9 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token badbad")
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
-			return 0, errors.New("x")
+			return 0, nil, errors.New("x")
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
@@ -85,15 +88,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/", nil)
 			req.Header.Set("Authorization", headerValue)
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return 123, []string{authz.ScopeUserAll}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -109,15 +112,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req.Header.Set("Authorization", "token abcdef")
 		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeUserAll; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll}, nil
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -143,15 +146,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			}
 			req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := authz.UserScopes; !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return 123, []string{authz.ScopeUserAll}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -161,19 +164,45 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		})
 	}
 
+	// 🚨 SECURITY: Test that tokens with fine-grained scopes can only be used for the requests that
+	// their scopes grant.
+	for _, tc := range []struct {
+		path           string
+		scopes         []string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"/.api/graphql", []string{authz.ScopeSearchRead}, http.StatusOK, "user 123"},
+		{"/.api/lsif/upload", []string{authz.ScopeCodeIntelUpload}, http.StatusOK, "user 123"},
+		{"/.api/lsif/upload", []string{authz.ScopeUserAll}, http.StatusOK, "user 123"},
+		{"/github.com/foo/bar/-/raw/README.md", []string{authz.ScopeRepoRead}, http.StatusOK, "user 123"},
+		{"/.api/lsif/upload", []string{authz.ScopeSearchRead}, http.StatusForbidden, "Access token lacks the required scope \"codeintel:upload\" for this request.\n"},
+		{"/settings", []string{authz.ScopeSettingsWrite}, http.StatusForbidden, "Access token lacks the required scope \"user:all\" for this request.\n"},
+	} {
+		t.Run(fmt.Sprintf("token with scopes %q requesting %s", tc.scopes, tc.path), func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			req.Header.Set("Authorization", "token abcdef")
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
+				return 123, tc.scopes, nil
+			}
+			defer func() { db.Mocks = db.MockStores{} }()
+			checkHTTPResponse(t, req, tc.wantStatusCode, tc.wantBody)
+		})
+	}
+
 	t.Run("valid sudo token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -189,7 +218,12 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			if want := "alice"; username != want {
 				t.Errorf("got %q, want %q", username, want)
 			}
-			return &types.User{ID: 456, SiteAdmin: true}, nil
+			return &types.User{ID: 456, Username: "alice", SiteAdmin: true}, nil
+		}
+		var auditLogEntry *types.AuditLogEntry
+		db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+			auditLogEntry = e
+			return nil
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 456")
@@ -202,6 +236,9 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		if !calledUsersGetByUsername {
 			t.Error("!calledUsersGetByUsername")
 		}
+		if auditLogEntry == nil || auditLogEntry.Action != backend.AuditAccessTokenSudo || *auditLogEntry.ActorUserID != 123 || auditLogEntry.Target != "alice" {
+			t.Errorf("unexpected audit log entry %+v", auditLogEntry)
+		}
 	})
 
 	// Test that if a sudo token's subject user is not a site admin (which means they were demoted
@@ -210,15 +247,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -242,15 +279,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="doesntexist"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -280,4 +317,40 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			t.Error("!calledUsersGetByUsername")
 		}
 	})
+
+	t.Run("valid sudo token, suspended sudo user", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
+			return 123, []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}, nil
+		}
+		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
+			return &types.User{ID: userID, SiteAdmin: true}, nil
+		}
+		var calledUsersGetByUsername bool
+		db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+			calledUsersGetByUsername = true
+			suspendedAt := time.Now()
+			return &types.User{ID: 456, Username: username, SuspendedAt: &suspendedAt}, nil
+		}
+		defer func() { db.Mocks = db.MockStores{} }()
+		checkHTTPResponse(t, req, http.StatusForbidden, "Unable to sudo to suspended user.\n")
+		if !calledUsersGetByUsername {
+			t.Error("!calledUsersGetByUsername")
+		}
+	})
+}
+
+func TestAuthorizationScheme(t *testing.T) {
+	tests := map[string]string{
+		"Bearer s3cr3t":       "Bearer",
+		"Basic\tdXNlcjpwdw==": "Basic",
+		"s3cr3t":              "",
+		"":                    "",
+	}
+	for headerValue, want := range tests {
+		if got := authorizationScheme(headerValue); got != want {
+			t.Errorf("%q: got %q, want %q", headerValue, got, want)
+		}
+	}
 }
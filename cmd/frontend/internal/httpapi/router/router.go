@@ -15,11 +15,16 @@ const (
 
 	Registry = "registry"
 
+	AuditLogExport = "audit-log.export"
+
+	SCIM = "scim"
+
 	RepoShield  = "repo.shield"
 	RepoRefresh = "repo.refresh"
 	Telemetry   = "telemetry"
 
 	GitHubWebhooks          = "github.webhooks"
+	GitLabWebhooks          = "gitlab.webhooks"
 	BitbucketServerWebhooks = "bitbucketServer.webhooks"
 
 	SavedQueriesListAll    = "internal.saved-queries.list-all"
@@ -63,10 +68,13 @@ func New(base *mux.Router) *mux.Router {
 	addRegistryRoute(base)
 	addGraphQLRoute(base)
 	base.Path("/github-webhooks").Methods("POST").Name(GitHubWebhooks)
+	base.Path("/gitlab-webhooks").Methods("POST").Name(GitLabWebhooks)
 	base.Path("/bitbucket-server-webhooks").Methods("POST").Name(BitbucketServerWebhooks)
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
+	base.Path("/audit-log/export").Methods("GET").Name(AuditLogExport)
+	base.PathPrefix("/scim/v2/").Name(SCIM)
 
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
@@ -54,6 +54,7 @@ func init() {
 // enforce the maxAge field in its session store implementations, so we include the expiry here.
 type sessionInfo struct {
 	Actor        *actor.Actor  `json:"actor"`
+	CreatedAt    time.Time     `json:"createdAt"`
 	LastActive   time.Time     `json:"lastActive"`
 	ExpiryPeriod time.Duration `json:"expiryPeriod"`
 }
@@ -231,7 +232,8 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 				expiryPeriod = defaultExpiryPeriod
 			}
 		}
-		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: time.Now()}
+		now := time.Now()
+		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, CreatedAt: now, LastActive: now}
 	}
 	return SetData(w, r, "actor", value)
 }
@@ -344,7 +346,8 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 		}
 
 		// Check that user still exists.
-		if _, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
+		user, err := db.Users.GetByID(r.Context(), info.Actor.UID)
+		if err != nil {
 			if errcode.IsNotFound(err) {
 				_ = deleteSession(w, r) // clear the bad value
 			} else {
@@ -355,6 +358,20 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return r.Context() // not authenticated
 		}
 
+		// 🚨 SECURITY: Check that the user's sessions were not invalidated (e.g., because the user
+		// was deactivated) after this session was created. Sessions created before CreatedAt was
+		// recorded have a zero CreatedAt, so they are also invalid.
+		if user.InvalidatedSessionsAt != nil && !info.CreatedAt.After(*user.InvalidatedSessionsAt) {
+			_ = deleteSession(w, r) // clear the bad value
+			return r.Context()      // not authenticated
+		}
+
+		// 🚨 SECURITY: Suspended users may not use their sessions.
+		if user.SuspendedAt != nil {
+			_ = deleteSession(w, r) // clear the bad value
+			return r.Context()      // not authenticated
+		}
+
 		// Renew session
 		if time.Since(info.LastActive) > 5*time.Minute {
 			info.LastActive = time.Now()
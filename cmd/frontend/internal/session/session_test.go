@@ -170,6 +170,89 @@ func TestSessionExpiry(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that sessions created before the user's sessions were invalidated are
+// not valid.
+func TestInvalidatedSessions(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	var invalidatedSessionsAt *time.Time
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, InvalidatedSessionsAt: invalidatedSessionsAt}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	newAuthedReq := func() *http.Request {
+		w := httptest.NewRecorder()
+		if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123, FromSessionCookie: true}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Expires.After(time.Now()) || cookie.MaxAge > 0 {
+				req.AddCookie(cookie)
+			}
+		}
+		return req
+	}
+
+	oldReq := newAuthedReq()
+	if gotActor := actor.FromContext(authenticateByCookie(oldReq, httptest.NewRecorder())); !gotActor.IsAuthenticated() {
+		t.Fatal("want session to be valid before the user's sessions are invalidated")
+	}
+
+	now := time.Now()
+	invalidatedSessionsAt = &now
+	w := httptest.NewRecorder()
+	if gotActor := actor.FromContext(authenticateByCookie(oldReq, w)); gotActor.IsAuthenticated() {
+		t.Errorf("want session created before the user's sessions were invalidated to be invalid, got actor %+v", gotActor)
+	}
+	if deleted := strings.Contains(w.Header().Get("Set-Cookie"), cookieName+"=;"); !deleted {
+		t.Error("want invalidated session to be deleted")
+	}
+
+	newReq := newAuthedReq()
+	if gotActor := actor.FromContext(authenticateByCookie(newReq, httptest.NewRecorder())); !gotActor.IsAuthenticated() {
+		t.Error("want session created after the user's sessions were invalidated to be valid")
+	}
+}
+
+func TestSuspendedUser(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	var suspendedAt *time.Time
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, SuspendedAt: suspendedAt}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	w := httptest.NewRecorder()
+	if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123, FromSessionCookie: true}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Expires.After(time.Now()) || cookie.MaxAge > 0 {
+			req.AddCookie(cookie)
+		}
+	}
+
+	if gotActor := actor.FromContext(authenticateByCookie(req, httptest.NewRecorder())); !gotActor.IsAuthenticated() {
+		t.Fatal("want session to be valid before the user is suspended")
+	}
+
+	now := time.Now()
+	suspendedAt = &now
+	w = httptest.NewRecorder()
+	if gotActor := actor.FromContext(authenticateByCookie(req, w)); gotActor.IsAuthenticated() {
+		t.Errorf("want session of suspended user to be invalid, got actor %+v", gotActor)
+	}
+	if deleted := strings.Contains(w.Header().Get("Set-Cookie"), cookieName+"=;"); !deleted {
+		t.Error("want session of suspended user to be deleted")
+	}
+}
+
 func TestCookieMiddleware(t *testing.T) {
 	cleanup := ResetMockSessionStore(t)
 	defer cleanup()
@@ -17,9 +17,9 @@ import (
 // It is exposed as function in a package so that it can be called by other
 // main package implementations such as Sourcegraph Enterprise, which import
 // proprietary/private code.
-func Main(githubWebhook, bitbucketServerWebhook http.Handler) {
+func Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook http.Handler) {
 	env.Lock()
-	err := cli.Main(githubWebhook, bitbucketServerWebhook)
+	err := cli.Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "fatal:", err)
 		os.Exit(1)
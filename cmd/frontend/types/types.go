@@ -85,6 +85,14 @@ type User struct {
 	SiteAdmin   bool
 	BuiltinAuth bool
 	Tags        []string
+
+	// InvalidatedSessionsAt is when the user's sessions were last invalidated. Sessions that were
+	// created before then are not valid.
+	InvalidatedSessionsAt *time.Time
+
+	// SuspendedAt is when the user was suspended, or nil if the user is not suspended. Suspended
+	// users can't sign in or use access tokens, and they don't count toward the licensed user count.
+	SuspendedAt *time.Time
 }
 
 type Org struct {
@@ -304,3 +312,22 @@ type Event struct {
 	Version         string
 	Timestamp       time.Time
 }
+
+// AuditLogEntry records a security-relevant change, such as a site
+// configuration update or the creation of an access token.
+type AuditLogEntry struct {
+	ID          int64
+	CreatedAt   time.Time
+	ActorUserID *int32 // nil if the change was not made by a user
+	ActorIP     string
+	Action      string
+	TargetType  string
+	Target      string
+	Diff        string // lines removed ("-") and added ("+") by the change
+
+	// PrevHash is the Hash of the previous entry. Hash covers PrevHash and
+	// the other fields, so that entries can't be changed, removed or
+	// reordered without breaking the chain.
+	PrevHash string
+	Hash     string
+}
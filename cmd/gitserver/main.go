@@ -19,6 +19,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/gitserver/server"
 	"github.com/sourcegraph/sourcegraph/internal/debugserver"
 	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
 )
@@ -28,6 +29,7 @@ var (
 	runRepoCleanup, _ = strconv.ParseBool(env.Get("SRC_RUN_REPO_CLEANUP", "", "Periodically remove inactive repositories."))
 	wantPctFree       = env.Get("SRC_REPOS_DESIRED_PERCENT_FREE", "10", "Target percentage of free space on disk.")
 	janitorInterval   = env.Get("SRC_REPOS_JANITOR_INTERVAL", "1m", "Interval between cleanup runs")
+	hostname          = env.Get("HOSTNAME", "", "Name of this gitserver in SRC_GIT_SERVERS. Used to transfer repositories when gitservers are added.")
 )
 
 func main() {
@@ -46,10 +48,16 @@ func main() {
 	if err != nil {
 		log.Fatalf("parsing $SRC_REPOS_DESIRED_PERCENT_FREE: %v", err)
 	}
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
 	gitserver := server.Server{
 		ReposDir:                reposDir,
 		DeleteStaleRepositories: runRepoCleanup,
 		DesiredPercentFree:      wantPctFree2,
+		Hostname:                hostname,
+		GitServerAddrs:          gitserver.DefaultClient.Addrs,
+		GitServerReplicas:       gitserver.DefaultClient.Replicas,
 	}
 	gitserver.RegisterMetrics()
 
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -37,6 +38,12 @@ const (
 	// repoTTLGC is how often we should reclone a repository once it is
 	// reporting git gc issues.
 	repoTTLGC = time.Hour * 24 * 2
+	// repoMaintenanceInterval is how often we update the commit-graph,
+	// multi-pack-index and reachability bitmaps of a repository.
+	repoMaintenanceInterval = time.Hour * 24
+	// maxPromisorPacks is the number of promisor packs above which we repack
+	// a partial clone during maintenance.
+	maxPromisorPacks = 50
 )
 
 var reposRemoved = prometheus.NewCounter(prometheus.CounterOpts{
@@ -56,9 +63,11 @@ var reposRecloned = prometheus.NewCounter(prometheus.CounterOpts{
 // cleanupRepos walks the repos directory and performs maintenance tasks:
 //
 // 1. Remove corrupt repos.
-// 2. Remove stale lock files.
-// 3. Remove inactive repos on sourcegraph.com
-// 4. Reclone repos after a while. (simulate git gc)
+// 2. Remove repos which were transferred to another gitserver.
+// 3. Remove stale lock files.
+// 4. Remove inactive repos on sourcegraph.com
+// 5. Reclone repos after a while. (simulate git gc)
+// 6. Write commit-graphs, multi-pack-indexes and bitmaps.
 func (s *Server) cleanupRepos() {
 	bCtx, bCancel := s.serverContext()
 	defer bCancel()
@@ -79,6 +88,53 @@ func (s *Server) cleanupRepos() {
 		return true, nil
 	}
 
+	var gitDirs []GitDir
+	err := bestEffortWalk(s.ReposDir, func(dir string, fi os.FileInfo) error {
+		if s.ignorePath(dir) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Look for $GIT_DIR
+		if !fi.IsDir() || fi.Name() != ".git" {
+			return nil
+		}
+
+		// We are sure this is a GIT_DIR after the above check
+		gitDirs = append(gitDirs, GitDir(dir))
+		return filepath.SkipDir
+	})
+	if err != nil {
+		log15.Error("cleanup: error iterating over repositories", "error", err)
+	}
+
+	// Ask the other gitservers about all our repos up front, rather than
+	// sending one request per repo.
+	names := make([]api.RepoName, 0, len(gitDirs))
+	for _, dir := range gitDirs {
+		names = append(names, s.name(dir))
+	}
+	rebalanced, err := s.rebalancedTo(bCtx, names)
+	if err != nil {
+		log15.Error("cleanup: error checking for rebalanced repositories", "error", err)
+	}
+
+	maybeRemoveRebalanced := func(dir GitDir) (done bool, err error) {
+		owner := rebalanced[s.name(dir)]
+		if owner == "" {
+			return false, nil
+		}
+
+		log15.Info("removing repo placed on another gitserver", "repo", dir, "owner", owner)
+		if err := s.removeRepoDirectory(dir); err != nil {
+			return true, err
+		}
+		reposRebalancedRemoved.Inc()
+		return true, nil
+	}
+
 	ensureGitAttributes := func(dir GitDir) (done bool, err error) {
 		return false, setGitAttributes(dir)
 	}
@@ -129,13 +185,54 @@ func (s *Server) cleanupRepos() {
 			return false, errors.Wrap(err, "failed to get remote URL")
 		}
 
-		if _, err := s.cloneRepo(ctx, repo, remoteURL, &cloneOptions{Block: true, Overwrite: true}); err != nil {
+		// keep partial clones partial.
+		filter, err := repoPartialCloneFilter(dir)
+		if err != nil {
+			return false, err
+		}
+
+		if _, err := s.cloneRepo(ctx, repo, remoteURL, &cloneOptions{Block: true, Overwrite: true, Filter: filter}); err != nil {
 			return true, err
 		}
 		reposRecloned.Inc()
 		return true, nil
 	}
 
+	var promisorBytes int64
+	measurePromisorPacks := func(dir GitDir) (done bool, err error) {
+		if !isPartialClone(dir) {
+			return false, nil
+		}
+		n, _, err := promisorPackBytes(dir)
+		promisorBytes += n
+		return false, err
+	}
+
+	maybeMaintain := func(dir GitDir) (done bool, err error) {
+		maintenanceTime, err := getMaintenanceTime(dir)
+		if err != nil {
+			return false, err
+		}
+		if time.Since(maintenanceTime) < repoMaintenanceInterval+jitterDuration(string(dir), repoMaintenanceInterval/4) {
+			return false, nil
+		}
+
+		// update the maintenance time first so that we don't retry on every
+		// cleanup run if maintenance fails.
+		if err := setMaintenanceTime(dir, time.Now()); err != nil {
+			return false, err
+		}
+
+		ctx, cancel := context.WithTimeout(bCtx, longGitCommandTimeout)
+		defer cancel()
+
+		if err := maintainRepo(ctx, dir); err != nil {
+			return false, err
+		}
+		reposMaintained.Inc()
+		return false, nil
+	}
+
 	removeStaleLocks := func(dir GitDir) (done bool, err error) {
 		gitDir := string(dir)
 
@@ -151,6 +248,14 @@ func (s *Server) cleanupRepos() {
 		if err := removeFileOlderThan(filepath.Join(gitDir, "packed-refs.lock"), time.Hour); err != nil {
 			multi = multierror.Append(multi, err)
 		}
+		// commit-graph and multi-pack-index writes are part of our own
+		// maintenance, which is bounded by longGitCommandTimeout.
+		if err := removeFileOlderThan(filepath.Join(gitDir, "objects", "info", "commit-graphs", "commit-graph-chain.lock"), time.Hour); err != nil {
+			multi = multierror.Append(multi, err)
+		}
+		if err := removeFileOlderThan(filepath.Join(gitDir, "objects", "pack", "multi-pack-index.lock"), time.Hour); err != nil {
+			multi = multierror.Append(multi, err)
+		}
 		// we use the same conservative age for locks inside of refs
 		if err := bestEffortWalk(filepath.Join(gitDir, "refs"), func(path string, fi os.FileInfo) error {
 			if fi.IsDir() {
@@ -169,6 +274,16 @@ func (s *Server) cleanupRepos() {
 		return false, multi
 	}
 
+	removeStaleTmpPacks := func(dir GitDir) (done bool, err error) {
+		// Fetches remove the temporary packs of interrupted fetches. Partial
+		// clones also fetch lazily while serving exec requests, which nothing
+		// cleans up after.
+		if isPartialClone(dir) {
+			s.cleanTmpFiles(dir)
+		}
+		return false, nil
+	}
+
 	type cleanupFn struct {
 		Name string
 		Do   func(GitDir) (bool, error)
@@ -176,9 +291,16 @@ func (s *Server) cleanupRepos() {
 	cleanups := []cleanupFn{
 		// Do some sanity checks on the repository.
 		{"maybe remove corrupt", maybeRemoveCorrupt},
+		// When gitservers are added, repositories placed on the new
+		// gitservers are transferred from this one. Once they are cloned
+		// there, our copy is no longer used.
+		{"maybe remove rebalanced", maybeRemoveRebalanced},
 		// If git is interrupted it can leave lock files lying around. It does
 		// not clean these up, and instead fails commands.
 		{"remove stale locks", removeStaleLocks},
+		// Lazy fetches in partial clones can be interrupted by the timeout of
+		// the exec request and leave temporary packs behind.
+		{"remove stale temporary packs", removeStaleTmpPacks},
 		// We always want to have the same git attributes file at
 		// info/attributes.
 		{"ensure git attributes", ensureGitAttributes},
@@ -187,24 +309,17 @@ func (s *Server) cleanupRepos() {
 		// these problems. git gc is slow and resource intensive. It is
 		// cheaper and faster to just reclone the repository.
 		{"maybe reclone", maybeReclone},
+		// git log, merge-base and reachability checks walk the commit
+		// history, which is slow for large repositories unless git can use
+		// a commit-graph and bitmaps. Since we never run git gc, we write
+		// them ourselves.
+		{"maybe maintain", maybeMaintain},
+		// Lazy fetches grow partial clones between cleanup runs. We measure
+		// their promisor packs to reserve space for that growth.
+		{"measure promisor packs", measurePromisorPacks},
 	}
 
-	err := bestEffortWalk(s.ReposDir, func(dir string, fi os.FileInfo) error {
-		if s.ignorePath(dir) {
-			if fi.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-
-		// Look for $GIT_DIR
-		if !fi.IsDir() || fi.Name() != ".git" {
-			return nil
-		}
-
-		// We are sure this is a GIT_DIR after the above check
-		gitDir := GitDir(dir)
-
+	for _, gitDir := range gitDirs {
 		for _, cfn := range cleanups {
 			done, err := cfn.Do(gitDir)
 			if err != nil {
@@ -214,16 +329,20 @@ func (s *Server) cleanupRepos() {
 				break
 			}
 		}
-		return filepath.SkipDir
-	})
-	if err != nil {
-		log15.Error("cleanup: error iterating over repositories", "error", err)
 	}
 
 	if s.DiskSizer == nil {
 		s.DiskSizer = &StatDiskSizer{}
 	}
-	b, err := s.howManyBytesToFree()
+	// Only growth is reserved: shrinking promisor packs (e.g. because
+	// partial clones were removed or recloned) doesn't need space.
+	promisorGrowth := promisorBytes - s.lastPromisorPackBytes
+	if s.lastPromisorPackBytes == 0 || promisorGrowth < 0 {
+		promisorGrowth = 0
+	}
+	s.lastPromisorPackBytes = promisorBytes
+
+	b, err := s.howManyBytesToFree(promisorGrowth)
 	if err != nil {
 		log15.Error("cleanup: ensuring free disk space", "error", err)
 	}
@@ -240,7 +359,11 @@ type DiskSizer interface {
 
 // howManyBytesToFree returns the number of bytes that should be freed to make sure
 // there is sufficient disk space free to satisfy s.DesiredPercentFree.
-func (s *Server) howManyBytesToFree() (int64, error) {
+//
+// promisorGrowth is the number of bytes the promisor packs of partial clones
+// grew by since the last cleanup run. Lazy fetches are expected to write about
+// as much again before the next run, so that space is freed as well.
+func (s *Server) howManyBytesToFree(promisorGrowth int64) (int64, error) {
 	// Check how much disk space is available.
 	mountPoint, err := findMountPoint(s.ReposDir)
 	if err != nil {
@@ -257,7 +380,7 @@ func (s *Server) howManyBytesToFree() (int64, error) {
 		return 0, errors.Wrap(err, "getting disk size")
 	}
 	desiredFreeBytes := uint64(float64(s.DesiredPercentFree) / 100.0 * float64(diskSizeBytes))
-	howManyBytesToFree := int64(desiredFreeBytes - actualFreeBytes)
+	howManyBytesToFree := int64(desiredFreeBytes-actualFreeBytes) + promisorGrowth
 	if howManyBytesToFree < 0 {
 		howManyBytesToFree = 0
 	}
@@ -266,6 +389,7 @@ func (s *Server) howManyBytesToFree() (int64, error) {
 		"desired percent free", s.DesiredPercentFree,
 		"actual percent free", float64(actualFreeBytes)/float64(diskSizeBytes)*100.0,
 		"amount to free in GiB", float64(howManyBytesToFree)/G,
+		"promisor pack growth in GiB", float64(promisorGrowth)/G,
 		"mount point", mountPoint)
 	return howManyBytesToFree, nil
 }
@@ -645,6 +769,125 @@ func getRecloneTime(dir GitDir) (time.Time, error) {
 	return time.Unix(sec, 0), nil
 }
 
+// setMaintenanceTime sets the time the commit-graph, multi-pack-index and
+// bitmaps of a repository were last updated.
+func setMaintenanceTime(dir GitDir, now time.Time) error {
+	err := gitConfigSet(dir, "sourcegraph.maintenanceTimestamp", strconv.FormatInt(now.Unix(), 10))
+	if err != nil {
+		return errors.Wrap(err, "failed to update maintenanceTimestamp")
+	}
+	return nil
+}
+
+// getMaintenanceTime returns the time the commit-graph, multi-pack-index and
+// bitmaps of a repository were last updated. If the value is not stored in the
+// repository, or can't be parsed, the zero Unix time is returned so that the
+// repository is maintained on the next cleanup run.
+func getMaintenanceTime(dir GitDir) (time.Time, error) {
+	value, err := gitConfigGet(dir, "sourcegraph.maintenanceTimestamp")
+	if err != nil {
+		return time.Unix(0, 0), errors.Wrap(err, "failed to determine maintenance timestamp")
+	}
+
+	sec, err := strconv.ParseInt(strings.TrimSpace(value), 10, 0)
+	if err != nil {
+		return time.Unix(0, 0), nil
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// maintainRepo writes or incrementally updates the commit-graph, the
+// multi-pack-index and its reachability bitmap of the repository at dir.
+//
+// The commit-graph is written as a chain of split graph files, so only the
+// commits added since the last run are written. The multi-pack-index covers
+// all packs, which lets us write a reachability bitmap without repacking the
+// repository.
+//
+// In a partial clone every lazy fetch of missing blobs writes a small promisor
+// pack. If there are more than maxPromisorPacks of them, the repository is
+// repacked first. git keeps the objects of promisor packs in a promisor pack,
+// so this doesn't fetch the blobs left out by the clone. We don't write a
+// bitmap for partial clones, since it can't cover the missing blobs.
+func maintainRepo(ctx context.Context, dir GitDir) error {
+	var steps [][]string
+
+	partial := isPartialClone(dir)
+	if partial {
+		if _, count, err := promisorPackBytes(dir); err != nil {
+			return err
+		} else if count > maxPromisorPacks {
+			steps = append(steps, []string{"repack", "-a", "-d", "-q"})
+		}
+	}
+
+	steps = append(steps, []string{"commit-graph", "write", "--reachable", "--split"})
+
+	// git multi-pack-index fails if there are no packs, e.g. in a repository
+	// which is still empty.
+	if packs, _ := filepath.Glob(dir.Path("objects", "pack", "*.pack")); len(packs) > 0 {
+		if partial || !gitVersionAtLeast(2, 34) {
+			// Writing a bitmap for a multi-pack-index requires git 2.34.
+			steps = append(steps, []string{"multi-pack-index", "write"})
+		} else {
+			steps = append(steps, []string{"multi-pack-index", "write", "--bitmap"})
+		}
+	}
+
+	for _, args := range steps {
+		start := time.Now()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = string(dir)
+		_, err := cmd.Output()
+		repoMaintenanceDuration.WithLabelValues(args[0], strconv.FormatBool(err == nil)).Observe(time.Since(start).Seconds())
+		if err != nil {
+			return errors.Wrapf(wrapCmdError(cmd, err), "maintenance step %s failed", args[0])
+		}
+	}
+	return nil
+}
+
+var (
+	gitVersionMu    sync.Mutex
+	gitVersionKnown bool
+	gitVersionMajor int
+	gitVersionMinor int
+)
+
+// gitVersionRe matches the version in the output of git version, e.g. "git
+// version 2.34.1" or "git version 2.24.3 (Apple Git-128)".
+var gitVersionRe = lazyregexp.New(`^git version (\d+)\.(\d+)`)
+
+// gitVersionAtLeast reports whether the installed git is at least version
+// major.minor. The version is determined once and then remembered. If it can't
+// be determined, gitVersionAtLeast returns false and tries again on the next
+// call.
+func gitVersionAtLeast(major, minor int) bool {
+	gitVersionMu.Lock()
+	defer gitVersionMu.Unlock()
+
+	if !gitVersionKnown {
+		// The probe doesn't use the caller's context, which may be about to
+		// be canceled.
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "git", "version").Output()
+		if err != nil {
+			log15.Warn("failed to determine git version", "error", err)
+			return false
+		}
+		m := gitVersionRe.FindSubmatch(bytes.TrimSpace(out))
+		if m == nil {
+			log15.Warn("failed to parse git version", "version", string(out))
+			return false
+		}
+		gitVersionMajor, _ = strconv.Atoi(string(m[1]))
+		gitVersionMinor, _ = strconv.Atoi(string(m[2]))
+		gitVersionKnown = true
+	}
+	return gitVersionMajor > major || (gitVersionMajor == major && gitVersionMinor >= minor)
+}
+
 // maybeCorruptStderrRe matches stderr lines from git which indicate there
 // might be repository corruption.
 //
@@ -657,6 +900,12 @@ func checkMaybeCorruptRepo(repo api.RepoName, dir GitDir, stderr string) {
 		return
 	}
 
+	// In a partial clone git fails to read objects it couldn't fetch from the
+	// promisor remote. Recloning doesn't help with that.
+	if strings.Contains(stderr, "promisor remote") {
+		return
+	}
+
 	log15.Warn("marking repo for recloning due to stderr output indicating repo corruption", "repo", repo, "stderr", stderr)
 
 	// We set a flag in the config for the cleanup janitor job to fix. The
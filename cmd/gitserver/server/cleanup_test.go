@@ -231,6 +231,95 @@ func TestCleanupOldLocks(t *testing.T) {
 	)
 }
 
+func TestCleanupMaintenance(t *testing.T) {
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+
+	repoNew := filepath.Join(root, "repo-new")
+	repoMaintained := filepath.Join(root, "repo-maintained")
+	for _, repo := range []string{repoNew, repoMaintained} {
+		if err := os.MkdirAll(repo, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{
+			{"init", "."},
+			{"commit", "--allow-empty", "-m", "hello"},
+			// Don't let gc write a commit-graph, so that maintenance
+			// starts a commit-graph chain.
+			{"-c", "gc.writeCommitGraph=false", "gc"},
+		} {
+			c := exec.Command("git", args...)
+			c.Dir = repo
+			c.Env = []string{
+				"GIT_COMMITTER_NAME=a",
+				"GIT_COMMITTER_EMAIL=a@a.com",
+				"GIT_AUTHOR_NAME=a",
+				"GIT_AUTHOR_EMAIL=a@a.com",
+			}
+			if out, err := c.CombinedOutput(); err != nil {
+				t.Fatalf("git %s failed: %s: %s", strings.Join(args, " "), err, out)
+			}
+		}
+	}
+
+	maintainedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := setMaintenanceTime(GitDir(filepath.Join(repoMaintained, ".git")), maintainedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{ReposDir: root}
+	s.Handler() // Handler as a side-effect sets up Server
+	s.cleanupRepos()
+
+	dir := GitDir(filepath.Join(repoNew, ".git"))
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graphs", "commit-graph-chain")); err != nil {
+		t.Errorf("expected commit-graph chain to be written: %v", err)
+	}
+	if _, err := os.Stat(dir.Path("objects", "pack", "multi-pack-index")); err != nil {
+		t.Errorf("expected multi-pack-index to be written: %v", err)
+	}
+	if gitVersionAtLeast(2, 34) {
+		if bitmaps, _ := filepath.Glob(dir.Path("objects", "pack", "multi-pack-index-*.bitmap")); len(bitmaps) == 0 {
+			t.Error("expected multi-pack-index bitmap to be written")
+		}
+	}
+	if ts, err := getMaintenanceTime(dir); err != nil {
+		t.Fatal(err)
+	} else if time.Since(ts) > time.Minute {
+		t.Errorf("expected maintenance time of repo-new to be updated, got %v", ts)
+	}
+
+	dir = GitDir(filepath.Join(repoMaintained, ".git"))
+	if _, err := os.Stat(dir.Path("objects", "info", "commit-graphs", "commit-graph-chain")); !os.IsNotExist(err) {
+		t.Errorf("expected recently maintained repo to be skipped, got err %v", err)
+	}
+	if ts, err := getMaintenanceTime(dir); err != nil {
+		t.Fatal(err)
+	} else if !ts.Equal(maintainedAt) {
+		t.Errorf("expected maintenance time of repo-maintained to be %v, got %v", maintainedAt, ts)
+	}
+}
+
+func TestGitVersionAtLeast_retriesFailures(t *testing.T) {
+	gitVersionMu.Lock()
+	gitVersionKnown = false
+	gitVersionMu.Unlock()
+
+	// Without git on the PATH the version can't be determined.
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	got := gitVersionAtLeast(1, 0)
+	os.Setenv("PATH", path)
+	if got {
+		t.Fatal("expected false without git")
+	}
+
+	// The failure isn't remembered.
+	if !gitVersionAtLeast(1, 0) {
+		t.Error("expected git to be at least version 1.0")
+	}
+}
+
 func TestSetupAndClearTmp(t *testing.T) {
 	root, cleanup := tmpDir(t)
 	defer cleanup()
@@ -367,10 +456,11 @@ func Test_howManyBytesToFree(t *testing.T) {
 	}
 
 	tcs := []struct {
-		name      string
-		diskSize  uint64
-		bytesFree uint64
-		want      int64
+		name           string
+		diskSize       uint64
+		bytesFree      uint64
+		promisorGrowth int64
+		want           int64
 	}{
 		{
 			name:      "if there is already enough space, no space is freed",
@@ -390,6 +480,13 @@ func Test_howManyBytesToFree(t *testing.T) {
 			bytesFree: 0.5 * G,
 			want:      int64(0.5 * G),
 		},
+		{
+			name:           "space for the growth of promisor packs is freed",
+			diskSize:       10 * G,
+			bytesFree:      1.5 * G,
+			promisorGrowth: 1 * G,
+			want:           int64(0.5 * G),
+		},
 	}
 
 	for _, tc := range tcs {
@@ -398,7 +495,7 @@ func Test_howManyBytesToFree(t *testing.T) {
 				diskSize:  tc.diskSize,
 				bytesFree: tc.bytesFree,
 			}
-			b, err := s.howManyBytesToFree()
+			b, err := s.howManyBytesToFree(tc.promisorGrowth)
 			if err != nil {
 				t.Fatal(err)
 			}
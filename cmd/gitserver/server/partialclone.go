@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+// blobLimitRe matches the blob size limits git accepts for
+// --filter=blob:limit=<n>: a number of bytes with an optional k, m or g
+// suffix.
+var blobLimitRe = lazyregexp.New(`^[0-9]+[kmg]?$`)
+
+// partialCloneFilter returns the object filter for a partial clone without the
+// blobs larger than blobLimit. It returns an empty filter if blobLimit is
+// empty, which means the repository is cloned in full.
+func partialCloneFilter(blobLimit string) (string, error) {
+	if blobLimit == "" {
+		return "", nil
+	}
+	if !blobLimitRe.MatchString(blobLimit) {
+		return "", errors.Errorf("invalid partial clone blob limit %q", blobLimit)
+	}
+	return "blob:limit=" + blobLimit, nil
+}
+
+// repoPartialCloneFilter returns the object filter the repository at dir was
+// cloned with, or an empty string if it is a full clone.
+func repoPartialCloneFilter(dir GitDir) (string, error) {
+	filter, err := gitConfigGet(dir, "remote.origin.partialclonefilter")
+	return strings.TrimSpace(filter), err
+}
+
+// isPartialClone is a cheap check whether the repository at dir is a partial
+// clone. It reads the config file directly instead of running git config,
+// since it is called for every exec request.
+func isPartialClone(dir GitDir) bool {
+	b, err := ioutil.ReadFile(dir.Path("config"))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(b), []byte("partialclonefilter"))
+}
+
+// fetchFilterArgs returns the extra arguments for git fetch in the repository
+// at dir. We fetch by URL rather than by remote name, so git doesn't apply
+// the filter of a partial clone by itself and would fetch all blobs of the new
+// commits without them.
+func fetchFilterArgs(dir GitDir) []string {
+	if filter, _ := repoPartialCloneFilter(dir); filter != "" {
+		return []string{"--filter=" + filter}
+	}
+	return nil
+}
+
+// promisorPackBytes returns the total size of the packs fetched from the
+// promisor remote of a partial clone. That includes the packs git writes for
+// every lazy fetch of missing blobs.
+func promisorPackBytes(dir GitDir) (n int64, count int, err error) {
+	markers, err := filepath.Glob(dir.Path("objects", "pack", "*.promisor"))
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, marker := range markers {
+		base := strings.TrimSuffix(marker, ".promisor")
+		for _, ext := range []string{".pack", ".idx"} {
+			fi, err := os.Stat(base + ext)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return 0, 0, err
+			}
+			n += fi.Size()
+		}
+	}
+	return n, len(markers), nil
+}
+
+// prefetchMissingBlobs fetches the blobs of treeish (limited to paths, if
+// any) that are missing from the partial clone at dir in a single request.
+// Otherwise git archive would lazily fetch them one request at a time.
+func prefetchMissingBlobs(ctx context.Context, dir GitDir, treeish string, paths []string) error {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--objects", "--missing=print", treeish, "--")
+	cmd.Dir = string(dir)
+	out, err := cmd.Output()
+	if err != nil {
+		return errors.Wrap(wrapCmdError(cmd, err), "listing missing objects")
+	}
+
+	missing := map[string]bool{}
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(line) > 1 && line[0] == '?' {
+			missing[string(line[1:])] = true
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if len(paths) > 0 {
+		// Only fetch the blobs below paths. git ls-tree only reads trees, so
+		// it doesn't trigger lazy fetches itself.
+		cmd = exec.CommandContext(ctx, "git", append([]string{"--literal-pathspecs", "ls-tree", "-r", "-z", "--full-tree", treeish, "--"}, paths...)...)
+		cmd.Dir = string(dir)
+		out, err := cmd.Output()
+		if err != nil {
+			return errors.Wrap(wrapCmdError(cmd, err), "listing tree")
+		}
+
+		wanted := map[string]bool{}
+		for _, entry := range bytes.Split(out, []byte{0}) {
+			// <mode> SP <type> SP <object> TAB <file>
+			fields := bytes.Fields(bytes.SplitN(entry, []byte("\t"), 2)[0])
+			if len(fields) == 3 && missing[string(fields[2])] {
+				wanted[string(fields[2])] = true
+			}
+		}
+		missing = wanted
+		if len(missing) == 0 {
+			return nil
+		}
+	}
+
+	var stdin bytes.Buffer
+	w := bufio.NewWriter(&stdin)
+	for oid := range missing {
+		_, _ = w.WriteString(oid + "\n")
+	}
+	_ = w.Flush()
+
+	cmd = exec.CommandContext(ctx, "git",
+		"-c", "fetch.negotiationAlgorithm=noop",
+		"fetch", "--no-tags", "--no-write-fetch-head", "--recurse-submodules=no", "--filter=blob:none", "--stdin", "origin")
+	cmd.Dir = string(dir)
+	cmd.Stdin = &stdin
+	if _, err := runWithRemoteOpts(ctx, cmd, nil); err != nil {
+		return errors.Wrap(err, "fetching missing blobs")
+	}
+	return nil
+}
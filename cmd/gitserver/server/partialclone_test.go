@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPartialCloneFilter(t *testing.T) {
+	for _, tc := range []struct {
+		blobLimit string
+		want      string
+		wantErr   bool
+	}{
+		{blobLimit: "", want: ""},
+		{blobLimit: "1024", want: "blob:limit=1024"},
+		{blobLimit: "1m", want: "blob:limit=1m"},
+		{blobLimit: "1M", wantErr: true},
+		{blobLimit: "1m --upload-pack=evil", wantErr: true},
+	} {
+		got, err := partialCloneFilter(tc.blobLimit)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("partialCloneFilter(%q): got error %v, want error: %t", tc.blobLimit, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("partialCloneFilter(%q) = %q, want %q", tc.blobLimit, got, tc.want)
+		}
+	}
+}
+
+func TestPrefetchMissingBlobs(t *testing.T) {
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+
+	remote := filepath.Join(root, "remote")
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = dir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		}
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s: %s", name, strings.Join(arg, " "), err, b)
+		}
+		return string(b)
+	}
+
+	// Setup a remote with two blobs above the limit of the partial clone.
+	cmd(root, "git", "init", "remote")
+	cmd(remote, "git", "config", "uploadpack.allowFilter", "true")
+	cmd(remote, "git", "config", "uploadpack.allowAnySHA1InWant", "true")
+	cmd(remote, "sh", "-c", "echo small > small.txt")
+	cmd(remote, "sh", "-c", "mkdir a b && seq 1000 > a/big.txt && seq 2000 > b/big.txt")
+	cmd(remote, "git", "add", ".")
+	cmd(remote, "git", "commit", "-m", "init")
+
+	// The filter is ignored for local paths, so we clone from a file URL.
+	cmd(root, "git", "clone", "--mirror", "--filter=blob:limit=1k", "file://"+remote, "clone.git")
+	dir := GitDir(filepath.Join(root, "clone.git"))
+
+	if !isPartialClone(dir) {
+		t.Fatal("expected a partial clone")
+	}
+	// git normalizes the filter when it stores it.
+	if filter, err := repoPartialCloneFilter(dir); err != nil || filter != "blob:limit=1024" {
+		t.Fatalf("got filter %q (error %v), want blob:limit=1024", filter, err)
+	}
+
+	missing := func() []string {
+		out := cmd(string(dir), "git", "rev-list", "--objects", "--missing=print", "HEAD")
+		var oids []string
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "?") {
+				oids = append(oids, line[1:])
+			}
+		}
+		return oids
+	}
+	if got := len(missing()); got != 2 {
+		t.Fatalf("got %d missing blobs after cloning, want 2", got)
+	}
+
+	if err := prefetchMissingBlobs(context.Background(), dir, "HEAD", []string{"a"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(missing()); got != 1 {
+		t.Fatalf("got %d missing blobs after prefetching a/, want 1", got)
+	}
+
+	if err := prefetchMissingBlobs(context.Background(), dir, "HEAD", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := missing(); len(got) != 0 {
+		t.Fatalf("got missing blobs %v after prefetching everything", got)
+	}
+
+	n, count, err := promisorPackBytes(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 || count != 3 {
+		t.Errorf("got %d promisor packs of %d bytes, want 3 packs", count, n)
+	}
+}
@@ -0,0 +1,459 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// Repositories are placed on gitservers with consistent hashing. When a
+// gitserver is added, about 1/N of the repositories are placed on it. Instead
+// of cloning them from the code host, it transfers them from the gitserver
+// they were previously placed on, and proxies requests for them there until
+// the transfer is done. The previous owner removes its copy during cleanup
+// once the new owner has it.
+//
+// Repositories can also be cloned on more than one gitserver (replicas). A
+// replica transfers the repository from the gitserver it is placed on (the
+// primary) in the same way.
+
+// rebalanceHeader is set on requests between gitservers, so that a proxied
+// request is never proxied again.
+const rebalanceHeader = "X-Gitserver-Rebalance"
+
+var rebalanceHTTPClient = &http.Client{}
+
+var (
+	reposTransferred = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_transferred",
+		Help:      "number of repos transferred from the gitserver they were previously placed on, instead of cloned",
+	}, []string{"success"})
+	reposRebalancedRemoved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_rebalanced_removed",
+		Help:      "number of repos removed during cleanup because they are placed on and cloned by another gitserver",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(reposTransferred)
+	prometheus.MustRegister(reposRebalancedRemoved)
+}
+
+// selfAddr returns the address of this gitserver in addrs, or "" if it isn't
+// in addrs. An address matches if it is s.Hostname, or if its host name
+// (without the port and domain) is s.Hostname.
+func (s *Server) selfAddr(addrs []string) string {
+	if s.Hostname == "" {
+		return ""
+	}
+	for _, addr := range addrs {
+		if addr == s.Hostname {
+			return addr
+		}
+		host := addr
+		if h, _, err := net.SplitHostPort(addr); err == nil {
+			host = h
+		}
+		if i := strings.IndexByte(host, '.'); i >= 0 && net.ParseIP(host) == nil {
+			host = host[:i]
+		}
+		if host == s.Hostname {
+			return addr
+		}
+	}
+	return ""
+}
+
+// addrs returns the addresses of all gitservers and the address of this
+// gitserver. self is "" if rebalancing is not configured or this gitserver
+// isn't in addrs.
+func (s *Server) addrs(ctx context.Context) (addrs []string, self string) {
+	if s.GitServerAddrs == nil {
+		return nil, ""
+	}
+	addrs = s.GitServerAddrs(ctx)
+	return addrs, s.selfAddr(addrs)
+}
+
+// replicaAddrs returns the addresses of the gitservers repo is cloned on. The
+// first one is the gitserver it is placed on.
+func (s *Server) replicaAddrs(ctx context.Context, addrs []string, repo api.RepoName) []string {
+	replicas := 1
+	if s.GitServerReplicas != nil {
+		replicas = s.GitServerReplicas(ctx)
+	}
+	return gitserver.RepoAddrs(addrs, repo, replicas)
+}
+
+// previousOwners returns the addresses of the gitservers repo can be
+// transferred from: if this gitserver is the primary of repo, the gitservers
+// it may have been placed on before. Otherwise the other replicas of repo,
+// starting with the primary.
+func (s *Server) previousOwners(ctx context.Context, repo api.RepoName) []string {
+	addrs, self := s.addrs(ctx)
+	if self == "" {
+		return nil
+	}
+	replicaAddrs := s.replicaAddrs(ctx, addrs, repo)
+	if len(replicaAddrs) == 0 {
+		return nil
+	}
+	if replicaAddrs[0] == self {
+		return gitserver.PreviousRepoAddrs(addrs, self, repo)
+	}
+
+	var owners []string
+	isReplica := false
+	for _, addr := range replicaAddrs {
+		if addr == self {
+			isReplica = true
+		} else {
+			owners = append(owners, addr)
+		}
+	}
+	if !isReplica {
+		return nil
+	}
+	return owners
+}
+
+// handleRepoTransfer streams a tar archive of the git directory of a repo. It
+// is used by the gitserver a repository is now placed on to transfer it from
+// this gitserver.
+func (s *Server) handleRepoTransfer(w http.ResponseWriter, r *http.Request) {
+	repo := protocol.NormalizeRepo(api.RepoName(r.URL.Query().Get("repo")))
+	if repo == "" {
+		http.Error(w, "repo is required", http.StatusBadRequest)
+		return
+	}
+
+	dir := s.dir(repo)
+	if !repoCloned(dir) {
+		http.Error(w, "repo not cloned", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	if err := writeRepoTar(w, dir); err != nil {
+		// We already sent a 200, but the receiver fails to read the truncated
+		// archive.
+		log15.Warn("failed to transfer repo", "repo", repo, "error", err)
+	}
+}
+
+// writeRepoTar writes a tar archive of the git directory dir to w. Git only
+// ever adds objects before updating refs, so we archive the refs before the
+// objects to get a consistent copy while the repository is being fetched
+// into. If a file disappears while we archive it (e.g. a pack removed by a
+// repack), the archive is not consistent and we fail.
+func writeRepoTar(w io.Writer, dir GitDir) error {
+	tw := tar.NewWriter(w)
+	written := map[string]bool{}
+	add := func(path string, fi os.FileInfo) error {
+		rel, err := filepath.Rel(string(dir), path)
+		if err != nil {
+			return err
+		}
+		if written[rel] || rel == "." {
+			return nil
+		}
+		written[rel] = true
+
+		// Skip lock files and temporary files of git processes that are
+		// running.
+		if strings.HasSuffix(rel, ".lock") || strings.HasPrefix(fi.Name(), "tmp_") {
+			return nil
+		}
+		if !fi.IsDir() && !fi.Mode().IsRegular() {
+			return nil
+		}
+
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if fi.IsDir() {
+			hdr.Name += "/"
+			return tw.WriteHeader(hdr)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = io.CopyN(tw, f, hdr.Size)
+		return err
+	}
+
+	for _, name := range []string{"HEAD", "packed-refs", "refs", ""} {
+		root := filepath.Join(string(dir), name)
+		err := filepath.Walk(root, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				if path == root && os.IsNotExist(err) && name != "" {
+					// packed-refs may not exist.
+					return nil
+				}
+				return err
+			}
+			return add(path, fi)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// transferRepo transfers repo from the gitserver it was previously placed on
+// to the git directory dst, which must not exist. It returns false if there
+// is no previous owner which has the repository.
+func (s *Server) transferRepo(ctx context.Context, repo api.RepoName, dst GitDir, lock *RepositoryLock) (bool, error) {
+	for _, from := range s.previousOwners(ctx, repo) {
+		lock.SetStatus(fmt.Sprintf("transferring from %s", from))
+		ok, err := transferRepoFrom(ctx, from, repo, dst)
+		if !ok && err == nil {
+			// from doesn't have it, try the next one.
+			continue
+		}
+		reposTransferred.WithLabelValues(fmt.Sprint(err == nil)).Inc()
+		if err != nil {
+			// Clean up, so the caller can fall back to cloning into dst.
+			os.RemoveAll(string(dst))
+			return false, errors.Wrapf(err, "failed to transfer %s from %s", repo, from)
+		}
+		log15.Info("repo transferred", "repo", repo, "from", from)
+		return true, nil
+	}
+	return false, nil
+}
+
+// transferRepoFrom requests the repo from the gitserver at from and extracts
+// it to dst. It returns false and no error if from doesn't have the repo.
+func transferRepoFrom(ctx context.Context, from string, repo api.RepoName, dst GitDir) (bool, error) {
+	req, err := http.NewRequest("GET", "http://"+from+"/repo-transfer?repo="+url.QueryEscape(string(repo)), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(rebalanceHeader, "transfer")
+	resp, err := rebalanceHTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, errors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	if err := extractRepoTar(resp.Body, dst); err != nil {
+		return true, err
+	}
+	if _, err := os.Stat(dst.Path("HEAD")); err != nil {
+		return true, errors.Wrap(err, "transferred repo is missing HEAD")
+	}
+	return true, nil
+}
+
+// extractRepoTar extracts a tar archive written by writeRepoTar to dst.
+func extractRepoTar(r io.Reader, dst GitDir) error {
+	if err := os.MkdirAll(string(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// 🚨 SECURITY: Never write outside of dst.
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return errors.Errorf("invalid path in repo archive: %q", hdr.Name)
+		}
+		path := filepath.Join(string(dst), name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unexpected file type in repo archive: %q", hdr.Name)
+		}
+	}
+}
+
+// proxyToPreviousOwner serves req with the gitserver repo was previously placed
+// on, while this gitserver is cloning or transferring it. It returns false if
+// no previous owner has the repo, in which case nothing was written to w.
+func (s *Server) proxyToPreviousOwner(w http.ResponseWriter, r *http.Request, req *protocol.ExecRequest) bool {
+	if r.Header.Get(rebalanceHeader) != "" {
+		return false
+	}
+
+	// The previous owner must not clone or fetch the repo.
+	proxied := *req
+	proxied.URL = ""
+	proxied.EnsureRevision = ""
+	body, err := json.Marshal(&proxied)
+	if err != nil {
+		return false
+	}
+
+	for _, from := range s.previousOwners(r.Context(), req.Repo) {
+		preq, err := http.NewRequest("POST", "http://"+from+"/exec", bytes.NewReader(body))
+		if err != nil {
+			return false
+		}
+		preq.Header.Set(rebalanceHeader, "proxy")
+		resp, err := rebalanceHTTPClient.Do(preq.WithContext(r.Context()))
+		if err != nil {
+			log15.Warn("failed to proxy exec request to previous owner", "repo", req.Repo, "addr", from, "error", err)
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		for k := range resp.Trailer {
+			w.Header().Add("Trailer", k)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.Copy(w, resp.Body)
+		resp.Body.Close()
+		for k, v := range resp.Trailer {
+			w.Header()[k] = v
+		}
+		return true
+	}
+	return false
+}
+
+// rebalanceBatchSize is the maximum number of repos we ask another gitserver
+// about in one request.
+const rebalanceBatchSize = 1000
+
+// rebalancedTo returns the repos among repos which are not cloned on this
+// gitserver (neither as primary nor as replica) but have been cloned by the
+// gitserver they are placed on, mapped to the address of that gitserver. The
+// other gitservers are asked in batches, so that checking all repos takes a
+// few requests per gitserver rather than one per repo.
+func (s *Server) rebalancedTo(ctx context.Context, repos []api.RepoName) (map[api.RepoName]string, error) {
+	addrs, self := s.addrs(ctx)
+	if self == "" {
+		return nil, nil
+	}
+
+	byOwner := make(map[string][]api.RepoName)
+outer:
+	for _, repo := range repos {
+		replicaAddrs := s.replicaAddrs(ctx, addrs, repo)
+		for _, addr := range replicaAddrs {
+			if addr == self {
+				continue outer
+			}
+		}
+		owner := replicaAddrs[0]
+		byOwner[owner] = append(byOwner[owner], repo)
+	}
+
+	rebalanced := make(map[api.RepoName]string)
+	var errs *multierror.Error
+	for owner, ownerRepos := range byOwner {
+		for len(ownerRepos) > 0 {
+			batch := ownerRepos
+			if len(batch) > rebalanceBatchSize {
+				batch = batch[:rebalanceBatchSize]
+			}
+			ownerRepos = ownerRepos[len(batch):]
+
+			cloned, err := reposCloned(ctx, owner, batch)
+			if err != nil {
+				// Try the other gitservers, this one is checked again on
+				// the next cleanup.
+				errs = multierror.Append(errs, errors.Wrapf(err, "checking repos cloned on %s", owner))
+				break
+			}
+			for _, repo := range cloned {
+				rebalanced[repo] = owner
+			}
+		}
+	}
+	return rebalanced, errs.ErrorOrNil()
+}
+
+// reposCloned returns the repos among repos which are cloned on the gitserver
+// at addr.
+func reposCloned(ctx context.Context, addr string, repos []api.RepoName) ([]api.RepoName, error) {
+	body, err := json.Marshal(&protocol.ReposClonedRequest{Repos: repos})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", "http://"+addr+"/repos-cloned", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(rebalanceHeader, "cleanup")
+	resp, err := rebalanceHTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var res protocol.ReposClonedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return res.Cloned, nil
+}
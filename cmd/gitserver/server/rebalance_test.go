@@ -0,0 +1,148 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+func TestTransferRepo(t *testing.T) {
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = dir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		}
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s: %s", name, strings.Join(arg, " "), err, b)
+		}
+		return string(b)
+	}
+
+	// Setup a remote with a commit.
+	remote := filepath.Join(root, "remote")
+	cmd(root, "git", "init", "remote")
+	cmd(remote, "sh", "-c", "echo hello world > hello.txt")
+	cmd(remote, "git", "add", "hello.txt")
+	cmd(remote, "git", "commit", "-m", "hello")
+	wantCommit := cmd(remote, "git", "rev-parse", "HEAD")
+
+	// Start two gitservers. Only the old one has a clone.
+	var addrs []string
+	startServer := func(name string) *Server {
+		reposDir := filepath.Join(root, name)
+		if err := os.MkdirAll(reposDir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		s := &Server{
+			ReposDir:       reposDir,
+			GitServerAddrs: func(context.Context) []string { return addrs },
+		}
+		ts := httptest.NewServer(s.Handler())
+		t.Cleanup(ts.Close)
+		s.Hostname = strings.TrimPrefix(ts.URL, "http://")
+		addrs = append(addrs, s.Hostname)
+		return s
+	}
+	oldServer := startServer("old")
+	newServer := startServer("new")
+
+	// Find a repo which is placed on the new gitserver.
+	var repo api.RepoName
+	for i := 0; ; i++ {
+		repo = api.RepoName(fmt.Sprintf("example.com/foo/bar%d", i))
+		if gitserver.RepoAddr(addrs, repo) == newServer.Hostname {
+			break
+		}
+	}
+	if _, err := oldServer.cloneRepo(context.Background(), repo, remote, &cloneOptions{Block: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old gitserver keeps its copy until the new one has it.
+	if rebalanced, err := oldServer.rebalancedTo(context.Background(), []api.RepoName{repo}); err != nil || rebalanced[repo] != "" {
+		t.Fatalf("got rebalancedTo %q (error %v) before the transfer, want none", rebalanced[repo], err)
+	}
+
+	// The new gitserver transfers the repo instead of cloning it from the
+	// remote, so it doesn't see new commits on the remote.
+	cmd(remote, "git", "commit", "--allow-empty", "-m", "after transfer")
+	if _, err := newServer.cloneRepo(context.Background(), repo, remote, &cloneOptions{Block: true}); err != nil {
+		t.Fatal(err)
+	}
+	if gotCommit := cmd(string(newServer.dir(repo)), "git", "rev-parse", "HEAD"); gotCommit != wantCommit {
+		t.Fatalf("failed to transfer: got %s, want %s", gotCommit, wantCommit)
+	}
+
+	if rebalanced, err := oldServer.rebalancedTo(context.Background(), []api.RepoName{repo}); err != nil || rebalanced[repo] != newServer.Hostname {
+		t.Fatalf("got rebalancedTo %q (error %v) after the transfer, want %q", rebalanced[repo], err, newServer.Hostname)
+	}
+	if rebalanced, err := newServer.rebalancedTo(context.Background(), []api.RepoName{repo}); err != nil || rebalanced[repo] != "" {
+		t.Fatalf("got rebalancedTo %q (error %v) on the owner, want none", rebalanced[repo], err)
+	}
+}
+
+func TestExtractRepoTar_invalidPath(t *testing.T) {
+	for _, name := range []string{"../evil", "/evil", "objects/../../evil"} {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: 4, Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte("evil")); err != nil {
+			t.Fatal(err)
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		root, cleanup := tmpDir(t)
+		dst := GitDir(filepath.Join(root, "dst", ".git"))
+		if err := extractRepoTar(&buf, dst); err == nil {
+			t.Errorf("expected %q to be rejected", name)
+		}
+		if _, err := os.Stat(filepath.Join(root, "evil")); !os.IsNotExist(err) {
+			t.Errorf("%q was written outside of the repo", name)
+		}
+		cleanup()
+	}
+}
+
+func TestSelfAddr(t *testing.T) {
+	addrs := []string{"gitserver-0.gitserver:3178", "gitserver-1.gitserver:3178", "10.0.0.1:3178"}
+	tests := []struct {
+		hostname string
+		want     string
+	}{
+		{hostname: "gitserver-1", want: "gitserver-1.gitserver:3178"},
+		{hostname: "gitserver-0.gitserver:3178", want: "gitserver-0.gitserver:3178"},
+		{hostname: "10.0.0.1:3178", want: "10.0.0.1:3178"},
+		{hostname: "gitserver-2", want: ""},
+		{hostname: "10", want: ""},
+		{hostname: "", want: ""},
+	}
+	for _, test := range tests {
+		s := &Server{Hostname: test.hostname}
+		if got := s.selfAddr(addrs); got != test.want {
+			t.Errorf("selfAddr with hostname %q: got %q, want %q", test.hostname, got, test.want)
+		}
+	}
+}
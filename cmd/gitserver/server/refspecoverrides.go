@@ -24,8 +24,9 @@ func useRefspecOverrides() bool {
 // monorepo. https://github.com/sourcegraph/customer/issues/19
 //
 // To not clone everything we instead init a bare repo and only add the
-// refspecs we care about. Then we finally do a fetch.
-func refspecOverridesCloneCmd(ctx context.Context, url, tmpPath string) (*exec.Cmd, error) {
+// refspecs we care about. Then we finally do a fetch. If filter is not empty
+// origin is configured as the promisor remote of a partial clone.
+func refspecOverridesCloneCmd(ctx context.Context, url, tmpPath, filter string) (*exec.Cmd, error) {
 	if err := os.MkdirAll(tmpPath, os.ModePerm); err != nil {
 		return nil, errors.Wrapf(err, "clone failed to create tmp dir")
 	}
@@ -37,6 +38,12 @@ func refspecOverridesCloneCmd(ctx context.Context, url, tmpPath string) (*exec.C
 	for _, refspec := range refspecOverrides {
 		cmds = append(cmds, []string{"config", "--add", "remote.origin.fetch", refspec})
 	}
+	if filter != "" {
+		cmds = append(cmds,
+			[]string{"config", "remote.origin.promisor", "true"},
+			[]string{"config", "remote.origin.partialclonefilter", filter},
+		)
+	}
 	for _, args := range cmds {
 		cmd := exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = tmpPath
@@ -51,6 +58,8 @@ func refspecOverridesCloneCmd(ctx context.Context, url, tmpPath string) (*exec.C
 
 // HACK(keegancsmith) workaround to experiment with cloning less in a large
 // monorepo. https://github.com/sourcegraph/customer/issues/19
-func refspecOverridesFetchCmd(ctx context.Context, url string) *exec.Cmd {
-	return exec.CommandContext(ctx, "git", append([]string{"fetch", "--prune", url}, refspecOverrides...)...)
+func refspecOverridesFetchCmd(ctx context.Context, url string, extraArgs []string) *exec.Cmd {
+	args := append([]string{"fetch", "--prune"}, extraArgs...)
+	args = append(args, url)
+	return exec.CommandContext(ctx, "git", append(args, refspecOverrides...)...)
 }
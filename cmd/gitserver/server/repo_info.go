@@ -47,6 +47,17 @@ func (s *Server) repoInfo(ctx context.Context, repo api.RepoName) (*protocol.Rep
 		} else {
 			resp.LastChanged = &lastChanged
 		}
+
+		if filter, err := repoPartialCloneFilter(dir); err != nil {
+			log15.Warn("error getting partial clone filter", "repo", repo, "err", err)
+		} else if filter != "" {
+			resp.PartialCloneFilter = filter
+			if n, _, err := promisorPackBytes(dir); err != nil {
+				log15.Warn("error computing promisor pack size", "repo", repo, "err", err)
+			} else {
+				resp.PromisorPackBytes = n
+			}
+		}
 	}
 	return &resp, nil
 }
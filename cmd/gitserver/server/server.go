@@ -116,6 +116,20 @@ type Server struct {
 	// DiskSizer tells how much disk is free and how large the disk is.
 	DiskSizer DiskSizer
 
+	// Hostname identifies this gitserver in the addresses returned by
+	// GitServerAddrs. It matches an address which is equal to it, or whose
+	// host without the port and domain is equal to it.
+	Hostname string
+
+	// GitServerAddrs returns the addresses of all gitservers. It is used to
+	// transfer repositories between gitservers when gitservers are added. If
+	// it is nil, repositories are always cloned from the code host.
+	GitServerAddrs func(context.Context) []string
+
+	// GitServerReplicas returns the number of gitservers each repository is
+	// cloned on. If it is nil, each repository is cloned on one gitserver.
+	GitServerReplicas func(context.Context) int
+
 	// skipCloneForTests is set by tests to avoid clones.
 	skipCloneForTests bool
 
@@ -138,6 +152,11 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	// lastPromisorPackBytes is the total size of the promisor packs of
+	// partial clones at the last cleanup run. It is only accessed by
+	// cleanupRepos.
+	lastPromisorPackBytes int64
 }
 
 type locks struct {
@@ -150,6 +169,7 @@ type locks struct {
 // than "git rev-parse", so this will return an appropriate timeout given the
 // command.
 func shortGitCommandTimeout(args []string) time.Duration {
+	args = gitSubcommandArgs(args)
 	if len(args) < 1 {
 		return time.Minute
 	}
@@ -170,10 +190,20 @@ func shortGitCommandTimeout(args []string) time.Duration {
 	}
 }
 
+// gitSubcommandArgs returns args with any leading global options such as
+// "--literal-pathspecs" removed, so that args[0] is the git subcommand.
+func gitSubcommandArgs(args []string) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		args = args[1:]
+	}
+	return args
+}
+
 // shortGitCommandSlow returns the threshold for regarding an git command as
 // slow. Some commands such as "git archive" are inherently slower than "git
 // rev-parse", so this will return an appropriate threshold given the command.
 func shortGitCommandSlow(args []string) time.Duration {
+	args = gitSubcommandArgs(args)
 	if len(args) < 1 {
 		return time.Second
 	}
@@ -229,11 +259,13 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/list-gitolite", s.handleListGitolite)
 	mux.HandleFunc("/is-repo-cloneable", s.handleIsRepoCloneable)
 	mux.HandleFunc("/is-repo-cloned", s.handleIsRepoCloned)
+	mux.HandleFunc("/repos-cloned", s.handleReposCloned)
 	mux.HandleFunc("/repos", s.handleRepoInfo)
 	mux.HandleFunc("/delete", s.handleRepoDelete)
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
 	mux.HandleFunc("/create-commit-from-patch", s.handleCreateCommitFromPatch)
+	mux.HandleFunc("/repo-transfer", s.handleRepoTransfer)
 	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -378,6 +410,23 @@ func (s *Server) handleIsRepoCloned(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) handleReposCloned(w http.ResponseWriter, r *http.Request) {
+	var req protocol.ReposClonedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := protocol.ReposClonedResponse{Cloned: []api.RepoName{}}
+	for _, repo := range req.Repos {
+		if repoCloned(s.dir(protocol.NormalizeRepo(repo))) {
+			resp.Cloned = append(resp.Cloned, repo)
+		}
+	}
+	if err := json.NewEncoder(w).Encode(&resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // handleRepoUpdate is a synchronous (waits for update to complete or
 // time out) method so it can yield errors. Updates are not
 // unconditional; we debounce them based on the provided
@@ -403,7 +452,10 @@ func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 		// optimistically, we assume that our cloning attempt might
 		// succeed.
 		resp.CloneInProgress = true
-		_, err := s.cloneRepo(ctx, req.Repo, req.URL, &cloneOptions{Block: true})
+		filter, err := partialCloneFilter(req.PartialCloneBlobLimit)
+		if err == nil {
+			_, err = s.cloneRepo(ctx, req.Repo, req.URL, &cloneOptions{Block: true, Filter: filter})
+		}
 		if err != nil {
 			log15.Warn("error cloning repo", "repo", req.Repo, "err", err)
 			resp.Error = err.Error()
@@ -470,9 +522,22 @@ func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == "POST" {
+		var body protocol.ArchiveRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		paths = append(paths, body.Paths...)
+	}
+
 	req := &protocol.ExecRequest{
 		Repo: api.RepoName(repo),
 		Args: []string{
+			// 🚨 SECURITY: paths are file names, not pathspecs. Without this,
+			// a path such as "*" or ":(glob)**" would archive other files.
+			"--literal-pathspecs",
+
 			"archive",
 
 			// Suppresses fatal error when the repo contains paths matching **/.git/** and instead
@@ -496,6 +561,19 @@ func (s *Server) handleArchive(w http.ResponseWriter, r *http.Request) {
 	req.Args = append(req.Args, treeish, "--")
 	req.Args = append(req.Args, paths...)
 
+	// In a partial clone git archive would fetch every missing blob in a
+	// separate request. Fetching them upfront in one request is much faster.
+	// This is best-effort, git archive still fetches whatever is missing.
+	dir := s.dir(protocol.NormalizeRepo(req.Repo))
+	if isPartialClone(dir) {
+		ctx, cancel := context.WithTimeout(r.Context(), longGitCommandTimeout)
+		err := prefetchMissingBlobs(ctx, dir, treeish, paths)
+		cancel()
+		if err != nil {
+			log15.Warn("failed to prefetch missing blobs for archive", "repo", repo, "treeish", treeish, "error", err)
+		}
+	}
+
 	s.exec(w, r, req)
 }
 
@@ -533,8 +611,8 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	{
 		repo := repotrackutil.GetTrackedRepo(req.Repo)
 		cmd := ""
-		if len(req.Args) > 0 {
-			cmd = req.Args[0]
+		if args := gitSubcommandArgs(req.Args); len(args) > 0 {
+			cmd = args[0]
 		}
 		args := strings.Join(req.Args, " ")
 
@@ -610,6 +688,10 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	dir := s.dir(req.Repo)
 	cloneProgress, cloneInProgress := s.locker.Status(dir)
 	if cloneInProgress {
+		if s.proxyToPreviousOwner(w, r, req) {
+			status = "proxied"
+			return
+		}
 		status = "clone-in-progress"
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(&protocol.NotFoundPayload{
@@ -625,7 +707,11 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 			_ = json.NewEncoder(w).Encode(&protocol.NotFoundPayload{CloneInProgress: false})
 			return
 		}
-		cloneProgress, err := s.cloneRepo(ctx, req.Repo, req.URL, nil)
+		filter, err := partialCloneFilter(req.PartialCloneBlobLimit)
+		if err != nil {
+			log15.Warn("ignoring partial clone blob limit", "repo", req.Repo, "err", err)
+		}
+		cloneProgress, err := s.cloneRepo(ctx, req.Repo, req.URL, &cloneOptions{Filter: filter})
 		if err != nil {
 			log15.Debug("error cloning repo", "repo", req.Repo, "err", err)
 			status = "repo-not-found"
@@ -633,6 +719,12 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 			_ = json.NewEncoder(w).Encode(&protocol.NotFoundPayload{CloneInProgress: false})
 			return
 		}
+		// The repo may have been placed on this gitserver recently. Until it
+		// is transferred, the previous owner can still serve it.
+		if s.proxyToPreviousOwner(w, r, req) {
+			status = "proxied"
+			return
+		}
 		status = "clone-in-progress"
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(&protocol.NotFoundPayload{
@@ -676,6 +768,11 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	cmd.Dir = string(dir)
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
+	if isPartialClone(dir) {
+		// git lazily fetches missing blobs from the promisor remote, so the
+		// command needs the same options as our fetches.
+		configureRemoteGitCommand(cmd, tlsExternal().(*tlsConfig))
+	}
 
 	exitStatus, execErr = runCommand(ctx, cmd)
 
@@ -724,6 +821,10 @@ type cloneOptions struct {
 
 	// Overwrite will overwrite the existing clone.
 	Overwrite bool
+
+	// Filter is the object filter of a partial clone, such as
+	// "blob:limit=1m". The repository is cloned in full if it is empty.
+	Filter string
 }
 
 // cloneRepo issues a git clone command for the given repo. It is
@@ -807,18 +908,38 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		tmpPath = filepath.Join(tmpPath, ".git")
 		tmp := GitDir(tmpPath)
 
+		var filter string
+		if opts != nil {
+			filter = opts.Filter
+		}
+
+		// If the repo was placed on this gitserver when gitservers were
+		// added, transfer it from the gitserver it was placed on before. This
+		// is much faster than cloning it from the code host.
+		if !overwrite {
+			transferred, err := s.transferRepo(ctx, repo, tmp, lock)
+			if err != nil {
+				log15.Warn("failed to transfer repo, cloning it instead", "repo", repo, "error", err)
+			}
+			if transferred {
+				return s.finishClone(repo, tmp, dstPath, false)
+			}
+		}
+
 		var cmd *exec.Cmd
 		if useRefspecOverrides() {
-			cmd, err = refspecOverridesCloneCmd(ctx, url, tmpPath)
+			cmd, err = refspecOverridesCloneCmd(ctx, url, tmpPath, filter)
 			if err != nil {
 				return err
 			}
+		} else if filter != "" {
+			cmd = exec.CommandContext(ctx, "git", "clone", "--mirror", "--progress", "--filter="+filter, url, tmpPath)
 		} else {
 			cmd = exec.CommandContext(ctx, "git", "clone", "--mirror", "--progress", url, tmpPath)
 		}
 		// see issue #7322: skip LFS content in repositories with Git LFS configured
 		cmd.Env = append(cmd.Env, "GIT_LFS_SKIP_SMUDGE=1")
-		log15.Info("cloning repo", "repo", repo, "tmp", tmpPath, "dst", dstPath)
+		log15.Info("cloning repo", "repo", repo, "tmp", tmpPath, "dst", dstPath, "filter", filter)
 
 		pr, pw := io.Pipe()
 		defer pw.Close()
@@ -835,30 +956,7 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 			return errors.Wrapf(err, "failed to update last changed time")
 		}
 
-		// Set gitattributes
-		if err := setGitAttributes(tmp); err != nil {
-			return err
-		}
-
-		if overwrite {
-			// remove the current repo by putting it into our temporary directory
-			err := renameAndSync(dstPath, filepath.Join(filepath.Dir(tmpPath), "old"))
-			if err != nil && !os.IsNotExist(err) {
-				return errors.Wrapf(err, "failed to remove old clone")
-			}
-		}
-
-		if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
-			return err
-		}
-		if err := renameAndSync(tmpPath, dstPath); err != nil {
-			return err
-		}
-
-		log15.Info("repo cloned", "repo", repo)
-		repoClonedCounter.Inc()
-
-		return nil
+		return s.finishClone(repo, tmp, dstPath, overwrite)
 	}
 
 	if opts != nil && opts.Block {
@@ -881,6 +979,37 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 	return "", nil
 }
 
+// finishClone moves the clone or transfer of repo at tmp to dstPath. If
+// overwrite is true, it replaces the existing clone at dstPath.
+func (s *Server) finishClone(repo api.RepoName, tmp GitDir, dstPath string, overwrite bool) error {
+	tmpPath := string(tmp)
+
+	// Set gitattributes
+	if err := setGitAttributes(tmp); err != nil {
+		return err
+	}
+
+	if overwrite {
+		// remove the current repo by putting it into our temporary directory
+		err := renameAndSync(dstPath, filepath.Join(filepath.Dir(tmpPath), "old"))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove old clone")
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dstPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := renameAndSync(tmpPath, dstPath); err != nil {
+		return err
+	}
+
+	log15.Info("repo cloned", "repo", repo)
+	repoClonedCounter.Inc()
+
+	return nil
+}
+
 // readCloneProgress scans the reader and saves the most recent line of output
 // as the lock status.
 func readCloneProgress(redactor *urlRedactor, lock *RepositoryLock, pr io.Reader) {
@@ -1308,9 +1437,11 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		cmd = customCmd
 		configRemoteOpts = false
 	} else if useRefspecOverrides() {
-		cmd = refspecOverridesFetchCmd(ctx, url)
+		cmd = refspecOverridesFetchCmd(ctx, url, fetchFilterArgs(dir))
 	} else {
-		cmd = exec.CommandContext(ctx, "git", "fetch", "--prune", url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*", "+refs/sourcegraph/*:refs/sourcegraph/*")
+		args := append([]string{"fetch", "--prune"}, fetchFilterArgs(dir)...)
+		args = append(args, url, "+refs/heads/*:refs/heads/*", "+refs/tags/*:refs/tags/*", "+refs/pull/*:refs/pull/*", "+refs/sourcegraph/*:refs/sourcegraph/*")
+		cmd = exec.CommandContext(ctx, "git", args...)
 	}
 	cmd.Dir = string(dir)
 
@@ -1,10 +1,12 @@
 package server
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
@@ -12,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -413,6 +416,73 @@ func TestCloneRepo(t *testing.T) {
 	}
 }
 
+func TestHandleArchive_literalPaths(t *testing.T) {
+	reposDir, cleanup := tmpDir(t)
+	defer cleanup()
+
+	repo := filepath.Join(reposDir, "example.com", "foo")
+	if err := os.MkdirAll(repo, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"git", "init", "."},
+		{"sh", "-c", "echo a > a.txt && echo star > '*.txt'"},
+		{"git", "add", "."},
+		{"git", "commit", "-m", "init"},
+	} {
+		c := exec.Command(args[0], args[1:]...)
+		c.Dir = repo
+		c.Env = append(os.Environ(), "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@a.com", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@a.com")
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("%s failed: %s: %s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	s := &Server{ReposDir: reposDir}
+	h := s.Handler()
+
+	origRepoCloned := repoCloned
+	repoCloned = func(dir GitDir) bool { return dir == s.dir("example.com/foo") }
+	defer func() { repoCloned = origRepoCloned }()
+
+	archive := func(req *http.Request) []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d", rec.Code, http.StatusOK)
+		}
+		var names []string
+		tr := tar.NewReader(rec.Body)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hdr.Typeflag == tar.TypeXGlobalHeader {
+				continue
+			}
+			names = append(names, hdr.Name)
+		}
+		return names
+	}
+
+	// "*.txt" is the name of a file, so it must not match a.txt.
+	want := []string{"*.txt"}
+	if got := archive(httptest.NewRequest("GET", "/archive?repo=example.com/foo&treeish=HEAD&format=tar&path=*.txt", nil)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %q, want %q", got, want)
+	}
+
+	// Paths can also be sent in the body of a POST request.
+	body := strings.NewReader(`{"paths": ["*.txt"]}`)
+	if got := archive(httptest.NewRequest("POST", "/archive?repo=example.com/foo&treeish=HEAD&format=tar", body)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got files %q from POST, want %q", got, want)
+	}
+}
+
 func TestRemoveBadRefs(t *testing.T) {
 	dir, cleanup := tmpDir(t)
 	defer cleanup()
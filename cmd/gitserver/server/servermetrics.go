@@ -10,7 +10,26 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
 )
 
+var (
+	reposMaintained = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repos_maintained",
+		Help:      "number of repos whose commit-graph, multi-pack-index and bitmaps were updated during cleanup",
+	})
+	repoMaintenanceDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "src",
+		Subsystem: "gitserver",
+		Name:      "repo_maintenance_duration_seconds",
+		Help:      "Duration of the repo maintenance steps run during cleanup.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"step", "success"})
+)
+
 func (s *Server) RegisterMetrics() {
+	prometheus.MustRegister(reposMaintained)
+	prometheus.MustRegister(repoMaintenanceDuration)
+
 	// test the latency of exec, which may increase under certain memory
 	// conditions
 	echoDuration := prometheus.NewGauge(prometheus.GaugeOpts{
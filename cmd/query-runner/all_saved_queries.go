@@ -164,5 +164,12 @@ func serveTestNotification(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if args.SavedSearch.Config.NotifyWebhook {
+		if err := webhookNotifyTest(r.Context(), args.SavedSearch); err != nil {
+			writeError(w, fmt.Errorf("error sending webhook notification: %s", err))
+			return
+		}
+	}
+
 	log15.Info("saved query test notification sent", "spec", args.SavedSearch.Spec, "key", args.SavedSearch.Spec.Key)
 }
@@ -54,6 +54,7 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ApproximateResultCount string
 				Ownership              string
 				PluralResults          string
+				Matches                []string
 			}{
 				URL:                    searchURL(n.newQuery, utmSourceEmail),
 				Description:            n.query.Description,
@@ -61,6 +62,7 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ApproximateResultCount: n.results.Data.Search.Results.ApproximateResultCount,
 				Ownership:              ownership,
 				PluralResults:          plural,
+				Matches:                n.matches,
 			}); err != nil {
 				log15.Error("Failed to send email notification for new saved search results.", "userID", recipient.spec.userID, "error", err)
 			}
@@ -74,14 +76,22 @@ var newSearchResultsEmailTemplates = txemail.MustValidate(txtypes.Templates{
 {{.ApproximateResultCount}} new search result{{.PluralResults}} found for {{.Ownership}} saved search:
 
   "{{.Description}}"
-
+{{if .Matches}}
+New matches:
+{{range .Matches}}
+  {{.}}{{end}}
+{{end}}
 View the new result{{.PluralResults}} on Sourcegraph: {{.URL}}
 `,
 	HTML: `
 <strong>{{.ApproximateResultCount}}</strong> new search result{{.PluralResults}} found for {{.Ownership}} saved search:
 
 <p style="padding-left: 16px">&quot;{{.Description}}&quot;</p>
-
+{{if .Matches}}
+<p>New matches:</p>
+<pre style="padding-left: 16px">{{range .Matches}}{{.}}
+{{end}}</pre>
+{{end}}
 <p><a href="{{.URL}}">View the new result{{.PluralResults}} on Sourcegraph</a></p>
 `,
 })
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// Code searches (as opposed to commit and diff searches) don't support the
+// after: filter, so they can't be restricted to new results. Instead, the
+// whole query is run and the fingerprints of its matches are stored. The
+// matches whose fingerprint was not found in the previous run are new.
+
+const (
+	// fingerprintResultLimit is the count: added to code searches which
+	// don't specify one.
+	fingerprintResultLimit = 1000
+
+	// maxStoredFingerprints bounds the fingerprints stored for a query.
+	maxStoredFingerprints = 10 * fingerprintResultLimit
+
+	// maxNotifiedMatches is the maximum number of new matches listed in
+	// email and Slack notifications.
+	maxNotifiedMatches = 10
+)
+
+var countFieldRegexp = lazyregexp.New(`(?i)(^|\s)count:`)
+
+// isCommitSearch reports whether q only searches commits or diffs, which
+// support the after: filter.
+func isCommitSearch(q string) bool {
+	info, err := query.ParseAndCheck(q)
+	if err != nil {
+		// The search fails anyway, treat it like a code search.
+		return false
+	}
+	types, _ := info.StringValues(query.FieldType)
+	if len(types) == 0 {
+		return false
+	}
+	for _, t := range types {
+		if t != "diff" && t != "commit" {
+			return false
+		}
+	}
+	return true
+}
+
+// withResultLimit adds a count: to query, unless it has one.
+func withResultLimit(query string) string {
+	if countFieldRegexp.MatchString(query) {
+		return query
+	}
+	return fmt.Sprintf("%s count:%d", query, fingerprintResultLimit)
+}
+
+// resultsDiff is the difference between the matches of a code search and
+// the matches of its previous run.
+type resultsDiff struct {
+	// added are the results with new matches. File matches only contain
+	// their new line matches.
+	added []interface{}
+
+	addedCount, removedCount int
+
+	// fingerprints are the fingerprints to store for the next run, sorted.
+	fingerprints []string
+}
+
+// diffResults compares the matches in results with the fingerprints of the
+// matches of the previous run.
+//
+// If complete is false, results may be missing matches (e.g. because the
+// result limit was hit or repositories timed out). Then no match is counted
+// as removed, and the fingerprints of the previous run are kept, so that
+// matches which are only missing this time aren't reported as new next time.
+func diffResults(results []interface{}, prev []string, complete bool) *resultsDiff {
+	seen := make(map[string]bool, len(prev))
+	for _, fp := range prev {
+		seen[fp] = true
+	}
+
+	d := &resultsDiff{}
+	current := make(map[string]bool)
+	var order []string // the fingerprints in current, in the order of the results
+	add := func(fp string) {
+		if !current[fp] {
+			current[fp] = true
+			order = append(order, fp)
+		}
+	}
+	for _, result := range results {
+		m, ok := result.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		lineMatches, _ := m["lineMatches"].([]interface{})
+		if m["__typename"] != "FileMatch" || len(lineMatches) == 0 {
+			// Other results (e.g. repositories, or files matching by path)
+			// match as a whole.
+			fp := resultFingerprint(m)
+			add(fp)
+			if !seen[fp] {
+				d.added = append(d.added, result)
+				d.addedCount++
+			}
+			continue
+		}
+
+		// A line match is identified by its file and its content, not its line
+		// number, so that it isn't new when lines above it change. Identical
+		// lines in a file are told apart by their occurrence.
+		file := fileMatchKey(m)
+		occurrences := make(map[string]int)
+		var addedLines []interface{}
+		for _, lm := range lineMatches {
+			preview := strings.TrimSpace(linePreview(lm))
+			occurrences[preview]++
+			fp := fingerprint(file, preview, strconv.Itoa(occurrences[preview]))
+			add(fp)
+			if !seen[fp] {
+				addedLines = append(addedLines, lm)
+			}
+		}
+		if len(addedLines) > 0 {
+			added := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				added[k] = v
+			}
+			added["lineMatches"] = addedLines
+			d.added = append(d.added, added)
+			d.addedCount += len(addedLines)
+		}
+	}
+
+	if complete {
+		for fp := range seen {
+			if !current[fp] {
+				d.removedCount++
+			}
+		}
+	} else if len(current)+len(seen) <= maxStoredFingerprints {
+		for _, fp := range prev {
+			add(fp)
+		}
+	}
+
+	// Only the fingerprints of the first results are stored, so matches
+	// beyond them are reported as new again on the next run.
+	if len(order) > maxStoredFingerprints {
+		order = order[:maxStoredFingerprints]
+	}
+	d.fingerprints = order
+	sort.Strings(d.fingerprints)
+	return d
+}
+
+// response returns v with only the added results.
+func (d *resultsDiff) response(v *gqlSearchResponse) *gqlSearchResponse {
+	added := *v
+	added.Data.Search.Results.Results = d.added
+	added.Data.Search.Results.ApproximateResultCount = strconv.Itoa(d.addedCount)
+	return &added
+}
+
+func fingerprint(parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:16])
+}
+
+func resultFingerprint(result map[string]interface{}) string {
+	if result["__typename"] == "FileMatch" {
+		return fingerprint(fileMatchKey(result))
+	}
+	b, _ := json.Marshal(result) // map keys are sorted, so this is stable
+	return fingerprint(string(b))
+}
+
+// fileMatchKey returns the repository and path of a file match. The revision
+// is left out, so that the matches in a file stay the same when a branch
+// moves.
+func fileMatchKey(m map[string]interface{}) string {
+	resource, _ := m["resource"].(string)
+	u, err := url.Parse(resource)
+	if err != nil {
+		return resource
+	}
+	return u.Host + u.Path + "/" + u.Fragment
+}
+
+func linePreview(lineMatch interface{}) string {
+	lm, _ := lineMatch.(map[string]interface{})
+	preview, _ := lm["preview"].(string)
+	return preview
+}
+
+// matchSummaries returns a line describing each of the first max matches in
+// results, e.g. "github.com/foo/bar/main.go:12: password := ...", followed by
+// a line with the number of matches left out, if any.
+func matchSummaries(results []interface{}, max int) []string {
+	var summaries []string
+	omitted := 0
+	add := func(s string) {
+		if len(summaries) < max {
+			summaries = append(summaries, s)
+		} else {
+			omitted++
+		}
+	}
+	for _, result := range results {
+		m, ok := result.(map[string]interface{})
+		if !ok || m["__typename"] != "FileMatch" {
+			continue
+		}
+		file := fileMatchKey(m)
+		lineMatches, _ := m["lineMatches"].([]interface{})
+		if len(lineMatches) == 0 {
+			add(file)
+			continue
+		}
+		for _, lm := range lineMatches {
+			lmm, _ := lm.(map[string]interface{})
+			lineNumber, _ := lmm["lineNumber"].(float64) // zero-based
+			preview := strings.TrimSpace(linePreview(lm))
+			if len(preview) > 200 {
+				preview = preview[:200] + "..."
+			}
+			add(fmt.Sprintf("%s:%d: %s", file, int(lineNumber)+1, preview))
+		}
+	}
+	if omitted > 0 {
+		summaries = append(summaries, fmt.Sprintf("... and %d more", omitted))
+	}
+	return summaries
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	fileMatch := func(path string, lines ...string) interface{} {
+		var lineMatches []interface{}
+		for i, line := range lines {
+			lineMatches = append(lineMatches, map[string]interface{}{"preview": line, "lineNumber": float64(i)})
+		}
+		return map[string]interface{}{
+			"__typename":  "FileMatch",
+			"resource":    "git://github.com/foo/bar?master#" + path,
+			"lineMatches": lineMatches,
+		}
+	}
+
+	first := diffResults([]interface{}{
+		fileMatch("a.go", "password := x", "password := x"),
+		fileMatch("b.go", "password := y"),
+	}, nil, true)
+	if first.addedCount != 3 || first.removedCount != 0 || len(first.fingerprints) != 3 {
+		t.Fatalf("first run: got %d added, %d removed, %d fingerprints, want 3, 0, 3", first.addedCount, first.removedCount, len(first.fingerprints))
+	}
+
+	// Moved lines are not new. A third identical line and a new file are,
+	// and the match in b.go is gone.
+	second := diffResults([]interface{}{
+		fileMatch("a.go", "", "  password := x", "password := x", "password := x"),
+		fileMatch("c.go", "password := z"),
+	}, first.fingerprints, true)
+	if second.addedCount != 3 || second.removedCount != 1 {
+		t.Fatalf("second run: got %d added, %d removed, want 3 added, 1 removed", second.addedCount, second.removedCount)
+	}
+	if want := []string{
+		"github.com/foo/bar/a.go:1: ",
+		"github.com/foo/bar/a.go:4: password := x",
+		"github.com/foo/bar/c.go:1: password := z",
+	}; !reflect.DeepEqual(matchSummaries(second.added, 10), want) {
+		t.Errorf("got matches %q, want %q", matchSummaries(second.added, 10), want)
+	}
+	if want := []string{"github.com/foo/bar/a.go:1: ", "... and 2 more"}; !reflect.DeepEqual(matchSummaries(second.added, 1), want) {
+		t.Errorf("got matches %q, want %q", matchSummaries(second.added, 1), want)
+	}
+
+	// Incomplete results don't remove fingerprints.
+	third := diffResults([]interface{}{fileMatch("c.go", "password := z")}, second.fingerprints, false)
+	if third.addedCount != 0 || third.removedCount != 0 || !reflect.DeepEqual(third.fingerprints, second.fingerprints) {
+		t.Errorf("incomplete run: got %d added, %d removed, fingerprints changed %v", third.addedCount, third.removedCount, !reflect.DeepEqual(third.fingerprints, second.fingerprints))
+	}
+}
+
+func TestDiffResults_maxStoredFingerprints(t *testing.T) {
+	var results []interface{}
+	for i := 0; i < maxStoredFingerprints+10; i++ {
+		results = append(results, map[string]interface{}{"__typename": "Repository", "name": strconv.Itoa(i)})
+	}
+	d := diffResults(results, nil, true)
+	if len(d.fingerprints) != maxStoredFingerprints {
+		t.Fatalf("got %d fingerprints, want %d", len(d.fingerprints), maxStoredFingerprints)
+	}
+
+	// The fingerprints of the first results are kept.
+	again := diffResults(results[:maxStoredFingerprints], d.fingerprints, true)
+	if again.addedCount != 0 || again.removedCount != 0 {
+		t.Errorf("got %d added, %d removed, want none", again.addedCount, again.removedCount)
+	}
+}
+
+func TestIsCommitSearch(t *testing.T) {
+	for query, want := range map[string]bool{
+		"type:diff password":           true,
+		"TYPE:commit author:alice":     true,
+		"type:diff type:commit foo":    true,
+		"password":                     false,
+		"type:diff type:file password": false,
+		`"type:diff" password`:         false,
+		"file:type:diff.go password":   false,
+	} {
+		if got := isCommitSearch(query); got != want {
+			t.Errorf("isCommitSearch(%q) = %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestWithResultLimit(t *testing.T) {
+	for query, want := range map[string]string{
+		"password patternType:literal":          "password patternType:literal count:1000",
+		"password count:50 patternType:literal": "password count:50 patternType:literal",
+		"Count:all password":                    "Count:all password",
+		"discount:x":                            "discount:x count:1000",
+	} {
+		if got := withResultLimit(query); got != want {
+			t.Errorf("withResultLimit(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
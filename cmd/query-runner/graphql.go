@@ -113,6 +113,7 @@ type gqlSearchResponse struct {
 		Search struct {
 			Results struct {
 				ApproximateResultCount string
+				LimitHit               bool
 				Cloning                []*api.Repo
 				Timedout               []*api.Repo
 				Results                []interface{}
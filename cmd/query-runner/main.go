@@ -23,6 +23,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/debugserver"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/eventlogger"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
 )
 
@@ -51,6 +52,12 @@ func main() {
 		log15.Error("failed to wait for frontend", "error", err)
 	}
 
+	var err error
+	webhookDoer, err = httpcli.NewExternalHTTPClientFactory().Doer()
+	if err != nil {
+		log.Fatalf("Failed to create the webhook HTTP client: %s", err)
+	}
+
 	http.HandleFunc(queryrunnerapi.PathTestNotification, serveTestNotification)
 
 	go func() {
@@ -138,16 +145,10 @@ func (e *executorT) run(ctx context.Context) error {
 // runQuery runs the given query if an appropriate amount of time has elapsed
 // since it last ran.
 func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) error {
-	if !query.Notify && !query.NotifySlack {
+	if !query.Notify && !query.NotifySlack && !query.NotifyWebhook {
 		// No need to run this query because there will be nobody to notify.
 		return nil
 	}
-	if !strings.Contains(query.Query, "type:diff") && !strings.Contains(query.Query, "type:commit") {
-		// TODO(slimsag): we temporarily do not support non-commit search
-		// queries, since those do not support the after:"time" operator.
-		return nil
-	}
-
 	info, err := api.InternalClient.SavedQueriesGetInfo(ctx, query.Query)
 	if err != nil {
 		return errors.Wrap(err, "SavedQueriesGetInfo")
@@ -175,21 +176,29 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		}
 	}
 
-	// Construct a new query which finds search results introduced after the
-	// last time we queried.
-	var latestKnownResult time.Time
-	if info != nil {
-		latestKnownResult = info.LatestResult
+	commitSearch := isCommitSearch(query.Query)
+	var newQuery string
+	if commitSearch {
+		// Construct a new query which finds search results introduced after the
+		// last time we queried.
+		var latestKnownResult time.Time
+		if info != nil {
+			latestKnownResult = info.LatestResult
+		} else {
+			// We've never executed this search query before, so use the current
+			// time. We'll most certainly find nothing, which is okay.
+			latestKnownResult = time.Now()
+		}
+		afterTime := latestKnownResult.UTC().Format(time.RFC3339)
+		newQuery = strings.Join([]string{query.Query, fmt.Sprintf(`after:"%s"`, afterTime)}, " ")
+		if debugPretendSavedQueryResultsExist {
+			debugPretendSavedQueryResultsExist = false
+			newQuery = query.Query
+		}
 	} else {
-		// We've never executed this search query before, so use the current
-		// time. We'll most certainly find nothing, which is okay.
-		latestKnownResult = time.Now()
-	}
-	afterTime := latestKnownResult.UTC().Format(time.RFC3339)
-	newQuery := strings.Join([]string{query.Query, fmt.Sprintf(`after:"%s"`, afterTime)}, " ")
-	if debugPretendSavedQueryResultsExist {
-		debugPretendSavedQueryResultsExist = false
-		newQuery = query.Query
+		// Code searches don't support after:, so we run the whole query and
+		// compare its matches with the previous run.
+		newQuery = withResultLimit(query.Query)
 	}
 
 	// Perform the search and mark the saved query as having been executed in
@@ -198,12 +207,29 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 	// constantly and potentially causing harm to the system. We'll retry at
 	// our normal interval, regardless of errors.
 	v, execDuration, searchErr := performSearch(ctx, newQuery)
-	if err := api.InternalClient.SavedQueriesSetInfo(ctx, &api.SavedQueryInfo{
+	newInfo := &api.SavedQueryInfo{
 		Query:        query.Query,
 		LastExecuted: time.Now(),
-		LatestResult: latestResultTime(info, v, searchErr),
 		ExecDuration: execDuration,
-	}); err != nil {
+	}
+	var diff *resultsDiff
+	if commitSearch {
+		newInfo.LatestResult = latestResultTime(info, v, searchErr)
+	} else {
+		newInfo.LatestResult = time.Now()
+		var prev []string
+		if info != nil {
+			prev = info.ResultFingerprints
+		}
+		newInfo.ResultFingerprints = prev
+		if searchErr == nil {
+			r := v.Data.Search.Results
+			complete := !r.LimitHit && len(r.Cloning) == 0 && len(r.Timedout) == 0
+			diff = diffResults(r.Results, prev, complete)
+			newInfo.ResultFingerprints = diff.fingerprints
+		}
+	}
+	if err := api.InternalClient.SavedQueriesSetInfo(ctx, newInfo); err != nil {
 		return errors.Wrap(err, "SavedQueriesSetInfo")
 	}
 
@@ -211,11 +237,29 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 		return searchErr
 	}
 
+	n := &notifier{
+		spec:     spec,
+		query:    query,
+		newQuery: newQuery,
+		results:  v,
+	}
+	if diff != nil {
+		if info == nil || info.ResultFingerprints == nil {
+			// This is the first run, so all matches are known now, but none
+			// of them is new.
+			return nil
+		}
+		log15.Debug("executor: compared results with the previous run", "query", query.Query, "added", diff.addedCount, "removed", diff.removedCount)
+		n.results = diff.response(v)
+		n.removedCount = diff.removedCount
+		n.matches = matchSummaries(diff.added, maxNotifiedMatches)
+	}
+
 	// Send notifications for new search results in a separate goroutine, so
 	// that we don't block other search queries from running in sequence (which
 	// is done intentionally, to ensure no overloading of searcher/gitserver).
 	go func() {
-		if err := notify(context.Background(), spec, query, newQuery, v); err != nil {
+		if err := notify(context.Background(), n); err != nil {
 			log15.Error("executor: failed to send notifications", "error", err)
 		}
 	}()
@@ -276,30 +320,23 @@ func latestResultTime(prevInfo *api.SavedQueryInfo, v *gqlSearchResponse, search
 var externalURL *url.URL
 
 // notify handles sending notifications for new search results.
-func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, results *gqlSearchResponse) error {
-	if len(results.Data.Search.Results.Results) == 0 {
+func notify(ctx context.Context, n *notifier) error {
+	if len(n.results.Data.Search.Results.Results) == 0 {
 		return nil
 	}
-	log15.Info("sending notifications", "new_results", len(results.Data.Search.Results.Results), "description", query.Description)
+	log15.Info("sending notifications", "new_results", len(n.results.Data.Search.Results.Results), "description", n.query.Description)
 
 	// Determine which users to notify.
-	recipients, err := getNotificationRecipients(ctx, spec, query)
+	recipients, err := getNotificationRecipients(ctx, n.spec, n.query)
 	if err != nil {
 		return err
 	}
+	n.recipients = recipients
 
-	// Send slack notifications.
-	n := &notifier{
-		spec:       spec,
-		query:      query,
-		newQuery:   newQuery,
-		results:    results,
-		recipients: recipients,
-	}
-
-	// Send Slack and email notifications.
+	// Send Slack, email and webhook notifications.
 	n.slackNotify(ctx)
 	n.emailNotify(ctx)
+	n.webhookNotify(ctx)
 	return nil
 }
 
@@ -309,11 +346,18 @@ type notifier struct {
 	newQuery   string
 	results    *gqlSearchResponse
 	recipients recipients
+
+	// For code searches, which are compared with their previous run,
+	// removedCount is the number of matches which are gone, and matches
+	// describe the first new matches.
+	removedCount int
+	matches      []string
 }
 
 const (
-	utmSourceEmail = "saved-search-email"
-	utmSourceSlack = "saved-search-slack"
+	utmSourceEmail   = "saved-search-email"
+	utmSourceSlack   = "saved-search-slack"
+	utmSourceWebhook = "saved-search-webhook"
 )
 
 func searchURL(query, utmSource string) string {
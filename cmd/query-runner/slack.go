@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 
@@ -22,6 +23,9 @@ func (n *notifier) slackNotify(ctx context.Context) {
 		searchURL(n.newQuery, utmSourceSlack),
 		n.query.Description,
 	)
+	if len(n.matches) > 0 {
+		text += "\n```\n" + strings.Join(n.matches, "\n") + "\n```"
+	}
 	for _, recipient := range n.recipients {
 		if err := slackNotify(ctx, recipient, text, n.query.SlackWebhookURL); err != nil {
 			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text, "error", err)
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+const (
+	// webhookMaxResults is the maximum number of search results included in
+	// a webhook payload.
+	webhookMaxResults = 10
+
+	// webhookTimeout bounds the time spent delivering a webhook notification,
+	// including retries.
+	webhookTimeout = time.Minute
+)
+
+// webhookDoer sends the webhook notifications. It retries failed deliveries,
+// which receivers can deduplicate by their X-Sourcegraph-Delivery header.
+var webhookDoer httpcli.Doer
+
+// webhookPayload is the JSON body POSTed to the webhook URL of a saved search.
+type webhookPayload struct {
+	// Event is "results" when the saved search has new results, or "test"
+	// for test notifications.
+	Event       string             `json:"event"`
+	SavedSearch webhookSavedSearch `json:"savedSearch"`
+
+	// Query is the query which found the new results. For commit and diff
+	// searches, it is the saved search query restricted to results after the
+	// previous run.
+	Query          string `json:"query,omitempty"`
+	NewResultCount string `json:"newResultCount,omitempty"`
+	SearchURL      string `json:"searchURL"`
+
+	// RemovedResultCount is the number of matches of a code search which
+	// are gone since the previous run.
+	RemovedResultCount int `json:"removedResultCount,omitempty"`
+
+	// Results are the first webhookMaxResults new results, in the shape of
+	// the GraphQL API search results. For code searches, file matches only
+	// contain their new line matches.
+	Results []interface{} `json:"results,omitempty"`
+}
+
+type webhookSavedSearch struct {
+	Description string `json:"description"`
+	Query       string `json:"query"`
+}
+
+func (n *notifier) webhookNotify(ctx context.Context) {
+	if !n.query.NotifyWebhook {
+		return
+	}
+
+	results := n.results.Data.Search.Results.Results
+	if len(results) > webhookMaxResults {
+		results = results[:webhookMaxResults]
+	}
+	payload := &webhookPayload{
+		Event: "results",
+		SavedSearch: webhookSavedSearch{
+			Description: n.query.Description,
+			Query:       n.query.Query,
+		},
+		Query:              n.newQuery,
+		NewResultCount:     n.results.Data.Search.Results.ApproximateResultCount,
+		SearchURL:          searchURL(n.newQuery, utmSourceWebhook),
+		RemovedResultCount: n.removedCount,
+		Results:            results,
+	}
+	if err := webhookNotify(ctx, n.query, payload); err != nil {
+		log15.Error("Failed to post webhook notification.", "description", n.query.Description, "error", err)
+		return
+	}
+	logEvent(0, "SavedSearchWebhookNotificationSent", "results")
+}
+
+func webhookNotifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig) error {
+	payload := &webhookPayload{
+		Event: "test",
+		SavedSearch: webhookSavedSearch{
+			Description: query.Config.Description,
+			Query:       query.Config.Query,
+		},
+		SearchURL: searchURL(query.Config.Query, utmSourceWebhook),
+	}
+	if err := webhookNotify(ctx, query.Config, payload); err != nil {
+		return err
+	}
+	logEvent(0, "SavedSearchWebhookNotificationSent", "test")
+	return nil
+}
+
+// webhookNotify POSTs payload to the webhook URL of the saved search. If the
+// saved search has a webhook secret, the payload is signed with it: the
+// X-Sourcegraph-Signature header is "sha256=" followed by the hex-encoded
+// HMAC-SHA256 of the body.
+func webhookNotify(ctx context.Context, query api.ConfigSavedQuery, payload *webhookPayload) error {
+	if query.WebhookURL == nil || *query.WebhookURL == "" {
+		return errors.New("unable to send webhook notification because the saved search has no webhook URL configured")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	req, err := http.NewRequest("POST", *query.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// All attempts of a delivery have the same ID, so that the receiver can
+	// ignore retries of a delivery it already processed.
+	delivery := uuid.New().String()
+	req.Header.Set("X-Sourcegraph-Delivery", delivery)
+	req.Header.Set("Idempotency-Key", delivery)
+	if query.WebhookSecret != nil && *query.WebhookSecret != "" {
+		req.Header.Set("X-Sourcegraph-Signature", "sha256="+webhookSignature(*query.WebhookSecret, body))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	resp, err := webhookDoer.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook responded with HTTP status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
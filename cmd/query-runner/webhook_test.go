@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+func TestWebhookNotify(t *testing.T) {
+	defer func(orig httpcli.Doer) { webhookDoer = orig }(webhookDoer)
+	webhookDoer = httpcli.NewRetryMiddleware(httpcli.RetryPolicy{MaxRetries: 2, MinDelay: time.Millisecond, MaxDelay: time.Millisecond})(http.DefaultClient)
+
+	defer func(orig *url.URL) { externalURL = orig }(externalURL)
+	externalURL, _ = url.Parse("https://sourcegraph.example.com")
+
+	type delivery struct {
+		id, signature string
+		payload       webhookPayload
+	}
+	var deliveries []delivery
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		d := delivery{id: r.Header.Get("X-Sourcegraph-Delivery"), signature: r.Header.Get("X-Sourcegraph-Signature")}
+		if err := json.Unmarshal(body, &d.payload); err != nil {
+			t.Error(err)
+		}
+		if want := "sha256=" + webhookSignature("s3cr3t", body); d.signature != want {
+			t.Errorf("got signature %q, want %q", d.signature, want)
+		}
+		deliveries = append(deliveries, d)
+
+		// Fail the first attempt, so that it is retried.
+		if len(deliveries) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	webhookURL, secret := ts.URL, "s3cr3t"
+	n := &notifier{
+		query: api.ConfigSavedQuery{
+			Description:   "new TODOs",
+			Query:         "TODO type:diff",
+			NotifyWebhook: true,
+			WebhookURL:    &webhookURL,
+			WebhookSecret: &secret,
+		},
+		newQuery: `TODO type:diff after:"2020-04-01T00:00:00Z"`,
+		results:  &gqlSearchResponse{},
+	}
+	n.results.Data.Search.Results.ApproximateResultCount = "30+"
+	for i := 0; i < 30; i++ {
+		n.results.Data.Search.Results.Results = append(n.results.Data.Search.Results.Results, map[string]interface{}{"__typename": "CommitSearchResult"})
+	}
+	n.webhookNotify(context.Background())
+
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d delivery attempts, want 2", len(deliveries))
+	}
+	if deliveries[0].id == "" || deliveries[0].id != deliveries[1].id {
+		t.Errorf("got delivery IDs %q and %q, want the same ID for retries", deliveries[0].id, deliveries[1].id)
+	}
+	p := deliveries[1].payload
+	if p.Event != "results" || p.SavedSearch.Query != n.query.Query || p.Query != n.newQuery || p.NewResultCount != "30+" {
+		t.Errorf("unexpected payload %+v", p)
+	}
+	if len(p.Results) != webhookMaxResults {
+		t.Errorf("got %d results in the payload, want %d", len(p.Results), webhookMaxResults)
+	}
+	if want := searchURL(n.newQuery, utmSourceWebhook); p.SearchURL != want {
+		t.Errorf("got search URL %q, want %q", p.SearchURL, want)
+	}
+}
+
+func TestWebhookNotify_errors(t *testing.T) {
+	defer func(orig httpcli.Doer) { webhookDoer = orig }(webhookDoer)
+	webhookDoer = http.DefaultClient
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Sourcegraph-Signature") != "" {
+			t.Error("unexpected signature without a webhook secret")
+		}
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	if err := webhookNotify(context.Background(), api.ConfigSavedQuery{NotifyWebhook: true}, &webhookPayload{}); err == nil {
+		t.Error("expected an error without a webhook URL")
+	}
+	if err := webhookNotify(context.Background(), api.ConfigSavedQuery{NotifyWebhook: true, WebhookURL: &ts.URL}, &webhookPayload{}); err == nil {
+		t.Error("expected an error for a 404 response")
+	}
+}
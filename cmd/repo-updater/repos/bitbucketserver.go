@@ -293,8 +293,9 @@ func (s BitbucketServerSource) makeRepo(repo *bitbucketserver.Repo, isArchived b
 		Private:     !repo.Public,
 		Sources: map[string]*SourceInfo{
 			urn: {
-				ID:       urn,
-				CloneURL: cloneURL,
+				ID:                    urn,
+				CloneURL:              cloneURL,
+				PartialCloneBlobLimit: s.config.PartialCloneBlobLimit,
 			},
 		},
 		Metadata: repo,
@@ -0,0 +1,297 @@
+package repos
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/time/rate"
+)
+
+// A GiteaSource yields repositories from a single Gitea connection configured
+// in Sourcegraph via the external services configuration.
+type GiteaSource struct {
+	svc             *ExternalService
+	config          *schema.GiteaConnection
+	exclude         excludeFunc
+	excludeArchived bool
+	excludeForks    bool
+	baseURL         *url.URL
+	client          *gitea.Client
+}
+
+// NewGiteaSource returns a new GiteaSource from the given external service.
+func NewGiteaSource(svc *ExternalService, cf *httpcli.Factory) (*GiteaSource, error) {
+	var c schema.GiteaConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, fmt.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newGiteaSource(svc, &c, cf)
+}
+
+func newGiteaSource(svc *ExternalService, c *schema.GiteaConnection, cf *httpcli.Factory) (*GiteaSource, error) {
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, err
+	}
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+
+	if cf == nil {
+		cf = httpcli.NewExternalHTTPClientFactory()
+	}
+
+	var opts []httpcli.Opt
+	if c.Certificate != "" {
+		opts = append(opts, httpcli.NewCertPoolOpt(c.Certificate))
+	}
+
+	cli, err := cf.Doer(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		eb              excludeBuilder
+		excludeArchived bool
+		excludeForks    bool
+	)
+
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		if r.Id != 0 {
+			eb.Exact(strconv.Itoa(r.Id))
+		}
+		eb.Pattern(r.Pattern)
+
+		if r.Archived {
+			excludeArchived = true
+		}
+
+		if r.Forks {
+			excludeForks = true
+		}
+	}
+
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	client := gitea.NewClient(baseURL, c.Token, cli)
+	if c.RateLimit != nil {
+		if c.RateLimit.Enabled {
+			client.RateLimit.SetLimit(rate.Limit(c.RateLimit.RequestsPerHour / 3600))
+		} else {
+			client.RateLimit.SetLimit(rate.Inf)
+		}
+	}
+
+	return &GiteaSource{
+		svc:             svc,
+		config:          c,
+		exclude:         exclude,
+		excludeArchived: excludeArchived,
+		excludeForks:    excludeForks,
+		baseURL:         baseURL,
+		client:          client,
+	}, nil
+}
+
+// ListRepos returns all Gitea repositories accessible to all connections configured
+// in Sourcegraph via the external services configuration.
+func (s GiteaSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	s.listAllRepos(ctx, results)
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s GiteaSource) ExternalServices() ExternalServices {
+	return ExternalServices{s.svc}
+}
+
+func (s GiteaSource) makeRepo(r *gitea.Repo) *Repo {
+	urn := s.svc.URN()
+	return &Repo{
+		Name: string(reposource.GiteaRepoName(
+			s.config.RepositoryPathPattern,
+			s.baseURL.Hostname(),
+			r.FullName,
+		)),
+		URI: string(reposource.GiteaRepoName(
+			"",
+			s.baseURL.Hostname(),
+			r.FullName,
+		)),
+		ExternalRepo: gitea.ExternalRepoSpec(r, *s.baseURL),
+		Description:  r.Description,
+		Fork:         r.Fork,
+		Archived:     r.Archived,
+		Private:      r.Private,
+		Sources: map[string]*SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: s.authenticatedRemoteURL(r),
+			},
+		},
+		Metadata: r,
+	}
+}
+
+// authenticatedRemoteURL returns the repository's Git remote URL with the configured
+// Gitea access token inserted in the URL userinfo.
+func (s *GiteaSource) authenticatedRemoteURL(repo *gitea.Repo) string {
+	if s.config.GitURLType == "ssh" {
+		return repo.SSHURL // SSH authentication must be provided out-of-band
+	}
+	if s.config.Token == "" {
+		return repo.CloneURL
+	}
+	u, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		log15.Warn("Error adding authentication to Gitea repository Git remote URL.", "url", repo.CloneURL, "error", err)
+		return repo.CloneURL
+	}
+	// Gitea accepts access tokens as the password of any user.
+	u.User = url.UserPassword("git", s.config.Token)
+	return u.String()
+}
+
+func (s *GiteaSource) excludes(r *gitea.Repo) bool {
+	if s.exclude(r.FullName) || s.exclude(strconv.FormatInt(r.ID, 10)) {
+		return true
+	}
+
+	if s.excludeArchived && r.Archived {
+		return true
+	}
+
+	if s.excludeForks && r.Fork {
+		return true
+	}
+
+	return false
+}
+
+func (s *GiteaSource) listAllRepos(ctx context.Context, results chan SourceResult) {
+	type batch struct {
+		repos []*gitea.Repo
+		err   error
+	}
+
+	ch := make(chan batch)
+
+	var wg sync.WaitGroup
+
+	// paginate sends all pages returned by list to ch.
+	paginate := func(what string, list func(gitea.Page) ([]*gitea.Repo, error)) {
+		defer wg.Done()
+
+		page := gitea.Page{Page: 1, Limit: gitea.DefaultPageLimit}
+		for {
+			if err := ctx.Err(); err != nil {
+				ch <- batch{err: err}
+				return
+			}
+			repos, err := list(page)
+			if err != nil {
+				ch <- batch{err: errors.Wrapf(err, "gitea.%s: page=%d", what, page.Page)}
+				return
+			}
+			ch <- batch{repos: repos}
+			if !page.HasMore(len(repos)) {
+				return
+			}
+			page = page.Next()
+		}
+	}
+
+	// List all explicitly configured repositories.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for _, nameWithOwner := range s.config.Repos {
+			owner, name, err := splitGiteaNameWithOwner(nameWithOwner)
+			if err != nil {
+				ch <- batch{err: err}
+				continue
+			}
+
+			repo, err := s.client.GetRepo(ctx, owner, name)
+			if err != nil {
+				if isGiteaNotFound(err) {
+					log15.Warn("skipping missing gitea.repos entry:", "name", nameWithOwner, "err", err)
+					continue
+				}
+				ch <- batch{err: errors.Wrapf(err, "gitea.repos: name=%q", nameWithOwner)}
+				continue
+			}
+
+			ch <- batch{repos: []*gitea.Repo{repo}}
+		}
+	}()
+
+	// List all repositories of the configured organizations.
+	for _, org := range s.config.Orgs {
+		wg.Add(1)
+		go func(org string) {
+			paginate(fmt.Sprintf("orgs: org=%q", org), func(page gitea.Page) ([]*gitea.Repo, error) {
+				return s.client.ListOrgRepos(ctx, org, page)
+			})
+		}(org)
+	}
+
+	// The only other valid repositoryQuery is "none", which the JSON schema
+	// enforces.
+	for _, repositoryQuery := range s.config.RepositoryQuery {
+		if repositoryQuery == "affiliated" {
+			wg.Add(1)
+			go paginate("affiliated", func(page gitea.Page) ([]*gitea.Repo, error) {
+				return s.client.ListAffiliatedRepos(ctx, page)
+			})
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	seen := make(map[int64]bool)
+	for b := range ch {
+		if b.err != nil {
+			results <- SourceResult{Source: s, Err: b.err}
+			continue
+		}
+
+		for _, repo := range b.repos {
+			if !seen[repo.ID] && !s.excludes(repo) {
+				results <- SourceResult{Source: s, Repo: s.makeRepo(repo)}
+				seen[repo.ID] = true
+			}
+		}
+	}
+}
+
+func splitGiteaNameWithOwner(nameWithOwner string) (owner, name string, err error) {
+	parts := strings.SplitN(nameWithOwner, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.Errorf("gitea.repos: invalid repository name %q, expected \"owner/name\"", nameWithOwner)
+	}
+	return parts[0], parts[1], nil
+}
+
+func isGiteaNotFound(err error) bool {
+	e, ok := errors.Cause(err).(interface{ NotFound() bool })
+	return ok && e.NotFound()
+}
@@ -289,8 +289,9 @@ func (s GithubSource) makeRepo(r *github.Repository) *Repo {
 		Private:      r.IsPrivate,
 		Sources: map[string]*SourceInfo{
 			urn: {
-				ID:       urn,
-				CloneURL: s.authenticatedRemoteURL(r),
+				ID:                    urn,
+				CloneURL:              s.authenticatedRemoteURL(r),
+				PartialCloneBlobLimit: s.config.PartialCloneBlobLimit,
 			},
 		},
 		Metadata: r,
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -111,6 +112,167 @@ func (s GitLabSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+var _ ChangesetSource = GitLabSource{}
+
+// CreateChangeset creates a GitLab merge request for the given *Changeset.
+// If an open merge request already exists for the same branches, it is
+// loaded instead and the returned bool is true.
+func (s GitLabSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
+	var exists bool
+
+	project, ok := c.Repo.Metadata.(*gitlab.Project)
+	if !ok {
+		return exists, errors.New("Changeset repo is not a GitLab project")
+	}
+
+	source := git.AbbreviateRef(c.HeadRef)
+	target := git.AbbreviateRef(c.BaseRef)
+
+	mr, err := s.client.CreateMergeRequest(ctx, project, gitlab.CreateMergeRequestOpts{
+		SourceBranch: source,
+		TargetBranch: target,
+		Title:        c.Title,
+		Description:  c.Body,
+	})
+	if err != nil {
+		if err != gitlab.ErrMergeRequestAlreadyExists {
+			return exists, err
+		}
+		mr, err = s.client.GetOpenMergeRequestByRefs(ctx, project, source, target)
+		if err != nil {
+			return exists, errors.Wrap(err, "fetching existing MR")
+		}
+		exists = true
+	}
+
+	if err := s.loadMergeRequestData(ctx, project, mr); err != nil {
+		return false, errors.Wrap(err, "loading extra metadata")
+	}
+	if err := c.SetMetadata(mr); err != nil {
+		return false, errors.Wrap(err, "setting changeset metadata")
+	}
+
+	return exists, nil
+}
+
+// CloseChangeset closes the merge request of the given *Changeset on the code
+// host and updates the Metadata column in the *campaigns.Changeset to the
+// newly closed merge request.
+func (s GitLabSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+
+	project, ok := c.Repo.Metadata.(*gitlab.Project)
+	if !ok {
+		return errors.New("Changeset repo is not a GitLab project")
+	}
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{
+		StateEvent: "close",
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.loadMergeRequestData(ctx, project, updated); err != nil {
+		return errors.Wrap(err, "loading extra metadata")
+	}
+
+	return c.SetMetadata(updated)
+}
+
+// LoadChangesets loads the latest state of the given Changesets from the codehost.
+func (s GitLabSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	var notFound []*Changeset
+
+	for i := range cs {
+		project, ok := cs[i].Repo.Metadata.(*gitlab.Project)
+		if !ok {
+			return errors.New("Changeset repo is not a GitLab project")
+		}
+
+		iid, err := strconv.Atoi(cs[i].ExternalID)
+		if err != nil {
+			return errors.Wrap(err, "parsing changeset external id")
+		}
+
+		mr, err := s.client.GetMergeRequest(ctx, project, iid)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				notFound = append(notFound, cs[i])
+				if cs[i].Changeset.Metadata == nil {
+					cs[i].Changeset.Metadata = &gitlab.MergeRequest{IID: iid, ProjectID: project.ID}
+				}
+				continue
+			}
+
+			return err
+		}
+
+		if err := s.loadMergeRequestData(ctx, project, mr); err != nil {
+			return errors.Wrap(err, "loading merge request data")
+		}
+		if err := cs[i].SetMetadata(mr); err != nil {
+			return errors.Wrap(err, "setting changeset metadata")
+		}
+	}
+
+	if len(notFound) > 0 {
+		return ChangesetsNotFoundError{Changesets: notFound}
+	}
+
+	return nil
+}
+
+// UpdateChangeset updates the merge request of the given *Changeset on the
+// code host.
+func (s GitLabSource) UpdateChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+
+	project, ok := c.Repo.Metadata.(*gitlab.Project)
+	if !ok {
+		return errors.New("Changeset repo is not a GitLab project")
+	}
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{
+		Title:        c.Title,
+		Description:  &c.Body,
+		TargetBranch: git.AbbreviateRef(c.BaseRef),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.loadMergeRequestData(ctx, project, updated); err != nil {
+		return errors.Wrap(err, "loading extra metadata")
+	}
+
+	return c.SetMetadata(updated)
+}
+
+// loadMergeRequestData loads the notes and pipelines of the given merge
+// request, which the merge request endpoints don't include.
+func (s GitLabSource) loadMergeRequestData(ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+	notes, err := s.client.GetMergeRequestNotes(ctx, project, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "loading mr notes")
+	}
+	mr.Notes = notes
+
+	pipelines, err := s.client.GetMergeRequestPipelines(ctx, project, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "loading mr pipelines")
+	}
+	mr.Pipelines = pipelines
+
+	return nil
+}
+
 func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 	urn := s.svc.URN()
 	return &Repo{
@@ -133,8 +295,9 @@ func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 		Private:      proj.Visibility == "private",
 		Sources: map[string]*SourceInfo{
 			urn: {
-				ID:       urn,
-				CloneURL: s.authenticatedRemoteURL(proj),
+				ID:                    urn,
+				CloneURL:              s.authenticatedRemoteURL(proj),
+				PartialCloneBlobLimit: s.config.PartialCloneBlobLimit,
 			},
 		},
 		Metadata: proj,
@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 	"github.com/sourcegraph/sourcegraph/internal/testutil"
@@ -212,3 +213,66 @@ func TestGitLabSource_makeRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestGitLabSource_CreateChangeset(t *testing.T) {
+	project := &gitlab.Project{ProjectCommon: gitlab.ProjectCommon{ID: 1}}
+	existing := &gitlab.MergeRequest{
+		IID:          42,
+		ProjectID:    1,
+		Title:        "Existing",
+		State:        gitlab.MergeRequestStateOpened,
+		SourceBranch: "campaign",
+		TargetBranch: "master",
+	}
+
+	gitlab.MockCreateMergeRequest = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, opts gitlab.CreateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+		if opts.SourceBranch != "campaign" || opts.TargetBranch != "master" {
+			t.Errorf("unexpected refs: %q -> %q", opts.SourceBranch, opts.TargetBranch)
+		}
+		return nil, gitlab.ErrMergeRequestAlreadyExists
+	}
+	gitlab.MockGetOpenMergeRequestByRefs = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, source, target string) (*gitlab.MergeRequest, error) {
+		return existing, nil
+	}
+	gitlab.MockGetMergeRequestNotes = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, iid int) ([]*gitlab.Note, error) {
+		return []*gitlab.Note{{ID: 1, Body: "approved this merge request", System: true}}, nil
+	}
+	gitlab.MockGetMergeRequestPipelines = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, iid int) ([]*gitlab.Pipeline, error) {
+		return []*gitlab.Pipeline{{ID: 2, Status: gitlab.PipelineStatusSuccess}}, nil
+	}
+	defer func() {
+		gitlab.MockCreateMergeRequest = nil
+		gitlab.MockGetOpenMergeRequestByRefs = nil
+		gitlab.MockGetMergeRequestNotes = nil
+		gitlab.MockGetMergeRequestPipelines = nil
+	}()
+
+	svc := ExternalService{ID: 1, Kind: "GITLAB"}
+	s, err := newGitLabSource(&svc, &schema.GitLabConnection{Url: "https://gitlab.com"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs := &Changeset{
+		Title:     "Campaign",
+		HeadRef:   "refs/heads/campaign",
+		BaseRef:   "refs/heads/master",
+		Repo:      &Repo{Metadata: project},
+		Changeset: &campaigns.Changeset{},
+	}
+
+	exists, err := s.CreateChangeset(context.Background(), cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists {
+		t.Error("existing merge request was not reported")
+	}
+	if have, want := cs.Changeset.ExternalID, "42"; have != want {
+		t.Errorf("have ExternalID %q, want %q", have, want)
+	}
+	mr := cs.Changeset.Metadata.(*gitlab.MergeRequest)
+	if len(mr.Notes) != 1 || len(mr.Pipelines) != 1 {
+		t.Errorf("notes and pipelines not loaded: %+v", mr)
+	}
+}
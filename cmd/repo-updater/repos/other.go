@@ -135,8 +135,9 @@ func (s OtherSource) otherRepoFromCloneURL(urn string, u *url.URL) (*Repo, error
 		},
 		Sources: map[string]*SourceInfo{
 			urn: {
-				ID:       urn,
-				CloneURL: repoURL,
+				ID:                    urn,
+				CloneURL:              repoURL,
+				PartialCloneBlobLimit: s.conn.PartialCloneBlobLimit,
 			},
 		},
 	}, nil
@@ -188,7 +189,8 @@ func (s OtherSource) srcExpose(ctx context.Context) ([]*Repo, error) {
 			urn: {
 				ID: urn,
 				// TODO we should allow this to be set
-				CloneURL: clonePrefix + strings.TrimPrefix(r.URI, "/") + "/.git",
+				CloneURL:              clonePrefix + strings.TrimPrefix(r.URI, "/") + "/.git",
+				PartialCloneBlobLimit: s.conn.PartialCloneBlobLimit,
 			},
 		}
 
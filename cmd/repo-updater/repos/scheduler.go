@@ -107,6 +107,10 @@ type configuredRepo2 struct {
 	URL  string
 	ID   api.RepoID
 	Name api.RepoName
+
+	// PartialCloneBlobLimit is passed on to gitserver when it clones the
+	// repo. See Repo.PartialCloneBlobLimit.
+	PartialCloneBlobLimit string
 }
 
 // notifyChanBuffer controls the buffer size of notification channels.
@@ -208,7 +212,7 @@ func (s *updateScheduler) runUpdateLoop(ctx context.Context) {
 
 // requestRepoUpdate sends a request to gitserver to request an update.
 var requestRepoUpdate = func(ctx context.Context, repo configuredRepo2, since time.Duration) (*gitserverprotocol.RepoUpdateResponse, error) {
-	return gitserver.DefaultClient.RequestRepoUpdate(ctx, gitserver.Repo{Name: repo.Name, URL: repo.URL}, since)
+	return gitserver.DefaultClient.RequestRepoUpdate(ctx, gitserver.Repo{Name: repo.Name, URL: repo.URL, PartialCloneBlobLimit: repo.PartialCloneBlobLimit}, since)
 }
 
 // configuredLimiter returns a mutable limiter that is
@@ -283,8 +287,9 @@ func (s *updateScheduler) remove(r *Repo) {
 
 func configuredRepo2FromRepo(r *Repo) configuredRepo2 {
 	repo := configuredRepo2{
-		ID:   r.ID,
-		Name: api.RepoName(r.Name),
+		ID:                    r.ID,
+		Name:                  api.RepoName(r.Name),
+		PartialCloneBlobLimit: r.PartialCloneBlobLimit(),
 	}
 
 	if urls := r.CloneURLs(); len(urls) > 0 {
@@ -296,11 +301,12 @@ func configuredRepo2FromRepo(r *Repo) configuredRepo2 {
 
 // UpdateOnce causes a single update of the given repository.
 // It neither adds nor removes the repo from the schedule.
-func (s *updateScheduler) UpdateOnce(id api.RepoID, name api.RepoName, url string) {
+func (s *updateScheduler) UpdateOnce(id api.RepoID, name api.RepoName, url, partialCloneBlobLimit string) {
 	repo := configuredRepo2{
-		ID:   id,
-		Name: name,
-		URL:  url,
+		ID:                    id,
+		Name:                  name,
+		URL:                   url,
+		PartialCloneBlobLimit: partialCloneBlobLimit,
 	}
 	schedManualFetch.Inc()
 	s.updateQueue.enqueue(repo, priorityHigh)
@@ -61,6 +61,8 @@ func NewSource(svc *ExternalService, cf *httpcli.Factory) (Source, error) {
 		return NewBitbucketServerSource(svc, cf)
 	case "bitbucketcloud":
 		return NewBitbucketCloudSource(svc, cf)
+	case "gitea":
+		return NewGiteaSource(svc, cf)
 	case "gitolite":
 		return NewGitoliteSource(svc, cf)
 	case "phabricator":
@@ -19,6 +19,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/awscodecommit"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
@@ -100,6 +101,8 @@ func (e ExternalService) Configuration() (cfg interface{}, _ error) {
 		cfg = &schema.GitHubConnection{}
 	case "gitlab":
 		cfg = &schema.GitLabConnection{}
+	case "gitea":
+		cfg = &schema.GiteaConnection{}
 	case "gitolite":
 		cfg = &schema.GitoliteConnection{}
 	case "phabricator":
@@ -124,6 +127,8 @@ func (e *ExternalService) Exclude(rs ...*Repo) error {
 		return e.excludeBitbucketServerRepos(rs...)
 	case "awscodecommit":
 		return e.excludeAWSCodeCommitRepos(rs...)
+	case "gitea":
+		return e.excludeGiteaRepos(rs...)
 	case "gitolite":
 		return e.excludeGitoliteRepos(rs...)
 	case "other":
@@ -328,6 +333,55 @@ func (e *ExternalService) excludeGitoliteRepos(rs ...*Repo) error {
 	})
 }
 
+// excludeGiteaRepos changes the configuration of a Gitea external service to exclude the
+// given repos from being synced.
+func (e *ExternalService) excludeGiteaRepos(rs ...*Repo) error {
+	if len(rs) == 0 {
+		return nil
+	}
+
+	return e.config("gitea", func(v interface{}) (string, interface{}, error) {
+		c := v.(*schema.GiteaConnection)
+		set := make(map[string]bool, len(c.Exclude)*2)
+		for _, ex := range c.Exclude {
+			if ex.Id != 0 {
+				set[strconv.Itoa(ex.Id)] = true
+			}
+
+			if ex.Name != "" {
+				set[strings.ToLower(ex.Name)] = true
+			}
+		}
+
+		for _, r := range rs {
+			repo, ok := r.Metadata.(*gitea.Repo)
+			if !ok {
+				continue
+			}
+
+			id := strconv.FormatInt(repo.ID, 10)
+			name := repo.FullName
+
+			if !set[name] && !set[id] {
+				c.Exclude = append(c.Exclude, &schema.ExcludedGiteaRepo{
+					Name: name,
+					Id:   int(repo.ID),
+				})
+
+				if id != "" {
+					set[id] = true
+				}
+
+				if name != "" {
+					set[name] = true
+				}
+			}
+		}
+
+		return "exclude", c.Exclude, nil
+	})
+}
+
 // excludeGithubRepos changes the configuration of a Github external service to exclude the
 // given repos from being synced.
 func (e *ExternalService) excludeGithubRepos(rs ...*Repo) error {
@@ -470,6 +524,8 @@ func (e ExternalService) schema() string {
 		return schema.GitHubSchemaJSON
 	case "gitlab":
 		return schema.GitLabSchemaJSON
+	case "gitea":
+		return schema.GiteaSchemaJSON
 	case "gitolite":
 		return schema.GitoliteSchemaJSON
 	case "phabricator":
@@ -574,6 +630,11 @@ type Repo struct {
 type SourceInfo struct {
 	ID       string
 	CloneURL string
+
+	// PartialCloneBlobLimit is the partialCloneBlobLimit of the external
+	// service. If set, gitserver creates a partial clone of the repo without
+	// the blobs larger than this size.
+	PartialCloneBlobLimit string `json:",omitempty"`
 }
 
 // ExternalServiceID returns the ID of the external service this
@@ -603,6 +664,50 @@ func (r *Repo) CloneURLs() []string {
 	return urls
 }
 
+// PartialCloneBlobLimit returns the blob size limit gitserver should use to
+// create a partial clone of this repo, or an empty string if it should be
+// cloned in full. If sources disagree, the smallest limit wins.
+func (r *Repo) PartialCloneBlobLimit() string {
+	var limit string
+	var limitBytes int64
+	for _, src := range r.Sources {
+		if src == nil || src.PartialCloneBlobLimit == "" {
+			continue
+		}
+		n, err := parseBlobLimit(src.PartialCloneBlobLimit)
+		if err != nil {
+			continue
+		}
+		if limit == "" || n < limitBytes {
+			limit, limitBytes = src.PartialCloneBlobLimit, n
+		}
+	}
+	return limit
+}
+
+// parseBlobLimit parses a blob size limit in the format git accepts for
+// --filter=blob:limit=<n>, i.e. a number of bytes with an optional k, m or g
+// suffix.
+func parseBlobLimit(s string) (int64, error) {
+	var unit int64 = 1
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "k":
+		unit = 1 << 10
+	case "m":
+		unit = 1 << 20
+	case "g":
+		unit = 1 << 30
+	}
+	if unit != 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * unit, nil
+}
+
 // ExternalServiceIDs returns the IDs of the external services this
 // repo belongs to.
 func (r *Repo) ExternalServiceIDs() []int64 {
@@ -1040,6 +1145,16 @@ func (r *RateLimiterRegistry) updateRateLimiter(svc *ExternalService) error {
 				limit = rate.Inf
 			}
 		}
+	case *schema.GiteaConnection:
+		// 8/s is the default limit we enforce
+		limit = rate.Limit(8)
+		if c != nil && c.RateLimit != nil {
+			if c.RateLimit.Enabled {
+				limit = rate.Limit(c.RateLimit.RequestsPerHour / 3600)
+			} else {
+				limit = rate.Inf
+			}
+		}
 	default:
 		return fmt.Errorf("internal rate limiting not support for %s", svc.Kind)
 	}
@@ -1,6 +1,7 @@
 package repos
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -460,6 +461,30 @@ func TestPick(t *testing.T) {
 	}
 }
 
+func TestRepo_PartialCloneBlobLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		limits []string
+		want   string
+	}{
+		{name: "no sources"},
+		{name: "full clone", limits: []string{""}, want: ""},
+		{name: "single source", limits: []string{"1m"}, want: "1m"},
+		{name: "smallest wins", limits: []string{"1m", "", "512k", "2g"}, want: "512k"},
+		{name: "bytes", limits: []string{"1k", "1000"}, want: "1000"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Repo{Sources: map[string]*SourceInfo{}}
+			for i, limit := range tc.limits {
+				r.Sources[strconv.Itoa(i)] = &SourceInfo{PartialCloneBlobLimit: limit}
+			}
+			if got := r.PartialCloneBlobLimit(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
 func formatJSON(t testing.TB, s string) string {
 	formatted, err := jsonc.Format(s, nil)
 	if err != nil {
@@ -38,7 +38,7 @@ type Server struct {
 		GetRepo(ctx context.Context, projectWithNamespace string) (*repos.Repo, error)
 	}
 	Scheduler interface {
-		UpdateOnce(id api.RepoID, name api.RepoName, url string)
+		UpdateOnce(id api.RepoID, name api.RepoName, url, partialCloneBlobLimit string)
 		ScheduleInfo(id api.RepoID) *protocol.RepoUpdateSchedulerInfoResult
 	}
 	GitserverClient interface {
@@ -301,7 +301,7 @@ func (s *Server) enqueueRepoUpdate(ctx context.Context, req *protocol.RepoUpdate
 			req.URL = urls[0]
 		}
 	}
-	s.Scheduler.UpdateOnce(repo.ID, req.Repo, req.URL)
+	s.Scheduler.UpdateOnce(repo.ID, req.Repo, req.URL, repo.PartialCloneBlobLimit())
 
 	return &protocol.RepoUpdateResponse{
 		ID:   repo.ID,
@@ -624,7 +624,7 @@ func newRepoInfo(r *repos.Repo) (*protocol.RepoInfo, error) {
 		Fork:         r.Fork,
 		Archived:     r.Archived,
 		Private:      r.Private,
-		VCS:          protocol.VCSInfo{URL: urls[0]},
+		VCS:          protocol.VCSInfo{URL: urls[0], PartialCloneBlobLimit: r.PartialCloneBlobLimit()},
 		ExternalRepo: r.ExternalRepo,
 	}
 
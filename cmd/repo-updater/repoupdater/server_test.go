@@ -1136,7 +1136,7 @@ func (s *fakeRepoSource) GetRepo(context.Context, string) (*repos.Repo, error) {
 
 type fakeScheduler struct{}
 
-func (s *fakeScheduler) UpdateOnce(_ api.RepoID, _ api.RepoName, _ string) {}
+func (s *fakeScheduler) UpdateOnce(_ api.RepoID, _ api.RepoName, _, _ string) {}
 func (s *fakeScheduler) ScheduleInfo(id api.RepoID) *protocol.RepoUpdateSchedulerInfoResult {
 	return &protocol.RepoUpdateSchedulerInfoResult{}
 }
@@ -14,6 +14,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
 	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/search"
@@ -29,6 +31,16 @@ import (
 var cacheDir = env.Get("CACHE_DIR", "/tmp", "directory to store cached archives.")
 var cacheSizeMB = env.Get("SEARCHER_CACHE_SIZE_MB", "100000", "maximum size of the on disk cache in megabytes")
 
+var (
+	sharedCacheDir        = env.Get("SEARCHER_SHARED_CACHE_DIR", "", "directory on a volume shared by all searchers to use as a second-tier archive cache.")
+	sharedCacheS3Bucket   = env.Get("SEARCHER_SHARED_CACHE_S3_BUCKET", "", "S3 bucket shared by all searchers to use as a second-tier archive cache.")
+	sharedCacheS3Prefix   = env.Get("SEARCHER_SHARED_CACHE_S3_PREFIX", "searcher-archives/", "prefix of the archive objects in SEARCHER_SHARED_CACHE_S3_BUCKET.")
+	sharedCacheS3Endpoint = env.Get("SEARCHER_SHARED_CACHE_S3_ENDPOINT", "", "endpoint of an S3 compatible blob store, such as MinIO. Defaults to AWS S3.")
+	sharedCacheS3Region   = env.Get("SEARCHER_SHARED_CACHE_S3_REGION", "us-east-1", "region of SEARCHER_SHARED_CACHE_S3_BUCKET.")
+	sharedCacheS3KeyID    = env.Get("SEARCHER_SHARED_CACHE_S3_ACCESS_KEY_ID", "", "access key ID for SEARCHER_SHARED_CACHE_S3_BUCKET.")
+	sharedCacheS3Secret   = env.Get("SEARCHER_SHARED_CACHE_S3_SECRET_ACCESS_KEY", "", "secret access key for SEARCHER_SHARED_CACHE_S3_BUCKET.")
+)
+
 const port = "3181"
 
 func main() {
@@ -51,8 +63,12 @@ func main() {
 			FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
 				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 			},
+			FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+				return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: paths})
+			},
 			Path:              filepath.Join(cacheDir, "searcher-archives"),
 			MaxCacheSizeBytes: cacheSizeBytes,
+			SharedCache:       newSharedCache(),
 		},
 		Log: log15.Root(),
 	}
@@ -85,6 +101,32 @@ func main() {
 	}
 }
 
+// newSharedCache returns the configured second-tier archive cache, or nil if
+// none is configured.
+func newSharedCache() store.SharedCache {
+	switch {
+	case sharedCacheS3Bucket != "":
+		config := defaults.Config()
+		config.Region = sharedCacheS3Region
+		if sharedCacheS3KeyID != "" {
+			config.Credentials = aws.StaticCredentialsProvider{
+				Value: aws.Credentials{
+					AccessKeyID:     sharedCacheS3KeyID,
+					SecretAccessKey: sharedCacheS3Secret,
+					Source:          "searcher-environment",
+				},
+			}
+		}
+		log15.Info("searcher: using shared archive cache", "bucket", sharedCacheS3Bucket, "endpoint", sharedCacheS3Endpoint)
+		return store.NewS3SharedCache(config, sharedCacheS3Endpoint, sharedCacheS3Bucket, sharedCacheS3Prefix)
+	case sharedCacheDir != "":
+		log15.Info("searcher: using shared archive cache", "dir", sharedCacheDir)
+		return &store.DirSharedCache{Dir: sharedCacheDir}
+	default:
+		return nil
+	}
+}
+
 func shutdownOnSIGINT(s *http.Server) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -42,6 +42,11 @@ type Request struct {
 	// The deadline for the search request.
 	// It is parsed with time.Time.UnmarshalText.
 	Deadline string
+
+	// Stream if true will make searcher respond with newline delimited JSON
+	// StreamEvents, writing each FileMatch as soon as it is found instead of
+	// a single Response once the search has finished.
+	Stream bool
 }
 
 // GitserverRepo returns the repository information necessary to perform gitserver requests.
@@ -105,6 +110,11 @@ type PatternInfo struct {
 
 	// CombyRule is a rule that constrains matching for structural search. It only applies when IsStructuralPat is true.
 	CombyRule string
+
+	// FilePaths, if non-empty, is the list of files to search. Only these
+	// files are fetched from gitserver. It only applies when IsStructuralPat
+	// is true.
+	FilePaths []string
 }
 
 func (p *PatternInfo) String() string {
@@ -166,6 +176,33 @@ type Response struct {
 	DeadlineHit bool
 }
 
+// StreamContentType is the Content-Type of a streaming response. Clients
+// can use it to detect searchers which do not support Request.Stream and
+// respond with a single Response instead.
+const StreamContentType = "application/x-ndjson"
+
+// StreamEvent is a single line of a streaming search response. Exactly one of
+// Match or Done is set. The last event of a successful stream always has Done
+// set.
+type StreamEvent struct {
+	Match *FileMatch  `json:",omitempty"`
+	Done  *StreamDone `json:",omitempty"`
+}
+
+// StreamDone is the trailer of a streaming search response.
+type StreamDone struct {
+	// LimitHit is true if the stream may not include all FileMatches because a match limit was hit.
+	LimitHit bool
+
+	// DeadlineHit is true if the stream may not include all FileMatches because a deadline was hit.
+	DeadlineHit bool
+
+	// Error is set if the search failed after the first FileMatch was
+	// sent. Errors which happen before that are reported with a non-200
+	// status code, like for non-streaming requests.
+	Error string `json:",omitempty"`
+}
+
 // FileMatch is the struct used by vscode to receive search results
 type FileMatch struct {
 	Path        string
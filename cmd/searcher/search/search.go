@@ -99,17 +99,14 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	matches, limitHit, deadlineHit, err := s.search(ctx, &p)
+	if p.Stream {
+		s.serveStream(ctx, w, &p)
+		return
+	}
+
+	matches, limitHit, deadlineHit, err := s.search(ctx, &p, nil)
 	if err != nil {
-		code := http.StatusInternalServerError
-		if isBadRequest(err) || ctx.Err() == context.Canceled {
-			code = http.StatusBadRequest
-		} else if isTemporary(err) {
-			code = http.StatusServiceUnavailable
-		} else {
-			log.Printf("internal error serving %#+v: %s", p, err)
-		}
-		http.Error(w, err.Error(), code)
+		writeSearchError(ctx, w, &p, err)
 		return
 	}
 	if matches == nil {
@@ -130,7 +127,47 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(&resp)
 }
 
-func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []protocol.FileMatch, limitHit, deadlineHit bool, err error) {
+// serveStream handles a search request with p.Stream set. Matches are written
+// as newline delimited protocol.StreamEvents as soon as they are found,
+// followed by a final event with Done set.
+func (s *Service) serveStream(ctx context.Context, w http.ResponseWriter, p *protocol.Request) {
+	sw := newStreamWriter(w)
+	_, limitHit, deadlineHit, err := s.search(ctx, p, sw.sendMatch)
+	if err != nil && !sw.started {
+		// Nothing has been written yet, so we can still report the error
+		// with a status code.
+		writeSearchError(ctx, w, p, err)
+		return
+	}
+
+	done := protocol.StreamDone{
+		LimitHit:    limitHit,
+		DeadlineHit: deadlineHit,
+	}
+	if err != nil {
+		done.Error = err.Error()
+	}
+	// Like in ServeHTTP, the only reasonable error is the client going away.
+	_ = sw.send(protocol.StreamEvent{Done: &done})
+}
+
+// writeSearchError responds to a failed search request with an appropriate
+// status code.
+func writeSearchError(ctx context.Context, w http.ResponseWriter, p *protocol.Request, err error) {
+	code := http.StatusInternalServerError
+	if isBadRequest(err) || ctx.Err() == context.Canceled {
+		code = http.StatusBadRequest
+	} else if isTemporary(err) {
+		code = http.StatusServiceUnavailable
+	} else {
+		log.Printf("internal error serving %#+v: %s", *p, err)
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// search runs the search described by p. If onMatch is non-nil it is called
+// with each match as soon as it is found.
+func (s *Service) search(ctx context.Context, p *protocol.Request, onMatch func(protocol.FileMatch)) (matches []protocol.FileMatch, limitHit, deadlineHit bool, err error) {
 	tr := nettrace.New("search", fmt.Sprintf("%s@%s", p.Repo, p.Commit))
 	tr.LazyPrintf("%s", p.Pattern)
 
@@ -151,6 +188,8 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	span.SetTag("patternMatchesContent", p.PatternMatchesContent)
 	span.SetTag("patternMatchesPath", p.PatternMatchesPath)
 	span.SetTag("deadline", p.Deadline)
+	span.SetTag("stream", strconv.FormatBool(p.Stream))
+	span.SetTag("filePaths", len(p.FilePaths))
 	defer func(start time.Time) {
 		code := "200"
 		// We often have canceled and timed out requests. We do not want to
@@ -204,7 +243,14 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	defer cancel()
 
 	getZf := func() (string, *store.ZipFile, error) {
-		path, err := s.Store.PrepareZip(prepareCtx, p.GitserverRepo(), p.Commit)
+		var path string
+		var err error
+		if p.IsStructuralPat && len(p.FilePaths) > 0 {
+			// Only fetch the files we will run comby on.
+			path, err = s.Store.PrepareZipPaths(prepareCtx, p.GitserverRepo(), p.Commit, p.FilePaths)
+		} else {
+			path, err = s.Store.PrepareZip(prepareCtx, p.GitserverRepo(), p.Commit)
+		}
 		if err != nil {
 			return "", nil, err
 		}
@@ -228,9 +274,19 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	archiveSize.Observe(float64(bytes))
 
 	if p.IsStructuralPat {
+		// If FilePaths is set, the archive only contains those files. They
+		// are not passed on to comby, which would interpret them as
+		// patterns.
 		matches, limitHit, err = structuralSearch(ctx, zipPath, p.Pattern, p.CombyRule, p.Languages, p.IncludePatterns, p.Repo)
+		// comby reports all matches at once, so there is nothing to stream
+		// before it finishes.
+		if err == nil && onMatch != nil {
+			for _, fm := range matches {
+				onMatch(fm)
+			}
+		}
 	} else {
-		matches, limitHit, err = regexSearch(ctx, rg, zf, p.FileMatchLimit, p.PatternMatchesContent, p.PatternMatchesPath)
+		matches, limitHit, err = regexSearch(ctx, rg, zf, p.FileMatchLimit, p.PatternMatchesContent, p.PatternMatchesPath, onMatch)
 	}
 	return matches, limitHit, false, err
 }
@@ -295,7 +295,10 @@ func (rg *readerGrep) FindZip(zf *store.ZipFile, f *store.SrcFile) (protocol.Fil
 }
 
 // regexSearch concurrently searches files in zr looking for matches using rg.
-func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMatchLimit int, patternMatchesContent, patternMatchesPaths bool) (fm []protocol.FileMatch, limitHit bool, err error) {
+//
+// If onMatch is non-nil it is called with every match as soon as it is
+// found. Calls to onMatch are serialized.
+func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMatchLimit int, patternMatchesContent, patternMatchesPaths bool, onMatch func(protocol.FileMatch)) (fm []protocol.FileMatch, limitHit bool, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "RegexSearch")
 	ext.Component.Set(span, "regex_search")
 	if rg.re != nil {
@@ -343,7 +346,11 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMat
 		for _, f := range files {
 			if rg.matchPath.MatchPath(f.Name) && rg.matchString(f.Name) {
 				if len(matches) < fileMatchLimit {
-					matches = append(matches, protocol.FileMatch{Path: f.Name})
+					fm := protocol.FileMatch{Path: f.Name}
+					matches = append(matches, fm)
+					if onMatch != nil {
+						onMatch(fm)
+					}
 				} else {
 					limitHit = true
 					break
@@ -415,6 +422,9 @@ func regexSearch(ctx context.Context, rg *readerGrep, zf *store.ZipFile, fileMat
 					matchesmu.Lock()
 					if len(matches) < fileMatchLimit {
 						matches = append(matches, fm)
+						if onMatch != nil {
+							onMatch(fm)
+						}
 					} else {
 						limitHit = true
 						cancel()
@@ -200,26 +200,29 @@ main.go:7:}
 				PatternInfo:  test.arg,
 				FetchTimeout: "2000ms",
 			}
-			m, err := doSearch(ts.URL, &req)
-			if err != nil {
-				t.Fatalf("%v failed: %s", test.arg, err)
-			}
-			sort.Sort(sortByPath(m))
-			got := toString(m)
-			err = sanityCheckSorted(m)
-			if err != nil {
-				t.Fatalf("%v malformed response: %s\n%s", test.arg, err, got)
-			}
 			// We have an extra newline to make expected readable
 			if len(test.want) > 0 {
 				test.want = test.want[1:]
 			}
-			if got != test.want {
-				d, err := testutil.Diff(test.want, got)
+			for _, stream := range []bool{false, true} {
+				req.Stream = stream
+				m, err := doSearch(ts.URL, &req)
+				if err != nil {
+					t.Fatalf("%v (stream=%v) failed: %s", test.arg, stream, err)
+				}
+				sort.Sort(sortByPath(m))
+				got := toString(m)
+				err = sanityCheckSorted(m)
 				if err != nil {
-					t.Fatal(err)
+					t.Fatalf("%v (stream=%v) malformed response: %s\n%s", test.arg, stream, err, got)
+				}
+				if got != test.want {
+					d, err := testutil.Diff(test.want, got)
+					if err != nil {
+						t.Fatal(err)
+					}
+					t.Fatalf("%s (stream=%v) unexpected response:\n%s", test.arg.String(), stream, d)
 				}
-				t.Fatalf("%s unexpected response:\n%s", test.arg.String(), d)
 			}
 		})
 	}
@@ -378,10 +381,18 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	if p.PatternMatchesPath {
 		form.Set("PatternMatchesPath", "true")
 	}
+	if p.Stream {
+		form.Set("Stream", "true")
+	}
 	resp, err := http.PostForm(u, form)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 200 && p.Stream {
+		return decodeStream(resp.Body)
+	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -399,6 +410,24 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	return r.Matches, err
 }
 
+func decodeStream(r io.Reader) ([]protocol.FileMatch, error) {
+	matches := []protocol.FileMatch{}
+	dec := json.NewDecoder(r)
+	for {
+		var ev protocol.StreamEvent
+		if err := dec.Decode(&ev); err != nil {
+			return nil, err
+		}
+		if ev.Done != nil {
+			if ev.Done.Error != "" {
+				return nil, errors.New(ev.Done.Error)
+			}
+			return matches, nil
+		}
+		matches = append(matches, *ev.Match)
+	}
+}
+
 func newStore(files map[string]string) (*store.Store, func(), error) {
 	buf := new(bytes.Buffer)
 	w := tar.NewWriter(buf)
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+)
+
+// streamWriter writes protocol.StreamEvents as newline delimited JSON to an
+// http.ResponseWriter, flushing after every event so clients can consume
+// matches as soon as they are found.
+//
+// The response header is only written with the first event. Until then
+// callers can still respond with an error status code instead.
+//
+// streamWriter is not safe for concurrent use.
+type streamWriter struct {
+	w   http.ResponseWriter
+	enc *json.Encoder
+
+	// started is true once the response header has been written.
+	started bool
+
+	// err is the first error encountered writing to w. Once set, all
+	// further events are dropped.
+	err error
+}
+
+func newStreamWriter(w http.ResponseWriter) *streamWriter {
+	return &streamWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+// send writes ev to the stream and flushes it.
+func (sw *streamWriter) send(ev protocol.StreamEvent) error {
+	if sw.err != nil {
+		return sw.err
+	}
+	if !sw.started {
+		sw.w.Header().Set("Content-Type", protocol.StreamContentType)
+		sw.w.WriteHeader(http.StatusOK)
+		sw.started = true
+	}
+	if err := sw.enc.Encode(&ev); err != nil {
+		sw.err = err
+		return err
+	}
+	if f, ok := sw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// sendMatch sends fm to the stream. Write errors are ignored since they are
+// only caused by the client going away, in which case the search context is
+// cancelled as well.
+func (sw *streamWriter) sendMatch(fm protocol.FileMatch) {
+	_ = sw.send(protocol.StreamEvent{Match: &fm})
+}
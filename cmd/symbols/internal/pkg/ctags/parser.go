@@ -18,6 +18,7 @@ type Entry struct {
 	Name       string
 	Path       string
 	Line       int
+	EndLine    int // the last line of the definition, or 0 if unknown
 	Kind       string
 	Language   string
 	Parent     string
@@ -32,6 +33,8 @@ const debug = false
 
 var logErrors = os.Getenv("DEPLOY_TYPE") == "dev"
 
+// Parser extracts the symbols defined in a file. Implementations need not be
+// safe for concurrent use.
 type Parser interface {
 	Parse(path string, content []byte) ([]Entry, error)
 	Close()
@@ -225,6 +228,7 @@ func (p *ctagsProcess) Parse(name string, content []byte) (entries []Entry, err
 			Name:        rep.Name,
 			Path:        rep.Path,
 			Line:        rep.Line,
+			EndLine:     rep.End,
 			Kind:        rep.Kind,
 			Language:    rep.Language,
 			Parent:      rep.Scope,
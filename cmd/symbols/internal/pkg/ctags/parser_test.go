@@ -101,6 +101,7 @@ class A implements B extends C {
 
 	for i := range want {
 		got[i].Pattern = ""
+		got[i].EndLine = 0 // depends on the ctags version
 		if !reflect.DeepEqual(got[i], want[i]) {
 			t.Fatalf("got %#v, want %#v", got[i], want[i])
 		}
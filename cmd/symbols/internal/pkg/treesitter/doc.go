@@ -0,0 +1,3 @@
+// Package treesitter extracts symbols using tree-sitter parsers. Unlike ctags,
+// it knows the full range of each definition and its enclosing scope.
+package treesitter
@@ -0,0 +1,188 @@
+package treesitter
+
+import (
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/java"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/python"
+)
+
+// languages are the languages that have a tree-sitter parser. The kinds of
+// the symbols are those used by ctags where possible, so that clients treat
+// them the same.
+var languages = []*language{
+	{name: "Go", extensions: []string{".go"}, grammar: golang.GetLanguage, define: defineGo},
+	{name: "Java", extensions: []string{".java"}, grammar: java.GetLanguage, define: defineJava},
+	{name: "JavaScript", extensions: []string{".js", ".jsx", ".mjs", ".cjs"}, grammar: javascript.GetLanguage, define: defineJavaScript},
+	{name: "Python", extensions: []string{".py"}, grammar: python.GetLanguage, define: definePython},
+}
+
+func defineGo(n *sitter.Node, src []byte, enclosing *scope) *definition {
+	switch n.Type() {
+	case "package_clause":
+		return &definition{names: namedChildrenOfType(n, "package_identifier"), kind: "package", leaf: true}
+
+	case "function_declaration":
+		return &definition{names: childrenByField(n, "name"), kind: "func", signature: goSignature(n, src), leaf: true}
+
+	case "method_declaration":
+		def := &definition{names: childrenByField(n, "name"), kind: "method", signature: goSignature(n, src), leaf: true}
+		if receiver := firstDescendantOfType(n.ChildByFieldName("receiver"), "type_identifier"); receiver != nil {
+			def.parent = &scope{name: receiver.Content(src)}
+		}
+		return def
+
+	case "type_spec", "type_alias":
+		kind := "type"
+		switch typ := n.ChildByFieldName("type"); {
+		case typ == nil:
+		case typ.Type() == "struct_type":
+			kind = "struct"
+		case typ.Type() == "interface_type":
+			kind = "interface"
+		}
+		return &definition{names: childrenByField(n, "name"), kind: kind, scope: true}
+
+	case "field_declaration":
+		if names := childrenByField(n, "name"); len(names) > 0 {
+			return &definition{names: names, kind: "field", scope: true}
+		}
+		// Embedded fields don't define symbols.
+		return &definition{leaf: true}
+
+	case "method_elem":
+		return &definition{names: childrenByField(n, "name"), kind: "method", signature: goSignature(n, src), leaf: true}
+
+	case "const_spec":
+		return &definition{names: childrenByField(n, "name"), kind: "constant", leaf: true}
+
+	case "var_spec":
+		return &definition{names: childrenByField(n, "name"), kind: "variable", leaf: true}
+	}
+	return nil
+}
+
+func goSignature(n *sitter.Node, src []byte) string {
+	signature := content(n.ChildByFieldName("parameters"), src)
+	if result := n.ChildByFieldName("result"); result != nil {
+		signature += " " + result.Content(src)
+	}
+	return signature
+}
+
+func defineJava(n *sitter.Node, src []byte, enclosing *scope) *definition {
+	switch n.Type() {
+	case "package_declaration":
+		return &definition{names: namedChildrenOfType(n, "scoped_identifier", "identifier"), kind: "package", leaf: true}
+
+	case "class_declaration", "interface_declaration", "enum_declaration":
+		kind := map[string]string{
+			"class_declaration":     "class",
+			"interface_declaration": "interface",
+			"enum_declaration":      "enum",
+		}[n.Type()]
+		return &definition{names: childrenByField(n, "name"), kind: kind, scope: true}
+
+	case "method_declaration", "constructor_declaration":
+		kind := "method"
+		if n.Type() == "constructor_declaration" {
+			kind = "constructor"
+		}
+		return &definition{names: childrenByField(n, "name"), kind: kind, signature: content(n.ChildByFieldName("parameters"), src), leaf: true}
+
+	case "field_declaration", "constant_declaration":
+		var names []*sitter.Node
+		for _, declarator := range childrenByField(n, "declarator") {
+			names = append(names, childrenByField(declarator, "name")...)
+		}
+		return &definition{names: names, kind: "field", leaf: true}
+
+	case "enum_constant":
+		return &definition{names: childrenByField(n, "name"), kind: "enumConstant", leaf: true}
+	}
+	return nil
+}
+
+func defineJavaScript(n *sitter.Node, src []byte, enclosing *scope) *definition {
+	switch n.Type() {
+	case "class_declaration", "class":
+		return &definition{names: childrenByField(n, "name"), kind: "class", scope: true}
+
+	case "function_declaration", "generator_function_declaration":
+		return &definition{names: childrenByField(n, "name"), kind: "function", signature: content(n.ChildByFieldName("parameters"), src), leaf: true}
+
+	case "method_definition":
+		names := childrenByField(n, "name")
+		kind := "method"
+		if len(names) == 1 && names[0].Content(src) == "constructor" {
+			kind = "constructor"
+		}
+		return &definition{names: names, kind: kind, signature: content(n.ChildByFieldName("parameters"), src), leaf: true}
+
+	case "field_definition":
+		return &definition{names: childrenByField(n, "property"), kind: "field", leaf: true}
+
+	case "variable_declarator":
+		name := n.ChildByFieldName("name")
+		if name == nil || name.Type() != "identifier" {
+			// Destructuring patterns don't define named symbols.
+			return &definition{leaf: true}
+		}
+		def := &definition{names: []*sitter.Node{name}, kind: "variable", leaf: true}
+		if declaration := n.Parent(); declaration != nil && declaration.Child(0).Type() == "const" {
+			def.kind = "constant"
+		}
+		switch value := n.ChildByFieldName("value"); {
+		case value == nil:
+		case value.Type() == "arrow_function", value.Type() == "function", value.Type() == "function_expression", value.Type() == "generator_function":
+			def.kind = "function"
+			def.signature = content(value.ChildByFieldName("parameters"), src)
+			if def.signature == "" {
+				// An arrow function with a single parameter has no parentheses.
+				def.signature = content(value.ChildByFieldName("parameter"), src)
+			}
+		case value.Type() == "class":
+			def.kind = "class"
+		}
+		return def
+
+	case "arrow_function", "function", "function_expression", "generator_function", "statement_block":
+		// Definitions in function bodies and blocks are local.
+		return &definition{leaf: true}
+	}
+	return nil
+}
+
+func definePython(n *sitter.Node, src []byte, enclosing *scope) *definition {
+	switch n.Type() {
+	case "class_definition":
+		return &definition{names: childrenByField(n, "name"), kind: "class", scope: true}
+
+	case "function_definition":
+		kind := "function"
+		if enclosing != nil && enclosing.kind == "class" {
+			kind = "method"
+		}
+		return &definition{names: childrenByField(n, "name"), kind: kind, signature: content(n.ChildByFieldName("parameters"), src), leaf: true}
+
+	case "assignment":
+		kind := "variable"
+		if enclosing != nil && enclosing.kind == "class" {
+			kind = "field"
+		}
+		var names []*sitter.Node
+		switch left := n.ChildByFieldName("left"); {
+		case left == nil:
+		case left.Type() == "identifier":
+			names = []*sitter.Node{left}
+		case left.Type() == "pattern_list", left.Type() == "tuple_pattern":
+			names = namedChildrenOfType(left, "identifier")
+		}
+		return &definition{names: names, kind: kind, leaf: true}
+
+	case "lambda":
+		return &definition{leaf: true}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package treesitter
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
+)
+
+// NewParser returns a parser that parses files of the given languages (e.g.
+// "Go") with tree-sitter, and all other files with fallback. The language
+// names are case insensitive. Closing the parser closes fallback.
+func NewParser(languageNames []string, fallback ctags.Parser) (ctags.Parser, error) {
+	p := &parser{
+		byExtension: map[string]*language{},
+		parsers:     map[*language]*sitter.Parser{},
+		fallback:    fallback,
+	}
+	for _, name := range languageNames {
+		lang := languageByName(strings.TrimSpace(name))
+		if lang == nil {
+			return nil, errors.Errorf("no tree-sitter parser for language %q (supported languages: %s)", name, strings.Join(Languages(), ", "))
+		}
+		for _, ext := range lang.extensions {
+			p.byExtension[ext] = lang
+		}
+	}
+	return p, nil
+}
+
+// Languages returns the names of the languages that have a tree-sitter parser.
+func Languages() []string {
+	names := make([]string, 0, len(languages))
+	for _, lang := range languages {
+		names = append(names, lang.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func languageByName(name string) *language {
+	for _, lang := range languages {
+		if strings.EqualFold(lang.name, name) {
+			return lang
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	byExtension map[string]*language
+	parsers     map[*language]*sitter.Parser // created on first use
+	fallback    ctags.Parser
+}
+
+func (p *parser) Parse(path string, content []byte) ([]ctags.Entry, error) {
+	lang, ok := p.byExtension[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return p.fallback.Parse(path, content)
+	}
+
+	sp, ok := p.parsers[lang]
+	if !ok {
+		sp = sitter.NewParser()
+		sp.SetLanguage(lang.grammar())
+		p.parsers[lang] = sp
+	}
+	tree, err := sp.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", path)
+	}
+	defer tree.Close()
+
+	t := tagger{lang: lang, path: path, src: content, lines: strings.Split(string(content), "\n")}
+	t.walk(tree.RootNode(), nil)
+	t.resolveParentKinds()
+	return t.entries, nil
+}
+
+func (p *parser) Close() {
+	for _, sp := range p.parsers {
+		sp.Close()
+	}
+	p.fallback.Close()
+}
+
+// language describes how to find the definitions in the syntax tree of a
+// language.
+type language struct {
+	name       string // as reported by ctags
+	extensions []string
+	grammar    func() *sitter.Language
+
+	// define returns the definition at n, or nil if n is not a definition.
+	// enclosing is the scope of the innermost enclosing definition, or nil at
+	// the top level.
+	define func(n *sitter.Node, src []byte, enclosing *scope) *definition
+}
+
+// definition describes the symbols defined by a node.
+type definition struct {
+	names     []*sitter.Node // a node can define several symbols (e.g. "var a, b int")
+	kind      string
+	signature string
+
+	// parent is the enclosing scope of the symbols if it is not the
+	// innermost enclosing definition (e.g. the receiver type of a Go method).
+	// If its kind is empty, it is resolved from the symbols in the file.
+	parent *scope
+
+	// scope is whether definitions nested in the node are in the scope of
+	// the (last) defined symbol.
+	scope bool
+
+	// leaf is whether nested nodes are skipped (e.g. function bodies, whose
+	// local definitions are not symbols).
+	leaf bool
+}
+
+// scope is the scope of a definition. Its name is qualified by the names of
+// its enclosing scopes (e.g. "Outer.Inner").
+type scope struct {
+	name string
+	kind string
+}
+
+type tagger struct {
+	lang    *language
+	path    string
+	src     []byte
+	lines   []string
+	entries []ctags.Entry
+}
+
+func (t *tagger) walk(n *sitter.Node, enclosing *scope) {
+	def := t.lang.define(n, t.src, enclosing)
+	if def == nil {
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			t.walk(n.NamedChild(i), enclosing)
+		}
+		return
+	}
+
+	parent := enclosing
+	if def.parent != nil {
+		parent = def.parent
+	}
+	inner := enclosing
+	for _, name := range def.names {
+		e := ctags.Entry{
+			Name:      name.Content(t.src),
+			Path:      t.path,
+			Line:      int(name.StartPoint().Row) + 1,
+			EndLine:   int(n.EndPoint().Row) + 1,
+			Kind:      def.kind,
+			Language:  t.lang.name,
+			Signature: def.signature,
+			Pattern:   t.pattern(int(name.StartPoint().Row)),
+		}
+		qualified := e.Name
+		if parent != nil {
+			e.Parent = parent.name
+			e.ParentKind = parent.kind
+			qualified = parent.name + "." + e.Name
+		}
+		t.entries = append(t.entries, e)
+		inner = &scope{name: qualified, kind: def.kind}
+	}
+
+	if def.leaf {
+		return
+	}
+	if !def.scope {
+		inner = enclosing
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		t.walk(n.NamedChild(i), inner)
+	}
+}
+
+// resolveParentKinds sets the unknown kinds of parents to the kinds of the
+// top-level symbols with the same names, falling back to "type".
+func (t *tagger) resolveParentKinds() {
+	kinds := map[string]string{}
+	for _, e := range t.entries {
+		if e.Parent == "" {
+			kinds[e.Name] = e.Kind
+		}
+	}
+	for i, e := range t.entries {
+		if e.Parent != "" && e.ParentKind == "" {
+			if kind, ok := kinds[e.Parent]; ok {
+				t.entries[i].ParentKind = kind
+			} else {
+				t.entries[i].ParentKind = "type"
+			}
+		}
+	}
+}
+
+// pattern returns a ctags search pattern for the given line, which clients
+// use to locate the symbol on the line.
+func (t *tagger) pattern(row int) string {
+	if row >= len(t.lines) {
+		return ""
+	}
+	line := strings.TrimSuffix(t.lines[row], "\r")
+	line = strings.NewReplacer(`\`, `\\`, `/`, `\/`).Replace(line)
+	return "/^" + line + "$/"
+}
+
+// childrenByField returns the children of n with the given field name.
+func childrenByField(n *sitter.Node, field string) []*sitter.Node {
+	var children []*sitter.Node
+	for i := 0; i < int(n.ChildCount()); i++ {
+		if n.FieldNameForChild(i) == field {
+			children = append(children, n.Child(i))
+		}
+	}
+	return children
+}
+
+// namedChildrenOfType returns the named children of n with one of the given
+// types.
+func namedChildrenOfType(n *sitter.Node, types ...string) []*sitter.Node {
+	var children []*sitter.Node
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		child := n.NamedChild(i)
+		for _, typ := range types {
+			if child.Type() == typ {
+				children = append(children, child)
+				break
+			}
+		}
+	}
+	return children
+}
+
+// firstDescendantOfType returns the first node of the given type in the
+// subtree of n (in pre-order), or nil.
+func firstDescendantOfType(n *sitter.Node, typ string) *sitter.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Type() == typ {
+		return n
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if d := firstDescendantOfType(n.NamedChild(i), typ); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// content returns the source of n, or "" if n is nil.
+func content(n *sitter.Node, src []byte) string {
+	if n == nil {
+		return ""
+	}
+	return n.Content(src)
+}
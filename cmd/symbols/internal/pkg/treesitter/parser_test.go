@@ -0,0 +1,123 @@
+package treesitter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
+)
+
+func TestParser(t *testing.T) {
+	fallback := &fakeParser{}
+	p, err := NewParser([]string{"go", "Python"}, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	tests := map[string]struct {
+		src  string
+		want []ctags.Entry
+	}{
+		"a/b.go": {
+			src: `package b
+
+type T struct {
+	A, B int
+	io.Reader
+}
+
+type I interface {
+	M(x int) error
+}
+
+const X = 1
+
+func F(a int) (int, error) {
+	var local int
+	return local, nil
+}
+
+func (t *T) M(x int) error {
+	return nil
+}
+`,
+			want: []ctags.Entry{
+				{Name: "b", Line: 1, EndLine: 1, Kind: "package", Pattern: `/^package b$/`},
+				{Name: "T", Line: 3, EndLine: 6, Kind: "struct", Pattern: `/^type T struct {$/`},
+				{Name: "A", Line: 4, EndLine: 4, Kind: "field", Parent: "T", ParentKind: "struct", Pattern: "/^\tA, B int$/"},
+				{Name: "B", Line: 4, EndLine: 4, Kind: "field", Parent: "T", ParentKind: "struct", Pattern: "/^\tA, B int$/"},
+				{Name: "I", Line: 8, EndLine: 10, Kind: "interface", Pattern: `/^type I interface {$/`},
+				{Name: "M", Line: 9, EndLine: 9, Kind: "method", Parent: "I", ParentKind: "interface", Signature: "(x int) error", Pattern: "/^\tM(x int) error$/"},
+				{Name: "X", Line: 12, EndLine: 12, Kind: "constant", Pattern: `/^const X = 1$/`},
+				{Name: "F", Line: 14, EndLine: 17, Kind: "func", Signature: "(a int) (int, error)", Pattern: `/^func F(a int) (int, error) {$/`},
+				{Name: "M", Line: 19, EndLine: 21, Kind: "method", Parent: "T", ParentKind: "struct", Signature: "(x int) error", Pattern: `/^func (t *T) M(x int) error {$/`},
+			},
+		},
+		"a/c.py": {
+			src: `X = 1
+
+class A:
+    Y = 2
+
+    def m(self, x):
+        z = 3
+        return z
+
+    class B:
+        pass
+
+def f(*args):
+    return 1
+`,
+			want: []ctags.Entry{
+				{Name: "X", Line: 1, EndLine: 1, Kind: "variable", Pattern: `/^X = 1$/`},
+				{Name: "A", Line: 3, EndLine: 11, Kind: "class", Pattern: `/^class A:$/`},
+				{Name: "Y", Line: 4, EndLine: 4, Kind: "field", Parent: "A", ParentKind: "class", Pattern: `/^    Y = 2$/`},
+				{Name: "m", Line: 6, EndLine: 8, Kind: "method", Parent: "A", ParentKind: "class", Signature: "(self, x)", Pattern: `/^    def m(self, x):$/`},
+				{Name: "B", Line: 10, EndLine: 11, Kind: "class", Parent: "A", ParentKind: "class", Pattern: `/^    class B:$/`},
+				{Name: "f", Line: 13, EndLine: 14, Kind: "function", Signature: "(*args)", Pattern: `/^def f(*args):$/`},
+			},
+		},
+	}
+	for path, test := range tests {
+		t.Run(path, func(t *testing.T) {
+			got, err := p.Parse(path, []byte(test.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range test.want {
+				test.want[i].Path = path
+				test.want[i].Language = map[string]string{"a/b.go": "Go", "a/c.py": "Python"}[path]
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	// Other languages are parsed by the fallback parser.
+	if _, err := p.Parse("a/d.java", []byte("class D {}")); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a/d.java"}; !reflect.DeepEqual(fallback.parsed, want) {
+		t.Errorf("got fallback parsed %v, want %v", fallback.parsed, want)
+	}
+}
+
+func TestNewParser_unknownLanguage(t *testing.T) {
+	if _, err := NewParser([]string{"Go", "Cobol"}, &fakeParser{}); err == nil {
+		t.Fatal("got nil error, want an error for an unsupported language")
+	}
+}
+
+type fakeParser struct {
+	parsed []string
+}
+
+func (p *fakeParser) Parse(path string, content []byte) ([]ctags.Entry, error) {
+	p.parsed = append(p.parsed, path)
+	return nil, nil
+}
+
+func (p *fakeParser) Close() {}
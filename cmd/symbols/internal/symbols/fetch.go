@@ -19,7 +19,10 @@ type parseRequest struct {
 	data []byte
 }
 
-func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName, commitID api.CommitID) (<-chan parseRequest, <-chan error, error) {
+// fetchRepositoryArchive fetches the archive of repo@commitID and sends a
+// parseRequest for every file in it that we want to parse. If paths is
+// non-empty, the archive only contains the given paths.
+func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName, commitID api.CommitID, paths []string) (<-chan parseRequest, <-chan error, error) {
 	fetchQueueSize.Inc()
 	s.fetchSem <- 1 // acquire concurrent fetches semaphore
 	fetchQueueSize.Dec()
@@ -59,8 +62,15 @@ func (s *Service) fetchRepositoryArchive(ctx context.Context, repo api.RepoName,
 		span.Finish()
 	}
 
-	r, err := s.FetchTar(ctx, gitserver.Repo{Name: repo}, commitID)
+	var r io.ReadCloser
+	var err error
+	if len(paths) > 0 {
+		r, err = s.FetchTarPaths(ctx, gitserver.Repo{Name: repo}, commitID, paths)
+	} else {
+		r, err = s.FetchTar(ctx, gitserver.Repo{Name: repo}, commitID)
+	}
 	if err != nil {
+		done(err)
 		return nil, nil, err
 	}
 
@@ -0,0 +1,265 @@
+package symbols
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/inconshreveable/log15"
+	"github.com/jmoiron/sqlx"
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+const (
+	// maxAncestorLookback is the number of ancestors of a commit we check for
+	// a cached symbols database.
+	maxAncestorLookback = 50
+
+	// maxIncrementalChangedFiles is the maximum number of changed files for
+	// which we update a copy of an ancestor's symbols database. Above that,
+	// parsing the whole archive is usually just as fast.
+	maxIncrementalChangedFiles = 1000
+
+	// deleteBatchSize is the number of paths deleted per statement. It is kept
+	// below SQLite's default limit of 999 host parameters.
+	deleteBatchSize = 500
+)
+
+// Changes are the paths of the files that changed between two commits.
+type Changes struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// ParseGitDiffNameStatus parses the output of `git diff -z --name-status
+// --no-renames`.
+func ParseGitDiffNameStatus(out []byte) (Changes, error) {
+	var changes Changes
+
+	fields := bytes.Split(bytes.TrimSuffix(out, []byte{0}), []byte{0})
+	if len(fields) == 1 && len(fields[0]) == 0 {
+		return changes, nil
+	}
+	if len(fields)%2 != 0 {
+		return changes, errors.Errorf("unexpected git diff output: odd number of fields (%d)", len(fields))
+	}
+
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], string(fields[i+1])
+		if len(status) == 0 {
+			return changes, errors.Errorf("unexpected git diff output: empty status for %q", path)
+		}
+		switch status[0] {
+		case 'A':
+			changes.Added = append(changes.Added, path)
+		case 'M', 'T':
+			changes.Modified = append(changes.Modified, path)
+		case 'D':
+			changes.Deleted = append(changes.Deleted, path)
+		default:
+			return changes, errors.Errorf("unexpected git diff status %q for %q", status, path)
+		}
+	}
+
+	return changes, nil
+}
+
+// writeSymbolsToNewDB writes the symbols of repo@commitID to the blank
+// database file `dbFile`. If the database of a recent ancestor of commitID is
+// cached, it is copied and only the files that changed between the two commits
+// are parsed again. Otherwise all files are parsed.
+func (s *Service) writeSymbolsToNewDB(ctx context.Context, dbFile string, repo api.RepoName, commitID api.CommitID) error {
+	if s.GitDiff == nil || s.ListAncestors == nil || s.FetchTarPaths == nil {
+		return s.writeAllSymbolsToNewDB(ctx, dbFile, repo, commitID)
+	}
+
+	err := s.writeChangedSymbolsToNewDB(ctx, dbFile, repo, commitID)
+	if err == nil {
+		incrementalUpdates.Inc()
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	if err != errNoAncestorDB {
+		incrementalUpdateFailed.Inc()
+		log15.Warn("Incremental symbols update failed, parsing all files.", "repo", repo, "commitID", commitID, "error", err)
+	}
+
+	// The failed attempt may have left a partial copy behind.
+	if err := os.Truncate(dbFile, 0); err != nil {
+		return err
+	}
+	return s.writeAllSymbolsToNewDB(ctx, dbFile, repo, commitID)
+}
+
+// errNoAncestorDB is returned by writeChangedSymbolsToNewDB when there is no
+// usable cached database of an ancestor commit.
+var errNoAncestorDB = errors.New("no cached symbols database for an ancestor commit")
+
+// writeChangedSymbolsToNewDB copies the cached database of the nearest
+// ancestor of commitID to `dbFile` and updates the symbols of the files that
+// changed since.
+func (s *Service) writeChangedSymbolsToNewDB(ctx context.Context, dbFile string, repo api.RepoName, commitID api.CommitID) (err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "writeChangedSymbolsToNewDB")
+	span.SetTag("repo", string(repo))
+	span.SetTag("commit", string(commitID))
+	defer func() {
+		if err != nil && err != errNoAncestorDB {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	ancestor, ancestorDB, err := s.openAncestorDB(ctx, repo, commitID)
+	if err != nil {
+		return err
+	}
+	defer ancestorDB.Close()
+	span.SetTag("ancestor", string(ancestor))
+
+	changes, err := s.GitDiff(ctx, repo, ancestor, commitID)
+	if err != nil {
+		return errors.Wrap(err, "GitDiff")
+	}
+	if n := len(changes.Added) + len(changes.Modified) + len(changes.Deleted); n > maxIncrementalChangedFiles {
+		span.SetTag("changedFiles", n)
+		return errNoAncestorDB
+	}
+
+	if err := copyToFile(dbFile, ancestorDB); err != nil {
+		return errors.Wrap(err, "copying ancestor database")
+	}
+
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	stale := make([]string, 0, len(changes.Modified)+len(changes.Deleted))
+	stale = append(stale, changes.Modified...)
+	stale = append(stale, changes.Deleted...)
+	if err := deleteSymbolsForPaths(tx, stale); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(changes.Added)+len(changes.Modified))
+	paths = append(paths, changes.Added...)
+	paths = append(paths, changes.Modified...)
+	if len(paths) > 0 {
+		insertStatement, err := prepareInsertSymbol(tx)
+		if err != nil {
+			return err
+		}
+
+		err = s.parseUncached(ctx, repo, commitID, paths, func(symbol protocol.Symbol) error {
+			symbolInDBValue := symbolToSymbolInDB(symbol)
+			_, err := insertStatement.Exec(&symbolInDBValue)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// openAncestorDB opens the cached symbols database of the nearest ancestor of
+// commitID. It returns errNoAncestorDB if none of the ancestors we look at
+// have a cached database.
+func (s *Service) openAncestorDB(ctx context.Context, repo api.RepoName, commitID api.CommitID) (api.CommitID, *os.File, error) {
+	ancestors, err := s.ListAncestors(ctx, repo, commitID, maxAncestorLookback)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "ListAncestors")
+	}
+
+	for _, ancestor := range ancestors {
+		f, err := s.cache.OpenIfExists(symbolsDBKey(repo, ancestor))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		return ancestor, f.File, nil
+	}
+
+	return "", nil, errNoAncestorDB
+}
+
+// deleteSymbolsForPaths deletes all symbols of the files at the given paths.
+func deleteSymbolsForPaths(tx *sqlx.Tx, paths []string) error {
+	for len(paths) > 0 {
+		n := len(paths)
+		if n > deleteBatchSize {
+			n = deleteBatchSize
+		}
+
+		in := make([]*sqlf.Query, 0, n)
+		for _, path := range paths[:n] {
+			in = append(in, sqlf.Sprintf("%s", path))
+		}
+		q := sqlf.Sprintf("DELETE FROM symbols WHERE path IN (%s)", sqlf.Join(in, ","))
+		if _, err := tx.Exec(q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return err
+		}
+
+		paths = paths[n:]
+	}
+	return nil
+}
+
+// copyToFile overwrites the file at path with the contents of r.
+func copyToFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var (
+	incrementalUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "symbols",
+		Subsystem: "store",
+		Name:      "incremental_updates",
+		Help:      "The total number of symbols databases derived from the database of an ancestor commit.",
+	})
+	incrementalUpdateFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "symbols",
+		Subsystem: "store",
+		Name:      "incremental_update_failed",
+		Help:      "The total number of incremental symbols database updates that failed and fell back to parsing all files.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(incrementalUpdates)
+	prometheus.MustRegister(incrementalUpdateFailed)
+}
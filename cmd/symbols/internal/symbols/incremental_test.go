@@ -0,0 +1,131 @@
+package symbols
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+)
+
+func TestParseGitDiffNameStatus(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		out     string
+		want    Changes
+		wantErr bool
+	}{
+		{name: "empty", out: ""},
+		{
+			name: "changes",
+			out:  "A\x00new.go\x00M\x00changed.go\x00T\x00link.go\x00D\x00old.go\x00",
+			want: Changes{
+				Added:    []string{"new.go"},
+				Modified: []string{"changed.go", "link.go"},
+				Deleted:  []string{"old.go"},
+			},
+		},
+		{name: "truncated", out: "A\x00new.go\x00M\x00", wantErr: true},
+		{name: "unknown status", out: "X\x00new.go\x00", wantErr: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseGitDiffNameStatus([]byte(test.out))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, test.wantErr)
+			}
+			if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestServiceIncremental(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	commits := map[api.CommitID]map[string]string{
+		"a": {"a.js": "x", "b.js": "y", "c.js": "z"},
+		"b": {"a.js": "x", "c.js": "w", "d.js": "v"},
+	}
+
+	var fetchedPaths []string
+	service := Service{
+		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+			return createTar(commits[commit])
+		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			fetchedPaths = append(fetchedPaths, paths...)
+			files := map[string]string{}
+			for _, path := range paths {
+				files[path] = commits[commit][path]
+			}
+			return createTar(files)
+		},
+		ListAncestors: func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error) {
+			if commit == "b" {
+				return []api.CommitID{"a"}, nil
+			}
+			return nil, nil
+		},
+		GitDiff: func(ctx context.Context, repo api.RepoName, commitA, commitB api.CommitID) (Changes, error) {
+			return Changes{
+				Added:    []string{"d.js"},
+				Modified: []string{"c.js"},
+				Deleted:  []string{"b.js"},
+			}, nil
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return contentParser{}, nil
+		},
+		Path: tmpDir,
+	}
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(commit api.CommitID) []protocol.Symbol {
+		result, err := service.search(context.Background(), protocol.SearchArgs{Repo: "r", CommitID: commit, First: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(result.Symbols, func(i, j int) bool { return result.Symbols[i].Path < result.Symbols[j].Path })
+		return result.Symbols
+	}
+
+	want := []protocol.Symbol{{Name: "x", Path: "a.js"}, {Name: "y", Path: "b.js"}, {Name: "z", Path: "c.js"}}
+	if got := search("a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if len(fetchedPaths) != 0 {
+		t.Errorf("expected the first commit to be parsed from a full archive, fetched paths %v", fetchedPaths)
+	}
+
+	want = []protocol.Symbol{{Name: "x", Path: "a.js"}, {Name: "w", Path: "c.js"}, {Name: "v", Path: "d.js"}}
+	if got := search("b"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	sort.Strings(fetchedPaths)
+	if want := []string{"c.js", "d.js"}; !reflect.DeepEqual(fetchedPaths, want) {
+		t.Errorf("fetched paths %v, want %v", fetchedPaths, want)
+	}
+}
+
+// contentParser returns a single symbol named after the content of the file.
+type contentParser struct{}
+
+func (contentParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
+	return []ctags.Entry{{Name: string(content), Path: name}}, nil
+}
+
+func (contentParser) Close() {}
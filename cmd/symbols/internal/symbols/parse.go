@@ -37,7 +37,9 @@ func (s *Service) startParsers() error {
 	return nil
 }
 
-func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID api.CommitID, callback func(symbol protocol.Symbol) error) (err error) {
+// parseUncached parses the files of repo@commitID and calls callback for every
+// symbol found. If paths is non-empty, only the given paths are parsed.
+func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID api.CommitID, paths []string, callback func(symbol protocol.Symbol) error) (err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "parseUncached")
 	defer func() {
 		if err != nil {
@@ -50,7 +52,7 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 	span.SetTag("commit", string(commitID))
 
 	tr := nettrace.New("parseUncached", string(repo))
-	tr.LazyPrintf("commitID: %s", commitID)
+	tr.LazyPrintf("commitID: %s paths: %d", commitID, len(paths))
 
 	totalSymbols := 0
 	defer func() {
@@ -63,7 +65,7 @@ func (s *Service) parseUncached(ctx context.Context, repo api.RepoName, commitID
 	}()
 
 	tr.LazyPrintf("fetch")
-	parseRequests, errChan, err := s.fetchRepositoryArchive(ctx, repo, commitID)
+	parseRequests, errChan, err := s.fetchRepositoryArchive(ctx, repo, commitID, paths)
 	tr.LazyPrintf("fetch (returned chans)")
 	if err != nil {
 		return err
@@ -180,6 +182,7 @@ func entryToSymbol(e ctags.Entry) protocol.Symbol {
 		Name:        e.Name,
 		Path:        e.Path,
 		Line:        e.Line,
+		EndLine:     e.EndLine,
 		Kind:        e.Kind,
 		Language:    e.Language,
 		Parent:      e.Parent,
@@ -112,10 +112,10 @@ func (s *Service) search(ctx context.Context, args protocol.SearchArgs) (result
 
 // getDBFile returns the path to the sqlite3 database for the repo@commit
 // specified in `args`. If the database doesn't already exist in the disk cache,
-// it will create a new one and write all the symbols into it.
+// it will create a new one and write the symbols into it.
 func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (string, error) {
-	diskcacheFile, err := s.cache.OpenWithPath(ctx, fmt.Sprintf("%d-%s@%s", symbolsDBVersion, args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
-		err := s.writeAllSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
+	diskcacheFile, err := s.cache.OpenWithPath(ctx, symbolsDBKey(args.Repo, args.CommitID), func(fetcherCtx context.Context, tempDBFile string) error {
+		err := s.writeSymbolsToNewDB(fetcherCtx, tempDBFile, args.Repo, args.CommitID)
 		if err != nil {
 			if err == context.Canceled {
 				log15.Error("Unable to parse repository symbols within the context", "repo", args.Repo, "commit", args.CommitID, "query", args.Query)
@@ -132,6 +132,12 @@ func (s *Service) getDBFile(ctx context.Context, args protocol.SearchArgs) (stri
 	return diskcacheFile.File.Name(), err
 }
 
+// symbolsDBKey returns the disk cache key of the symbols database for
+// repo@commitID.
+func symbolsDBKey(repo api.RepoName, commitID api.CommitID) string {
+	return fmt.Sprintf("%d-%s@%s", symbolsDBVersion, repo, commitID)
+}
+
 // isLiteralEquality checks if the given regex matches literal strings exactly.
 // Returns whether or not the regex is exact, along with the literal string if
 // so.
@@ -207,6 +213,23 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 	}
 	conditions = append(conditions, negateAll(makeCondition("path", args.ExcludePattern))...)
 
+	if len(args.Kinds) > 0 {
+		kinds := make([]*sqlf.Query, 0, len(args.Kinds))
+		for _, kind := range args.Kinds {
+			kinds = append(kinds, sqlf.Sprintf("%s", strings.ToLower(kind)))
+		}
+		conditions = append(conditions, sqlf.Sprintf("LOWER(kind) IN (%s)", sqlf.Join(kinds, ",")))
+	}
+
+	// Unlike name and path, parent has no lowercase column or index, so we
+	// always match it with REGEXP.
+	for _, parentPattern := range args.ParentPatterns {
+		if !args.IsCaseSensitive {
+			parentPattern = "(?i:" + parentPattern + ")"
+		}
+		conditions = append(conditions, sqlf.Sprintf("parent REGEXP %s", parentPattern))
+	}
+
 	var sqlQuery *sqlf.Query
 	if len(conditions) == 0 {
 		sqlQuery = sqlf.Sprintf("SELECT * FROM symbols LIMIT %s", args.First)
@@ -232,7 +255,7 @@ func filterSymbols(ctx context.Context, db *sqlx.DB, args protocol.SearchArgs) (
 // filenames to prevent a newer version of the symbols service from attempting
 // to read from a database created by an older (and likely incompatible) symbols
 // service. Increment this when you change the database schema.
-const symbolsDBVersion = 3
+const symbolsDBVersion = 4
 
 // symbolInDB is the same as `protocol.Symbol`, but with two additional columns:
 // namelowercase and pathlowercase, which enable indexed case insensitive
@@ -243,6 +266,7 @@ type symbolInDB struct {
 	Path          string
 	PathLowercase string // derived from `Path`
 	Line          int
+	EndLine       int
 	Kind          string
 	Language      string
 	Parent        string
@@ -260,6 +284,7 @@ func symbolToSymbolInDB(symbol protocol.Symbol) symbolInDB {
 		Path:          symbol.Path,
 		PathLowercase: strings.ToLower(symbol.Path),
 		Line:          symbol.Line,
+		EndLine:       symbol.EndLine,
 		Kind:          symbol.Kind,
 		Language:      symbol.Language,
 		Parent:        symbol.Parent,
@@ -276,6 +301,7 @@ func symbolInDBToSymbol(symbolInDB symbolInDB) protocol.Symbol {
 		Name:       symbolInDB.Name,
 		Path:       symbolInDB.Path,
 		Line:       symbolInDB.Line,
+		EndLine:    symbolInDB.EndLine,
 		Kind:       symbolInDB.Kind,
 		Language:   symbolInDB.Language,
 		Parent:     symbolInDB.Parent,
@@ -302,16 +328,45 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 		return err
 	}
 
+	if err := createSymbolsTable(tx); err != nil {
+		return err
+	}
+
+	insertStatement, err := prepareInsertSymbol(tx)
+	if err != nil {
+		return err
+	}
+
+	err = s.parseUncached(ctx, repoName, commitID, nil, func(symbol protocol.Symbol) error {
+		symbolInDBValue := symbolToSymbolInDB(symbol)
+		_, err := insertStatement.Exec(&symbolInDBValue)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createSymbolsTable creates the symbols table and its indexes.
+func createSymbolsTable(tx *sqlx.Tx) error {
 	// The column names are the lowercase version of fields in `symbolInDB`
 	// because sqlx lowercases struct fields by default. See
 	// http://jmoiron.github.io/sqlx/#query
-	_, err = tx.Exec(
+	_, err := tx.Exec(
 		`CREATE TABLE IF NOT EXISTS symbols (
 			name VARCHAR(256) NOT NULL,
 			namelowercase VARCHAR(256) NOT NULL,
 			path VARCHAR(4096) NOT NULL,
 			pathlowercase VARCHAR(4096) NOT NULL,
 			line INT NOT NULL,
+			endline INT NOT NULL,
 			kind VARCHAR(255) NOT NULL,
 			language VARCHAR(255) NOT NULL,
 			parent VARCHAR(255) NOT NULL,
@@ -345,28 +400,15 @@ func (s *Service) writeAllSymbolsToNewDB(ctx context.Context, dbFile string, rep
 		return err
 	}
 
-	insertStatement, err := tx.PrepareNamed(
+	return nil
+}
+
+// prepareInsertSymbol prepares the statement to insert a symbolInDB into the
+// symbols table.
+func prepareInsertSymbol(tx *sqlx.Tx) (*sqlx.NamedStmt, error) {
+	return tx.PrepareNamed(
 		fmt.Sprintf(
 			"INSERT INTO symbols %s VALUES %s",
-			"( name,  namelowercase,  path,  pathlowercase,  line,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
-			"(:name, :namelowercase, :path, :pathlowercase, :line, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
-	if err != nil {
-		return err
-	}
-
-	err = s.parseUncached(ctx, repoName, commitID, func(symbol protocol.Symbol) error {
-		symbolInDBValue := symbolToSymbolInDB(symbol)
-		_, err := insertStatement.Exec(&symbolInDBValue)
-		return err
-	})
-	if err != nil {
-		return err
-	}
-
-	err = tx.Commit()
-	if err != nil {
-		return err
-	}
-
-	return nil
+			"( name,  namelowercase,  path,  pathlowercase,  line,  endline,  kind,  language,  parent,  parentkind,  signature,  pattern,  filelimited)",
+			"(:name, :namelowercase, :path, :pathlowercase, :line, :endline, :kind, :language, :parent, :parentkind, :signature, :pattern, :filelimited)"))
 }
@@ -15,7 +15,6 @@ import (
 )
 
 func BenchmarkSearch(b *testing.B) {
-	MustRegisterSqlite3WithPcre()
 	ctagsCommand := ctags.GetCommand()
 
 	log15.Root().SetHandler(log15.LvlFilterHandler(log15.LvlError, log15.Root().GetHandler()))
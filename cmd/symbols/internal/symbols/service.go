@@ -22,10 +22,27 @@ type Service struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(context.Context, gitserver.Repo, api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains the given
+	// paths. It is used to fetch the files that changed since the commit of a
+	// cached symbols database.
+	FetchTarPaths func(context.Context, gitserver.Repo, api.CommitID, []string) (io.ReadCloser, error)
+
 	// MaxConcurrentFetchTar is the maximum number of concurrent calls allowed
-	// to FetchTar. It defaults to 15.
+	// to FetchTar and FetchTarPaths. It defaults to 15.
 	MaxConcurrentFetchTar int
 
+	// ListAncestors returns up to n ancestors of the given commit, nearest
+	// first.
+	ListAncestors func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error)
+
+	// GitDiff returns the paths of the files that changed between commitA and
+	// commitB.
+	//
+	// When GitDiff, ListAncestors and FetchTarPaths are set, the symbols
+	// database for a commit is derived from the database of its nearest cached
+	// ancestor, and only the changed files are parsed again.
+	GitDiff func(ctx context.Context, repo api.RepoName, commitA, commitB api.CommitID) (Changes, error)
+
 	NewParser func() (ctags.Parser, error)
 
 	// NumParserProcesses is the maximum number of ctags parser child processes to run.
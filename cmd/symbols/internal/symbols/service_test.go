@@ -39,6 +39,7 @@ func init() {
 			panic(fmt.Errorf("can't find the libsqlite3-pcre library because LIBSQLITE3_PCRE was not set and %s doesn't exist at the root of the repository - try building it with `./dev/build-libsqlite3pcre.sh`", libSqlite3Pcre))
 		}
 	}
+	MustRegisterSqlite3WithPcre()
 }
 
 func TestIsLiteralEquality(t *testing.T) {
@@ -74,8 +75,6 @@ func TestIsLiteralEquality(t *testing.T) {
 }
 
 func TestService(t *testing.T) {
-	MustRegisterSqlite3WithPcre()
-
 	tmpDir, err := ioutil.TempDir("", "")
 	if err != nil {
 		t.Fatal(err)
@@ -88,7 +87,10 @@ func TestService(t *testing.T) {
 			return createTar(files)
 		},
 		NewParser: func() (ctags.Parser, error) {
-			return mockParser{"x", "y"}, nil
+			return mockParser{
+				{Name: "x", Kind: "function"},
+				{Name: "y", Kind: "variable", Parent: "x"},
+			}, nil
 		},
 		Path: tmpDir,
 	}
@@ -99,8 +101,8 @@ func TestService(t *testing.T) {
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
 	client := symbolsclient.Client{URL: server.URL}
-	x := protocol.Symbol{Name: "x", Path: "a.js"}
-	y := protocol.Symbol{Name: "y", Path: "a.js"}
+	x := protocol.Symbol{Name: "x", Path: "a.js", Kind: "function"}
+	y := protocol.Symbol{Name: "y", Path: "a.js", Kind: "variable", Parent: "x"}
 
 	tests := map[string]struct {
 		args search.SymbolsParameters
@@ -146,6 +148,26 @@ func TestService(t *testing.T) {
 			args: search.SymbolsParameters{ExcludePattern: "a.js", IsCaseSensitive: true, First: 10},
 			want: protocol.SearchResult{},
 		},
+		"kind": {
+			args: search.SymbolsParameters{Kinds: []string{"Function"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{x}},
+		},
+		"multiplekinds": {
+			args: search.SymbolsParameters{Kinds: []string{"function", "variable"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{x, y}},
+		},
+		"nokindmatches": {
+			args: search.SymbolsParameters{Kinds: []string{"struct"}, First: 10},
+			want: protocol.SearchResult{},
+		},
+		"parent": {
+			args: search.SymbolsParameters{ParentPatterns: []string{"^X$"}, First: 10},
+			want: protocol.SearchResult{Symbols: []protocol.Symbol{y}},
+		},
+		"casesensitivenoparentmatch": {
+			args: search.SymbolsParameters{ParentPatterns: []string{"^X$"}, IsCaseSensitive: true, First: 10},
+			want: protocol.SearchResult{},
+		},
 	}
 	for label, test := range tests {
 		t.Run(label, func(t *testing.T) {
@@ -184,12 +206,13 @@ func createTar(files map[string]string) (io.ReadCloser, error) {
 	return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
 }
 
-type mockParser []string
+type mockParser []ctags.Entry
 
 func (m mockParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
 	entries := make([]ctags.Entry, len(m))
-	for i, name := range m {
-		entries[i] = ctags.Entry{Name: name, Path: "a.js"}
+	for i, e := range m {
+		e.Path = "a.js"
+		entries[i] = e
 	}
 	return entries, nil
 }
@@ -13,12 +13,14 @@ import (
 	"os/signal"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/treesitter"
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/symbols"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/debugserver"
@@ -35,6 +37,7 @@ func main() {
 		cacheDir       = env.Get("CACHE_DIR", "/tmp/symbols-cache", "directory to store cached symbols")
 		cacheSizeMB    = env.Get("SYMBOLS_CACHE_SIZE_MB", "100000", "maximum size of the disk cache in megabytes")
 		ctagsProcesses = env.Get("CTAGS_PROCESSES", strconv.Itoa(runtime.GOMAXPROCS(0)), "number of ctags child processes to run")
+		treeSitter     = env.Get("SYMBOLS_TREE_SITTER_LANGUAGES", "", "comma-separated list of languages ("+strings.Join(treesitter.Languages(), ", ")+") whose symbols are parsed with tree-sitter instead of ctags")
 	)
 
 	env.Lock()
@@ -50,11 +53,47 @@ func main() {
 		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
 			return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
 		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: paths})
+		},
+		ListAncestors: func(ctx context.Context, repo api.RepoName, commit api.CommitID, n int) ([]api.CommitID, error) {
+			// The first commit listed is commit itself.
+			cmd := gitserver.DefaultClient.Command("git", "rev-list", "--max-count="+strconv.Itoa(n+1), string(commit))
+			cmd.Repo = gitserver.Repo{Name: repo}
+			out, err := cmd.Output(ctx)
+			if err != nil {
+				return nil, err
+			}
+			var ancestors []api.CommitID
+			for _, line := range strings.Fields(string(out)) {
+				if line != string(commit) {
+					ancestors = append(ancestors, api.CommitID(line))
+				}
+			}
+			return ancestors, nil
+		},
+		GitDiff: func(ctx context.Context, repo api.RepoName, commitA, commitB api.CommitID) (symbols.Changes, error) {
+			cmd := gitserver.DefaultClient.Command("git", "diff", "-z", "--name-status", "--no-renames", string(commitA), string(commitB))
+			cmd.Repo = gitserver.Repo{Name: repo}
+			out, err := cmd.Output(ctx)
+			if err != nil {
+				return symbols.Changes{}, err
+			}
+			return symbols.ParseGitDiffNameStatus(out)
+		},
 		NewParser: func() (ctags.Parser, error) {
 			parser, err := ctags.NewParser(ctags.GetCommand())
 			if err != nil {
 				return nil, errors.Wrap(err, fmt.Sprintf("command: %s", ctags.GetCommand()))
 			}
+			if treeSitter != "" {
+				treeSitterParser, err := treesitter.NewParser(strings.Split(treeSitter, ","), parser)
+				if err != nil {
+					parser.Close()
+					return nil, err
+				}
+				return treeSitterParser, nil
+			}
 			return parser, nil
 		},
 		Path: cacheDir,
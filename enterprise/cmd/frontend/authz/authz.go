@@ -15,6 +15,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/github"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
@@ -136,6 +137,7 @@ type ExternalServicesStore interface {
 	ListGitLabConnections(context.Context) ([]*schema.GitLabConnection, error)
 	ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error)
 	ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error)
+	ListBitbucketCloudConnections(context.Context) ([]*schema.BitbucketCloudConnection, error)
 }
 
 // ProvidersFromConfig returns the set of permission-related providers derived from the site config.
@@ -188,6 +190,15 @@ func ProvidersFromConfig(
 		warnings = append(warnings, bbsWarnings...)
 	}
 
+	if bbcConns, err := s.ListBitbucketCloudConnections(ctx); err != nil {
+		seriousProblems = append(seriousProblems, fmt.Sprintf("Could not load Bitbucket Cloud external service configs: %s", err))
+	} else {
+		bbcProviders, bbcProblems, bbcWarnings := bitbucketcloud.NewAuthzProviders(bbcConns)
+		providers = append(providers, bbcProviders...)
+		seriousProblems = append(seriousProblems, bbcProblems...)
+		warnings = append(warnings, bbcWarnings...)
+	}
+
 	// 🚨 SECURITY: Warn the admin when both code host authz provider and the permissions user mapping are configured.
 	if cfg.SiteConfiguration.PermissionsUserMapping != nil &&
 		cfg.SiteConfiguration.PermissionsUserMapping.Enabled && len(providers) > 0 {
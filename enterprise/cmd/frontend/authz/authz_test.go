@@ -14,6 +14,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -78,6 +79,7 @@ func Test_authzProvidersFromConfig(t *testing.T) {
 		cfg                          conf.Unified
 		gitlabConnections            []*schema.GitLabConnection
 		bitbucketServerConnections   []*schema.BitbucketServerConnection
+		bitbucketCloudConnections    []*schema.BitbucketCloudConnection
 		expAuthzAllowAccessByDefault bool
 		expAuthzProviders            func(*testing.T, []authz.Provider)
 		expSeriousProblems           []string
@@ -453,6 +455,56 @@ func Test_authzProvidersFromConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			description: "Bitbucket Cloud TTL error",
+			cfg:         conf.Unified{},
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: &schema.BitbucketCloudAuthorization{
+						IdentityProvider: schema.BitbucketCloudIdentityProvider{
+							Username: &schema.BitbucketCloudUsernameIdentity{
+								Type: "username",
+							},
+						},
+						Ttl: "invalid",
+					},
+					Url:         "https://bitbucket.org",
+					Username:    "admin",
+					AppPassword: "secret-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: false,
+			expSeriousProblems:           []string{"1 error occurred:\n\t* authorization.ttl: time: invalid duration invalid\n\n"},
+		},
+		{
+			description: "Bitbucket Cloud username matching",
+			cfg:         conf.Unified{},
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: &schema.BitbucketCloudAuthorization{
+						IdentityProvider: schema.BitbucketCloudIdentityProvider{
+							Username: &schema.BitbucketCloudUsernameIdentity{
+								Type: "username",
+							},
+						},
+						Ttl: "15m",
+					},
+					Url:         "https://bitbucket.org",
+					Username:    "admin",
+					AppPassword: "secret-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: true,
+			expAuthzProviders: func(t *testing.T, have []authz.Provider) {
+				if len(have) == 0 {
+					t.Fatalf("no providers")
+				}
+
+				if have[0].ServiceType() != bitbucketcloud.ServiceType {
+					t.Fatalf("no Bitbucket Cloud authz provider returned")
+				}
+			},
+		},
 
 		// For Sourcegraph authz provider
 		{
@@ -519,6 +571,34 @@ func Test_authzProvidersFromConfig(t *testing.T) {
 			expAuthzAllowAccessByDefault: false,
 			expSeriousProblems:           []string{"The permissions user mapping (site configuration `permissions.userMapping`) cannot be enabled when \"bitbucketServer\" authorization providers are in use. Blocking access to all repositories until the conflict is resolved."},
 		},
+		{
+			description: "Conflicted configuration between Sourcegraph and Bitbucket Cloud authz provider",
+			cfg: conf.Unified{
+				SiteConfiguration: schema.SiteConfiguration{
+					PermissionsUserMapping: &schema.PermissionsUserMapping{
+						Enabled: true,
+						BindID:  "email",
+					},
+				},
+			},
+			bitbucketCloudConnections: []*schema.BitbucketCloudConnection{
+				{
+					Authorization: &schema.BitbucketCloudAuthorization{
+						IdentityProvider: schema.BitbucketCloudIdentityProvider{
+							Username: &schema.BitbucketCloudUsernameIdentity{
+								Type: "username",
+							},
+						},
+						Ttl: "15m",
+					},
+					Url:         "https://bitbucket.org",
+					Username:    "admin",
+					AppPassword: "secret-password",
+				},
+			},
+			expAuthzAllowAccessByDefault: false,
+			expSeriousProblems:           []string{"The permissions user mapping (site configuration `permissions.userMapping`) cannot be enabled when \"bitbucketCloud\" authorization providers are in use. Blocking access to all repositories until the conflict is resolved."},
+		},
 	}
 
 	for _, test := range tests {
@@ -527,6 +607,7 @@ func Test_authzProvidersFromConfig(t *testing.T) {
 		store := fakeStore{
 			gitlabs:          test.gitlabConnections,
 			bitbucketServers: test.bitbucketServerConnections,
+			bitbucketClouds:  test.bitbucketCloudConnections,
 		}
 
 		allowAccessByDefault, authzProviders, seriousProblems, _ :=
@@ -563,6 +644,7 @@ type fakeStore struct {
 	gitlabs          []*schema.GitLabConnection
 	githubs          []*schema.GitHubConnection
 	bitbucketServers []*schema.BitbucketServerConnection
+	bitbucketClouds  []*schema.BitbucketCloudConnection
 }
 
 func (s fakeStore) ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error) {
@@ -576,3 +658,7 @@ func (s fakeStore) ListGitLabConnections(context.Context) ([]*schema.GitLabConne
 func (s fakeStore) ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error) {
 	return s.bitbucketServers, nil
 }
+
+func (s fakeStore) ListBitbucketCloudConnections(context.Context) ([]*schema.BitbucketCloudConnection, error) {
+	return s.bitbucketClouds, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
 )
 
@@ -158,3 +159,12 @@ func (s *authzStore) RevokeUserPermissions(ctx context.Context, args *db.RevokeU
 	}
 	return nil
 }
+
+// SubRepoPermissions returns the sub-repository permissions of a user for the given repositories,
+// which implements the db.AuthzStore interface.
+func (s *authzStore) SubRepoPermissions(ctx context.Context, args *db.SubRepoPermissionsArgs) (map[api.RepoID]*authz.SubRepoPermissions, error) {
+	if args.UserID <= 0 || len(args.RepoIDs) == 0 {
+		return nil, nil
+	}
+	return s.store.LoadUserSubRepoPermissions(ctx, args.UserID, args.RepoIDs)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/github"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
@@ -21,5 +22,8 @@ func NewExternalServicesStore() *db.ExternalServicesStore {
 		BitbucketServerValidators: []func(*schema.BitbucketServerConnection) error{
 			bitbucketserver.ValidateAuthz,
 		},
+		BitbucketCloudValidators: []func(*schema.BitbucketCloudConnection) error{
+			bitbucketcloud.ValidateAuthz,
+		},
 	}
 }
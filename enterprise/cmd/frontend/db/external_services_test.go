@@ -387,6 +387,28 @@ func TestExternalServices_ValidateConfig(t *testing.T) {
 			config: `{"exclude": [{"pattern": "["}]}`,
 			assert: includes(`exclude.0.pattern: Does not match format 'regex'`),
 		},
+		{
+			kind:   "BITBUCKETCLOUD",
+			desc:   "missing identityProvider in authorization",
+			config: `{"authorization": {}}`,
+			assert: includes(`authorization: identityProvider is required`),
+		},
+		{
+			kind: "BITBUCKETCLOUD",
+			desc: "valid authorization",
+			config: `
+			{
+				"url": "https://bitbucket.org/",
+				"username": "admin",
+				"appPassword": "app-password",
+				"teams": ["sglocal"],
+				"authorization": {
+					"identityProvider": { "type": "username" },
+					"ttl": "1h"
+				}
+			}`,
+			assert: equals(`<nil>`),
+		},
 		{
 			kind: "BITBUCKETSERVER",
 			desc: "valid with url, username, token, repositoryQuery",
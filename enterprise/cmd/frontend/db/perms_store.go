@@ -21,7 +21,8 @@ var ErrPermsUpdatedAtNotSet = errors.New("permissions UpdatedAt timestamp must b
 
 // PermsStore is the unified interface for managing permissions explicitly in the database.
 // It is concurrency-safe and maintains data consistency over the 'user_permissions',
-// 'repo_permissions', 'user_pending_permissions', and 'repo_pending_permissions' tables.
+// 'repo_permissions', 'user_pending_permissions', 'repo_pending_permissions' and
+// 'sub_repo_permissions' tables.
 type PermsStore struct {
 	db    dbutil.DB
 	clock func() time.Time
@@ -845,6 +846,9 @@ DO UPDATE SET
 //
 // This method starts its own transaction for update consistency if the caller hasn't started one already.
 //
+// This method does not grant sub-repository permissions, so no path of repositories on code hosts with
+// sub-repository permissions is readable by the user until the user's permissions are synced.
+//
 // 🚨 SECURITY: This method takes arbitrary string as a valid bind ID and does not interpret the meaning
 // of the value it represents. Therefore, it is caller's responsibility to ensure the legitimate relation
 // between the given user ID and the bind ID found in p.
@@ -1082,8 +1086,8 @@ AND service_id = %s
 	return bindIDs, nil
 }
 
-// DeleteAllUserPermissions deletes all rows with given user ID from the "user_permissions" table,
-// which effectively removes access to all repositories for the user.
+// DeleteAllUserPermissions deletes all rows with given user ID from the "user_permissions" and
+// "sub_repo_permissions" tables, which effectively removes access to all repositories for the user.
 func (s *PermsStore) DeleteAllUserPermissions(ctx context.Context, userID int32) (err error) {
 	ctx, save := s.observe(ctx, "DeleteAllUserPermissions", "")
 	defer func() { save(&err, otlog.Int32("userID", userID)) }()
@@ -1093,6 +1097,9 @@ func (s *PermsStore) DeleteAllUserPermissions(ctx context.Context, userID int32)
 	if err = s.execute(ctx, sqlf.Sprintf(`DELETE FROM user_permissions WHERE user_id = %s`, userID)); err != nil {
 		return errors.Wrap(err, "execute delete user permissions query")
 	}
+	if err = s.execute(ctx, sqlf.Sprintf(`DELETE FROM sub_repo_permissions WHERE user_id = %s`, userID)); err != nil {
+		return errors.Wrap(err, "execute delete sub-repository permissions query")
+	}
 
 	return nil
 }
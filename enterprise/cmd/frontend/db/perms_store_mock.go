@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
@@ -18,4 +19,6 @@ type MockPerms struct {
 	ListPendingUsers             func(ctx context.Context) ([]string, error)
 	ListExternalAccounts         func(ctx context.Context, userID int32) ([]*extsvc.Account, error)
 	GetUserIDsByExternalAccounts func(ctx context.Context, accounts *extsvc.Accounts) (map[string]int32, error)
+	LoadUserSubRepoPermissions   func(ctx context.Context, userID int32, repoIDs []api.RepoID) (map[api.RepoID]*authz.SubRepoPermissions, error)
+	SetUserSubRepoPermissions    func(ctx context.Context, userID int32, perms map[api.RepoID]*authz.SubRepoPermissions) error
 }
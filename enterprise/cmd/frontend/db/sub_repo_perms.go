@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// LoadUserSubRepoPermissions returns the stored sub-repository permissions of the user for
+// the given repositories. Repositories without sub-repository permissions are not in the
+// returned map.
+func (s *PermsStore) LoadUserSubRepoPermissions(ctx context.Context, userID int32, repoIDs []api.RepoID) (_ map[api.RepoID]*authz.SubRepoPermissions, err error) {
+	if Mocks.Perms.LoadUserSubRepoPermissions != nil {
+		return Mocks.Perms.LoadUserSubRepoPermissions(ctx, userID, repoIDs)
+	}
+
+	ctx, save := s.observe(ctx, "LoadUserSubRepoPermissions", "")
+	defer func() { save(&err, otlog.Int32("userID", userID), otlog.Int("repoIDs.count", len(repoIDs))) }()
+
+	if len(repoIDs) == 0 {
+		return map[api.RepoID]*authz.SubRepoPermissions{}, nil
+	}
+
+	items := make([]*sqlf.Query, len(repoIDs))
+	for i := range repoIDs {
+		items[i] = sqlf.Sprintf("%s", repoIDs[i])
+	}
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/sub_repo_perms.go:PermsStore.LoadUserSubRepoPermissions
+SELECT repo_id, path_includes, path_excludes
+FROM sub_repo_permissions
+WHERE user_id = %s
+AND repo_id IN (%s)
+`, userID, sqlf.Join(items, ","))
+
+	var rows *sql.Rows
+	rows, err = s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := make(map[api.RepoID]*authz.SubRepoPermissions)
+	for rows.Next() {
+		var repoID api.RepoID
+		p := &authz.SubRepoPermissions{}
+		if err = rows.Scan(&repoID, pq.Array(&p.PathIncludes), pq.Array(&p.PathExcludes)); err != nil {
+			return nil, err
+		}
+		perms[repoID] = p
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return perms, nil
+}
+
+// SetUserSubRepoPermissions performs a full update of the sub-repository permissions of the
+// user: the stored permissions of repositories that are not in perms are removed.
+func (s *PermsStore) SetUserSubRepoPermissions(ctx context.Context, userID int32, perms map[api.RepoID]*authz.SubRepoPermissions) (err error) {
+	if Mocks.Perms.SetUserSubRepoPermissions != nil {
+		return Mocks.Perms.SetUserSubRepoPermissions(ctx, userID, perms)
+	}
+
+	ctx, save := s.observe(ctx, "SetUserSubRepoPermissions", "")
+	defer func() { save(&err, otlog.Int32("userID", userID), otlog.Int("perms.count", len(perms))) }()
+
+	// Open a transaction for update consistency.
+	txs, err := s.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer txs.Done(&err)
+
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/sub_repo_perms.go:PermsStore.SetUserSubRepoPermissions
+DELETE FROM sub_repo_permissions WHERE user_id = %s
+`, userID)
+	if err = txs.execute(ctx, q); err != nil {
+		return errors.Wrap(err, "execute delete sub-repository permissions query")
+	}
+
+	if len(perms) == 0 {
+		return nil
+	}
+
+	updatedAt := txs.clock()
+	items := make([]*sqlf.Query, 0, len(perms))
+	for repoID, p := range perms {
+		if p == nil {
+			continue
+		}
+		items = append(items, sqlf.Sprintf("(%s, %s, %s, %s, %s)",
+			userID,
+			repoID,
+			pq.Array(nonNilStrings(p.PathIncludes)),
+			pq.Array(nonNilStrings(p.PathExcludes)),
+			updatedAt,
+		))
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	q = sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/sub_repo_perms.go:PermsStore.SetUserSubRepoPermissions
+INSERT INTO sub_repo_permissions
+  (user_id, repo_id, path_includes, path_excludes, updated_at)
+VALUES
+  %s
+`, sqlf.Join(items, ",\n"))
+	if err = txs.execute(ctx, q); err != nil {
+		return errors.Wrap(err, "execute insert sub-repository permissions query")
+	}
+
+	return nil
+}
+
+// nonNilStrings returns ss, or an empty slice if ss is nil, because a nil slice is stored as
+// NULL by pq.Array.
+func nonNilStrings(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func testPermsStore_SubRepoPermissions(db *sql.DB) func(*testing.T) {
+	return func(t *testing.T) {
+		s := NewPermsStore(db, time.Now)
+		t.Cleanup(func() {
+			cleanupUsersTable(t, s)
+			cleanupReposTable(t, s)
+		})
+
+		ctx := context.Background()
+
+		qs := []*sqlf.Query{
+			sqlf.Sprintf(`INSERT INTO users(username) VALUES('alice')`),                  // ID=1
+			sqlf.Sprintf(`INSERT INTO users(username) VALUES('bob')`),                    // ID=2
+			sqlf.Sprintf(`INSERT INTO repo(name, private) VALUES('private_repo', TRUE)`), // ID=1
+			sqlf.Sprintf(`INSERT INTO repo(name, private) VALUES('monorepo', TRUE)`),     // ID=2
+		}
+		for _, q := range qs {
+			if err := s.execute(ctx, q); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		alice := map[api.RepoID]*authz.SubRepoPermissions{
+			2: {PathIncludes: []string{"docs/**", "src/**"}, PathExcludes: []string{"src/secret/**"}},
+		}
+		if err := s.SetUserSubRepoPermissions(ctx, 1, alice); err != nil {
+			t.Fatal(err)
+		}
+		bob := map[api.RepoID]*authz.SubRepoPermissions{
+			1: {PathIncludes: []string{"**"}},
+			2: {PathIncludes: []string{"docs/**"}},
+		}
+		if err := s.SetUserSubRepoPermissions(ctx, 2, bob); err != nil {
+			t.Fatal(err)
+		}
+
+		loaded, err := s.LoadUserSubRepoPermissions(ctx, 1, []api.RepoID{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(alice, loaded); diff != "" {
+			t.Fatal(diff)
+		}
+
+		// Setting the permissions again replaces all of them.
+		bob = map[api.RepoID]*authz.SubRepoPermissions{
+			2: {PathIncludes: []string{"src/**"}},
+		}
+		if err := s.SetUserSubRepoPermissions(ctx, 2, bob); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err = s.LoadUserSubRepoPermissions(ctx, 2, []api.RepoID{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[api.RepoID]*authz.SubRepoPermissions{
+			2: {PathIncludes: []string{"src/**"}, PathExcludes: []string{}},
+		}
+		if diff := cmp.Diff(want, loaded); diff != "" {
+			t.Fatal(diff)
+		}
+
+		// Deleting all permissions of alice doesn't affect bob.
+		if err := s.DeleteAllUserPermissions(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err = s.LoadUserSubRepoPermissions(ctx, 1, []api.RepoID{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded) != 0 {
+			t.Fatalf("got %d sub-repository permissions for alice, want none", len(loaded))
+		}
+		loaded, err = s.LoadUserSubRepoPermissions(ctx, 2, []api.RepoID{2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, loaded); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package bitbucketcloud
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	iauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// NewAuthzProviders returns the set of Bitbucket Cloud authz providers derived from the connections.
+// It also returns any validation problems with the config, separating these into "serious problems" and
+// "warnings". "Serious problems" are those that should make Sourcegraph set authz.allowAccessByDefault
+// to false. "Warnings" are all other validation problems.
+func NewAuthzProviders(
+	conns []*schema.BitbucketCloudConnection,
+) (ps []authz.Provider, problems []string, warnings []string) {
+	// Authorization (i.e., permissions) providers
+	for _, c := range conns {
+		p, err := newAuthzProvider(c)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if p != nil {
+			ps = append(ps, p)
+		}
+	}
+
+	for _, p := range ps {
+		for _, problem := range p.Validate() {
+			warnings = append(warnings, fmt.Sprintf("BitbucketCloud config for %s was invalid: %s", p.ServiceID(), problem))
+		}
+	}
+
+	return ps, problems, warnings
+}
+
+func newAuthzProvider(c *schema.BitbucketCloudConnection) (authz.Provider, error) {
+	if c.Authorization == nil {
+		return nil, nil
+	}
+
+	errs := new(multierror.Error)
+
+	ttl, err := iauthz.ParseTTL(c.Authorization.Ttl)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		errs = multierror.Append(errs, errors.Errorf("Could not parse URL for Bitbucket Cloud %q: %s", c.Url, err))
+		return nil, errs.ErrorOrNil()
+	}
+
+	apiURLString := c.ApiURL
+	if apiURLString == "" {
+		apiURLString = "https://api.bitbucket.org"
+	}
+	apiURL, err := url.Parse(apiURLString)
+	if err != nil {
+		errs = multierror.Append(errs, errors.Errorf("Could not parse API URL for Bitbucket Cloud %q: %s", apiURLString, err))
+		return nil, errs.ErrorOrNil()
+	}
+
+	cli, err := httpcli.NewExternalHTTPClientFactory().Doer()
+	if err != nil {
+		errs = multierror.Append(errs, err)
+		return nil, errs.ErrorOrNil()
+	}
+
+	client := bitbucketcloud.NewClient(extsvc.NormalizeBaseURL(apiURL), cli)
+	client.Username = c.Username
+	client.AppPassword = c.AppPassword
+
+	var p authz.Provider
+	switch idp := c.Authorization.IdentityProvider; {
+	case idp.Username != nil:
+		p = NewProvider(client, baseURL, workspaces(c), ttl, nil)
+	default:
+		errs = multierror.Append(errs, errors.Errorf("No identityProvider was specified"))
+	}
+
+	return p, errs.ErrorOrNil()
+}
+
+// workspaces returns the workspaces whose repositories are synced from the given connection,
+// which are the workspaces of the configured teams and the workspace of the configured user.
+func workspaces(c *schema.BitbucketCloudConnection) []string {
+	seen := make(map[string]bool, len(c.Teams)+1)
+	ws := make([]string, 0, len(c.Teams)+1)
+	for _, w := range append([]string{c.Username}, c.Teams...) {
+		if w != "" && !seen[w] {
+			seen[w] = true
+			ws = append(ws, w)
+		}
+	}
+	return ws
+}
+
+// ValidateAuthz validates the authorization fields of the given Bitbucket Cloud external
+// service config.
+func ValidateAuthz(c *schema.BitbucketCloudConnection) error {
+	_, err := newAuthzProvider(c)
+	return err
+}
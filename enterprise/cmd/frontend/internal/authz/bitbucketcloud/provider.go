@@ -0,0 +1,348 @@
+// Package bitbucketcloud contains an authorization provider for Bitbucket Cloud.
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"time"
+
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined from the workspace memberships and repository permissions of Bitbucket Cloud.
+type Provider struct {
+	client     *bitbucketcloud.Client
+	codeHost   *extsvc.CodeHost
+	workspaces []string
+	pageSize   int // Page size to use in paginated requests.
+	cacheTTL   time.Duration
+	cache      cache
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// cache describes the shape of the user permissions cache that Provider uses internally.
+type cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+}
+
+type userReposCacheVal struct {
+	RepoIDs []extsvc.RepoID
+	TTL     time.Duration
+}
+
+// noAccountCacheVal is cached for usernames that are not the nickname of a member of any of
+// the configured workspaces.
+type noAccountCacheVal struct {
+	TTL time.Duration
+}
+
+// NewProvider returns a new Bitbucket Cloud authorization provider that uses the given
+// bitbucketcloud.Client to fetch the permissions of the repositories in the given workspaces.
+// The client's credentials must belong to an administrator of the workspaces. It assumes
+// usernames of Sourcegraph accounts match 1-1 with nicknames of Bitbucket Cloud accounts.
+func NewProvider(cli *bitbucketcloud.Client, baseURL *url.URL, workspaces []string, cacheTTL time.Duration, mockCache cache) *Provider {
+	p := &Provider{
+		client:     cli,
+		codeHost:   extsvc.NewCodeHost(baseURL, bitbucketcloud.ServiceType),
+		workspaces: workspaces,
+		pageSize:   100,
+		cacheTTL:   cacheTTL,
+		cache:      mockCache,
+	}
+	// Note: this will use the same underlying Redis instance and key namespace for every instance
+	// of Provider, so that different instances, even in different processes, share cache entries.
+	if p.cache == nil {
+		p.cache = rcache.NewWithTTL(fmt.Sprintf("bitbucketCloudAuthz:%s", p.codeHost.ServiceID), int(math.Ceil(cacheTTL.Seconds())))
+	}
+	return p
+}
+
+// Validate validates that the Provider can read the repository permissions of the
+// configured workspaces with the credentials it was configured with.
+func (p *Provider) Validate() (problems []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, w := range p.workspaces {
+		_, _, err := p.client.RepoPermissions(ctx, &bitbucketcloud.PageToken{Pagelen: 1}, w, "")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("workspace %q: %s", w, err))
+		}
+	}
+	return problems
+}
+
+// ServiceID returns the absolute URL that identifies the Bitbucket Cloud instance
+// this provider is configured with.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "bitbucketCloud".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// RepoPerms returns the permissions the given external account has in relation to the given
+// set of repos. Public repositories are readable by everyone, and private repositories are
+// readable by the accounts returned by FetchUserPerms, which are cached for the configured TTL.
+func (p *Provider) RepoPerms(ctx context.Context, acct *extsvc.Account, repos []*types.Repo) (perms []authz.RepoPerms, err error) {
+	tr, ctx := trace.New(ctx, "bitbucketcloud.authz.provider.RepoPerms", "")
+	defer func() {
+		tr.LogFields(
+			otlog.Int("repos.count", len(repos)),
+			otlog.Int("perms.count", len(perms)),
+		)
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	var visible map[extsvc.RepoID]bool
+	if acct != nil && extsvc.IsHostOfAccount(p.codeHost, acct) {
+		visible, err = p.userRepoIDs(ctx, acct)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	perms = make([]authz.RepoPerms, 0, len(repos))
+	for _, r := range repos {
+		if !r.Private || visible[extsvc.RepoID(r.ExternalRepo.ID)] {
+			perms = append(perms, authz.RepoPerms{Repo: r, Perms: authz.Read})
+		}
+	}
+	return perms, nil
+}
+
+// userRepoIDs returns the set of IDs of the repositories the given account can read, from
+// the cache if possible.
+func (p *Provider) userRepoIDs(ctx context.Context, acct *extsvc.Account) (map[extsvc.RepoID]bool, error) {
+	key := "u:" + acct.AccountID
+
+	var ids []extsvc.RepoID
+	if b, ok := p.cache.Get(key); ok && p.cacheTTL > 0 {
+		var val userReposCacheVal
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, err
+		}
+		// If the cache TTL is now less than the cache entry TTL, the entry is invalid.
+		if val.TTL <= p.cacheTTL {
+			ids = val.RepoIDs
+		}
+	}
+
+	if ids == nil {
+		var err error
+		// 🚨 SECURITY: Partial results are never cached or used, because they would deny
+		// access to repositories for the whole TTL.
+		if ids, err = p.FetchUserPerms(ctx, acct); err != nil {
+			return nil, err
+		}
+		if p.cacheTTL > 0 {
+			b, err := json.Marshal(userReposCacheVal{RepoIDs: ids, TTL: p.cacheTTL})
+			if err != nil {
+				return nil, err
+			}
+			p.cache.Set(key, b)
+		}
+	}
+
+	visible := make(map[extsvc.RepoID]bool, len(ids))
+	for _, id := range ids {
+		visible[id] = true
+	}
+	return visible, nil
+}
+
+// FetchAccount satisfies the authz.Provider interface. It returns the account of the
+// member of the configured workspaces whose nickname is the username of the user.
+//
+// The Bitbucket Cloud API can't look up workspace members by nickname, so finding out that
+// a user is not a member means listing all members of the workspaces. This is cached for
+// the configured TTL, so a user who becomes a member is found once the cache entry expires.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, _ []*extsvc.Account) (acct *extsvc.Account, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	tr, ctx := trace.New(ctx, "bitbucketcloud.authz.provider.FetchAccount", "")
+	defer func() {
+		tr.LogFields(
+			otlog.String("user.name", user.Username),
+			otlog.Int32("user.id", user.ID),
+		)
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	key := "n:" + user.Username
+	if b, ok := p.cache.Get(key); ok && p.cacheTTL > 0 {
+		var val noAccountCacheVal
+		if err := json.Unmarshal(b, &val); err != nil {
+			return nil, err
+		}
+		// If the cache TTL is now less than the cache entry TTL, the entry is invalid.
+		if val.TTL <= p.cacheTTL {
+			return nil, nil
+		}
+	}
+
+	for _, w := range p.workspaces {
+		page := &bitbucketcloud.PageToken{Pagelen: p.pageSize}
+		for {
+			members, next, err := p.client.WorkspaceMembers(ctx, page, w)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list members of workspace %q", w)
+			}
+
+			for _, m := range members {
+				if m.User == nil || m.User.Nickname != user.Username {
+					continue
+				}
+
+				accountData, err := json.Marshal(m.User)
+				if err != nil {
+					return nil, err
+				}
+
+				return &extsvc.Account{
+					UserID: user.ID,
+					AccountSpec: extsvc.AccountSpec{
+						ServiceType: p.codeHost.ServiceType,
+						ServiceID:   p.codeHost.ServiceID,
+						AccountID:   m.User.UUID,
+					},
+					AccountData: extsvc.AccountData{
+						Data: (*json.RawMessage)(&accountData),
+					},
+				}, nil
+			}
+
+			if !next.HasMore() {
+				break
+			}
+			page = next
+		}
+	}
+
+	if p.cacheTTL > 0 {
+		b, err := json.Marshal(noAccountCacheVal{TTL: p.cacheTTL})
+		if err != nil {
+			return nil, err
+		}
+		p.cache.Set(key, b)
+	}
+	return nil, nil
+}
+
+// FetchUserPerms returns a list of repository IDs (on code host) that the given account
+// has read access on the code host. The repository ID has the same value as it would be
+// used as api.ExternalRepoSpec.ID. The returned list may include public repositories that
+// the account has explicit permissions on, which callers can ignore.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account) ([]extsvc.RepoID, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case account.Data == nil:
+		return nil, errors.New("no account data provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, fmt.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	var user bitbucketcloud.Account
+	if err := json.Unmarshal(*account.Data, &user); err != nil {
+		return nil, errors.Wrap(err, "unmarshaling account data")
+	}
+
+	seen := make(map[string]bool)
+	var ids []extsvc.RepoID
+	for _, w := range p.workspaces {
+		err := p.eachRepoPermission(ctx, w, fmt.Sprintf("user.uuid=%q", user.UUID), func(perm *bitbucketcloud.RepoPermission) {
+			if perm.Repository != nil && !seen[perm.Repository.UUID] {
+				seen[perm.Repository.UUID] = true
+				ids = append(ids, extsvc.RepoID(perm.Repository.UUID))
+			}
+		})
+		if err != nil {
+			return ids, err
+		}
+	}
+
+	return ids, nil
+}
+
+// FetchRepoPerms returns a list of user IDs (on code host) who have read access to
+// the given repo on the code host. The user ID has the same value as it would
+// be used as extsvc.Account.AccountID. The returned list includes both direct access
+// and inherited from the group and workspace membership.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+	switch {
+	case repo == nil:
+		return nil, errors.New("no repo provided")
+	case !extsvc.IsHostOfRepo(p.codeHost, &repo.ExternalRepoSpec):
+		return nil, fmt.Errorf("not a code host of the repo: want %q but have %q",
+			p.codeHost.ServiceID, repo.ServiceID)
+	}
+
+	seen := make(map[string]bool)
+	var ids []extsvc.AccountID
+	for _, w := range p.workspaces {
+		err := p.eachRepoPermission(ctx, w, fmt.Sprintf("repository.uuid=%q", repo.ID), func(perm *bitbucketcloud.RepoPermission) {
+			if perm.User != nil && !seen[perm.User.UUID] {
+				seen[perm.User.UUID] = true
+				ids = append(ids, extsvc.AccountID(perm.User.UUID))
+			}
+		})
+		if err != nil {
+			return ids, err
+		}
+
+		// A repository belongs to exactly one workspace.
+		if len(ids) > 0 {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+// eachRepoPermission calls fn for each repository permission in the given workspace that
+// matches the query.
+func (p *Provider) eachRepoPermission(ctx context.Context, workspace, query string, fn func(*bitbucketcloud.RepoPermission)) error {
+	page := &bitbucketcloud.PageToken{Pagelen: p.pageSize}
+	for {
+		perms, next, err := p.client.RepoPermissions(ctx, page, workspace, query)
+		if err != nil {
+			return errors.Wrapf(err, "list repository permissions of workspace %q", workspace)
+		}
+
+		for _, perm := range perms {
+			fn(perm)
+		}
+
+		if !next.HasMore() {
+			return nil
+		}
+		page = next
+	}
+}
@@ -0,0 +1,312 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+var (
+	alice = &bitbucketcloud.Account{UUID: "{alice}", Nickname: "alice"}
+	bob   = &bitbucketcloud.Account{UUID: "{bob}", Nickname: "bob"}
+
+	repoSecret = &bitbucketcloud.Repo{UUID: "{secret}", FullName: "sglocal/secret"}
+	repoShared = &bitbucketcloud.Repo{UUID: "{shared}", FullName: "sglocal/shared"}
+	repoTeam   = &bitbucketcloud.Repo{UUID: "{team}", FullName: "team/team"}
+)
+
+// fakeAPI serves the workspace members and repository permissions endpoints of the
+// Bitbucket Cloud API from the given permissions, one result per page.
+func fakeAPI(t *testing.T, perms map[string][]*bitbucketcloud.RepoPermission) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/2.0/workspaces/"), "/")
+		workspace := parts[0]
+
+		var values []interface{}
+		switch path := strings.Join(parts[1:], "/"); path {
+		case "members":
+			seen := make(map[string]bool)
+			for _, p := range perms[workspace] {
+				if !seen[p.User.UUID] {
+					seen[p.User.UUID] = true
+					values = append(values, &bitbucketcloud.WorkspaceMembership{User: p.User})
+				}
+			}
+		case "permissions/repositories":
+			q := r.URL.Query().Get("q")
+			for _, p := range perms[workspace] {
+				if q == "" || q == `user.uuid="`+p.User.UUID+`"` || q == `repository.uuid="`+p.Repository.UUID+`"` {
+					values = append(values, p)
+				}
+			}
+		default:
+			t.Errorf("unexpected path %q", r.URL.Path)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+
+		// Paginate one value per page to exercise the pagination.
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		resp := map[string]interface{}{"values": values}
+		if page < len(values) {
+			resp["values"] = values[page : page+1]
+		}
+		if page+1 < len(values) {
+			next := *r.URL
+			q := next.Query()
+			q.Set("page", strconv.Itoa(page+1))
+			next.RawQuery = q.Encode()
+			resp["next"] = srv.URL + next.String()
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	return srv
+}
+
+func newTestProvider(t *testing.T, srv *httptest.Server, workspaces ...string) *Provider {
+	apiURL, _ := url.Parse(srv.URL)
+	cli := bitbucketcloud.NewClient(apiURL, srv.Client())
+	return NewProvider(cli, &url.URL{Scheme: "https", Host: "bitbucket.org"}, workspaces, time.Hour, newMockCache())
+}
+
+func testPerms() map[string][]*bitbucketcloud.RepoPermission {
+	return map[string][]*bitbucketcloud.RepoPermission{
+		"sglocal": {
+			{Permission: "admin", User: alice, Repository: repoSecret},
+			{Permission: "read", User: alice, Repository: repoShared},
+			{Permission: "write", User: bob, Repository: repoShared},
+		},
+		"team": {
+			{Permission: "read", User: bob, Repository: repoTeam},
+		},
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	srv := fakeAPI(t, testPerms())
+	defer srv.Close()
+	p := newTestProvider(t, srv, "sglocal", "team")
+
+	acct, err := p.FetchAccount(context.Background(), &types.User{ID: 7, Username: "bob"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct == nil {
+		t.Fatal("got nil account")
+	}
+	if acct.UserID != 7 || acct.AccountID != bob.UUID || acct.ServiceID != "https://bitbucket.org/" || acct.ServiceType != "bitbucketCloud" {
+		t.Errorf("unexpected account: %+v", acct.AccountSpec)
+	}
+
+	acct, err = p.FetchAccount(context.Background(), &types.User{ID: 8, Username: "mallory"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct != nil {
+		t.Errorf("got account %+v for unknown user, want nil", acct)
+	}
+
+	t.Run("unknown users are cached", func(t *testing.T) {
+		var requests int
+		apiURL, _ := url.Parse(srv.URL)
+		cli := bitbucketcloud.NewClient(apiURL, httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			requests++
+			return srv.Client().Do(r)
+		}))
+		p := NewProvider(cli, &url.URL{Scheme: "https", Host: "bitbucket.org"}, []string{"sglocal", "team"}, time.Hour, newMockCache())
+
+		for i := 0; i < 2; i++ {
+			acct, err := p.FetchAccount(context.Background(), &types.User{ID: 8, Username: "mallory"}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if acct != nil {
+				t.Errorf("got account %+v for unknown user, want nil", acct)
+			}
+		}
+		// One page per member of each workspace.
+		if requests != 3 {
+			t.Errorf("got %d requests, want 3", requests)
+		}
+
+		// A shorter TTL invalidates the cached entry.
+		p.cacheTTL = time.Minute
+		if _, err := p.FetchAccount(context.Background(), &types.User{ID: 8, Username: "mallory"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if requests != 6 {
+			t.Errorf("got %d requests, want 6", requests)
+		}
+	})
+}
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	srv := fakeAPI(t, testPerms())
+	defer srv.Close()
+	p := newTestProvider(t, srv, "sglocal", "team")
+
+	t.Run("nil account", func(t *testing.T) {
+		_, err := p.FetchUserPerms(context.Background(), nil)
+		if want := "no account provided"; err == nil || err.Error() != want {
+			t.Fatalf("got error %v, want %q", err, want)
+		}
+	})
+
+	t.Run("not the code host of the account", func(t *testing.T) {
+		acct := account(t, alice)
+		acct.ServiceID = "https://github.com/"
+		_, err := p.FetchUserPerms(context.Background(), acct)
+		if err == nil {
+			t.Fatal("got nil error")
+		}
+	})
+
+	for _, tc := range []struct {
+		user *bitbucketcloud.Account
+		want []extsvc.RepoID
+	}{
+		{user: alice, want: []extsvc.RepoID{"{secret}", "{shared}"}},
+		{user: bob, want: []extsvc.RepoID{"{shared}", "{team}"}},
+	} {
+		t.Run(tc.user.Nickname, func(t *testing.T) {
+			ids, err := p.FetchUserPerms(context.Background(), account(t, tc.user))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if diff := cmp.Diff(tc.want, ids); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestProvider_FetchRepoPerms(t *testing.T) {
+	srv := fakeAPI(t, testPerms())
+	defer srv.Close()
+	p := newTestProvider(t, srv, "sglocal", "team")
+
+	for _, tc := range []struct {
+		repo *bitbucketcloud.Repo
+		want []extsvc.AccountID
+	}{
+		{repo: repoSecret, want: []extsvc.AccountID{"{alice}"}},
+		{repo: repoShared, want: []extsvc.AccountID{"{alice}", "{bob}"}},
+		{repo: repoTeam, want: []extsvc.AccountID{"{bob}"}},
+	} {
+		t.Run(tc.repo.FullName, func(t *testing.T) {
+			ids, err := p.FetchRepoPerms(context.Background(), &extsvc.Repository{
+				URI: "bitbucket.org/" + tc.repo.FullName,
+				ExternalRepoSpec: api.ExternalRepoSpec{
+					ID:          tc.repo.UUID,
+					ServiceType: p.ServiceType(),
+					ServiceID:   p.ServiceID(),
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			if diff := cmp.Diff(tc.want, ids); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	srv := fakeAPI(t, testPerms())
+	defer srv.Close()
+	p := newTestProvider(t, srv, "sglocal", "team")
+
+	repos := []*types.Repo{
+		{ID: 1, Name: "bitbucket.org/sglocal/secret", Private: true, ExternalRepo: api.ExternalRepoSpec{ID: "{secret}", ServiceType: p.ServiceType(), ServiceID: p.ServiceID()}},
+		{ID: 2, Name: "bitbucket.org/team/team", Private: true, ExternalRepo: api.ExternalRepoSpec{ID: "{team}", ServiceType: p.ServiceType(), ServiceID: p.ServiceID()}},
+		{ID: 3, Name: "bitbucket.org/sglocal/public", ExternalRepo: api.ExternalRepoSpec{ID: "{public}", ServiceType: p.ServiceType(), ServiceID: p.ServiceID()}},
+	}
+
+	for _, tc := range []struct {
+		name string
+		acct *extsvc.Account
+		want []api.RepoID
+	}{
+		{name: "anonymous", want: []api.RepoID{3}},
+		{name: "alice", acct: account(t, alice), want: []api.RepoID{1, 3}},
+		{name: "bob", acct: account(t, bob), want: []api.RepoID{2, 3}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := p.RepoPerms(context.Background(), tc.acct, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var have []api.RepoID
+			for _, perm := range perms {
+				if perm.Perms != authz.Read {
+					t.Errorf("got perms %v for repo %d, want read", perm.Perms, perm.Repo.ID)
+				}
+				have = append(have, perm.Repo.ID)
+			}
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("got repos %v, want %v", have, tc.want)
+			}
+		})
+	}
+
+	// The permissions are cached, so removing them on the code host takes effect
+	// after the TTL only.
+	srv.Close()
+	perms, err := p.RepoPerms(context.Background(), account(t, alice), repos[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(perms) != 1 {
+		t.Errorf("got %d perms from cache, want 1", len(perms))
+	}
+}
+
+func account(t *testing.T, user *bitbucketcloud.Account) *extsvc.Account {
+	data, err := json.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &extsvc.Account{
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: "bitbucketCloud",
+			ServiceID:   "https://bitbucket.org/",
+			AccountID:   user.UUID,
+		},
+		AccountData: extsvc.AccountData{
+			Data: (*json.RawMessage)(&data),
+		},
+	}
+}
+
+type mockCache map[string][]byte
+
+func newMockCache() mockCache { return make(mockCache) }
+
+func (c mockCache) Get(key string) ([]byte, bool) {
+	b, ok := c[key]
+	return b, ok
+}
+
+func (c mockCache) Set(key string, b []byte) { c[key] = b }
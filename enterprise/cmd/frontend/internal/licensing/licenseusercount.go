@@ -23,7 +23,7 @@ var (
 // package to query Sourcegraph users. It allows decoupling this package
 // from the OSS db package.
 type UsersStore interface {
-	// Count returns the total count of active Sourcegraph users.
+	// Count returns the total count of active Sourcegraph users, which excludes suspended users.
 	Count(context.Context) (int, error)
 }
 
@@ -0,0 +1,389 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+// groupResource is a SCIM group (RFC 7643 section 4.2). Groups are Sourcegraph organizations, and
+// their members are the organizations' members.
+type groupResource struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members"`
+	Meta        *meta         `json:"meta,omitempty"`
+}
+
+type groupMember struct {
+	Value string `json:"value"` // the user ID
+	Ref   string `json:"$ref,omitempty"`
+}
+
+func toGroupResource(ctx context.Context, org *types.Org) (*groupResource, error) {
+	memberships, err := db.OrgMembers.GetByOrgID(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := org.Name
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		displayName = *org.DisplayName
+	}
+	res := &groupResource{
+		Schemas:     []string{groupSchema},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: displayName,
+		Members:     []groupMember{},
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      org.CreatedAt,
+			LastModified: org.UpdatedAt,
+			Location:     location("Group", org.ID),
+		},
+	}
+	for _, m := range memberships {
+		res.Members = append(res.Members, groupMember{Value: strconv.Itoa(int(m.UserID)), Ref: location("User", m.UserID)})
+	}
+	return res, nil
+}
+
+func getOrg(r *http.Request) (*types.Org, error) {
+	id, err := resourceID(r, "Group")
+	if err != nil {
+		return nil, err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), id)
+	if errcode.IsNotFound(err) {
+		return nil, notFound("Group", strconv.Itoa(int(id)))
+	}
+	return org, err
+}
+
+func serveListGroups(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	p, err := parseListParams(r.URL.Query(), "displayName")
+	if err != nil {
+		return err
+	}
+
+	var (
+		orgs  []*types.Org
+		total int
+	)
+	if p.filterAttr != "" {
+		// The name of an organization created by SCIM is its normalized displayName.
+		if name, err := auth.NormalizeUsername(p.filterValue); err == nil {
+			org, err := db.Orgs.GetByName(ctx, name)
+			if err != nil && !errcode.IsNotFound(err) {
+				return err
+			}
+			if org != nil {
+				total = 1
+				if p.startIndex == 1 && p.count > 0 {
+					orgs = append(orgs, org)
+				}
+			}
+		}
+	} else {
+		if total, err = db.Orgs.Count(ctx, db.OrgsListOptions{}); err != nil {
+			return err
+		}
+		if p.count > 0 {
+			orgs, err = db.Orgs.List(ctx, &db.OrgsListOptions{LimitOffset: &db.LimitOffset{Limit: p.count, Offset: p.startIndex - 1}})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	resp := listResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: total,
+		StartIndex:   p.startIndex,
+		Resources:    []interface{}{},
+	}
+	for _, org := range orgs {
+		res, err := toGroupResource(ctx, org)
+		if err != nil {
+			return err
+		}
+		resp.Resources = append(resp.Resources, res)
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+func serveGetGroup(w http.ResponseWriter, r *http.Request) error {
+	org, err := getOrg(r)
+	if err != nil {
+		return err
+	}
+	return writeGroup(w, r, http.StatusOK, org)
+}
+
+func writeGroup(w http.ResponseWriter, r *http.Request, status int, org *types.Org) error {
+	res, err := toGroupResource(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusCreated {
+		w.Header().Set("Location", res.Meta.Location)
+	}
+	writeJSON(w, status, res)
+	return nil
+}
+
+func serveCreateGroup(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var res groupResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	if res.DisplayName == "" {
+		return badRequest("invalidValue", "displayName is required")
+	}
+	name, err := auth.NormalizeUsername(res.DisplayName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid displayName: %s", err)
+	}
+	memberIDs, err := parseMembers(ctx, res.Members)
+	if err != nil {
+		return err
+	}
+
+	org, err := db.Orgs.Create(ctx, name, &res.DisplayName)
+	if err != nil {
+		if db.IsOrgNameAlreadyExists(err) {
+			return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("group %q already exists", res.DisplayName)}
+		}
+		return err
+	}
+	if err := setMembers(ctx, org.ID, memberIDs); err != nil {
+		return err
+	}
+	return writeGroup(w, r, http.StatusCreated, org)
+}
+
+func serveReplaceGroup(w http.ResponseWriter, r *http.Request) error {
+	org, err := getOrg(r)
+	if err != nil {
+		return err
+	}
+	var res groupResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	memberIDs, err := parseMembers(r.Context(), res.Members)
+	if err != nil {
+		return err
+	}
+	return saveAndWriteGroup(w, r, org, res.DisplayName, memberIDs)
+}
+
+var memberFilterPathRegexp = lazyregexp.New(`(?i)^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+
+func servePatchGroup(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	org, err := getOrg(r)
+	if err != nil {
+		return err
+	}
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	current, err := toGroupResource(ctx, org)
+	if err != nil {
+		return err
+	}
+	displayName := current.DisplayName
+	memberIDs, err := parseMembers(ctx, current.Members)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range req.Operations {
+		opName := strings.ToLower(op.Op)
+		if opName != "add" && opName != "replace" && opName != "remove" {
+			return badRequest("invalidSyntax", "unsupported operation %q", op.Op)
+		}
+
+		var value struct {
+			DisplayName *string       `json:"displayName"`
+			Members     []groupMember `json:"members"`
+		}
+		path := strings.TrimPrefix(strings.ToLower(op.Path), strings.ToLower(groupSchema)+":")
+		switch {
+		case path == "":
+			// The operation's value is an object with the attributes to change, e.g.
+			// {"op": "replace", "value": {"displayName": "Engineering"}}.
+			if opName == "remove" {
+				return badRequest("noTarget", "remove operations require a path")
+			}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return badRequest("invalidValue", "invalid operation value: %s", err)
+			}
+
+		case path == "displayname":
+			if opName == "remove" {
+				return badRequest("mutability", "displayName is required")
+			}
+			value.DisplayName = new(string)
+			if err := json.Unmarshal(op.Value, value.DisplayName); err != nil {
+				return badRequest("invalidValue", "invalid displayName: %s", err)
+			}
+
+		case path == "members":
+			if len(op.Value) > 0 {
+				if err := json.Unmarshal(op.Value, &value.Members); err != nil {
+					return badRequest("invalidValue", "invalid members: %s", err)
+				}
+			}
+			if opName == "remove" && len(value.Members) == 0 {
+				// Remove all members.
+				opName = "replace"
+			}
+			if value.Members == nil {
+				value.Members = []groupMember{}
+			}
+
+		case memberFilterPathRegexp.MatchString(op.Path):
+			if opName != "remove" {
+				return badRequest("invalidPath", "unsupported path %q for operation %q", op.Path, op.Op)
+			}
+			var id string
+			if err := json.Unmarshal([]byte(memberFilterPathRegexp.FindStringSubmatch(op.Path)[1]), &id); err != nil {
+				return badRequest("invalidPath", "invalid path %q", op.Path)
+			}
+			value.Members = []groupMember{{Value: id}}
+
+		default:
+			// Ignore attributes that groups don't have, such as externalId.
+			continue
+		}
+
+		if value.DisplayName != nil {
+			displayName = *value.DisplayName
+		}
+		if value.Members != nil {
+			ids, err := parseMembers(ctx, value.Members)
+			if err != nil {
+				return err
+			}
+			switch opName {
+			case "add":
+				memberIDs = append(memberIDs, ids...)
+			case "replace":
+				memberIDs = ids
+			case "remove":
+				memberIDs = removeIDs(memberIDs, ids)
+			}
+		}
+	}
+	return saveAndWriteGroup(w, r, org, displayName, memberIDs)
+}
+
+func saveAndWriteGroup(w http.ResponseWriter, r *http.Request, org *types.Org, displayName string, memberIDs []int32) error {
+	ctx := r.Context()
+	// The organization's name can't be changed, so only its display name is updated.
+	if displayName != "" && (org.DisplayName == nil || *org.DisplayName != displayName) {
+		var err error
+		if org, err = db.Orgs.Update(ctx, org.ID, &displayName); err != nil {
+			return err
+		}
+	}
+	if err := setMembers(ctx, org.ID, memberIDs); err != nil {
+		return err
+	}
+	return writeGroup(w, r, http.StatusOK, org)
+}
+
+func serveDeleteGroup(w http.ResponseWriter, r *http.Request) error {
+	org, err := getOrg(r)
+	if err != nil {
+		return err
+	}
+	if err := db.Orgs.Delete(r.Context(), org.ID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// parseMembers returns the user IDs of the group members, and an error if any of them is not a
+// user.
+func parseMembers(ctx context.Context, members []groupMember) ([]int32, error) {
+	ids := make([]int32, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 32)
+		if err != nil {
+			return nil, badRequest("invalidValue", "unknown member %q", m.Value)
+		}
+		if _, err := db.Users.GetByID(ctx, int32(id)); err != nil {
+			if errcode.IsNotFound(err) {
+				return nil, badRequest("invalidValue", "unknown member %q", m.Value)
+			}
+			return nil, err
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
+// setMembers adds and removes members of the organization so that its members are exactly the
+// users with the given IDs.
+func setMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	memberships, err := db.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	want := make(map[int32]bool, len(userIDs))
+	for _, id := range userIDs {
+		want[id] = true
+	}
+	for _, m := range memberships {
+		if want[m.UserID] {
+			delete(want, m.UserID) // already a member
+			continue
+		}
+		if err := db.OrgMembers.Remove(ctx, orgID, m.UserID); err != nil {
+			return err
+		}
+	}
+	for _, id := range userIDs {
+		if !want[id] {
+			continue
+		}
+		delete(want, id)
+		if _, err := db.OrgMembers.Create(ctx, orgID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeIDs(ids, remove []int32) []int32 {
+	removed := make(map[int32]bool, len(remove))
+	for _, id := range remove {
+		removed[id] = true
+	}
+	var kept []int32
+	for _, id := range ids {
+		if !removed[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
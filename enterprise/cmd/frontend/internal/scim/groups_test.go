@@ -0,0 +1,165 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// mockOrgStore mocks the DB stores with an in-memory set of organizations and their members.
+type mockOrgStore struct {
+	orgs    map[int32]*types.Org
+	members map[int32]map[int32]bool // org ID -> user ID -> is member
+}
+
+func newMockOrgStore(t *testing.T) *mockOrgStore {
+	s := &mockOrgStore{orgs: map[int32]*types.Org{}, members: map[int32]map[int32]bool{}}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if id > 10 {
+			return nil, &errcode.Mock{IsNotFound: true}
+		}
+		return &types.User{ID: id}, nil
+	}
+	db.Mocks.Orgs.GetByID = func(ctx context.Context, id int32) (*types.Org, error) {
+		if org, ok := s.orgs[id]; ok {
+			return org, nil
+		}
+		return nil, &errcode.Mock{IsNotFound: true}
+	}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		for _, org := range s.orgs {
+			if org.Name == name {
+				return org, nil
+			}
+		}
+		return nil, &errcode.Mock{IsNotFound: true}
+	}
+	db.Mocks.Orgs.Create = func(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+		id := int32(len(s.orgs) + 1)
+		s.orgs[id] = &types.Org{ID: id, Name: name, DisplayName: displayName}
+		s.members[id] = map[int32]bool{}
+		return s.orgs[id], nil
+	}
+	db.Mocks.Orgs.Update = func(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+		s.orgs[id].DisplayName = displayName
+		return s.orgs[id], nil
+	}
+	db.Mocks.Orgs.Delete = func(ctx context.Context, id int32) error {
+		delete(s.orgs, id)
+		return nil
+	}
+	db.Mocks.OrgMembers.GetByOrgID = func(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+		var memberships []*types.OrgMembership
+		for _, userID := range s.memberIDs(orgID) {
+			memberships = append(memberships, &types.OrgMembership{OrgID: orgID, UserID: userID})
+		}
+		return memberships, nil
+	}
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		if s.members[orgID][userID] {
+			t.Errorf("user %d is already a member of org %d", userID, orgID)
+		}
+		s.members[orgID][userID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+		delete(s.members[orgID], userID)
+		return nil
+	}
+	return s
+}
+
+func (s *mockOrgStore) memberIDs(orgID int32) []int32 {
+	var ids []int32
+	for id := range s.members[orgID] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestGroups(t *testing.T) {
+	mockSCIMConfig(t)
+	s := newMockOrgStore(t)
+	defer resetMocks()
+
+	// Create a group.
+	var created groupResource
+	rec := doRequest(t, "POST", "/Groups", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+		"displayName": "Platform Team",
+		"members": [{"value": "1"}, {"value": "2"}]
+	}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: got status %d, want %d (body %q)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if created.ID != "1" || created.DisplayName != "Platform Team" || len(created.Members) != 2 {
+		t.Errorf("create: got %+v", created)
+	}
+	if got, want := s.orgs[1].Name, "Platform-Team"; got != want {
+		t.Errorf("create: got org name %q, want %q", got, want)
+	}
+
+	// Creating a group with an unknown member fails.
+	if rec := doRequest(t, "POST", "/Groups", `{"displayName": "Other", "members": [{"value": "11"}]}`, nil); rec.Code != http.StatusBadRequest {
+		t.Errorf("create with unknown member: got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// Find the group by displayName.
+	var list listResponse
+	doRequest(t, "GET", `/Groups?filter=displayName+eq+%22Platform+Team%22`, "", &list)
+	if list.TotalResults != 1 || len(list.Resources) != 1 {
+		t.Errorf("filter: got %+v, want group 1", list)
+	}
+
+	// Patch the group's members and display name, in the ways that Okta and Azure AD do.
+	rec = doRequest(t, "PATCH", "/Groups/1", `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "3"}, {"value": "4"}]},
+			{"op": "Remove", "path": "members[value eq \"1\"]"},
+			{"op": "remove", "path": "members", "value": [{"value": "4"}]},
+			{"op": "Replace", "value": {"id": "1", "displayName": "Platform"}},
+			{"op": "replace", "path": "externalId", "value": "x"}
+		]
+	}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: got status %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got, want := s.memberIDs(1), []int32{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("patch: got members %v, want %v", got, want)
+	}
+	if got := s.orgs[1].DisplayName; got == nil || *got != "Platform" {
+		t.Errorf("patch: got display name %v, want %q", got, "Platform")
+	}
+
+	// Replace the group's members.
+	var replaced groupResource
+	doRequest(t, "PUT", "/Groups/1", `{"displayName": "Platform", "members": [{"value": "5"}]}`, &replaced)
+	if got, want := s.memberIDs(1), []int32{5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("replace: got members %v, want %v", got, want)
+	}
+	if want := []groupMember{{Value: "5", Ref: location("User", 5)}}; !reflect.DeepEqual(replaced.Members, want) {
+		t.Errorf("replace: got members %+v, want %+v", replaced.Members, want)
+	}
+
+	// Remove all members.
+	doRequest(t, "PATCH", "/Groups/1", `{"Operations": [{"op": "remove", "path": "members"}]}`, nil)
+	if got := s.memberIDs(1); len(got) != 0 {
+		t.Errorf("remove all: got members %v, want none", got)
+	}
+
+	// Delete the group.
+	if rec := doRequest(t, "DELETE", "/Groups/1", "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: got status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := doRequest(t, "GET", "/Groups/1", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted group: got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
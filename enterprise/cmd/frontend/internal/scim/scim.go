@@ -0,0 +1,301 @@
+// Package scim implements the SCIM 2.0 user and group provisioning API (RFC 7643 and RFC 7644),
+// which lets an identity provider create, update and deactivate users and organizations.
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+)
+
+const (
+	userSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	groupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	serviceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	listResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	patchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	errorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// pathPrefix is the path at which the SCIM API is served.
+const pathPrefix = "/.api/scim/v2"
+
+// maxCount is the maximum number of resources returned in a list response.
+const maxCount = 100
+
+// NewHandler returns the handler for the SCIM API.
+func NewHandler() http.Handler {
+	r := mux.NewRouter().PathPrefix(pathPrefix).Subrouter()
+	r.Path("/ServiceProviderConfig").Methods("GET").Handler(handler(serveServiceProviderConfig))
+	r.Path("/Users").Methods("GET").Handler(handler(serveListUsers))
+	r.Path("/Users").Methods("POST").Handler(handler(serveCreateUser))
+	r.Path("/Users/{id}").Methods("GET").Handler(handler(serveGetUser))
+	r.Path("/Users/{id}").Methods("PUT").Handler(handler(serveReplaceUser))
+	r.Path("/Users/{id}").Methods("PATCH").Handler(handler(servePatchUser))
+	r.Path("/Users/{id}").Methods("DELETE").Handler(handler(serveDeleteUser))
+	r.Path("/Groups").Methods("GET").Handler(handler(serveListGroups))
+	r.Path("/Groups").Methods("POST").Handler(handler(serveCreateGroup))
+	r.Path("/Groups/{id}").Methods("GET").Handler(handler(serveGetGroup))
+	r.Path("/Groups/{id}").Methods("PUT").Handler(handler(serveReplaceGroup))
+	r.Path("/Groups/{id}").Methods("PATCH").Handler(handler(servePatchGroup))
+	r.Path("/Groups/{id}").Methods("DELETE").Handler(handler(serveDeleteGroup))
+	r.NotFoundHandler = handler(func(w http.ResponseWriter, r *http.Request) error {
+		return &scimError{status: http.StatusNotFound, detail: "no such SCIM endpoint"}
+	})
+	return authMiddleware(r)
+}
+
+// authMiddleware authenticates SCIM requests with the bearer token in the auth.scim site
+// configuration.
+func authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := conf.Get().AuthScim
+		if cfg == nil || cfg.BearerToken == "" {
+			writeError(w, &scimError{status: http.StatusNotFound, detail: "SCIM provisioning is not enabled (set auth.scim in the site configuration to enable it)"})
+			return
+		}
+
+		// 🚨 SECURITY: Compare the token in constant time, so that it can't be guessed by timing
+		// requests.
+		token := bearerToken(r)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.BearerToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="SCIM"`)
+			writeError(w, &scimError{status: http.StatusUnauthorized, detail: "invalid SCIM bearer token"})
+			return
+		}
+
+		// 🚨 SECURITY: SCIM requests are not made by a user. Clear the actor that may have been
+		// set from a session cookie or access token, so that it isn't used to decide which user
+		// a provisioned account belongs to.
+		next.ServeHTTP(w, r.WithContext(actor.WithActor(r.Context(), &actor.Actor{})))
+	})
+}
+
+// bearerToken returns the token in the request's "Authorization: Bearer TOKEN" header, if any.
+func bearerToken(r *http.Request) string {
+	const prefix = "bearer "
+	value := r.Header.Get("Authorization")
+	if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(value[len(prefix):])
+}
+
+// handler returns an http.Handler that writes the error returned by h as a SCIM error response.
+func handler(h func(http.ResponseWriter, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			if _, ok := err.(*scimError); !ok {
+				log15.Error("SCIM request failed.", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+			writeError(w, err)
+		}
+	})
+}
+
+// scimError is an error that is returned to the client as a SCIM error response (RFC 7644
+// section 3.12).
+type scimError struct {
+	status   int
+	scimType string // e.g. "invalidFilter" or "uniqueness"
+	detail   string
+}
+
+func (e *scimError) Error() string { return e.detail }
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{status: http.StatusBadRequest, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func notFound(resourceType, id string) error {
+	return &scimError{status: http.StatusNotFound, detail: fmt.Sprintf("%s %q not found", resourceType, id)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		// Don't show internal errors (which may contain sensitive info) to the client.
+		e = &scimError{status: http.StatusInternalServerError, detail: "internal error"}
+	}
+	writeJSON(w, e.status, struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+	}{
+		Schemas:  []string{errorSchema},
+		Status:   strconv.Itoa(e.status),
+		ScimType: e.scimType,
+		Detail:   e.detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log15.Error("Failed to write SCIM response.", "error", err)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// meta is the metadata of a resource.
+type meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+func location(resourceType string, id int32) string {
+	return globals.ExternalURL().String() + pathPrefix + "/" + resourceType + "s/" + strconv.Itoa(int(id))
+}
+
+// resourceID returns the ID in the request's URL path. The IDs of users and groups are the IDs of
+// Sourcegraph users and organizations.
+func resourceID(r *http.Request, resourceType string) (int32, error) {
+	id := mux.Vars(r)["id"]
+	n, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return 0, notFound(resourceType, id)
+	}
+	return int32(n), nil
+}
+
+// listResponse is the response to a request that lists resources.
+type listResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// listParams are the query parameters of a request that lists resources.
+type listParams struct {
+	// filterAttr and filterValue are the attribute and value of an "attr eq value" filter, which
+	// is the only kind of filter that is supported. filterAttr is empty if there is no filter.
+	filterAttr, filterValue string
+
+	startIndex int // 1-based
+	count      int
+}
+
+var filterRegexp = lazyregexp.New(`(?i)^\s*([a-z.]+)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseListParams parses the query parameters of a request that lists resources. Only "attr eq
+// value" filters on the given attributes are supported.
+func parseListParams(q url.Values, filterAttrs ...string) (*listParams, error) {
+	p := listParams{startIndex: 1, count: maxCount}
+	if filter := q.Get("filter"); filter != "" {
+		m := filterRegexp.FindStringSubmatch(filter)
+		if m == nil {
+			return nil, badRequest("invalidFilter", `unsupported filter %q (only "attribute eq \"value\"" filters are supported)`, filter)
+		}
+		for _, attr := range filterAttrs {
+			if strings.EqualFold(m[1], attr) {
+				p.filterAttr = attr
+			}
+		}
+		if p.filterAttr == "" {
+			return nil, badRequest("invalidFilter", "unsupported filter attribute %q (supported attributes: %s)", m[1], strings.Join(filterAttrs, ", "))
+		}
+		if err := json.Unmarshal([]byte(m[2]), &p.filterValue); err != nil {
+			return nil, badRequest("invalidFilter", "invalid filter value %s", m[2])
+		}
+	}
+	if s := q.Get("startIndex"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, badRequest("invalidValue", "invalid startIndex %q", s)
+		}
+		if n > 1 {
+			p.startIndex = n
+		}
+	}
+	if s := q.Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, badRequest("invalidValue", "invalid count %q", s)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n < p.count {
+			p.count = n
+		}
+	}
+	return &p, nil
+}
+
+// patchRequest is the body of a PATCH request (RFC 7644 section 3.5.2).
+type patchRequest struct {
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// flexBool is a boolean that is also decoded from the strings "true" and "false" (in any case),
+// which some identity providers (such as Azure AD) send instead of JSON booleans.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		v, err := strconv.ParseBool(strings.ToLower(s))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", s)
+		}
+		*b = flexBool(v)
+		return nil
+	}
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = flexBool(v)
+	return nil
+}
+
+func serveServiceProviderConfig(w http.ResponseWriter, r *http.Request) error {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{serviceProviderConfigSchema},
+		"patch":          supported{true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": maxCount},
+		"changePassword": supported{false},
+		"sort":           supported{false},
+		"etag":           supported{false},
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the bearer token in the auth.scim site configuration",
+		}},
+	})
+	return nil
+}
@@ -0,0 +1,157 @@
+package scim
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+func mockSCIMConfig(t *testing.T) {
+	t.Helper()
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{BearerToken: testToken}}})
+}
+
+// doRequest makes a SCIM request with the test bearer token and decodes the JSON response into
+// v (if non-nil).
+func doRequest(t *testing.T, method, path string, body string, v interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, pathPrefix+path, bodyReader)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, req)
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s %s: invalid response %q: %s", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec
+}
+
+// 🚨 SECURITY: This tests that SCIM requests must have the bearer token in the site configuration.
+func TestAuthMiddleware(t *testing.T) {
+	defer conf.Mock(nil)
+
+	tests := []struct {
+		name          string
+		config        *schema.AuthScim
+		authorization string
+		wantStatus    int
+	}{
+		{name: "disabled", authorization: "Bearer " + testToken, wantStatus: http.StatusNotFound},
+		{name: "no token", config: &schema.AuthScim{BearerToken: testToken}, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", config: &schema.AuthScim{BearerToken: testToken}, authorization: "Bearer x" + testToken[1:], wantStatus: http.StatusUnauthorized},
+		{name: "access token", config: &schema.AuthScim{BearerToken: testToken}, authorization: "token " + testToken, wantStatus: http.StatusUnauthorized},
+		{name: "valid token", config: &schema.AuthScim{BearerToken: testToken}, authorization: "Bearer " + testToken, wantStatus: http.StatusOK},
+		{name: "valid token with lowercase scheme", config: &schema.AuthScim{BearerToken: testToken}, authorization: "bearer " + testToken, wantStatus: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: test.config}})
+			req := httptest.NewRequest("GET", pathPrefix+"/ServiceProviderConfig", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			NewHandler().ServeHTTP(rec, req)
+			if rec.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d (body %q)", rec.Code, test.wantStatus, rec.Body.String())
+			}
+			if got, want := rec.Header().Get("Content-Type"), "application/scim+json"; got != want {
+				t.Errorf("got Content-Type %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseListParams(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    *listParams
+		wantErr string
+	}{
+		{query: "", want: &listParams{startIndex: 1, count: maxCount}},
+		{query: "startIndex=3&count=10", want: &listParams{startIndex: 3, count: 10}},
+		{query: "startIndex=0&count=1000", want: &listParams{startIndex: 1, count: maxCount}},
+		{
+			query: url.Values{"filter": {`userName eq "alice@example.com"`}}.Encode(),
+			want:  &listParams{filterAttr: "userName", filterValue: "alice@example.com", startIndex: 1, count: maxCount},
+		},
+		{
+			query: url.Values{"filter": {`USERNAME EQ "a \"b\""`}}.Encode(),
+			want:  &listParams{filterAttr: "userName", filterValue: `a "b"`, startIndex: 1, count: maxCount},
+		},
+		{query: url.Values{"filter": {`userName sw "a"`}}.Encode(), wantErr: `unsupported filter "userName sw \"a\"" (only "attribute eq \"value\"" filters are supported)`},
+		{query: url.Values{"filter": {`title eq "a"`}}.Encode(), wantErr: `unsupported filter attribute "title" (supported attributes: userName, externalId)`},
+		{query: "count=x", wantErr: `invalid count "x"`},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			q, err := url.ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseListParams(q, "userName", "externalId")
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("got error %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	active := flexBool(true)
+	res := &userResource{Schemas: []string{userSchema}, UserName: "alice", DisplayName: "Alice", Active: &active}
+	patched, err := applyPatch(res, []patchOperation{
+		{Op: "Replace", Path: "active", Value: json.RawMessage(`"False"`)},
+		{Op: "add", Path: "name.givenName", Value: json.RawMessage(`"Alice"`)},
+		{Op: "replace", Value: json.RawMessage(`{"DISPLAYNAME": "Alice Smith", "name.familyName": "Smith"}`)},
+		{Op: "replace", Path: `emails[type eq "work"].value`, Value: json.RawMessage(`"alice@example.com"`)},
+		{Op: "replace", Path: userSchema + ":userName", Value: json.RawMessage(`"alice.smith"`)},
+	}, userSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got userResource
+	if err := json.Unmarshal(patched, &got); err != nil {
+		t.Fatal(err)
+	}
+	inactive := flexBool(false)
+	want := userResource{
+		Schemas:     []string{userSchema},
+		UserName:    "alice.smith",
+		Name:        &userName{GivenName: "Alice", FamilyName: "Smith"},
+		DisplayName: "Alice Smith",
+		Active:      &inactive,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := applyPatch(res, []patchOperation{{Op: "move", Path: "active"}}, userSchema); err == nil {
+		t.Error("want error for unsupported operation")
+	}
+}
@@ -0,0 +1,579 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// Each provisioned user has a SCIM external account, which records the user's SCIM attributes
+// that aren't Sourcegraph user attributes. Its account ID is the user's externalId, or their
+// userName if the identity provider doesn't set an externalId.
+const (
+	serviceType = "scim"
+	serviceID   = "scim"
+)
+
+// accountData is the data of a SCIM external account. A user's SCIM "active" attribute isn't
+// stored here: a user is active if and only if they aren't suspended, so that suspending or
+// unsuspending them on Sourcegraph is reflected in SCIM.
+type accountData struct {
+	UserName   string `json:"userName"`
+	ExternalID string `json:"externalId,omitempty"`
+}
+
+func (d *accountData) externalAccountData() (extsvc.AccountData, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return extsvc.AccountData{}, err
+	}
+	raw := json.RawMessage(b)
+	return extsvc.AccountData{Data: &raw}, nil
+}
+
+func accountSpec(externalID, userName string) extsvc.AccountSpec {
+	accountID := externalID
+	if accountID == "" {
+		accountID = userName
+	}
+	return extsvc.AccountSpec{ServiceType: serviceType, ServiceID: serviceID, AccountID: accountID}
+}
+
+// getAccount returns the user's SCIM external account and its data, or nil if the user has none
+// (because they weren't provisioned with SCIM).
+func getAccount(ctx context.Context, userID int32) (*extsvc.Account, *accountData, error) {
+	accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{UserID: userID, ServiceType: serviceType, ServiceID: serviceID})
+	if err != nil || len(accounts) == 0 {
+		return nil, nil, err
+	}
+	data, err := parseAccountData(accounts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	return accounts[0], data, nil
+}
+
+func parseAccountData(account *extsvc.Account) (*accountData, error) {
+	var data accountData
+	if account.Data != nil {
+		if err := json.Unmarshal(*account.Data, &data); err != nil {
+			return nil, err
+		}
+	}
+	return &data, nil
+}
+
+// userResource is a SCIM user (RFC 7643 section 4.1).
+type userResource struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *userName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []userEmail `json:"emails,omitempty"`
+	Active      *flexBool   `json:"active,omitempty"`
+	Meta        *meta       `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type userEmail struct {
+	Value   string   `json:"value"`
+	Type    string   `json:"type,omitempty"`
+	Primary flexBool `json:"primary,omitempty"`
+}
+
+// displayName returns the display name of the user, which is their name if they have no
+// displayName.
+func (u *userResource) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+	}
+	return ""
+}
+
+// primaryEmail returns the primary email of the user, or their first email if none is primary.
+func (u *userResource) primaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+func toUserResource(ctx context.Context, user *types.User) (*userResource, error) {
+	_, data, err := getAccount(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = &accountData{UserName: user.Username}
+	}
+	emails, err := db.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: user.ID, OnlyVerified: true})
+	if err != nil {
+		return nil, err
+	}
+
+	active := flexBool(user.SuspendedAt == nil)
+	res := &userResource{
+		Schemas:     []string{userSchema},
+		ID:          strconv.Itoa(int(user.ID)),
+		ExternalID:  data.ExternalID,
+		UserName:    data.UserName,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     location("User", user.ID),
+		},
+	}
+	for i, email := range emails {
+		// The primary email is the oldest verified email (see db.UserEmails.GetPrimaryEmail).
+		res.Emails = append(res.Emails, userEmail{Value: email.Email, Primary: i == 0})
+	}
+	return res, nil
+}
+
+func getUser(r *http.Request) (*types.User, error) {
+	id, err := resourceID(r, "User")
+	if err != nil {
+		return nil, err
+	}
+	user, err := db.Users.GetByID(r.Context(), id)
+	if errcode.IsNotFound(err) {
+		return nil, notFound("User", strconv.Itoa(int(id)))
+	}
+	return user, err
+}
+
+func serveListUsers(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	p, err := parseListParams(r.URL.Query(), "userName", "externalId")
+	if err != nil {
+		return err
+	}
+
+	var (
+		users []*types.User
+		total int
+	)
+	if p.filterAttr != "" {
+		userIDs, err := findUserIDs(ctx, p.filterAttr, p.filterValue)
+		if err != nil {
+			return err
+		}
+		total = len(userIDs)
+		for i := p.startIndex - 1; i < len(userIDs) && len(users) < p.count; i++ {
+			user, err := db.Users.GetByID(ctx, userIDs[i])
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+	} else {
+		if total, err = db.Users.Count(ctx, nil); err != nil {
+			return err
+		}
+		if p.count > 0 {
+			users, err = db.Users.List(ctx, &db.UsersListOptions{LimitOffset: &db.LimitOffset{Limit: p.count, Offset: p.startIndex - 1}})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	resp := listResponse{
+		Schemas:      []string{listResponseSchema},
+		TotalResults: total,
+		StartIndex:   p.startIndex,
+		Resources:    []interface{}{},
+	}
+	for _, user := range users {
+		res, err := toUserResource(ctx, user)
+		if err != nil {
+			return err
+		}
+		resp.Resources = append(resp.Resources, res)
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+	writeJSON(w, http.StatusOK, resp)
+	return nil
+}
+
+// findUserIDs returns the IDs of the users with the given userName or externalId.
+func findUserIDs(ctx context.Context, attr, value string) ([]int32, error) {
+	opt := db.ExternalAccountsListOptions{ServiceType: serviceType, ServiceID: serviceID}
+	if attr == "externalId" {
+		opt.AccountID = value
+	}
+	accounts, err := db.ExternalAccounts.List(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []int32
+	for _, account := range accounts {
+		data, err := parseAccountData(account)
+		if err != nil {
+			return nil, err
+		}
+		if (attr == "userName" && strings.EqualFold(data.UserName, value)) || (attr == "externalId" && data.ExternalID == value) {
+			userIDs = append(userIDs, account.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
+func serveGetUser(w http.ResponseWriter, r *http.Request) error {
+	user, err := getUser(r)
+	if err != nil {
+		return err
+	}
+	res, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+func serveCreateUser(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var res userResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	if res.UserName == "" {
+		return badRequest("invalidValue", "userName is required")
+	}
+	username, err := auth.NormalizeUsername(res.UserName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid userName: %s", err)
+	}
+
+	spec := accountSpec(res.ExternalID, res.UserName)
+	accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{ServiceType: serviceType, ServiceID: serviceID, AccountID: spec.AccountID})
+	if err != nil {
+		return err
+	}
+	if len(accounts) > 0 {
+		return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("user %q already exists", res.UserName)}
+	}
+
+	data := accountData{UserName: res.UserName, ExternalID: res.ExternalID}
+	extAccountData, err := data.externalAccountData()
+	if err != nil {
+		return err
+	}
+	email := res.primaryEmail()
+	// An existing user with the same verified email (e.g., one who signed in with SSO before
+	// SCIM provisioning was enabled) is associated with the SCIM account instead of creating a
+	// new user.
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: username,
+			Email:    email,
+			// The identity provider is trusted to have verified the email.
+			EmailIsVerified: email != "",
+			DisplayName:     res.displayName(),
+		},
+		ExternalAccount:     spec,
+		ExternalAccountData: extAccountData,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		if db.IsUsernameExists(err) {
+			return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: safeErrMsg}
+		}
+		log15.Error("Failed to provision SCIM user.", "userName", res.UserName, "error", err)
+		return &scimError{status: http.StatusInternalServerError, detail: safeErrMsg}
+	}
+
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if res.Active != nil && !bool(*res.Active) {
+		if err := deactivateUser(ctx, user); err != nil {
+			return err
+		}
+		if user, err = db.Users.GetByID(ctx, userID); err != nil {
+			return err
+		}
+	}
+	created, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", created.Meta.Location)
+	writeJSON(w, http.StatusCreated, created)
+	return nil
+}
+
+func serveReplaceUser(w http.ResponseWriter, r *http.Request) error {
+	user, err := getUser(r)
+	if err != nil {
+		return err
+	}
+	var res userResource
+	if err := readJSON(r, &res); err != nil {
+		return err
+	}
+	return saveAndWriteUser(w, r, user, &res)
+}
+
+func servePatchUser(w http.ResponseWriter, r *http.Request) error {
+	user, err := getUser(r)
+	if err != nil {
+		return err
+	}
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+	current, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	patched, err := applyPatch(current, req.Operations, userSchema)
+	if err != nil {
+		return err
+	}
+	var res userResource
+	if err := json.Unmarshal(patched, &res); err != nil {
+		return badRequest("invalidValue", "invalid patched user: %s", err)
+	}
+	return saveAndWriteUser(w, r, user, &res)
+}
+
+func saveAndWriteUser(w http.ResponseWriter, r *http.Request, user *types.User, res *userResource) error {
+	ctx := r.Context()
+	if err := saveUser(ctx, user, res); err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	saved, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, saved)
+	return nil
+}
+
+// saveUser updates the user and their SCIM external account to match res. Attributes that are
+// missing from res are left unchanged. The user's username and emails are never changed, because
+// users may have changed them on Sourcegraph.
+func saveUser(ctx context.Context, user *types.User, res *userResource) error {
+	account, data, err := getAccount(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var spec extsvc.AccountSpec
+	if account != nil {
+		spec = account.AccountSpec
+	} else {
+		// The user was not provisioned with SCIM, so associate them with a new SCIM account.
+		spec = accountSpec(res.ExternalID, res.UserName)
+		accounts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{ServiceType: serviceType, ServiceID: serviceID, AccountID: spec.AccountID})
+		if err != nil {
+			return err
+		}
+		if len(accounts) > 0 {
+			return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf("user %q already exists", spec.AccountID)}
+		}
+		data = &accountData{UserName: user.Username}
+	}
+
+	wasActive := user.SuspendedAt == nil
+	active := wasActive
+	if res.UserName != "" {
+		data.UserName = res.UserName
+	}
+	if res.ExternalID != "" {
+		data.ExternalID = res.ExternalID
+	}
+	if res.Active != nil {
+		active = bool(*res.Active)
+	}
+	extAccountData, err := data.externalAccountData()
+	if err != nil {
+		return err
+	}
+	if err := db.ExternalAccounts.AssociateUserAndSave(ctx, user.ID, spec, extAccountData); err != nil {
+		return err
+	}
+
+	if displayName := res.displayName(); displayName != "" && displayName != user.DisplayName {
+		if err := db.Users.Update(ctx, user.ID, db.UserUpdate{DisplayName: &displayName}); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case wasActive && !active:
+		return deactivateUser(ctx, user)
+	case !wasActive && active:
+		if err := db.Users.SetIsSuspended(ctx, user.ID, false); err != nil {
+			return err
+		}
+		logSetActive(ctx, user, true)
+	}
+	return nil
+}
+
+// deactivateUser suspends the user and revokes their sessions and access tokens.
+func deactivateUser(ctx context.Context, user *types.User) error {
+	// 🚨 SECURITY: Suspend the user and revoke their access tokens and sessions, so that a user
+	// who was deactivated in the identity provider can't keep using Sourcegraph.
+	if err := db.Users.SetIsSuspended(ctx, user.ID, true); err != nil {
+		return err
+	}
+	if err := db.AccessTokens.DeleteBySubjectUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := db.Users.InvalidateSessionsByID(ctx, user.ID); err != nil {
+		return err
+	}
+	logSetActive(ctx, user, false)
+	return nil
+}
+
+func logSetActive(ctx context.Context, user *types.User, active bool) {
+	backend.LogAuditEvent(ctx, backend.AuditEvent{
+		Action:     backend.AuditUserSetActive,
+		TargetType: "user",
+		Target:     user.Username,
+		Before:     fmt.Sprintf("active: %t", !active),
+		After:      fmt.Sprintf("active: %t", active),
+	})
+}
+
+func serveDeleteUser(w http.ResponseWriter, r *http.Request) error {
+	user, err := getUser(r)
+	if err != nil {
+		return err
+	}
+	// Deleting the user also deletes their access tokens, and their sessions are no longer valid.
+	if err := db.Users.Delete(r.Context(), user.ID); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// applyPatch applies the PATCH operations to the resource v and returns the patched resource as
+// JSON.
+func applyPatch(v interface{}, ops []patchOperation, schema string) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	for _, op := range ops {
+		var value interface{}
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, badRequest("invalidSyntax", "invalid operation value: %s", err)
+			}
+		}
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path != "" {
+				setAttr(m, op.Path, value, schema)
+				continue
+			}
+			attrs, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, badRequest("invalidValue", "the value of an operation without a path must be an object")
+			}
+			for path, value := range attrs {
+				setAttr(m, path, value, schema)
+			}
+		case "remove":
+			if op.Path == "" {
+				return nil, badRequest("noTarget", "remove operations require a path")
+			}
+			setAttr(m, op.Path, nil, schema)
+		default:
+			return nil, badRequest("invalidSyntax", "unsupported operation %q", op.Op)
+		}
+	}
+	return json.Marshal(m)
+}
+
+// setAttr sets the attribute at path (such as "active" or "name.givenName") in m, or removes it
+// if value is nil. Attribute names are case-insensitive. Paths with value filters (such as
+// `emails[type eq "work"].value`) and paths of extension schemas are not supported and are
+// ignored.
+func setAttr(m map[string]interface{}, path string, value interface{}, schema string) {
+	if prefix := schema + ":"; len(path) > len(prefix) && strings.EqualFold(path[:len(prefix)], prefix) {
+		path = path[len(prefix):]
+	}
+	if strings.ContainsAny(path, "[:") {
+		return
+	}
+
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		key := attrKey(m, name)
+		child, ok := m[key].(map[string]interface{})
+		if !ok {
+			if value == nil {
+				return
+			}
+			child = map[string]interface{}{}
+			m[key] = child
+		}
+		m = child
+	}
+	key := attrKey(m, names[len(names)-1])
+	if value == nil {
+		delete(m, key)
+	} else {
+		m[key] = value
+	}
+}
+
+// attrKey returns the key in m of the attribute with the given (case-insensitive) name.
+func attrKey(m map[string]interface{}, name string) string {
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
@@ -0,0 +1,266 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// mockUserStore mocks the DB stores with an in-memory set of users and their SCIM accounts.
+type mockUserStore struct {
+	users    map[int32]*types.User
+	accounts map[int32]*extsvc.Account // by user ID
+
+	revokedTokens       []int32
+	invalidatedSessions []int32
+	deleted             []int32
+	auditActions        []string
+}
+
+func newMockUserStore(t *testing.T) *mockUserStore {
+	s := &mockUserStore{users: map[int32]*types.User{}, accounts: map[int32]*extsvc.Account{}}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if u, ok := s.users[id]; ok {
+			return u, nil
+		}
+		return nil, &errcode.Mock{IsNotFound: true}
+	}
+	db.Mocks.Users.Update = func(id int32, update db.UserUpdate) error {
+		if update.DisplayName != nil {
+			s.users[id].DisplayName = *update.DisplayName
+		}
+		return nil
+	}
+	db.Mocks.Users.Delete = func(ctx context.Context, id int32) error {
+		s.deleted = append(s.deleted, id)
+		return nil
+	}
+	db.Mocks.Users.SetIsSuspended = func(ctx context.Context, id int32, suspended bool) error {
+		if suspended {
+			now := time.Now()
+			s.users[id].SuspendedAt = &now
+		} else {
+			s.users[id].SuspendedAt = nil
+		}
+		return nil
+	}
+	db.Mocks.Users.InvalidateSessionsByID = func(ctx context.Context, id int32) error {
+		s.invalidatedSessions = append(s.invalidatedSessions, id)
+		return nil
+	}
+	db.Mocks.AccessTokens.DeleteBySubjectUserID = func(subjectUserID int32) error {
+		s.revokedTokens = append(s.revokedTokens, subjectUserID)
+		return nil
+	}
+	db.Mocks.UserEmails.ListByUser = func(ctx context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+		if !opt.OnlyVerified {
+			t.Error("want only verified emails to be listed")
+		}
+		return []*db.UserEmail{{UserID: opt.UserID, Email: s.users[opt.UserID].Username + "@example.com"}}, nil
+	}
+	db.Mocks.ExternalAccounts.List = func(opt db.ExternalAccountsListOptions) ([]*extsvc.Account, error) {
+		if opt.ServiceType != serviceType || opt.ServiceID != serviceID {
+			t.Errorf("got service %q %q, want SCIM accounts", opt.ServiceType, opt.ServiceID)
+		}
+		var accounts []*extsvc.Account
+		for _, a := range s.accounts {
+			if (opt.UserID == 0 || a.UserID == opt.UserID) && (opt.AccountID == "" || a.AccountID == opt.AccountID) {
+				accounts = append(accounts, a)
+			}
+		}
+		return accounts, nil
+	}
+	db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.AccountSpec, data extsvc.AccountData) error {
+		s.accounts[userID] = &extsvc.Account{UserID: userID, AccountSpec: spec, AccountData: data}
+		return nil
+	}
+	db.Mocks.AuditLog.Insert = func(e *types.AuditLogEntry) error {
+		s.auditActions = append(s.auditActions, e.Action+" "+e.Target+" "+e.Diff)
+		return nil
+	}
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		if actor.FromContext(ctx).IsAuthenticated() {
+			t.Error("want SCIM requests to have no actor")
+		}
+		if !op.CreateIfNotExist {
+			t.Error("want CreateIfNotExist")
+		}
+		id := int32(len(s.users) + 1)
+		s.users[id] = &types.User{ID: id, Username: op.UserProps.Username, DisplayName: op.UserProps.DisplayName, CreatedAt: time.Now()}
+		s.accounts[id] = &extsvc.Account{UserID: id, AccountSpec: op.ExternalAccount, AccountData: op.ExternalAccountData}
+		return id, "", nil
+	}
+	return s
+}
+
+func resetMocks() {
+	db.Mocks = db.MockStores{}
+	auth.MockGetAndSaveUser = nil
+	conf.Mock(nil)
+}
+
+func TestUsers(t *testing.T) {
+	mockSCIMConfig(t)
+	s := newMockUserStore(t)
+	defer resetMocks()
+
+	// Create a user.
+	var created userResource
+	rec := doRequest(t, "POST", "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"externalId": "00u1",
+		"name": {"givenName": "Alice", "familyName": "Smith"},
+		"emails": [{"value": "alice@example.com", "primary": true}],
+		"active": true
+	}`, &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: got status %d, want %d (body %q)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if created.ID != "1" || created.UserName != "alice@example.com" || created.ExternalID != "00u1" || created.DisplayName != "Alice Smith" || created.Active == nil || !bool(*created.Active) {
+		t.Errorf("create: got %+v", created)
+	}
+	if got, want := s.users[1].Username, "alice"; got != want {
+		t.Errorf("create: got username %q, want %q", got, want)
+	}
+	if got, want := s.accounts[1].AccountID, "00u1"; got != want {
+		t.Errorf("create: got account ID %q, want %q", got, want)
+	}
+	if got, want := rec.Header().Get("Location"), created.Meta.Location; got != want {
+		t.Errorf("create: got Location %q, want %q", got, want)
+	}
+
+	// Creating the same user again is a conflict.
+	if rec := doRequest(t, "POST", "/Users", `{"userName": "alice@example.com", "externalId": "00u1"}`, nil); rec.Code != http.StatusConflict {
+		t.Errorf("create again: got status %d, want %d", rec.Code, http.StatusConflict)
+	}
+
+	// Find the user by userName.
+	var list listResponse
+	doRequest(t, "GET", `/Users?filter=userName+eq+%22ALICE%40example.com%22`, "", &list)
+	if list.TotalResults != 1 || len(list.Resources) != 1 || list.Resources[0].(map[string]interface{})["id"] != "1" {
+		t.Errorf("filter: got %+v, want user 1", list)
+	}
+	doRequest(t, "GET", `/Users?filter=userName+eq+%22bob%22`, "", &list)
+	if list.TotalResults != 0 || len(list.Resources) != 0 {
+		t.Errorf("filter: got %+v, want no users", list)
+	}
+
+	// Deactivating the user suspends them and revokes their sessions and access tokens.
+	var patched userResource
+	rec = doRequest(t, "PATCH", "/Users/1", `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [{"op": "Replace", "path": "active", "value": "False"}]
+	}`, &patched)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deactivate: got status %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if patched.Active == nil || bool(*patched.Active) {
+		t.Errorf("deactivate: got active %v, want false", patched.Active)
+	}
+	if s.users[1].SuspendedAt == nil {
+		t.Error("deactivate: want user to be suspended")
+	}
+	if len(s.revokedTokens) != 1 || s.revokedTokens[0] != 1 {
+		t.Errorf("deactivate: got revoked access tokens of users %v, want [1]", s.revokedTokens)
+	}
+	if len(s.invalidatedSessions) != 1 || s.invalidatedSessions[0] != 1 {
+		t.Errorf("deactivate: got invalidated sessions of users %v, want [1]", s.invalidatedSessions)
+	}
+	var data accountData
+	if err := json.Unmarshal(*s.accounts[1].Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if want := (accountData{UserName: "alice@example.com", ExternalID: "00u1"}); data != want {
+		t.Errorf("deactivate: got account data %+v, want %+v", data, want)
+	}
+
+	// Deactivating an inactive user again and reactivating them don't revoke anything.
+	doRequest(t, "PUT", "/Users/1", `{"userName": "alice@example.com", "externalId": "00u1", "displayName": "Alice", "active": false}`, nil)
+	rec = doRequest(t, "PUT", "/Users/1", `{"userName": "alice@example.com", "externalId": "00u1", "displayName": "Alice", "active": true}`, &patched)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reactivate: got status %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if patched.Active == nil || !bool(*patched.Active) || patched.DisplayName != "Alice" {
+		t.Errorf("reactivate: got %+v", patched)
+	}
+	if s.users[1].SuspendedAt != nil {
+		t.Error("reactivate: want user to be unsuspended")
+	}
+	if len(s.revokedTokens) != 1 || len(s.invalidatedSessions) != 1 {
+		t.Errorf("reactivate: got revoked access tokens of users %v and invalidated sessions of users %v, want only the first deactivation", s.revokedTokens, s.invalidatedSessions)
+	}
+	wantAudit := []string{"user.set_active alice -active: true\n+active: false", "user.set_active alice -active: false\n+active: true"}
+	if len(s.auditActions) != len(wantAudit) || s.auditActions[0] != wantAudit[0] || s.auditActions[1] != wantAudit[1] {
+		t.Errorf("got audit log %q, want %q", s.auditActions, wantAudit)
+	}
+
+	// A user unsuspended on Sourcegraph is active in SCIM, and deactivating them again in the
+	// identity provider suspends them again.
+	if err := db.Users.SetIsSuspended(context.Background(), 1, true); err != nil {
+		t.Fatal(err)
+	}
+	doRequest(t, "GET", "/Users/1", "", &patched)
+	if patched.Active == nil || bool(*patched.Active) {
+		t.Errorf("suspended on Sourcegraph: got active %v, want false", patched.Active)
+	}
+	if err := db.Users.SetIsSuspended(context.Background(), 1, false); err != nil {
+		t.Fatal(err)
+	}
+	doRequest(t, "GET", "/Users/1", "", &patched)
+	if patched.Active == nil || !bool(*patched.Active) {
+		t.Errorf("unsuspended on Sourcegraph: got active %v, want true", patched.Active)
+	}
+	rec = doRequest(t, "PUT", "/Users/1", `{"userName": "alice@example.com", "externalId": "00u1", "displayName": "Alice", "active": false}`, &patched)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deactivate again: got status %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if s.users[1].SuspendedAt == nil {
+		t.Error("deactivate again: want user to be suspended")
+	}
+	if len(s.revokedTokens) != 2 || len(s.invalidatedSessions) != 2 {
+		t.Errorf("deactivate again: got revoked access tokens of users %v and invalidated sessions of users %v, want [1 1]", s.revokedTokens, s.invalidatedSessions)
+	}
+
+	// Delete the user.
+	if rec := doRequest(t, "DELETE", "/Users/1", "", nil); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: got status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if len(s.deleted) != 1 || s.deleted[0] != 1 {
+		t.Errorf("delete: got deleted users %v, want [1]", s.deleted)
+	}
+
+	if rec := doRequest(t, "GET", "/Users/2", "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("get nonexistent user: got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestCreateUser_inactive(t *testing.T) {
+	mockSCIMConfig(t)
+	s := newMockUserStore(t)
+	defer resetMocks()
+
+	rec := doRequest(t, "POST", "/Users", `{"userName": "bob", "active": false}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d (body %q)", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if got, want := s.accounts[1].AccountID, "bob"; got != want {
+		t.Errorf("got account ID %q, want %q", got, want)
+	}
+	if s.users[1].SuspendedAt == nil {
+		t.Error("want user to be suspended")
+	}
+	if len(s.revokedTokens) != 1 || len(s.invalidatedSessions) != 1 {
+		t.Errorf("got revoked access tokens of users %v and invalidated sessions of users %v, want [1]", s.revokedTokens, s.invalidatedSessions)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	_ "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
 	_ "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/registry"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/scim"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
 	campaignsResolvers "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns/resolvers"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/lsifserver/proxy"
@@ -38,6 +39,7 @@ func main() {
 	initLicensing()
 	initResolvers()
 	initLSIFEndpoints()
+	initSCIMEndpoints()
 
 	// Connect to the database.
 	if err := shared.InitDB(); err != nil {
@@ -75,6 +77,7 @@ func main() {
 	repositories := repos.NewDBStore(dbconn.Global, sql.TxOptions{})
 
 	githubWebhook := campaigns.NewGitHubWebhook(campaignsStore, repositories, clock)
+	gitlabWebhook := campaigns.NewGitLabWebhook(campaignsStore, repositories, clock)
 
 	bitbucketWebhookName := "sourcegraph-" + globalState.SiteID
 	bitbucketServerWebhook := campaigns.NewBitbucketServerWebhook(
@@ -86,7 +89,7 @@ func main() {
 
 	go bitbucketServerWebhook.Upsert(30 * time.Second)
 
-	shared.Main(githubWebhook, bitbucketServerWebhook)
+	shared.Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook)
 }
 
 func initLicensing() {
@@ -138,8 +141,13 @@ func initLSIFEndpoints() {
 	httpapi.NewLSIFServerProxy = proxy.NewProxy
 }
 
+func initSCIMEndpoints() {
+	httpapi.NewSCIMHandler = scim.NewHandler
+}
+
 type usersStore struct{}
 
 func (usersStore) Count(ctx context.Context) (int, error) {
-	return db.Users.Count(ctx, nil)
+	// Suspended users don't count toward the licensed user count.
+	return db.Users.Count(ctx, &db.UsersListOptions{ExcludeSuspended: true})
 }
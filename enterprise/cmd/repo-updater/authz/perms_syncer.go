@@ -163,6 +163,8 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32, noPerms b
 	}
 
 	var repoSpecs []api.ExternalRepoSpec
+	subRepoPerms := make(map[api.ExternalRepoSpec]*authz.SubRepoPermissions)
+	subRepoPermsServiceIDs := make(map[string]bool)
 	for _, acct := range accts {
 		provider := s.providers()[acct.ServiceID]
 		if provider == nil {
@@ -170,6 +172,23 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32, noPerms b
 			continue
 		}
 
+		// 🚨 SECURITY: Partial results are never used for sub-repository permissions because
+		// a missing entry grants access to the whole repository.
+		if sp, ok := provider.(authz.SubRepoPermsProvider); ok {
+			subRepoPermsServiceIDs[provider.ServiceID()] = true
+			perms, err := sp.FetchUserSubRepoPerms(ctx, acct)
+			if err != nil {
+				return errors.Wrap(err, "fetch user sub-repository permissions")
+			}
+			for extID, p := range perms {
+				subRepoPerms[api.ExternalRepoSpec{
+					ID:          string(extID),
+					ServiceType: provider.ServiceType(),
+					ServiceID:   provider.ServiceID(),
+				}] = p
+			}
+		}
+
 		extIDs, err := provider.FetchUserPerms(ctx, acct)
 		if err != nil {
 			// Process partial results if this is an initial fetch.
@@ -200,6 +219,26 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32, noPerms b
 		}
 	}
 
+	// Save sub-repository permissions to database before repository permissions. Repositories
+	// on a code host with sub-repository permissions are not readable at all without an entry
+	// (see db.SubRepoPathMatchers), which is the case when access to them is granted by
+	// syncRepoPerms or GrantPendingPermissions, so every such repository gets an entry here:
+	// the one fetched from the code host, or one that matches every path.
+	if len(subRepoPermsServiceIDs) > 0 {
+		perms := make(map[api.RepoID]*authz.SubRepoPermissions)
+		for i := range rs {
+			if p, ok := subRepoPerms[rs[i].ExternalRepo]; ok {
+				perms[rs[i].ID] = p
+			} else if subRepoPermsServiceIDs[rs[i].ExternalRepo.ServiceID] {
+				perms[rs[i].ID] = &authz.SubRepoPermissions{PathIncludes: []string{"**"}}
+			}
+		}
+		err = s.permsStore.SetUserSubRepoPermissions(ctx, userID, perms)
+		if err != nil {
+			return errors.Wrap(err, "set user sub-repository permissions")
+		}
+	}
+
 	// Save permissions to database
 	p := &authz.UserPermissions{
 		UserID: userID,
@@ -286,6 +325,10 @@ func (s *PermsSyncer) syncRepoPerms(ctx context.Context, repoID api.RepoID, noPe
 	}
 
 	// Save permissions to database
+	//
+	// NOTE: Sub-repository permissions can only be fetched per user, so on code hosts with
+	// sub-repository permissions, users who gain access to the repository here can't read
+	// any of its paths until their permissions are synced by syncUserPerms.
 	p := &authz.RepoPermissions{
 		RepoID:  int32(repoID),
 		Perm:    authz.Read, // Note: We currently only support read for repository permissions.
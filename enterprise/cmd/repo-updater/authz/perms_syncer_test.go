@@ -196,6 +196,103 @@ func TestPermsSyncer_syncUserPerms(t *testing.T) {
 	}
 }
 
+type mockSubRepoPermsProvider struct {
+	*mockProvider
+
+	fetchUserSubRepoPerms func(context.Context, *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error)
+}
+
+func (p *mockSubRepoPermsProvider) FetchUserSubRepoPerms(ctx context.Context, acct *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error) {
+	return p.fetchUserSubRepoPerms(ctx, acct)
+}
+
+func TestPermsSyncer_syncUserPerms_subRepoPerms(t *testing.T) {
+	p := &mockSubRepoPermsProvider{
+		mockProvider: &mockProvider{
+			serviceType: gitlab.ServiceType,
+			serviceID:   "https://gitlab.com/",
+			fetchUserPerms: func(context.Context, *extsvc.Account) ([]extsvc.RepoID, error) {
+				return []extsvc.RepoID{"1", "2"}, nil
+			},
+		},
+	}
+	authz.SetProviders(false, []authz.Provider{p})
+	defer authz.SetProviders(true, nil)
+
+	edb.Mocks.Perms.ListExternalAccounts = func(context.Context, int32) ([]*extsvc.Account, error) {
+		return []*extsvc.Account{{
+			AccountSpec: extsvc.AccountSpec{
+				ServiceType: p.ServiceType(),
+				ServiceID:   p.ServiceID(),
+			},
+		}}, nil
+	}
+	var calls []string
+	edb.Mocks.Perms.SetUserSubRepoPermissions = func(_ context.Context, userID int32, perms map[api.RepoID]*authz.SubRepoPermissions) error {
+		calls = append(calls, "SetUserSubRepoPermissions")
+		want := map[api.RepoID]*authz.SubRepoPermissions{
+			10: {PathIncludes: []string{"**"}}, // readable in full
+			20: {PathIncludes: []string{"docs/**"}},
+		}
+		if diff := cmp.Diff(want, perms); diff != "" {
+			return fmt.Errorf("perms: %v", diff)
+		}
+		return nil
+	}
+	edb.Mocks.Perms.SetUserPermissions = func(context.Context, *authz.UserPermissions) error {
+		calls = append(calls, "SetUserPermissions")
+		return nil
+	}
+	defer func() {
+		edb.Mocks.Perms = edb.MockPerms{}
+	}()
+
+	reposStore := &mockReposStore{
+		listRepos: func(_ context.Context, args repos.StoreListReposArgs) ([]*repos.Repo, error) {
+			rs := make([]*repos.Repo, len(args.ExternalRepos))
+			for i, spec := range args.ExternalRepos {
+				rs[i] = &repos.Repo{ID: api.RepoID(10 * (i + 1)), ExternalRepo: spec}
+			}
+			return rs, nil
+		},
+	}
+	clock := func() time.Time {
+		return time.Now().UTC().Truncate(time.Microsecond)
+	}
+	s := NewPermsSyncer(reposStore, edb.NewPermsStore(nil, clock), clock)
+	s.metrics.syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{}, []string{"type", "success"})
+	s.metrics.syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"type"})
+
+	t.Run("success", func(t *testing.T) {
+		calls = nil
+		p.fetchUserSubRepoPerms = func(context.Context, *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error) {
+			return map[extsvc.RepoID]*authz.SubRepoPermissions{
+				"2": {PathIncludes: []string{"docs/**"}},
+				"3": {PathIncludes: []string{"src/**"}}, // not readable by the user
+			}, nil
+		}
+		if err := s.syncUserPerms(context.Background(), 1, false); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"SetUserSubRepoPermissions", "SetUserPermissions"}, calls); diff != "" {
+			t.Fatalf("calls: %v", diff)
+		}
+	})
+
+	t.Run("error is not ignored on first sync", func(t *testing.T) {
+		calls = nil
+		p.fetchUserSubRepoPerms = func(context.Context, *extsvc.Account) (map[extsvc.RepoID]*authz.SubRepoPermissions, error) {
+			return nil, errors.New("random error")
+		}
+		if err := s.syncUserPerms(context.Background(), 1, true); err == nil {
+			t.Fatal("got nil error")
+		}
+		if len(calls) != 0 {
+			t.Fatalf("got calls %v, want none", calls)
+		}
+	})
+}
+
 func TestPermsSyncer_syncRepoPerms(t *testing.T) {
 	p := &mockProvider{
 		serviceType: gitlab.ServiceType,
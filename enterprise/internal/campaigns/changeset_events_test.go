@@ -312,6 +312,27 @@ func TestChangesetEventsState(t *testing.T) {
 			},
 			want: cmpgn.ChangesetStateMerged,
 		},
+		{
+			sortedEvents: ChangesetEvents{
+				{Kind: cmpgn.ChangesetEventKindGitLabClosed},
+				{Kind: cmpgn.ChangesetEventKindGitLabReopened},
+			},
+			want: cmpgn.ChangesetStateOpen,
+		},
+		{
+			sortedEvents: ChangesetEvents{
+				{Kind: cmpgn.ChangesetEventKindGitLabClosed},
+			},
+			want: cmpgn.ChangesetStateClosed,
+		},
+		{
+			sortedEvents: ChangesetEvents{
+				{Kind: cmpgn.ChangesetEventKindGitLabMerged},
+				// Merged is a final state. Events after should be ignored.
+				{Kind: cmpgn.ChangesetEventKindGitLabClosed},
+			},
+			want: cmpgn.ChangesetStateMerged,
+		},
 		{
 			sortedEvents: ChangesetEvents{
 				// GitHub emits Closed and Merged events at the same time.
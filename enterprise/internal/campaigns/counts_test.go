@@ -9,6 +9,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 func TestCalcCounts(t *testing.T) {
@@ -1182,6 +1183,24 @@ func TestCalcCounts(t *testing.T) {
 				{Time: daysAgo(0), Total: 1, Open: 1, OpenPending: 1},
 			},
 		},
+		{
+			codehosts: "gitlab",
+			name:      "single changeset closed, reopened",
+			changesets: []*campaigns.Changeset{
+				glChangeset(1, daysAgo(3)),
+			},
+			start: daysAgo(3),
+			events: []Event{
+				fakeEvent{t: daysAgo(2), kind: campaigns.ChangesetEventKindGitLabClosed, id: 1},
+				fakeEvent{t: daysAgo(1), kind: campaigns.ChangesetEventKindGitLabReopened, id: 1},
+			},
+			want: []*ChangesetCounts{
+				{Time: daysAgo(3), Total: 1, Open: 1, OpenPending: 1},
+				{Time: daysAgo(2), Total: 1, Open: 0, Closed: 1},
+				{Time: daysAgo(1), Total: 1, Open: 1, OpenPending: 1},
+				{Time: daysAgo(0), Total: 1, Open: 1, OpenPending: 1},
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -1226,6 +1245,10 @@ func bbsChangeset(id int64, t time.Time) *campaigns.Changeset {
 	}
 }
 
+func glChangeset(id int64, t time.Time) *campaigns.Changeset {
+	return &campaigns.Changeset{ID: id, Metadata: &gitlab.MergeRequest{CreatedAt: t}}
+}
+
 func setExternalDeletedAt(c *campaigns.Changeset, t time.Time) *campaigns.Changeset {
 	c.SetDeleted()
 	c.ExternalDeletedAt = t
@@ -11,6 +11,7 @@ import (
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // SetDerivedState will update the external state fields on the Changeset based
@@ -43,6 +44,9 @@ func ComputeCheckState(c *cmpgn.Changeset, events ChangesetEvents) cmpgn.Changes
 
 	case *bitbucketserver.PullRequest:
 		return computeBitbucketBuildStatus(c.UpdatedAt, m, events)
+
+	case *gitlab.MergeRequest:
+		return computeGitLabPipelineState(c.UpdatedAt, m, events)
 	}
 
 	return cmpgn.ChangesetCheckStateUnknown
@@ -134,6 +138,65 @@ func parseBitbucketBuildState(s string) cmpgn.ChangesetCheckState {
 	}
 }
 
+func computeGitLabPipelineState(lastSynced time.Time, mr *gitlab.MergeRequest, events []*cmpgn.ChangesetEvent) cmpgn.ChangesetCheckState {
+	pipelines := make(map[int]*gitlab.Pipeline, len(mr.Pipelines)+1)
+
+	// Pipelines from last sync
+	for _, p := range mr.Pipelines {
+		pipelines[p.ID] = p
+	}
+	if mr.HeadPipeline != nil {
+		pipelines[mr.HeadPipeline.ID] = mr.HeadPipeline
+	}
+
+	// Add any events we've received since our last sync
+	for _, e := range events {
+		switch m := e.Metadata.(type) {
+		case *gitlab.Pipeline:
+			if e.Timestamp().Before(lastSynced) {
+				continue
+			}
+			pipelines[m.ID] = m
+		}
+	}
+
+	// Only the most recent pipeline for the head commit of the merge request
+	// determines its state, just like in the GitLab UI.
+	var latest *gitlab.Pipeline
+	for _, p := range pipelines {
+		if mr.SHA != "" && p.SHA != mr.SHA {
+			continue
+		}
+		if latest == nil || latest.ID < p.ID {
+			latest = p
+		}
+	}
+
+	if latest == nil {
+		return cmpgn.ChangesetCheckStateUnknown
+	}
+	return parseGitLabPipelineStatus(latest.Status)
+}
+
+func parseGitLabPipelineStatus(s gitlab.PipelineStatus) cmpgn.ChangesetCheckState {
+	switch s {
+	case gitlab.PipelineStatusSuccess:
+		return cmpgn.ChangesetCheckStatePassed
+	case gitlab.PipelineStatusFailed, gitlab.PipelineStatusCanceled:
+		return cmpgn.ChangesetCheckStateFailed
+	case gitlab.PipelineStatusCreated,
+		gitlab.PipelineStatusWaitingForResource,
+		gitlab.PipelineStatusPreparing,
+		gitlab.PipelineStatusPending,
+		gitlab.PipelineStatusRunning,
+		gitlab.PipelineStatusManual,
+		gitlab.PipelineStatusScheduled:
+		return cmpgn.ChangesetCheckStatePending
+	default:
+		return cmpgn.ChangesetCheckStateUnknown
+	}
+}
+
 func computeGitHubCheckState(lastSynced time.Time, pr *github.PullRequest, events []*cmpgn.ChangesetEvent) cmpgn.ChangesetCheckState {
 	// We should only consider the latest commit. This could be from a sync or a webhook that
 	// has occurred later
@@ -306,6 +369,8 @@ func computeSingleChangesetState(c *cmpgn.Changeset) (s cmpgn.ChangesetState, er
 		} else {
 			s = cmpgn.ChangesetState(m.State)
 		}
+	case *gitlab.MergeRequest:
+		s = cmpgn.GitLabMergeRequestState(m.State)
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -342,6 +407,22 @@ func computeSingleChangesetReviewState(c *cmpgn.Changeset) (s cmpgn.ChangesetRev
 				states[cmpgn.ChangesetReviewStateApproved] = true
 			}
 		}
+
+	case *gitlab.MergeRequest:
+		// GitLab only exposes approvals through system notes, so we replay
+		// them to find out who currently approves the merge request.
+		approvedBy := map[string]bool{}
+		for _, n := range m.Notes {
+			switch n.Action() {
+			case gitlab.NoteActionApproved:
+				approvedBy[n.Author.Username] = true
+			case gitlab.NoteActionUnapproved:
+				delete(approvedBy, n.Author.Username)
+			}
+		}
+		if len(approvedBy) > 0 {
+			states[cmpgn.ChangesetReviewStateApproved] = true
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
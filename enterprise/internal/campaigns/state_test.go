@@ -8,6 +8,7 @@ import (
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 func TestComputeGithubCheckState(t *testing.T) {
@@ -258,3 +259,86 @@ func TestComputeBitbucketBuildStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeGitLabPipelineState(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	sha := "abcdef"
+	lastSynced := now.Add(-1 * time.Minute)
+
+	pipeline := func(id int, sha string, status gitlab.PipelineStatus) *gitlab.Pipeline {
+		return &gitlab.Pipeline{
+			ID:        id,
+			SHA:       sha,
+			Status:    status,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	pipelineEvent := func(p *gitlab.Pipeline) *cmpgn.ChangesetEvent {
+		return &cmpgn.ChangesetEvent{
+			Kind:     cmpgn.ChangesetEventKindGitLabPipeline,
+			Metadata: p,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pipelines []*gitlab.Pipeline
+		events    []*cmpgn.ChangesetEvent
+		want      cmpgn.ChangesetCheckState
+	}{
+		{
+			name: "no pipelines",
+			want: cmpgn.ChangesetCheckStateUnknown,
+		},
+		{
+			name:      "single success",
+			pipelines: []*gitlab.Pipeline{pipeline(1, sha, gitlab.PipelineStatusSuccess)},
+			want:      cmpgn.ChangesetCheckStatePassed,
+		},
+		{
+			name:      "single running",
+			pipelines: []*gitlab.Pipeline{pipeline(1, sha, gitlab.PipelineStatusRunning)},
+			want:      cmpgn.ChangesetCheckStatePending,
+		},
+		{
+			name:      "single failed",
+			pipelines: []*gitlab.Pipeline{pipeline(1, sha, gitlab.PipelineStatusFailed)},
+			want:      cmpgn.ChangesetCheckStateFailed,
+		},
+		{
+			name: "latest pipeline wins",
+			pipelines: []*gitlab.Pipeline{
+				pipeline(1, sha, gitlab.PipelineStatusFailed),
+				pipeline(2, sha, gitlab.PipelineStatusSuccess),
+			},
+			want: cmpgn.ChangesetCheckStatePassed,
+		},
+		{
+			name: "pipelines for other commits are ignored",
+			pipelines: []*gitlab.Pipeline{
+				pipeline(1, sha, gitlab.PipelineStatusSuccess),
+				pipeline(2, "123456", gitlab.PipelineStatusFailed),
+			},
+			want: cmpgn.ChangesetCheckStatePassed,
+		},
+		{
+			name:      "events since last sync have precedence",
+			pipelines: []*gitlab.Pipeline{pipeline(1, sha, gitlab.PipelineStatusRunning)},
+			events: []*cmpgn.ChangesetEvent{
+				pipelineEvent(pipeline(1, sha, gitlab.PipelineStatusSuccess)),
+			},
+			want: cmpgn.ChangesetCheckStatePassed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := &gitlab.MergeRequest{SHA: sha, Pipelines: tc.pipelines}
+			have := computeGitLabPipelineState(lastSynced, mr, tc.events)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
@@ -79,7 +79,7 @@ func (s *SyncRegistry) Add(extServiceID int64) {
 	service := services[0]
 
 	switch service.Kind {
-	case "GITHUB", "BITBUCKETSERVER":
+	case "GITHUB", "BITBUCKETSERVER", "GITLAB":
 	// Supported by campaigns
 	default:
 		log15.Warn("Syncer not started for unsupported code host", "kind", service.Kind)
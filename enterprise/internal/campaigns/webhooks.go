@@ -2,6 +2,7 @@ package campaigns
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -22,6 +23,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	bbs "github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -79,6 +82,8 @@ func extractExternalServiceID(extSvc *repos.ExternalService) (string, error) {
 		serviceID = c.Url
 	case *schema.BitbucketServerConnection:
 		serviceID = c.Url
+	case *schema.GitLabConnection:
+		serviceID = c.Url
 	}
 	if serviceID == "" {
 		return "", errors.New("could not determine service id")
@@ -184,6 +189,15 @@ type BitbucketServerWebhook struct {
 	Name string
 }
 
+// GitLabWebhook receives GitLab project and group webhook events that are
+// relevant to campaigns, normalizes those events into ChangesetEvents and
+// upserts them to the database. Every changeset affected by an event is also
+// enqueued for a priority sync, since GitLab webhook payloads don't contain
+// everything we need to compute the state of a changeset.
+type GitLabWebhook struct {
+	*Webhook
+}
+
 func NewGitHubWebhook(store *Store, repos repos.Store, now func() time.Time) *GitHubWebhook {
 	return &GitHubWebhook{&Webhook{store, repos, now, github.ServiceType}}
 }
@@ -195,6 +209,10 @@ func NewBitbucketServerWebhook(store *Store, repos repos.Store, now func() time.
 	}
 }
 
+func NewGitLabWebhook(store *Store, repos repos.Store, now func() time.Time) *GitLabWebhook {
+	return &GitLabWebhook{&Webhook{store, repos, now, gitlab.ServiceType}}
+}
+
 // ServeHTTP implements the http.Handler interface.
 func (h *GitHubWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	e, extSvc, httpErr := h.parseEvent(r)
@@ -902,6 +920,206 @@ func (h *BitbucketServerWebhook) convertEvent(theirs interface{}) (prs []PR, our
 	return
 }
 
+// ServeHTTP implements the http.Handler interface.
+func (h *GitLabWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, extSvc, httpErr := h.parseEvent(r)
+	if httpErr != nil {
+		respond(w, httpErr.code, httpErr)
+		return
+	}
+	if e == nil {
+		respond(w, http.StatusOK, nil) // Nothing to do
+		return
+	}
+
+	externalServiceID, err := extractExternalServiceID(extSvc)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	prs, ev := h.convertEvent(r.Context(), externalServiceID, e)
+	if len(prs) == 0 {
+		respond(w, http.StatusOK, nil) // Nothing to do
+		return
+	}
+
+	m := new(multierror.Error)
+	if ev != nil {
+		for _, pr := range prs {
+			err := h.upsertChangesetEvent(r.Context(), externalServiceID, pr, ev)
+			if err != nil {
+				m = multierror.Append(m, err)
+			}
+		}
+	}
+	if m.ErrorOrNil() != nil {
+		respond(w, http.StatusInternalServerError, m)
+		return
+	}
+
+	// Failing to enqueue the syncs isn't fatal: the changesets will still be
+	// synced by the regular background syncing, so we don't want GitLab to
+	// retry the delivery.
+	if err := h.enqueueChangesetSyncs(r.Context(), externalServiceID, prs); err != nil {
+		log15.Error("Enqueueing GitLab changeset syncs failed", "err", err)
+	}
+}
+
+func (h *GitLabWebhook) parseEvent(r *http.Request) (interface{}, *repos.ExternalService, *httpError) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	// 🚨 SECURITY: GitLab doesn't sign its payloads, but sends the configured
+	// secret token verbatim. Try to authenticate the request with any of the
+	// stored secrets in GitLab external services config, and return a 401 to
+	// the client if there are no secrets or none of them match.
+	args := repos.StoreListExternalServicesArgs{Kinds: []string{"GITLAB"}}
+	es, err := h.Repos.ListExternalServices(r.Context(), args)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	token := []byte(gitlab.WebhookToken(r))
+
+	var extSvc *repos.ExternalService
+	for _, e := range es {
+		c, _ := e.Configuration()
+		con, ok := c.(*schema.GitLabConnection)
+		if !ok {
+			continue
+		}
+
+		for _, hook := range con.Webhooks {
+			if hook.Secret == "" {
+				continue
+			}
+
+			if subtle.ConstantTimeCompare(token, []byte(hook.Secret)) == 1 {
+				extSvc = e
+				break
+			}
+		}
+		if extSvc != nil {
+			break
+		}
+	}
+
+	if extSvc == nil {
+		return nil, nil, &httpError{http.StatusUnauthorized, errors.New("invalid GitLab webhook token")}
+	}
+
+	e, err := gitlab.ParseWebhookEvent(gitlab.WebhookEventType(r), payload)
+	if err == gitlab.ErrUnknownWebhookEvent {
+		return nil, extSvc, nil
+	} else if err != nil {
+		return nil, nil, &httpError{http.StatusBadRequest, err}
+	}
+
+	return e, extSvc, nil
+}
+
+func (h *GitLabWebhook) convertEvent(ctx context.Context, externalServiceID string, theirs interface{}) (prs []PR, ours interface{ Key() string }) {
+	log15.Debug("GitLab webhook received", "type", fmt.Sprintf("%T", theirs))
+
+	switch e := theirs.(type) {
+	case *gitlab.MergeRequestEvent:
+		// The payload doesn't contain the system note GitLab records for the
+		// change, so we only enqueue a sync of the merge request. The sync
+		// loads the note along with its ID, which keeps the event from being
+		// stored twice.
+		prs = append(prs, gitLabPR(&e.ObjectAttributes.WebhookMergeRequest, e.Project))
+
+	case *gitlab.NoteEvent:
+		note := e.Note()
+		if note == nil {
+			return nil, nil
+		}
+		prs = append(prs, gitLabPR(e.MergeRequest, e.Project))
+		if note.Action() != "" {
+			ours = note
+		}
+
+	case *gitlab.PipelineEvent:
+		if e.MergeRequest != nil {
+			prs = append(prs, gitLabPR(e.MergeRequest, e.Project))
+			return prs, e.Pipeline(h.Now())
+		}
+
+		// Branch pipelines don't reference a merge request, so we have to
+		// look up the changesets by their branch instead.
+		repoExternalID := strconv.Itoa(e.Project.ID)
+		spec := api.ExternalRepoSpec{
+			ID:          repoExternalID,
+			ServiceID:   externalServiceID,
+			ServiceType: gitlab.ServiceType,
+		}
+
+		ids, err := h.Store.GetChangesetExternalIDs(ctx, spec, []string{e.ObjectAttributes.Ref})
+		if err != nil {
+			log15.Error("Error executing GetChangesetExternalIDs", "err", err)
+			return nil, nil
+		}
+
+		for _, id := range ids {
+			i, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				log15.Error("Error parsing external id", "err", err)
+				continue
+			}
+			prs = append(prs, PR{ID: i, RepoExternalID: repoExternalID})
+		}
+		if len(prs) > 0 {
+			ours = e.Pipeline(h.Now())
+		}
+	}
+
+	return
+}
+
+// gitLabPR returns the PR for the given merge request. Campaigns create merge
+// requests in the project they are targeting, which is the project we store
+// the changeset for.
+func gitLabPR(mr *gitlab.WebhookMergeRequest, project gitlab.WebhookProject) PR {
+	projectID := mr.TargetProjectID
+	if projectID == 0 {
+		projectID = project.ID
+	}
+	return PR{ID: int64(mr.IID), RepoExternalID: strconv.Itoa(projectID)}
+}
+
+// enqueueChangesetSyncs enqueues the changesets of the given PRs for a
+// priority sync.
+func (h *GitLabWebhook) enqueueChangesetSyncs(ctx context.Context, externalServiceID string, prs []PR) error {
+	ids := make([]int64, 0, len(prs))
+	for _, pr := range prs {
+		r, err := h.getRepoForPR(ctx, h.Store, pr, externalServiceID)
+		if err != nil {
+			log15.Debug("Webhook event could not be matched to repo", "err", err)
+			continue
+		}
+
+		cs, err := h.Store.GetChangeset(ctx, GetChangesetOpts{
+			RepoID:              r.ID,
+			ExternalID:          strconv.FormatInt(pr.ID, 10),
+			ExternalServiceType: h.ServiceType,
+		})
+		if err == ErrNoResults {
+			continue
+		} else if err != nil {
+			return err
+		}
+		ids = append(ids, cs.ID)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return repoupdater.DefaultClient.EnqueueChangesetSync(ctx, ids)
+}
+
 type httpError struct {
 	code int
 	err  error
@@ -25,6 +25,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/httptestutil"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
@@ -269,6 +270,166 @@ func testGitHubWebhook(db *sql.DB) func(*testing.T) {
 	}
 }
 
+func TestGitLabWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	repoStore := new(repos.FakeStore)
+	err := repoStore.UpsertExternalServices(ctx, &repos.ExternalService{
+		Kind:        "GITLAB",
+		DisplayName: "GitLab",
+		Config:      `{"url": "https://gitlab.com", "token": "abc", "projectQuery": ["none"], "webhooks": [{"secret": "secret"}]}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	hook := NewGitLabWebhook(nil, repoStore, func() time.Time { return now })
+
+	approved := `{
+		"object_kind": "merge_request",
+		"user": {"id": 1, "username": "alice"},
+		"project": {"id": 5, "path_with_namespace": "sourcegraph/sourcegraph"},
+		"object_attributes": {
+			"id": 100,
+			"iid": 2,
+			"source_project_id": 5,
+			"target_project_id": 5,
+			"action": "approved",
+			"updated_at": "2020-04-01 10:00:00 UTC"
+		}
+	}`
+
+	t.Run("parseEvent", func(t *testing.T) {
+		for _, tc := range []struct {
+			name      string
+			token     string
+			eventType string
+			code      int
+			wantEvent bool
+		}{
+			{name: "unauthorized", token: "wrong-secret", eventType: "Merge Request Hook", code: http.StatusUnauthorized},
+			{name: "no token", eventType: "Merge Request Hook", code: http.StatusUnauthorized},
+			{name: "unknown event", token: "secret", eventType: "Push Hook"},
+			{name: "merge request", token: "secret", eventType: "Merge Request Hook", wantEvent: true},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				req, err := http.NewRequest("POST", "", strings.NewReader(approved))
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("X-Gitlab-Event", tc.eventType)
+				if tc.token != "" {
+					req.Header.Set("X-Gitlab-Token", tc.token)
+				}
+
+				e, extSvc, httpErr := hook.parseEvent(req)
+				if tc.code != 0 {
+					if httpErr == nil || httpErr.code != tc.code {
+						t.Fatalf("have error %v, want status code %d", httpErr, tc.code)
+					}
+					return
+				}
+				if httpErr != nil {
+					t.Fatal(httpErr)
+				}
+				if extSvc == nil {
+					t.Fatal("no external service returned")
+				}
+				if have, want := e != nil, tc.wantEvent; have != want {
+					t.Fatalf("have event %T, want event: %t", e, want)
+				}
+			})
+		}
+	})
+
+	t.Run("convertEvent", func(t *testing.T) {
+		mergeRequest := &gitlab.WebhookMergeRequest{IID: 2, SourceProjectID: 5, TargetProjectID: 5}
+		wantPRs := []PR{{ID: 2, RepoExternalID: "5"}}
+
+		noteEvent := &gitlab.NoteEvent{User: gitlab.User{Username: "bob"}, MergeRequest: mergeRequest}
+		noteEvent.ObjectAttributes.ID = 42
+		noteEvent.ObjectAttributes.Note = "LGTM"
+		noteEvent.ObjectAttributes.NoteableType = "MergeRequest"
+
+		issueNoteEvent := &gitlab.NoteEvent{}
+		issueNoteEvent.ObjectAttributes.NoteableType = "Issue"
+
+		pipelineEvent := &gitlab.PipelineEvent{MergeRequest: mergeRequest}
+		pipelineEvent.ObjectAttributes.ID = 7
+		pipelineEvent.ObjectAttributes.Status = gitlab.PipelineStatusRunning
+
+		updatedEvent := &gitlab.MergeRequestEvent{}
+		updatedEvent.ObjectAttributes.WebhookMergeRequest = *mergeRequest
+		updatedEvent.ObjectAttributes.Action = "update"
+
+		approvedEvent, err := gitlab.ParseWebhookEvent("Merge Request Hook", []byte(approved))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			name     string
+			event    interface{}
+			wantPRs  []PR
+			wantKind campaigns.ChangesetEventKind
+			wantKey  string
+		}{
+			{
+				name:    "approved",
+				event:   approvedEvent,
+				wantPRs: wantPRs,
+			},
+			{
+				name:    "updated",
+				event:   updatedEvent,
+				wantPRs: wantPRs,
+			},
+			{
+				name:     "comment",
+				event:    noteEvent,
+				wantPRs:  wantPRs,
+				wantKind: campaigns.ChangesetEventKindGitLabCommented,
+				wantKey:  "42",
+			},
+			{
+				name:  "issue comment",
+				event: issueNoteEvent,
+			},
+			{
+				name:     "merge request pipeline",
+				event:    pipelineEvent,
+				wantPRs:  wantPRs,
+				wantKind: campaigns.ChangesetEventKindGitLabPipeline,
+				wantKey:  "7",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				prs, ev := hook.convertEvent(ctx, "https://gitlab.com/", tc.event)
+				if diff := cmp.Diff(prs, tc.wantPRs); diff != "" {
+					t.Errorf("unexpected PRs: %s", diff)
+				}
+
+				if tc.wantKind == "" {
+					if ev != nil {
+						t.Errorf("have event %+v, want none", ev)
+					}
+					return
+				}
+				if ev == nil {
+					t.Fatal("no event returned")
+				}
+				if have, want := campaigns.ChangesetEventKindFor(ev), tc.wantKind; have != want {
+					t.Errorf("have kind %q, want %q", have, want)
+				}
+				if have, want := ev.Key(), tc.wantKey; have != want {
+					t.Errorf("have key %q, want %q", have, want)
+				}
+			})
+		}
+	})
+}
+
 type event struct {
 	name  string
 	event interface{}
@@ -218,6 +218,10 @@ func ExecChangesetJob(
 				if cfg.Token != "" {
 					externalService = e
 				}
+			case *schema.GitLabConnection:
+				if cfg.Token != "" {
+					externalService = e
+				}
 			}
 			if externalService != nil {
 				break
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -37,6 +38,13 @@ func uploadProxyHandler() func(http.ResponseWriter, *http.Request) {
 		indexerName := q.Get("indexerName")
 		ctx := r.Context()
 
+		// 🚨 SECURITY: Ensure that access tokens with fine-grained scopes can upload LSIF data
+		// only if they have the "codeintel:upload" scope.
+		if err := backend.CheckActorHasScope(ctx, authz.ScopeCodeIntelUpload); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
 		repo, ok := ensureRepoAndCommitExist(ctx, w, repoName, commit)
 		if !ok {
 			return
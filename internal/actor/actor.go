@@ -24,6 +24,11 @@ type Actor struct {
 	// to selectively display a logout link. (If the actor wasn't authenticated with a session
 	// cookie, logout would be ineffective.)
 	FromSessionCookie bool `json:"-"`
+
+	// AccessTokenScopes are the scopes of the access token that was used to authenticate the
+	// actor, or nil if the actor wasn't authenticated with an access token. Access token
+	// authenticated actors may only perform the actions that these scopes grant.
+	AccessTokenScopes []string `json:"-"`
 }
 
 // FromUser returns an actor corresponding to a user
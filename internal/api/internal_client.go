@@ -100,6 +100,9 @@ type ConfigSavedQuery struct {
 	UserID          *int32  `json:"userID"`
 	OrgID           *int32  `json:"orgID"`
 	SlackWebhookURL *string `json:"slackWebhookURL"`
+	NotifyWebhook   bool    `json:"notifyWebhook,omitempty"`
+	WebhookURL      *string `json:"webhookURL"`
+	WebhookSecret   *string `json:"webhookSecret"`
 }
 
 func (sq ConfigSavedQuery) Equals(other ConfigSavedQuery) bool {
@@ -150,6 +153,11 @@ type SavedQueryInfo struct {
 
 	// ExecDuration is the amount of time it took for the query to execute.
 	ExecDuration time.Duration
+
+	// ResultFingerprints are the fingerprints of the matches of the last
+	// execution of a code search query, which can't be restricted to new
+	// results with `after:`. It is nil if they are not known.
+	ResultFingerprints []string
 }
 
 // SavedQueriesGetInfo gets the info from the DB for the given saved query. nil
@@ -13,6 +13,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // SupportedExternalServices are the external service types currently supported
@@ -22,6 +23,7 @@ import (
 var SupportedExternalServices = map[string]struct{}{
 	github.ServiceType:          {},
 	bitbucketserver.ServiceType: {},
+	gitlab.ServiceType:          {},
 }
 
 // IsRepoSupported returns whether the given ExternalRepoSpec is supported by
@@ -305,6 +307,12 @@ func (c *Changeset) SetMetadata(meta interface{}) error {
 		c.ExternalServiceType = bitbucketserver.ServiceType
 		c.ExternalBranch = git.AbbreviateRef(pr.FromRef.ID)
 		c.ExternalUpdatedAt = unixMilliToTime(int64(pr.UpdatedDate))
+	case *gitlab.MergeRequest:
+		c.Metadata = pr
+		c.ExternalID = strconv.Itoa(pr.IID)
+		c.ExternalServiceType = gitlab.ServiceType
+		c.ExternalBranch = pr.SourceBranch
+		c.ExternalUpdatedAt = pr.UpdatedAt
 	default:
 		return errors.New("unknown changeset type")
 	}
@@ -328,6 +336,8 @@ func (c *Changeset) Title() (string, error) {
 		return m.Title, nil
 	case *bitbucketserver.PullRequest:
 		return m.Title, nil
+	case *gitlab.MergeRequest:
+		return m.Title, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -342,6 +352,8 @@ func (c *Changeset) ExternalCreatedAt() time.Time {
 		return m.CreatedAt
 	case *bitbucketserver.PullRequest:
 		return unixMilliToTime(int64(m.CreatedDate))
+	case *gitlab.MergeRequest:
+		return m.CreatedAt
 	default:
 		return time.Time{}
 	}
@@ -354,6 +366,8 @@ func (c *Changeset) Body() (string, error) {
 		return m.Body, nil
 	case *bitbucketserver.PullRequest:
 		return m.Description, nil
+	case *gitlab.MergeRequest:
+		return m.Description, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -387,6 +401,8 @@ func (c *Changeset) state() (s ChangesetState, err error) {
 		} else {
 			s = ChangesetState(m.State)
 		}
+	case *gitlab.MergeRequest:
+		s = GitLabMergeRequestState(m.State)
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -409,6 +425,8 @@ func (c *Changeset) URL() (s string, err error) {
 		}
 		selfLink := m.Links.Self[0]
 		return selfLink.Href, nil
+	case *gitlab.MergeRequest:
+		return m.WebURL, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -461,6 +479,27 @@ func (c *Changeset) Events() (events []*ChangesetEvent) {
 			addEvent(s)
 		}
 
+	case *gitlab.MergeRequest:
+		events = make([]*ChangesetEvent, 0, len(m.Notes)+len(m.Pipelines))
+		addEvent := func(e Keyer) {
+			events = append(events, &ChangesetEvent{
+				ChangesetID: c.ID,
+				Key:         e.Key(),
+				Kind:        ChangesetEventKindFor(e),
+				Metadata:    e,
+			})
+		}
+		for _, n := range m.Notes {
+			// System notes we don't track, such as pushed commits, are
+			// skipped.
+			if n.Action() == "" {
+				continue
+			}
+			addEvent(n)
+		}
+		for _, p := range m.Pipelines {
+			addEvent(p)
+		}
 	}
 	return events
 }
@@ -474,6 +513,8 @@ func (c *Changeset) HeadRefOid() (string, error) {
 		return m.HeadRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.DiffRefs.HeadSHA, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -487,6 +528,8 @@ func (c *Changeset) HeadRef() (string, error) {
 		return "refs/heads/" + m.HeadRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.FromRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.SourceBranch, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -501,6 +544,8 @@ func (c *Changeset) BaseRefOid() (string, error) {
 		return m.BaseRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.DiffRefs.BaseSHA, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -514,6 +559,8 @@ func (c *Changeset) BaseRef() (string, error) {
 		return "refs/heads/" + m.BaseRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.ToRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.TargetBranch, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -531,6 +578,12 @@ func (c *Changeset) Labels() []ChangesetLabel {
 			}
 		}
 		return labels
+	case *gitlab.MergeRequest:
+		labels := make([]ChangesetLabel, len(m.Labels))
+		for i, l := range m.Labels {
+			labels[i] = ChangesetLabel{Name: l}
+		}
+		return labels
 	default:
 		return []ChangesetLabel{}
 	}
@@ -585,6 +638,8 @@ func (e *ChangesetEvent) Actor() string {
 		a = e.Actor.Login
 	case *github.LabelEvent:
 		a = e.Actor.Login
+	case *gitlab.Note:
+		a = e.Author.Username
 	}
 
 	return a
@@ -613,6 +668,16 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 			return "", errors.New("activity user is blank")
 		}
 		return username, nil
+
+	case *gitlab.Note:
+		if meta.Action() != gitlab.NoteActionApproved && meta.Action() != gitlab.NoteActionUnapproved {
+			return "", nil
+		}
+		username := meta.Author.Username
+		if username == "" {
+			return "", errors.New("note author is blank")
+		}
+		return username, nil
 	default:
 		return "", nil
 	}
@@ -621,7 +686,8 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 // ReviewState returns the review state of the ChangesetEvent if it is a review event.
 func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 	switch e.Kind {
-	case ChangesetEventKindBitbucketServerApproved:
+	case ChangesetEventKindBitbucketServerApproved,
+		ChangesetEventKindGitLabApproved:
 		return ChangesetReviewStateApproved, nil
 
 	// BitbucketServer's "REVIEWED" activity is created when someone clicks
@@ -644,7 +710,8 @@ func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 		return s, nil
 
 	case ChangesetEventKindGitHubReviewDismissed,
-		ChangesetEventKindBitbucketServerUnapproved:
+		ChangesetEventKindBitbucketServerUnapproved,
+		ChangesetEventKindGitLabUnapproved:
 		return ChangesetReviewStateDismissed, nil
 
 	default:
@@ -704,6 +771,13 @@ func (e *ChangesetEvent) Timestamp() time.Time {
 		t = unixMilliToTime(int64(e.CreatedDate))
 	case *bitbucketserver.CommitStatus:
 		t = unixMilliToTime(int64(e.Status.DateAdded))
+	case *gitlab.Note:
+		t = e.CreatedAt
+	case *gitlab.Pipeline:
+		t = e.UpdatedAt
+		if t.IsZero() {
+			t = e.CreatedAt
+		}
 	}
 
 	return t
@@ -1025,6 +1099,30 @@ func (e *ChangesetEvent) Update(o *ChangesetEvent) {
 		}
 		e.CheckRuns = o.CheckRuns
 
+	case *gitlab.Note:
+		o := o.Metadata.(*gitlab.Note)
+
+		if e.Author.Username == "" {
+			e.Author = o.Author
+		}
+
+		if o.Body != "" && e.Body != o.Body {
+			e.Body = o.Body
+		}
+
+		if e.CreatedAt.IsZero() {
+			e.CreatedAt = o.CreatedAt
+		}
+
+		if e.UpdatedAt.Before(o.UpdatedAt) {
+			e.UpdatedAt = o.UpdatedAt
+		}
+
+	case *gitlab.Pipeline:
+		o := o.Metadata.(*gitlab.Pipeline)
+		// We always get the full pipeline, so safe to replace it
+		*e = *o
+
 	default:
 		panic(errors.Errorf("unknown changeset event metadata %T", e))
 	}
@@ -1156,6 +1254,10 @@ func ChangesetEventKindFor(e interface{}) ChangesetEventKind {
 		return ChangesetEventKind("bitbucketserver:" + strings.ToLower(string(e.Action)))
 	case *bitbucketserver.CommitStatus:
 		return ChangesetEventKindBitbucketServerCommitStatus
+	case *gitlab.Note:
+		return ChangesetEventKind("gitlab:" + string(e.Action()))
+	case *gitlab.Pipeline:
+		return ChangesetEventKindGitLabPipeline
 	default:
 		panic(errors.Errorf("unknown changeset event kind for %T", e))
 	}
@@ -1211,6 +1313,13 @@ func NewChangesetEventMetadata(k ChangesetEventKind) (interface{}, error) {
 		case ChangesetEventKindCheckRun:
 			return new(github.CheckRun), nil
 		}
+	case strings.HasPrefix(string(k), "gitlab"):
+		switch k {
+		case ChangesetEventKindGitLabPipeline:
+			return new(gitlab.Pipeline), nil
+		default:
+			return new(gitlab.Note), nil
+		}
 	}
 	return nil, errors.Errorf("unknown changeset event kind %q", k)
 }
@@ -1252,6 +1361,14 @@ const (
 	ChangesetEventKindBitbucketServerCommented    ChangesetEventKind = "bitbucketserver:commented"
 	ChangesetEventKindBitbucketServerMerged       ChangesetEventKind = "bitbucketserver:merged"
 	ChangesetEventKindBitbucketServerCommitStatus ChangesetEventKind = "bitbucketserver:commit_status"
+
+	ChangesetEventKindGitLabCommented  ChangesetEventKind = "gitlab:commented"
+	ChangesetEventKindGitLabApproved   ChangesetEventKind = "gitlab:approved"
+	ChangesetEventKindGitLabUnapproved ChangesetEventKind = "gitlab:unapproved"
+	ChangesetEventKindGitLabClosed     ChangesetEventKind = "gitlab:closed"
+	ChangesetEventKindGitLabReopened   ChangesetEventKind = "gitlab:reopened"
+	ChangesetEventKindGitLabMerged     ChangesetEventKind = "gitlab:merged"
+	ChangesetEventKindGitLabPipeline   ChangesetEventKind = "gitlab:pipeline"
 )
 
 // ChangesetSyncData represents data about the sync status of a changeset
@@ -1267,6 +1384,22 @@ type ChangesetSyncData struct {
 	ExternalServiceIDs []int64
 }
 
+// GitLabMergeRequestState maps the state of a GitLab merge request to a
+// ChangesetState. A "locked" merge request is in the process of being merged
+// and is still considered open.
+func GitLabMergeRequestState(s gitlab.MergeRequestState) ChangesetState {
+	switch s {
+	case gitlab.MergeRequestStateOpened, gitlab.MergeRequestStateLocked:
+		return ChangesetStateOpen
+	case gitlab.MergeRequestStateClosed:
+		return ChangesetStateClosed
+	case gitlab.MergeRequestStateMerged:
+		return ChangesetStateMerged
+	default:
+		return ChangesetState(s)
+	}
+}
+
 func unixMilliToTime(ms int64) time.Time {
 	return time.Unix(0, ms*int64(time.Millisecond))
 }
@@ -3,6 +3,7 @@ package conf
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/sourcegraph/sourcegraph/schema"
@@ -18,6 +19,39 @@ func diff(before, after *Unified) (fields map[string]struct{}) {
 	return diff
 }
 
+// FieldDiff describes a site configuration property whose value differs
+// between two versions of the site configuration.
+type FieldDiff struct {
+	Field  string      // the name of the property, e.g. "externalURL" or "experimentalFeatures::discussions"
+	Before interface{} // the value in the older version
+	After  interface{} // the value in the newer version
+}
+
+// DiffSiteConfiguration parses the two site configurations and returns the
+// properties that have different values, sorted by name.
+func DiffSiteConfiguration(before, after string) ([]FieldDiff, error) {
+	var beforeCfg, afterCfg schema.SiteConfiguration
+	if err := parseConfigData(before, &beforeCfg); err != nil {
+		return nil, err
+	}
+	if err := parseConfigData(after, &afterCfg); err != nil {
+		return nil, err
+	}
+
+	beforeFields := getJSONFields(beforeCfg, "")
+	afterFields := getJSONFields(afterCfg, "")
+	diffs := []FieldDiff{}
+	for fieldName := range diffStruct(beforeCfg, afterCfg, "") {
+		diffs = append(diffs, FieldDiff{
+			Field:  fieldName,
+			Before: beforeFields[fieldName],
+			After:  afterFields[fieldName],
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs, nil
+}
+
 func diffStruct(before, after interface{}, prefix string) (fields map[string]struct{}) {
 	fields = make(map[string]struct{})
 	beforeFields := getJSONFields(before, prefix)
@@ -90,3 +90,29 @@ func toSlice(m map[string]struct{}) []string {
 	}
 	return s
 }
+
+func TestDiffSiteConfiguration(t *testing.T) {
+	got, err := DiffSiteConfiguration(
+		`{"externalURL": "https://a.example.com", "maxReposToSearch": 10, "experimentalFeatures": {"discussions": "enabled"}}`,
+		`{
+			// comments and trailing commas are allowed
+			"externalURL": "https://b.example.com",
+			"maxReposToSearch": 10,
+			"experimentalFeatures": {"discussions": "disabled"},
+		}`,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FieldDiff{
+		{Field: "experimentalFeatures::discussions", Before: "enabled", After: "disabled"},
+		{Field: "externalURL", Before: "https://a.example.com", After: "https://b.example.com"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v want %#v", got, want)
+	}
+
+	if _, err := DiffSiteConfiguration(`{`, `{}`); err == nil {
+		t.Error("got nil error for invalid JSON")
+	}
+}
@@ -196,6 +196,12 @@ func ValidateSite(input string) (messages []string, err error) {
 	return problems.Messages(), nil
 }
 
+// ValidateSettings validates settings (such as the global settings) against the
+// settings JSON Schema.
+func ValidateSettings(input string) (messages []string, err error) {
+	return doValidate(input, schema.SettingsSchemaJSON)
+}
+
 func doValidate(inputStr, schema string) (messages []string, err error) {
 	input := jsonc.Normalize(inputStr)
 
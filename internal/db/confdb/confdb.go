@@ -17,11 +17,12 @@ import (
 
 // Config contains the contents of a critical/site config along with associated metadata.
 type Config struct {
-	ID        int32     // the unique ID of this config
-	Type      string    // either "critical" or "site"
-	Contents  string    // the raw JSON content (with comments and trailing commas allowed)
-	CreatedAt time.Time // the date when this config was created
-	UpdatedAt time.Time // the date when this config was updated
+	ID           int32     // the unique ID of this config
+	Type         string    // either "critical" or "site"
+	Contents     string    // the raw JSON content (with comments and trailing commas allowed)
+	AuthorUserID *int32    // the user who saved this config, or nil if it was not saved by a user
+	CreatedAt    time.Time // the date when this config was created
+	UpdatedAt    time.Time // the date when this config was updated
 }
 
 // SiteConfig contains the contents of a site config along with associated metadata.
@@ -43,7 +44,7 @@ var ErrNewerEdit = errors.New("someone else has already applied a newer edit")
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
-func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (latest *SiteConfig, err error) {
+func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, authorUserID *int32, contents string) (latest *SiteConfig, err error) {
 	tx, done, err := newTransaction(ctx)
 	if err != nil {
 		return nil, err
@@ -58,7 +59,7 @@ func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (
 		lastID = newLastID
 	}
 
-	criticalSite, err := createIfUpToDate(ctx, tx, typeSite, lastID, contents)
+	criticalSite, err := createIfUpToDate(ctx, tx, typeSite, lastID, authorUserID, contents)
 	return (*SiteConfig)(criticalSite), err
 }
 
@@ -71,7 +72,7 @@ func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
-func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (latest *CriticalConfig, err error) {
+func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, authorUserID *int32, contents string) (latest *CriticalConfig, err error) {
 	tx, done, err := newTransaction(ctx)
 	if err != nil {
 		return nil, err
@@ -86,7 +87,7 @@ func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, contents strin
 		lastID = newLastID
 	}
 
-	criticalSite, err := createIfUpToDate(ctx, tx, typeCritical, lastID, contents)
+	criticalSite, err := createIfUpToDate(ctx, tx, typeCritical, lastID, authorUserID, contents)
 	return (*CriticalConfig)(criticalSite), err
 }
 
@@ -132,6 +133,77 @@ func CriticalGetLatest(ctx context.Context) (latest *CriticalConfig, err error)
 	return (*CriticalConfig)(critical), err
 }
 
+// SiteGetByID returns the site config with the given ID. An error is returned if
+// there is no such site config.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteGetByID(ctx context.Context, id int32) (*SiteConfig, error) {
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s AND id=%s", typeSite, id)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseQueryRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) != 1 {
+		return nil, &SiteConfigNotFoundError{ID: id}
+	}
+	return (*SiteConfig)(versions[0]), nil
+}
+
+// SiteConfigNotFoundError occurs when a site config version is not found.
+type SiteConfigNotFoundError struct {
+	ID int32
+}
+
+func (e *SiteConfigNotFoundError) Error() string {
+	return fmt.Sprintf("site configuration version not found: %d", e.ID)
+}
+
+func (e *SiteConfigNotFoundError) NotFound() bool { return true }
+
+// SiteList returns the versions of the site config that have been saved to
+// the database, newest first. At most limit versions are returned (or all of
+// them if limit is 0), after skipping the first offset versions.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteList(ctx context.Context, limit, offset int) ([]*SiteConfig, error) {
+	limitClause := sqlf.Sprintf("")
+	if limit > 0 {
+		limitClause = sqlf.Sprintf("LIMIT %d", limit)
+	}
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC %s OFFSET %d", typeSite, limitClause, offset)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseQueryRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	siteVersions := make([]*SiteConfig, len(versions))
+	for i, v := range versions {
+		siteVersions[i] = (*SiteConfig)(v)
+	}
+	return siteVersions, nil
+}
+
+// SiteCount returns the number of versions of the site config that have been
+// saved to the database.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteCount(ctx context.Context) (int, error) {
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM critical_and_site_config WHERE type=%s", typeSite)
+	var count int
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
 func newTransaction(ctx context.Context) (tx queryable, done func(), err error) {
 	rtx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -161,21 +233,22 @@ func addDefault(ctx context.Context, tx queryable, configType configType, conten
 	}
 
 	// Create the default.
-	latest, err = createIfUpToDate(ctx, tx, configType, nil, contents)
+	latest, err = createIfUpToDate(ctx, tx, configType, nil, nil, contents)
 	if err != nil {
 		return nil, err
 	}
 	return &latest.ID, nil
 }
 
-func createIfUpToDate(ctx context.Context, tx queryable, configType configType, lastID *int32, contents string) (latest *Config, err error) {
+func createIfUpToDate(ctx context.Context, tx queryable, configType configType, lastID *int32, authorUserID *int32, contents string) (latest *Config, err error) {
 	// Validate JSON syntax before saving.
 	if _, errs := jsonx.Parse(contents, jsonx.ParseOptions{Comments: true, TrailingCommas: true}); len(errs) > 0 {
 		return nil, fmt.Errorf("invalid settings JSON: %v", errs)
 	}
 
 	new := Config{
-		Contents: contents,
+		Contents:     contents,
+		AuthorUserID: authorUserID,
 	}
 
 	latest, err = getLatest(ctx, tx, configType)
@@ -188,8 +261,8 @@ func createIfUpToDate(ctx context.Context, tx queryable, configType configType,
 
 	err = tx.QueryRowContext(
 		ctx,
-		"INSERT INTO critical_and_site_config(type, contents) VALUES($1, $2) RETURNING id, created_at, updated_at",
-		configType, new.Contents,
+		"INSERT INTO critical_and_site_config(type, contents, author_user_id) VALUES($1, $2, $3) RETURNING id, created_at, updated_at",
+		configType, new.Contents, new.AuthorUserID,
 	).Scan(&new.ID, &new.CreatedAt, &new.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -198,7 +271,7 @@ func createIfUpToDate(ctx context.Context, tx queryable, configType configType,
 }
 
 func getLatest(ctx context.Context, tx queryable, configType configType) (*Config, error) {
-	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", configType)
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", configType)
 	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
@@ -219,7 +292,7 @@ func parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*Config, error) {
 	defer rows.Close()
 	for rows.Next() {
 		f := Config{}
-		err := rows.Scan(&f.ID, &f.Type, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
+		err := rows.Scan(&f.ID, &f.Type, &f.Contents, &f.AuthorUserID, &f.CreatedAt, &f.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -5,7 +5,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
 )
 
 func TestCriticalGetLatestDefault(t *testing.T) {
@@ -36,7 +38,7 @@ func TestCriticalCreate_RejectInvalidJSON(t *testing.T) {
 
 	malformedJSON := "[This is malformed.}"
 
-	_, err := CriticalCreateIfUpToDate(ctx, nil, malformedJSON)
+	_, err := CriticalCreateIfUpToDate(ctx, nil, nil, malformedJSON)
 
 	if err == nil || !strings.Contains(err.Error(), "invalid settings JSON") {
 		t.Fatalf("expected parse error after creating configuration with malformed JSON, got: %+v", err)
@@ -160,7 +162,7 @@ func TestCriticalCreateIfUpToDate(t *testing.T) {
 			dbtesting.SetupGlobalTestDB(t)
 			ctx := context.Background()
 			for _, p := range test.sequence {
-				output, err := CriticalCreateIfUpToDate(ctx, &p.input.lastID, p.input.contents)
+				output, err := CriticalCreateIfUpToDate(ctx, &p.input.lastID, nil, p.input.contents)
 				if err != nil {
 					if err == p.expected.err {
 						continue
@@ -198,3 +200,69 @@ func TestCriticalCreateIfUpToDate(t *testing.T) {
 		})
 	}
 }
+
+func TestSiteHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	var authorUserID int32
+	if err := dbconn.Global.QueryRowContext(ctx, "INSERT INTO users(username) VALUES('alice') RETURNING id").Scan(&authorUserID); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := SiteGetLatest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := SiteCreateIfUpToDate(ctx, &latest.ID, &authorUserID, `{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SiteCreateIfUpToDate(ctx, &first.ID, nil, `{"a": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := SiteGetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Contents != first.Contents || got.AuthorUserID == nil || *got.AuthorUserID != authorUserID {
+		t.Errorf("got %+v, want contents %q and author %d", got, first.Contents, authorUserID)
+	}
+	if _, err := SiteGetByID(ctx, 12345); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+
+	// The default site config, first and second.
+	count, err := SiteCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("got count %d, want 3", count)
+	}
+
+	versions, err := SiteList(ctx, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].ID != second.ID || versions[1].ID != first.ID {
+		t.Errorf("got %+v, want versions %d and %d", versions, second.ID, first.ID)
+	}
+	if versions[0].AuthorUserID != nil {
+		t.Errorf("got author %d, want nil", *versions[0].AuthorUserID)
+	}
+
+	versions, err = SiteList(ctx, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].ID != latest.ID {
+		t.Errorf("got %+v, want the default site config %d", versions, latest.ID)
+	}
+}
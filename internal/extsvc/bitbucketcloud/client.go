@@ -113,6 +113,46 @@ func (c *Client) Repos(ctx context.Context, pageToken *PageToken, accountName st
 	return repos, next, err
 }
 
+// WorkspaceMembers returns a list of the memberships of the given workspace, which
+// identify the users who are members of the workspace. The pagination works the same
+// way as for Repos.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/members
+func (c *Client) WorkspaceMembers(ctx context.Context, pageToken *PageToken, workspace string) ([]*WorkspaceMembership, *PageToken, error) {
+	var members []*WorkspaceMembership
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &members)
+	} else {
+		next, err = c.page(ctx, fmt.Sprintf("/2.0/workspaces/%s/members", workspace), nil, pageToken, &members)
+	}
+	return members, next, err
+}
+
+// RepoPermissions returns a list of the explicit and inherited repository permissions
+// of the users in the given workspace. If query is not empty, it is used as the "q"
+// parameter to filter the results (e.g. `user.uuid="{...}"`). The credentials of the
+// client must belong to an administrator of the workspace. The pagination works the
+// same way as for Repos.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories
+func (c *Client) RepoPermissions(ctx context.Context, pageToken *PageToken, workspace, query string) ([]*RepoPermission, *PageToken, error) {
+	var perms []*RepoPermission
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &perms)
+	} else {
+		var qry url.Values
+		if query != "" {
+			qry = url.Values{"q": []string{query}}
+		}
+		next, err = c.page(ctx, fmt.Sprintf("/2.0/workspaces/%s/permissions/repositories", workspace), qry, pageToken, &perms)
+	}
+	return perms, next, err
+}
+
 func (c *Client) page(ctx context.Context, path string, qry url.Values, token *PageToken, results interface{}) (*PageToken, error) {
 	if qry == nil {
 		qry = make(url.Values)
@@ -245,6 +285,35 @@ type Repo struct {
 	Links       Links  `json:"links"`
 }
 
+// Account is a Bitbucket Cloud user account.
+type Account struct {
+	UUID        string `json:"uuid"`
+	AccountID   string `json:"account_id"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}
+
+// Workspace is a Bitbucket Cloud workspace, which owns repositories.
+type Workspace struct {
+	UUID string `json:"uuid"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// WorkspaceMembership is the membership of a user in a workspace.
+type WorkspaceMembership struct {
+	User      *Account   `json:"user"`
+	Workspace *Workspace `json:"workspace"`
+}
+
+// RepoPermission is the permission of a user on a repository. Permission is one of
+// "read", "write" or "admin".
+type RepoPermission struct {
+	Permission string   `json:"permission"`
+	User       *Account `json:"user"`
+	Repository *Repo    `json:"repository"`
+}
+
 type Links struct {
 	Clone CloneLinks `json:"clone"`
 	HTML  Link       `json:"html"`
@@ -26,6 +26,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"golang.org/x/time/rate"
@@ -84,6 +85,10 @@ type Client struct {
 	// of rate limiting in HTTP response headers).
 	RateLimit *rate.Limiter
 
+	// RateLimitMonitor records the Retry-After deadlines Bitbucket Server sends
+	// with throttled (429) responses.
+	RateLimitMonitor *ratelimit.Monitor
+
 	// OAuth client used to authenticate requests, if set via SetOAuth.
 	// Takes precedence over Token and Username / Password authentication.
 	Oauth *oauth.Client
@@ -114,12 +119,13 @@ func NewClient(c *schema.BitbucketServerConnection, httpClient httpcli.Doer) (*C
 	}
 
 	client := &Client{
-		httpClient: httpClient,
-		URL:        u,
-		Username:   c.Username,
-		Password:   c.Password,
-		Token:      c.Token,
-		RateLimit:  l,
+		httpClient:       httpClient,
+		URL:              u,
+		Username:         c.Username,
+		Password:         c.Password,
+		Token:            c.Token,
+		RateLimit:        l,
+		RateLimitMonitor: &ratelimit.Monitor{},
 	}
 
 	if c.Authorization != nil {
@@ -797,12 +803,13 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 		log15.Warn("Bitbucket self-enforced API rate limit: request delayed longer than expected due to rate limit", "delay", d)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req.WithContext(ratelimit.WithMonitor(req.Context(), c.RateLimitMonitor)))
 	if err != nil {
 		return err
 	}
 
 	defer resp.Body.Close()
+	c.RateLimitMonitor.Update(resp.Header)
 
 	bs, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -16,6 +17,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 var update = flag.Bool("update", false, "update testdata")
@@ -730,6 +734,36 @@ func TestClient_RepoIDs(t *testing.T) {
 	checkGolden(t, "RepoIDs", ids)
 }
 
+func TestClient_RateLimitMonitor(t *testing.T) {
+	var fromCtx *ratelimit.Monitor
+	doer := httpcli.DoerFunc(func(req *http.Request) (*http.Response, error) {
+		fromCtx = ratelimit.MonitorFromContext(req.Context())
+		header := make(http.Header)
+		header.Set("Retry-After", "60")
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+
+	cli, err := NewClient(&schema.BitbucketServerConnection{Url: "https://bitbucket.example.com", Token: "t"}, doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cli.LoadUser(context.Background(), &User{Slug: "u"}); err == nil {
+		t.Fatal("expected error from throttled response")
+	}
+
+	if fromCtx != cli.RateLimitMonitor {
+		t.Errorf("request context carries monitor %p, want client monitor %p", fromCtx, cli.RateLimitMonitor)
+	}
+	if _, _, retry, _ := cli.RateLimitMonitor.Get(); retry <= 0 {
+		t.Errorf("got retry %s, want Retry-After deadline to be recorded", retry)
+	}
+}
+
 func checkGolden(t *testing.T, name string, got interface{}) {
 	t.Helper()
 
@@ -0,0 +1,257 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+	"golang.org/x/time/rate"
+)
+
+var requestCounter = metrics.NewRequestMeter("gitea", "Total number of requests sent to the Gitea API.")
+
+// These fields define the default self-imposed Gitea rate limit. Gitea does
+// not impose a rate limit on API requests itself, so this protects
+// self-hosted instances from being overloaded by repository syncing.
+//
+// See https://godoc.org/golang.org/x/time/rate#Limiter for an explanation of these fields.
+const (
+	rateLimitRequestsPerSecond = 8 // 480/min or 28800/hr
+	rateLimitMaxBurstRequests  = 500
+)
+
+// Global limiter cache so that we reuse the same rate limiter for
+// the same code host, even between config changes.
+var limiterMu sync.Mutex
+var limiterCache = make(map[string]*rate.Limiter)
+
+// DefaultPageLimit is the number of results requested per page. Gitea caps
+// this at its MAX_RESPONSE_ITEMS setting, which is 50 by default.
+const DefaultPageLimit = 50
+
+// Client access a Gitea instance via the REST API v1.
+type Client struct {
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// URL is the base URL of the Gitea instance.
+	URL *url.URL
+
+	// Token is the access token used to authenticate API requests.
+	Token string
+
+	// RateLimit is the self-imposed rate limiter (since Gitea does not have a concept
+	// of rate limiting in HTTP response headers).
+	RateLimit *rate.Limiter
+}
+
+// NewClient creates a new Gitea API client for the instance at baseURL. If a nil httpClient
+// is provided, http.DefaultClient will be used.
+func NewClient(baseURL *url.URL, token string, httpClient httpcli.Doer) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	httpClient = requestCounter.Doer(httpClient, func(u *url.URL) string {
+		// The third component of the Path (/api/v1/{category}/...) mostly
+		// maps to the type of API request we are making.
+		var category string
+		if parts := strings.SplitN(u.Path, "/", 5); len(parts) > 3 {
+			category = parts[3]
+		}
+		return category
+	})
+
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+
+	l, ok := limiterCache[baseURL.String()]
+	if !ok {
+		l = rate.NewLimiter(rateLimitRequestsPerSecond, rateLimitMaxBurstRequests)
+		limiterCache[baseURL.String()] = l
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		URL:        baseURL,
+		Token:      token,
+		RateLimit:  l,
+	}
+}
+
+// Repo is a Gitea repository as returned by the API.
+type Repo struct {
+	ID            int64  `json:"id"`
+	Owner         *User  `json:"owner"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Description   string `json:"description"`
+	Empty         bool   `json:"empty"`
+	Private       bool   `json:"private"`
+	Fork          bool   `json:"fork"`
+	Parent        *Repo  `json:"parent,omitempty"`
+	Mirror        bool   `json:"mirror"`
+	Archived      bool   `json:"archived"`
+	HTMLURL       string `json:"html_url"`
+	SSHURL        string `json:"ssh_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// User is a Gitea user or organization.
+type User struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+}
+
+// Page identifies a page of a paginated Gitea API response. Pages are
+// numbered starting at 1.
+type Page struct {
+	Page  int
+	Limit int
+}
+
+// Values returns the query parameters selecting p.
+func (p Page) Values() url.Values {
+	v := url.Values{}
+	if p.Page > 0 {
+		v.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return v
+}
+
+// Next returns the page following p.
+func (p Page) Next() Page {
+	if p.Page == 0 {
+		p.Page = 1
+	}
+	return Page{Page: p.Page + 1, Limit: p.Limit}
+}
+
+// HasMore reports whether there may be more results after a page of p
+// containing n results.
+func (p Page) HasMore(n int) bool {
+	limit := p.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	return n >= limit
+}
+
+// ListOrgRepos returns a page of repositories of the given organization that
+// are visible to the authenticated user.
+func (c *Client) ListOrgRepos(ctx context.Context, org string, page Page) ([]*Repo, error) {
+	var repos []*Repo
+	err := c.get(ctx, "orgs/"+url.PathEscape(org)+"/repos", page.Values(), &repos)
+	return repos, err
+}
+
+// ListAffiliatedRepos returns a page of repositories the authenticated user
+// owns or has access to.
+func (c *Client) ListAffiliatedRepos(ctx context.Context, page Page) ([]*Repo, error) {
+	var repos []*Repo
+	err := c.get(ctx, "user/repos", page.Values(), &repos)
+	return repos, err
+}
+
+// GetRepo returns the repository with the given owner and name.
+func (c *Client) GetRepo(ctx context.Context, owner, name string) (*Repo, error) {
+	var repo Repo
+	if err := c.get(ctx, "repos/"+url.PathEscape(owner)+"/"+url.PathEscape(name), nil, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, qry url.Values, result interface{}) error {
+	u := url.URL{Path: "api/v1/" + path, RawQuery: qry.Encode()}
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, result)
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result interface{}) error {
+	req.URL = c.URL.ResolveReference(req.URL)
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+
+	req, ht := nethttp.TraceRequest(ot.GetTracer(ctx),
+		req.WithContext(ctx),
+		nethttp.OperationName("Gitea"),
+		nethttp.ClientTrace(false))
+	defer ht.Finish()
+
+	startWait := time.Now()
+	if err := c.RateLimit.Wait(ctx); err != nil {
+		return err
+	}
+
+	if d := time.Since(startWait); d > 200*time.Millisecond {
+		log15.Warn("Gitea self-enforced API rate limit: request delayed longer than expected due to rate limit", "delay", d)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return errors.WithStack(&httpError{
+			URL:        req.URL,
+			StatusCode: resp.StatusCode,
+			Body:       bs,
+		})
+	}
+
+	if result != nil {
+		return json.Unmarshal(bs, result)
+	}
+
+	return nil
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Gitea API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
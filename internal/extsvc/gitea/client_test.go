@@ -0,0 +1,104 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+)
+
+func newTestClient(t testing.TB, h http.HandlerFunc) (*Client, func()) {
+	t.Helper()
+
+	srv := httptest.NewServer(h)
+	u, err := url.Parse(srv.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClient(u, "secret", srv.Client()), srv.Close
+}
+
+func TestClient_ListOrgRepos(t *testing.T) {
+	cli, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if have, want := r.Header.Get("Authorization"), "token secret"; have != want {
+			t.Errorf("Authorization header: have %q, want %q", have, want)
+		}
+		if have, want := r.URL.Path, "/api/v1/orgs/gitea/repos"; have != want {
+			t.Errorf("path: have %q, want %q", have, want)
+		}
+		q := r.URL.Query()
+		switch q.Get("page") {
+		case "1":
+			fmt.Fprint(w, `[{"id":1,"name":"tea","full_name":"gitea/tea","clone_url":"https://gitea.example.com/gitea/tea.git","owner":{"id":2,"login":"gitea"}},{"id":3,"name":"old","full_name":"gitea/old","archived":true,"fork":true}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	defer done()
+
+	page := Page{Page: 1, Limit: 2}
+	repos, err := cli.ListOrgRepos(context.Background(), "gitea", page)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*Repo{
+		{
+			ID:       1,
+			Name:     "tea",
+			FullName: "gitea/tea",
+			CloneURL: "https://gitea.example.com/gitea/tea.git",
+			Owner:    &User{ID: 2, Login: "gitea"},
+		},
+		{
+			ID:       3,
+			Name:     "old",
+			FullName: "gitea/old",
+			Archived: true,
+			Fork:     true,
+		},
+	}
+	if diff := cmp.Diff(want, repos); diff != "" {
+		t.Fatalf("repos mismatch (-want +have):\n%s", diff)
+	}
+	if !page.HasMore(len(repos)) {
+		t.Fatal("expected more pages")
+	}
+
+	page = page.Next()
+	if repos, err = cli.ListOrgRepos(context.Background(), "gitea", page); err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 0 || page.HasMore(len(repos)) {
+		t.Fatalf("expected last page to be empty, got %d repos", len(repos))
+	}
+}
+
+func TestClient_GetRepo(t *testing.T) {
+	cli, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/gitea/tea" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"id":1,"name":"tea","full_name":"gitea/tea","description":"A CLI"}`)
+	})
+	defer done()
+
+	repo, err := cli.GetRepo(context.Background(), "gitea", "tea")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&Repo{ID: 1, Name: "tea", FullName: "gitea/tea", Description: "A CLI"}, repo); diff != "" {
+		t.Fatalf("repo mismatch (-want +have):\n%s", diff)
+	}
+
+	_, err = cli.GetRepo(context.Background(), "gitea", "missing")
+	if e, ok := errors.Cause(err).(*httpError); !ok || !e.NotFound() {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
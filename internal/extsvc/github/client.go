@@ -215,7 +215,7 @@ func (c *Client) do(ctx context.Context, token string, req *http.Request, result
 		span.Finish()
 	}()
 
-	resp, err = c.httpClient.Do(req.WithContext(ctx))
+	resp, err = c.httpClient.Do(req.WithContext(ratelimit.WithMonitor(ctx, c.RateLimit)))
 	if err != nil {
 		return err
 	}
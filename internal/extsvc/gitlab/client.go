@@ -227,7 +227,7 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 		span.Finish()
 	}()
 
-	resp, err = c.httpClient.Do(req.WithContext(ctx))
+	resp, err = c.httpClient.Do(req.WithContext(ratelimit.WithMonitor(ctx, c.RateLimit)))
 	if err != nil {
 		trace("GitLab API error", "method", req.Method, "url", req.URL.String(), "err", err)
 		return nil, err
@@ -236,7 +236,7 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 	trace("GitLab API", "method", req.Method, "url", req.URL.String(), "respCode", resp.StatusCode)
 
 	c.RateLimit.Update(resp.Header)
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.Wrap(httpError(resp.StatusCode), fmt.Sprintf("unexpected response from GitLab API (%s)", req.URL))
 	}
 
@@ -0,0 +1,173 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	eventTypeHeader = "X-Gitlab-Event"
+	tokenHeader     = "X-Gitlab-Token"
+)
+
+// WebhookEventType returns the type of the GitLab webhook event in the given
+// request, such as "Merge Request Hook".
+func WebhookEventType(r *http.Request) string {
+	return r.Header.Get(eventTypeHeader)
+}
+
+// WebhookToken returns the secret token GitLab sent along with the webhook
+// event in the given request.
+func WebhookToken(r *http.Request) string {
+	return r.Header.Get(tokenHeader)
+}
+
+// ErrUnknownWebhookEvent is returned by ParseWebhookEvent for event types we
+// don't handle.
+var ErrUnknownWebhookEvent = errors.New("unknown webhook event type")
+
+// ParseWebhookEvent parses the payload of a GitLab webhook event of the given
+// type into one of MergeRequestEvent, NoteEvent or PipelineEvent.
+func ParseWebhookEvent(eventType string, payload []byte) (e interface{}, err error) {
+	switch eventType {
+	case "Merge Request Hook":
+		e = &MergeRequestEvent{}
+	case "Note Hook":
+		e = &NoteEvent{}
+	case "Pipeline Hook":
+		e = &PipelineEvent{}
+	default:
+		return nil, ErrUnknownWebhookEvent
+	}
+	return e, json.Unmarshal(payload, e)
+}
+
+// WebhookTime is a timestamp in a GitLab webhook payload. Depending on the
+// GitLab version and event type, timestamps are either encoded in RFC 3339 or
+// in the "2006-01-02 15:04:05 MST" format.
+type WebhookTime struct {
+	time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *WebhookTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return errors.Errorf("invalid GitLab webhook timestamp %q", s)
+}
+
+// WebhookProject is the project included in GitLab webhook payloads.
+type WebhookProject struct {
+	ID                int    `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+// WebhookMergeRequest is the merge request included in note and pipeline
+// webhook payloads.
+type WebhookMergeRequest struct {
+	ID              int    `json:"id"`
+	IID             int    `json:"iid"`
+	SourceBranch    string `json:"source_branch"`
+	TargetBranch    string `json:"target_branch"`
+	SourceProjectID int    `json:"source_project_id"`
+	TargetProjectID int    `json:"target_project_id"`
+	State           string `json:"state"`
+}
+
+// MergeRequestEvent is sent when a merge request is opened, updated, closed,
+// reopened, merged, approved or unapproved. Its payload doesn't include the
+// system note GitLab records for the change, so receivers should sync the
+// merge request to pick up that note.
+type MergeRequestEvent struct {
+	User             User           `json:"user"`
+	Project          WebhookProject `json:"project"`
+	ObjectAttributes struct {
+		WebhookMergeRequest
+		Title     string      `json:"title"`
+		Action    string      `json:"action"`
+		UpdatedAt WebhookTime `json:"updated_at"`
+	} `json:"object_attributes"`
+}
+
+// NoteEvent is sent when a comment is added to a commit, merge request, issue
+// or snippet.
+type NoteEvent struct {
+	User             User           `json:"user"`
+	Project          WebhookProject `json:"project"`
+	ObjectAttributes struct {
+		ID           int         `json:"id"`
+		Note         string      `json:"note"`
+		NoteableType string      `json:"noteable_type"`
+		System       bool        `json:"system"`
+		CreatedAt    WebhookTime `json:"created_at"`
+		UpdatedAt    WebhookTime `json:"updated_at"`
+	} `json:"object_attributes"`
+	MergeRequest *WebhookMergeRequest `json:"merge_request"`
+}
+
+// PipelineEvent is sent when the status of a pipeline changes.
+type PipelineEvent struct {
+	Project          WebhookProject `json:"project"`
+	ObjectAttributes struct {
+		ID         int            `json:"id"`
+		Ref        string         `json:"ref"`
+		SHA        string         `json:"sha"`
+		Status     PipelineStatus `json:"status"`
+		CreatedAt  WebhookTime    `json:"created_at"`
+		FinishedAt WebhookTime    `json:"finished_at"`
+	} `json:"object_attributes"`
+	MergeRequest *WebhookMergeRequest `json:"merge_request"`
+}
+
+// Note returns the Note contained in the event, or nil if the note wasn't
+// made on a merge request.
+func (e *NoteEvent) Note() *Note {
+	if !strings.EqualFold(e.ObjectAttributes.NoteableType, "MergeRequest") || e.MergeRequest == nil {
+		return nil
+	}
+
+	return &Note{
+		ID:        e.ObjectAttributes.ID,
+		Body:      e.ObjectAttributes.Note,
+		Author:    e.User,
+		CreatedAt: e.ObjectAttributes.CreatedAt.Time,
+		UpdatedAt: e.ObjectAttributes.UpdatedAt.Time,
+		System:    e.ObjectAttributes.System,
+	}
+}
+
+// Pipeline returns the Pipeline contained in the event. Pipelines that are
+// still running don't have a finish time, in which case receivedAt is used as
+// their UpdatedAt timestamp.
+func (e *PipelineEvent) Pipeline(receivedAt time.Time) *Pipeline {
+	updatedAt := e.ObjectAttributes.FinishedAt.Time
+	if updatedAt.IsZero() {
+		updatedAt = receivedAt
+	}
+
+	return &Pipeline{
+		ID:        e.ObjectAttributes.ID,
+		SHA:       e.ObjectAttributes.SHA,
+		Ref:       e.ObjectAttributes.Ref,
+		Status:    e.ObjectAttributes.Status,
+		CreatedAt: e.ObjectAttributes.CreatedAt.Time,
+		UpdatedAt: updatedAt,
+	}
+}
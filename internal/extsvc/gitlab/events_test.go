@@ -0,0 +1,63 @@
+package gitlab
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	t.Run("unknown event", func(t *testing.T) {
+		if _, err := ParseWebhookEvent("Push Hook", []byte(`{}`)); err != ErrUnknownWebhookEvent {
+			t.Errorf("have err %v, want %v", err, ErrUnknownWebhookEvent)
+		}
+	})
+
+	t.Run("merge request", func(t *testing.T) {
+		payload := `{
+			"user": {"username": "alice"},
+			"project": {"id": 5},
+			"object_attributes": {
+				"iid": 2,
+				"target_project_id": 5,
+				"action": "close",
+				"updated_at": "2020-04-01 10:00:00 UTC"
+			}
+		}`
+
+		e, err := ParseWebhookEvent("Merge Request Hook", []byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mre, ok := e.(*MergeRequestEvent)
+		if !ok {
+			t.Fatalf("have event %T, want *MergeRequestEvent", e)
+		}
+		if mre.ObjectAttributes.IID != 2 || mre.ObjectAttributes.TargetProjectID != 5 {
+			t.Errorf("unexpected object attributes: %+v", mre.ObjectAttributes)
+		}
+		if have, want := mre.ObjectAttributes.Action, "close"; have != want {
+			t.Errorf("have action %q, want %q", have, want)
+		}
+		if want := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC); !mre.ObjectAttributes.UpdatedAt.Equal(want) {
+			t.Errorf("have updated at %v, want %v", mre.ObjectAttributes.UpdatedAt.Time, want)
+		}
+	})
+}
+
+func TestWebhookTime_UnmarshalJSON(t *testing.T) {
+	want := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+	for _, s := range []string{
+		`"2020-04-01T10:00:00Z"`,
+		`"2020-04-01 10:00:00 UTC"`,
+		`"2020-04-01 12:00:00 +0200"`,
+	} {
+		var have WebhookTime
+		if err := have.UnmarshalJSON([]byte(s)); err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if !have.Equal(want) {
+			t.Errorf("%s: have %v, want %v", s, have.Time, want)
+		}
+	}
+}
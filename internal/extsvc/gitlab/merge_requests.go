@@ -0,0 +1,337 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterhellberg/link"
+	"github.com/pkg/errors"
+)
+
+// MergeRequestState is the state of a GitLab merge request.
+type MergeRequestState string
+
+// Known MergeRequestStates.
+const (
+	MergeRequestStateOpened MergeRequestState = "opened"
+	MergeRequestStateClosed MergeRequestState = "closed"
+	MergeRequestStateLocked MergeRequestState = "locked"
+	MergeRequestStateMerged MergeRequestState = "merged"
+)
+
+// MergeRequest is a GitLab merge request (equivalent to a GitHub pull
+// request).
+type MergeRequest struct {
+	ID           int               `json:"id"`
+	IID          int               `json:"iid"`
+	ProjectID    int               `json:"project_id"`
+	Title        string            `json:"title"`
+	Description  string            `json:"description"`
+	State        MergeRequestState `json:"state"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	MergedAt     *time.Time        `json:"merged_at"`
+	ClosedAt     *time.Time        `json:"closed_at"`
+	Labels       []string          `json:"labels"`
+	SourceBranch string            `json:"source_branch"`
+	TargetBranch string            `json:"target_branch"`
+	SHA          string            `json:"sha"`
+	DiffRefs     DiffRefs          `json:"diff_refs"`
+	WebURL       string            `json:"web_url"`
+	Author       User              `json:"author"`
+	HeadPipeline *Pipeline         `json:"head_pipeline"`
+
+	// Notes and Pipelines are not returned by the merge request endpoints.
+	// They are loaded separately with GetMergeRequestNotes and
+	// GetMergeRequestPipelines.
+	Notes     []*Note     `json:"notes,omitempty"`
+	Pipelines []*Pipeline `json:"pipelines,omitempty"`
+}
+
+// DiffRefs are the git object IDs a merge request diff is computed from.
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// Note is a comment on a merge request. GitLab also records state changes,
+// such as approvals or the merge request being closed, as system notes.
+type Note struct {
+	ID        int       `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	System    bool      `json:"system"`
+}
+
+// Key is a unique key identifying this note in the context of its merge
+// request.
+func (n *Note) Key() string {
+	return strconv.Itoa(n.ID)
+}
+
+// NoteAction defines the action a Note represents.
+type NoteAction string
+
+// Known NoteActions.
+const (
+	NoteActionCommented  NoteAction = "commented"
+	NoteActionApproved   NoteAction = "approved"
+	NoteActionUnapproved NoteAction = "unapproved"
+	NoteActionClosed     NoteAction = "closed"
+	NoteActionReopened   NoteAction = "reopened"
+	NoteActionMerged     NoteAction = "merged"
+)
+
+// Action returns the NoteAction the Note represents. User notes are always
+// comments. System notes are mapped from their body, and an empty action is
+// returned for system notes we don't track, such as pushed commits or
+// changed titles.
+func (n *Note) Action() NoteAction {
+	if !n.System {
+		return NoteActionCommented
+	}
+
+	switch strings.ToLower(strings.TrimSpace(n.Body)) {
+	case "approved this merge request":
+		return NoteActionApproved
+	case "unapproved this merge request":
+		return NoteActionUnapproved
+	case "closed", "status changed to closed":
+		return NoteActionClosed
+	case "reopened", "status changed to reopened":
+		return NoteActionReopened
+	case "merged", "status changed to merged":
+		return NoteActionMerged
+	default:
+		return ""
+	}
+}
+
+// PipelineStatus is the status of a GitLab CI pipeline.
+type PipelineStatus string
+
+// Known PipelineStatuses.
+const (
+	PipelineStatusCreated            PipelineStatus = "created"
+	PipelineStatusWaitingForResource PipelineStatus = "waiting_for_resource"
+	PipelineStatusPreparing          PipelineStatus = "preparing"
+	PipelineStatusPending            PipelineStatus = "pending"
+	PipelineStatusRunning            PipelineStatus = "running"
+	PipelineStatusSuccess            PipelineStatus = "success"
+	PipelineStatusFailed             PipelineStatus = "failed"
+	PipelineStatusCanceled           PipelineStatus = "canceled"
+	PipelineStatusSkipped            PipelineStatus = "skipped"
+	PipelineStatusManual             PipelineStatus = "manual"
+	PipelineStatusScheduled          PipelineStatus = "scheduled"
+)
+
+// Pipeline is a GitLab CI pipeline run for a commit.
+type Pipeline struct {
+	ID        int            `json:"id"`
+	SHA       string         `json:"sha"`
+	Ref       string         `json:"ref"`
+	Status    PipelineStatus `json:"status"`
+	WebURL    string         `json:"web_url"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Key is a unique key identifying this pipeline in the context of its merge
+// request.
+func (p *Pipeline) Key() string { return strconv.Itoa(p.ID) }
+
+// ErrMergeRequestAlreadyExists is returned by CreateMergeRequest when an open
+// merge request already exists for the given source branch.
+var ErrMergeRequestAlreadyExists = errors.New("merge request already exists")
+
+// ErrMergeRequestNotFound is returned by GetOpenMergeRequestByRefs when no
+// open merge request exists for the given refs.
+var ErrMergeRequestNotFound = errors.New("merge request not found")
+
+// CreateMergeRequestOpts are the options for CreateMergeRequest.
+type CreateMergeRequestOpts struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description,omitempty"`
+}
+
+// CreateMergeRequest opens a new merge request in the given project.
+func (c *Client) CreateMergeRequest(ctx context.Context, project *Project, opts CreateMergeRequestOpts) (*MergeRequest, error) {
+	if MockCreateMergeRequest != nil {
+		return MockCreateMergeRequest(c, ctx, project, opts)
+	}
+
+	req, err := newJSONRequest("POST", fmt.Sprintf("projects/%d/merge_requests", project.ID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		if HTTPErrorCode(err) == http.StatusConflict {
+			return nil, ErrMergeRequestAlreadyExists
+		}
+		return nil, errors.Wrap(err, "creating merge request")
+	}
+	return &mr, nil
+}
+
+// GetMergeRequest returns the merge request with the given project-scoped
+// IID.
+func (c *Client) GetMergeRequest(ctx context.Context, project *Project, iid int) (*MergeRequest, error) {
+	if MockGetMergeRequest != nil {
+		return MockGetMergeRequest(c, ctx, project, iid)
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, iid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// GetOpenMergeRequestByRefs returns the open merge request from source to
+// target, or ErrMergeRequestNotFound if there is none.
+func (c *Client) GetOpenMergeRequestByRefs(ctx context.Context, project *Project, source, target string) (*MergeRequest, error) {
+	if MockGetOpenMergeRequestByRefs != nil {
+		return MockGetOpenMergeRequestByRefs(c, ctx, project, source, target)
+	}
+
+	q := make(url.Values)
+	q.Set("state", string(MergeRequestStateOpened))
+	q.Set("source_branch", source)
+	q.Set("target_branch", target)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests?%s", project.ID, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mrs []*MergeRequest
+	if _, err := c.do(ctx, req, &mrs); err != nil {
+		return nil, err
+	}
+
+	switch len(mrs) {
+	case 0:
+		return nil, ErrMergeRequestNotFound
+	case 1:
+		return mrs[0], nil
+	default:
+		return nil, errors.Errorf("found %d open merge requests from %q to %q, expected 1", len(mrs), source, target)
+	}
+}
+
+// UpdateMergeRequestOpts are the options for UpdateMergeRequest. Empty fields
+// are left unchanged. Description is a pointer so that it can be cleared.
+type UpdateMergeRequestOpts struct {
+	Title        string  `json:"title,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	TargetBranch string  `json:"target_branch,omitempty"`
+	// StateEvent is either "close" or "reopen".
+	StateEvent string `json:"state_event,omitempty"`
+}
+
+// UpdateMergeRequest updates the given merge request and returns the updated
+// merge request.
+func (c *Client) UpdateMergeRequest(ctx context.Context, project *Project, mr *MergeRequest, opts UpdateMergeRequestOpts) (*MergeRequest, error) {
+	if MockUpdateMergeRequest != nil {
+		return MockUpdateMergeRequest(c, ctx, project, mr, opts)
+	}
+
+	req, err := newJSONRequest("PUT", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, mr.IID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated MergeRequest
+	if _, err := c.do(ctx, req, &updated); err != nil {
+		return nil, errors.Wrap(err, "updating merge request")
+	}
+	return &updated, nil
+}
+
+// GetMergeRequestNotes returns all notes on the given merge request, oldest
+// first.
+func (c *Client) GetMergeRequestNotes(ctx context.Context, project *Project, iid int) ([]*Note, error) {
+	if MockGetMergeRequestNotes != nil {
+		return MockGetMergeRequestNotes(c, ctx, project, iid)
+	}
+
+	var all []*Note
+	urlStr := fmt.Sprintf("projects/%d/merge_requests/%d/notes?sort=asc&order_by=created_at&per_page=100", project.ID, iid)
+	for urlStr != "" {
+		var page []*Note
+		next, err := c.getPage(ctx, urlStr, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		urlStr = next
+	}
+	return all, nil
+}
+
+// GetMergeRequestPipelines returns all pipelines run for the given merge
+// request.
+func (c *Client) GetMergeRequestPipelines(ctx context.Context, project *Project, iid int) ([]*Pipeline, error) {
+	if MockGetMergeRequestPipelines != nil {
+		return MockGetMergeRequestPipelines(c, ctx, project, iid)
+	}
+
+	var all []*Pipeline
+	urlStr := fmt.Sprintf("projects/%d/merge_requests/%d/pipelines?per_page=100", project.ID, iid)
+	for urlStr != "" {
+		var page []*Pipeline
+		next, err := c.getPage(ctx, urlStr, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		urlStr = next
+	}
+	return all, nil
+}
+
+// getPage fetches a single page of a paginated list endpoint into result and
+// returns the URL of the next page, or an empty string on the last page.
+func (c *Client) getPage(ctx context.Context, urlStr string, result interface{}) (string, error) {
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return "", err
+	}
+	respHeader, err := c.do(ctx, req, result)
+	if err != nil {
+		return "", err
+	}
+
+	// Get URL to next page. See https://docs.gitlab.com/ee/api/README.html#pagination-link-header.
+	if l := link.Parse(respHeader.Get("Link"))["next"]; l != nil {
+		return l.URI, nil
+	}
+	return "", nil
+}
+
+func newJSONRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling request body")
+	}
+	return http.NewRequest(method, urlStr, bytes.NewReader(data))
+}
@@ -0,0 +1,135 @@
+package gitlab
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+func TestClient_CreateMergeRequest(t *testing.T) {
+	project := &Project{ProjectCommon: ProjectCommon{ID: 1}}
+	opts := CreateMergeRequestOpts{
+		SourceBranch: "campaign",
+		TargetBranch: "master",
+		Title:        "Campaign",
+	}
+
+	t.Run("created", func(t *testing.T) {
+		mock := mockHTTPResponseBody{
+			responseBody: `{"id": 10, "iid": 2, "project_id": 1, "title": "Campaign", "state": "opened", "source_branch": "campaign", "target_branch": "master"}`,
+		}
+		c := newTestClient(t)
+		c.httpClient = &mock
+
+		mr, err := c.CreateMergeRequest(context.Background(), project, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mr.IID != 2 || mr.State != MergeRequestStateOpened || mr.SourceBranch != "campaign" {
+			t.Errorf("unexpected merge request: %+v", mr)
+		}
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		c := newTestClient(t)
+		c.httpClient = mockHTTPEmptyResponse{statusCode: http.StatusConflict}
+
+		_, err := c.CreateMergeRequest(context.Background(), project, opts)
+		if err != ErrMergeRequestAlreadyExists {
+			t.Errorf("have err %v, want %v", err, ErrMergeRequestAlreadyExists)
+		}
+	})
+}
+
+func TestClient_GetOpenMergeRequestByRefs(t *testing.T) {
+	project := &Project{ProjectCommon: ProjectCommon{ID: 1}}
+
+	for _, tc := range []struct {
+		name     string
+		body     string
+		wantIID  int
+		wantErr  bool
+		notFound bool
+	}{
+		{name: "none", body: `[]`, wantErr: true, notFound: true},
+		{name: "one", body: `[{"iid": 3}]`, wantIID: 3},
+		{name: "many", body: `[{"iid": 3}, {"iid": 4}]`, wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestClient(t)
+			c.httpClient = &mockHTTPResponseBody{responseBody: tc.body}
+
+			mr, err := c.GetOpenMergeRequestByRefs(context.Background(), project, "campaign", "master")
+			if have, want := err != nil, tc.wantErr; have != want {
+				t.Fatalf("have err %v, want error: %t", err, want)
+			}
+			if tc.notFound && err != ErrMergeRequestNotFound {
+				t.Errorf("have err %v, want %v", err, ErrMergeRequestNotFound)
+			}
+			if err == nil && mr.IID != tc.wantIID {
+				t.Errorf("have IID %d, want %d", mr.IID, tc.wantIID)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateMergeRequest_description(t *testing.T) {
+	project := &Project{ProjectCommon: ProjectCommon{ID: 1}}
+	empty := ""
+
+	for _, tc := range []struct {
+		name string
+		opts UpdateMergeRequestOpts
+		want string
+	}{
+		{name: "unchanged", opts: UpdateMergeRequestOpts{StateEvent: "close"}, want: `{"state_event":"close"}`},
+		{name: "cleared", opts: UpdateMergeRequestOpts{Description: &empty}, want: `{"description":""}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var body string
+			c := newTestClient(t)
+			c.httpClient = httpcli.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				b, err := ioutil.ReadAll(req.Body)
+				if err != nil {
+					return nil, err
+				}
+				body = string(b)
+				return &http.Response{
+					Request:    req,
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader(`{"iid": 2}`)),
+				}, nil
+			})
+
+			if _, err := c.UpdateMergeRequest(context.Background(), project, &MergeRequest{IID: 2}, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			if body != tc.want {
+				t.Errorf("have body %s, want %s", body, tc.want)
+			}
+		})
+	}
+}
+
+func TestNote_Action(t *testing.T) {
+	for _, tc := range []struct {
+		note Note
+		want NoteAction
+	}{
+		{note: Note{Body: "LGTM"}, want: NoteActionCommented},
+		{note: Note{Body: "approved this merge request", System: true}, want: NoteActionApproved},
+		{note: Note{Body: "unapproved this merge request", System: true}, want: NoteActionUnapproved},
+		{note: Note{Body: "closed", System: true}, want: NoteActionClosed},
+		{note: Note{Body: "Status changed to reopened", System: true}, want: NoteActionReopened},
+		{note: Note{Body: "merged", System: true}, want: NoteActionMerged},
+		{note: Note{Body: "added 1 commit", System: true}, want: ""},
+	} {
+		if have := tc.note.Action(); have != tc.want {
+			t.Errorf("%q: have action %q, want %q", tc.note.Body, have, tc.want)
+		}
+	}
+}
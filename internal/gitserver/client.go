@@ -28,6 +28,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
@@ -60,6 +61,9 @@ func NewClient(cli httpcli.Doer) *Client {
 		Addrs: func(ctx context.Context) []string {
 			return conf.Get().ServiceConnections.GitServers
 		},
+		Replicas: func(ctx context.Context) int {
+			return conf.Get().GitServerReplicas
+		},
 		HTTPClient:  cli,
 		HTTPLimiter: parallel.NewRun(500),
 		// Use the binary name for UserAgent. This should effectively identify
@@ -82,9 +86,17 @@ type Client struct {
 	// concurrent use. It may return different results at different times.
 	Addrs func(ctx context.Context) []string
 
+	// Replicas is a function which returns the number of gitservers each
+	// repository is cloned on. If it is nil or returns less than 1, each
+	// repository is cloned on a single gitserver.
+	Replicas func(ctx context.Context) int
+
 	// UserAgent is a string identifing who the client is. It will be logged in
 	// the telemetry in gitserver.
 	UserAgent string
+
+	// unhealthy are the gitservers which recently could not be reached.
+	unhealthy unhealthyAddrs
 }
 
 // AddrForRepo returns the gitserver address to use for the given repo name.
@@ -103,12 +115,73 @@ func (c *Client) addrForKey(ctx context.Context, key string) string {
 	return addrForKey(addrs, key)
 }
 
+// addrForKey returns the address in addrs that key is placed on. Keys are
+// placed with consistent hashing, so adding or removing a gitserver only moves
+// about 1/N of the keys to a different gitserver.
 func addrForKey(addrs []string, key string) string {
+	addr, _ := addrsMap(addrs).Get(key, nil) // static maps never return an error
+	return addr
+}
+
+// legacyAddrForKey returns the address in addrs that key was placed on by
+// versions of gitserver before consistent hashing.
+func legacyAddrForKey(addrs []string, key string) string {
 	sum := md5.Sum([]byte(key))
 	serverIndex := binary.BigEndian.Uint64(sum[:]) % uint64(len(addrs))
 	return addrs[serverIndex]
 }
 
+var addrsMapCache struct {
+	sync.Mutex
+	addrs string
+	m     *endpoint.Map
+}
+
+// addrsMap returns the consistent hash map over addrs. The last map is
+// cached, since the addresses rarely change.
+func addrsMap(addrs []string) *endpoint.Map {
+	key := strings.Join(addrs, " ")
+	addrsMapCache.Lock()
+	defer addrsMapCache.Unlock()
+	if addrsMapCache.m == nil || addrsMapCache.addrs != key {
+		addrsMapCache.addrs = key
+		addrsMapCache.m = endpoint.Static(addrs...)
+	}
+	return addrsMapCache.m
+}
+
+// RepoAddr returns the address in addrs that repo is placed on. It is the
+// same address Client.AddrForRepo returns if the client's addresses are
+// addrs.
+func RepoAddr(addrs []string, repo api.RepoName) string {
+	if len(addrs) == 0 {
+		return ""
+	}
+	return addrForKey(addrs, string(protocol.NormalizeRepo(repo)))
+}
+
+// PreviousRepoAddrs returns the addresses in addrs that repo may have been
+// placed on before it was placed on addr: the gitserver it was placed on
+// before addr was added to addrs, and the gitserver it was placed on by
+// versions of gitserver before consistent hashing with the same addresses.
+// A gitserver can transfer a repository from these instead of cloning it from
+// the code host when gitservers are added. addr is never returned.
+func PreviousRepoAddrs(addrs []string, addr string, repo api.RepoName) []string {
+	if len(addrs) < 2 {
+		return nil
+	}
+	key := string(protocol.NormalizeRepo(repo))
+
+	var prev []string
+	if a, _ := addrsMap(addrs).Get(key, map[string]bool{addr: true}); a != "" {
+		prev = append(prev, a)
+	}
+	if a := legacyAddrForKey(addrs, key); a != addr && (len(prev) == 0 || a != prev[0]) {
+		prev = append(prev, a)
+	}
+	return prev
+}
+
 // ArchiveOptions contains options for the Archive func.
 type ArchiveOptions struct {
 	Treeish string   // the tree or commit to produce an archive for
@@ -142,7 +215,10 @@ func (a *archiveReader) Close() error {
 }
 
 // ArchiveURL returns a URL from which an archive of the given Git repository can
-// be downloaded from.
+// be downloaded from. The URL points to the gitserver the repository is placed
+// on, which has every revision the caller could have resolved. If that
+// gitserver recently could not be reached, it points to a healthy replica
+// instead.
 func (c *Client) ArchiveURL(ctx context.Context, repo Repo, opt ArchiveOptions) *url.URL {
 	q := url.Values{
 		"repo":    {string(repo.Name)},
@@ -156,7 +232,7 @@ func (c *Client) ArchiveURL(ctx context.Context, repo Repo, opt ArchiveOptions)
 
 	return &url.URL{
 		Scheme:   "http",
-		Host:     c.AddrForRepo(ctx, repo.Name),
+		Host:     c.archiveAddr(ctx, repo.Name),
 		Path:     "/archive",
 		RawQuery: q.Encode(),
 	}
@@ -181,8 +257,14 @@ func (c *Client) Archive(ctx context.Context, repo Repo, opt ArchiveOptions) (_
 		return nil, err
 	}
 
-	u := c.ArchiveURL(ctx, repo, opt)
-	resp, err := c.do(ctx, repo.Name, "GET", u.String(), nil)
+	// The paths can be too many to fit into the URL, so they are sent in the
+	// request body.
+	method, payload := "GET", interface{}(nil)
+	if len(opt.Paths) > 0 {
+		method, payload = "POST", &protocol.ArchiveRequest{Paths: opt.Paths}
+	}
+	u := c.ArchiveURL(ctx, repo, ArchiveOptions{Treeish: opt.Treeish, Format: opt.Format})
+	resp, err := c.doRead(ctx, repo.Name, method, "archive?"+u.RawQuery, payload, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -244,12 +326,24 @@ func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, err
 	}
 
 	req := &protocol.ExecRequest{
-		Repo:           repoName,
-		URL:            c.Repo.URL,
-		EnsureRevision: c.EnsureRevision,
-		Args:           c.Args[1:],
+		Repo:                  repoName,
+		URL:                   c.Repo.URL,
+		PartialCloneBlobLimit: c.Repo.PartialCloneBlobLimit,
+		EnsureRevision:        c.EnsureRevision,
+		Args:                  c.Args[1:],
+	}
+	var resp *http.Response
+	var err error
+	if c.readOnly() {
+		// A replica must not fetch the revision, it is only updated by the
+		// repo-updater. If it doesn't have the revision yet, the command is
+		// rerun on the primary, which may fetch it.
+		replicaReq := *req
+		replicaReq.EnsureRevision = ""
+		resp, err = c.client.doRead(ctx, repoName, "POST", "exec", &replicaReq, req)
+	} else {
+		resp, err = c.client.do(ctx, repoName, "POST", "exec", req)
 	}
-	resp, err := c.client.httpPost(ctx, repoName, "exec", req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -294,6 +388,30 @@ type Cmd struct {
 	ExitStatus     int
 }
 
+// readOnlyGitCommands are the git subcommands which never modify the
+// repository, so they can run on any gitserver the repository is cloned on.
+var readOnlyGitCommands = map[string]bool{
+	"blame":        true,
+	"cat-file":     true,
+	"diff":         true,
+	"for-each-ref": true,
+	"grep":         true,
+	"log":          true,
+	"ls-files":     true,
+	"ls-tree":      true,
+	"merge-base":   true,
+	"rev-list":     true,
+	"rev-parse":    true,
+	"shortlog":     true,
+	"show":         true,
+	"show-ref":     true,
+}
+
+// readOnly reports whether c can run on a replica of the repository.
+func (c *Cmd) readOnly() bool {
+	return len(c.Args) > 1 && readOnlyGitCommands[c.Args[1]]
+}
+
 // Repo represents a repository on gitserver. It contains the information necessary to identify and
 // create/clone it.
 type Repo struct {
@@ -303,6 +421,11 @@ type Repo struct {
 	// this field is optional (it will use the last-used Git remote URL). If the repository is not
 	// cloned on the gitserver, the request will fail.
 	URL string
+
+	// PartialCloneBlobLimit is the blob size limit gitserver uses if it
+	// needs to clone the repository. If set, gitserver creates a partial clone
+	// without the blobs larger than this size.
+	PartialCloneBlobLimit string
 }
 
 // Command creates a new Cmd. Command name must be 'git',
@@ -561,13 +684,35 @@ func (c *Client) doListOne(ctx context.Context, urlSuffix, addr string) ([]strin
 // Repo updates are not guaranteed to occur. If a repo has been updated
 // recently (within the Since duration specified in the request), the
 // update won't happen.
+//
+// The repo is updated on every gitserver it is cloned on. The response is the
+// one of the primary gitserver, errors of the other replicas are only logged.
 func (c *Client) RequestRepoUpdate(ctx context.Context, repo Repo, since time.Duration) (*protocol.RepoUpdateResponse, error) {
 	req := &protocol.RepoUpdateRequest{
-		Repo:  repo.Name,
-		URL:   repo.URL,
-		Since: since,
+		Repo:                  repo.Name,
+		URL:                   repo.URL,
+		PartialCloneBlobLimit: repo.PartialCloneBlobLimit,
+		Since:                 since,
+	}
+
+	addrs := c.ReplicaAddrsForRepo(ctx, repo.Name)
+	var wg sync.WaitGroup
+	for _, addr := range addrs[1:] {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			if _, err := c.requestRepoUpdate(ctx, addr, req); err != nil {
+				log15.Warn("failed to update repo on gitserver replica", "repo", repo.Name, "addr", addr, "error", err)
+			}
+		}(addr)
 	}
-	resp, err := c.httpPost(ctx, repo.Name, "repo-update", req)
+	info, err := c.requestRepoUpdate(ctx, addrs[0], req)
+	wg.Wait()
+	return info, err
+}
+
+func (c *Client) requestRepoUpdate(ctx context.Context, addr string, req *protocol.RepoUpdateRequest) (*protocol.RepoUpdateResponse, error) {
+	resp, err := c.do(ctx, req.Repo, "POST", "http://"+addr+"/repo-update", req)
 	if err != nil {
 		return nil, err
 	}
@@ -736,12 +881,22 @@ func (c *Client) RepoInfo(ctx context.Context, repos ...api.RepoName) (*protocol
 	return &res, err.ErrorOrNil()
 }
 
-// Remove removes the repository clone from gitserver.
+// Remove removes the repository clone from every gitserver it is cloned on.
 func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	req := &protocol.RepoDeleteRequest{
 		Repo: repo,
 	}
-	resp, err := c.httpPost(ctx, repo, "delete", req)
+	err := new(multierror.Error)
+	for _, addr := range c.ReplicaAddrsForRepo(ctx, repo) {
+		if e := c.remove(ctx, addr, req); e != nil {
+			err = multierror.Append(err, e)
+		}
+	}
+	return err.ErrorOrNil()
+}
+
+func (c *Client) remove(ctx context.Context, addr string, req *protocol.RepoDeleteRequest) error {
+	resp, err := c.do(ctx, req.Repo, "POST", "http://"+addr+"/delete", req)
 	if err != nil {
 		return err
 	}
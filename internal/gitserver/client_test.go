@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -22,6 +24,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/gitserver/server"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
@@ -33,7 +36,7 @@ func TestClient_ListCloned(t *testing.T) {
 			switch r.URL.String() {
 			case "http://gitserver-0/list?cloned":
 				return &http.Response{
-					Body: ioutil.NopCloser(bytes.NewBufferString(`["repo0-a", "repo0-b"]`)),
+					Body: ioutil.NopCloser(bytes.NewBufferString(`["repo-a", "repo-c"]`)),
 				}, nil
 			case "http://gitserver-1/list?cloned":
 				return &http.Response{
@@ -45,7 +48,7 @@ func TestClient_ListCloned(t *testing.T) {
 		}),
 	}
 
-	want := []string{"repo0-a", "repo1-a", "repo1-b"}
+	want := []string{"repo-a", "repo1-a", "repo1-b"}
 	got, err := cli.ListCloned(context.Background())
 	if err != nil {
 		t.Fatal(err)
@@ -57,6 +60,218 @@ func TestClient_ListCloned(t *testing.T) {
 	}
 }
 
+func TestRepoAddr_addGitserver(t *testing.T) {
+	var addrs []string
+	for i := 0; i < 10; i++ {
+		addrs = append(addrs, fmt.Sprintf("gitserver-%d:3178", i))
+	}
+	const added = "gitserver-10:3178"
+	newAddrs := append(append([]string{}, addrs...), added)
+
+	const n = 10000
+	moved := 0
+	for i := 0; i < n; i++ {
+		repo := api.RepoName(fmt.Sprintf("github.com/org/repo-%d", i))
+		before, after := gitserver.RepoAddr(addrs, repo), gitserver.RepoAddr(newAddrs, repo)
+		if before == after {
+			continue
+		}
+		moved++
+		if after != added {
+			t.Fatalf("%s moved from %s to %s, want it to only move to the added gitserver", repo, before, after)
+		}
+		if prev := gitserver.PreviousRepoAddrs(newAddrs, added, repo); len(prev) == 0 || prev[0] != before {
+			t.Fatalf("PreviousRepoAddrs(%s) = %v, want %s first", repo, prev, before)
+		}
+	}
+
+	// We expect about 1/11 of the repos to move.
+	if moved == 0 || moved > n/5 {
+		t.Errorf("%d of %d repos moved when adding a gitserver", moved, n)
+	}
+}
+
+func TestRepoAddrs(t *testing.T) {
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2", "gitserver-3"}
+	for i := 0; i < 100; i++ {
+		repo := api.RepoName(fmt.Sprintf("github.com/org/repo-%d", i))
+		for replicas := 0; replicas <= len(addrs)+1; replicas++ {
+			got := gitserver.RepoAddrs(addrs, repo, replicas)
+
+			want := replicas
+			if want < 1 {
+				want = 1
+			} else if want > len(addrs) {
+				want = len(addrs)
+			}
+			if len(got) != want {
+				t.Fatalf("RepoAddrs(%s, %d) = %v, want %d addresses", repo, replicas, got, want)
+			}
+			if got[0] != gitserver.RepoAddr(addrs, repo) {
+				t.Fatalf("RepoAddrs(%s, %d) = %v, want the primary %s first", repo, replicas, got, gitserver.RepoAddr(addrs, repo))
+			}
+			seen := map[string]bool{}
+			for _, addr := range got {
+				if seen[addr] {
+					t.Fatalf("RepoAddrs(%s, %d) = %v contains duplicates", repo, replicas, got)
+				}
+				seen[addr] = true
+			}
+
+			// Adding a replica keeps the existing ones.
+			if replicas > 1 {
+				if prev := gitserver.RepoAddrs(addrs, repo, replicas-1); !cmp.Equal(prev, got[:len(prev)]) {
+					t.Fatalf("RepoAddrs(%s, %d) = %v is not a prefix of %v", repo, replicas-1, prev, got)
+				}
+			}
+		}
+	}
+}
+
+func TestClient_replicas(t *testing.T) {
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2"}
+	const repo = "github.com/org/repo"
+	replicaAddrs := gitserver.RepoAddrs(addrs, repo, 2)
+
+	var (
+		mu   sync.Mutex
+		hits map[string]int
+		down map[string]bool
+	)
+	cli := &gitserver.Client{
+		Addrs:    func(ctx context.Context) []string { return addrs },
+		Replicas: func(ctx context.Context) int { return 2 },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			hits[r.URL.Host]++
+			if down[r.URL.Host] {
+				return nil, errors.New("connection refused")
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("ok")),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+	run := func(n int) {
+		t.Helper()
+		mu.Lock()
+		hits = map[string]int{}
+		mu.Unlock()
+		for i := 0; i < n; i++ {
+			cmd := cli.Command("git", "rev-parse", "HEAD")
+			cmd.Repo = gitserver.Repo{Name: repo}
+			if out, err := cmd.Output(context.Background()); err != nil || string(out) != "ok" {
+				t.Fatalf("got %q (error %v), want ok", out, err)
+			}
+		}
+	}
+
+	// Reads are spread across both replicas and never go to the third
+	// gitserver.
+	run(100)
+	if hits[replicaAddrs[0]] == 0 || hits[replicaAddrs[1]] == 0 || len(hits) != 2 {
+		t.Errorf("reads were not spread across the replicas %v: %v", replicaAddrs, hits)
+	}
+
+	// If the primary is down, reads fail over to the other replica and stop
+	// trying the primary.
+	down = map[string]bool{replicaAddrs[0]: true}
+	run(100)
+	if hits[replicaAddrs[0]] > 1 || hits[replicaAddrs[1]] != 100 {
+		t.Errorf("reads did not fail over from the primary %s: %v", replicaAddrs[0], hits)
+	}
+
+	// Archive URLs point to the primary, unless it is down.
+	if got := cli.ArchiveURL(context.Background(), gitserver.Repo{Name: repo}, gitserver.ArchiveOptions{}).Host; got != replicaAddrs[1] {
+		t.Errorf("got archive URL host %s while the primary is down, want %s", got, replicaAddrs[1])
+	}
+	down = nil
+	other := &gitserver.Client{Addrs: cli.Addrs, Replicas: cli.Replicas}
+	if got := other.ArchiveURL(context.Background(), gitserver.Repo{Name: repo}, gitserver.ArchiveOptions{}).Host; got != replicaAddrs[0] {
+		t.Errorf("got archive URL host %s, want the primary %s", got, replicaAddrs[0])
+	}
+}
+
+func TestClient_replicasWritesAndMissingRevisions(t *testing.T) {
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2"}
+	const repo = "github.com/org/repo"
+	replicaAddrs := gitserver.RepoAddrs(addrs, repo, 3)
+	primary := replicaAddrs[0]
+
+	var (
+		mu   sync.Mutex
+		hits map[string]int
+	)
+	cli := &gitserver.Client{
+		Addrs:    func(ctx context.Context) []string { return addrs },
+		Replicas: func(ctx context.Context) int { return 3 },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			var req protocol.ExecRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return nil, err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			hits[r.URL.Host]++
+
+			// Only the primary has the new commit, and only it may fetch.
+			if r.URL.Host != primary && req.EnsureRevision != "" {
+				t.Errorf("replica %s was asked to ensure revision %q", r.URL.Host, req.EnsureRevision)
+			}
+			if r.URL.Host != primary && req.Args[len(req.Args)-1] == "new" {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+					Trailer: http.Header{
+						"X-Exec-Exit-Status": {"128"},
+						"X-Exec-Stderr":      {"fatal: ambiguous argument 'new': unknown revision or path not in the working tree."},
+					},
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(r.URL.Host)),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+	run := func(ensureRevision string, args ...string) []byte {
+		t.Helper()
+		mu.Lock()
+		hits = map[string]int{}
+		mu.Unlock()
+		cmd := cli.Command("git", args...)
+		cmd.Repo = gitserver.Repo{Name: repo}
+		cmd.EnsureRevision = ensureRevision
+		out, err := cmd.Output(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// Commands which may modify the repository always run on the primary.
+	for i := 0; i < 20; i++ {
+		if out := run("", "update-ref", "refs/heads/x", "HEAD"); string(out) != primary {
+			t.Fatalf("update-ref ran on %s, want the primary %s", out, primary)
+		}
+	}
+
+	// A replica which doesn't have a revision yet falls back to the primary.
+	for i := 0; i < 20; i++ {
+		if out := run("new", "rev-parse", "new"); string(out) != primary {
+			t.Fatalf("rev-parse of a missing revision was served by %s, want the primary %s", out, primary)
+		}
+		if hits[primary] != 1 {
+			t.Fatalf("got %d requests to the primary, want 1: %v", hits[primary], hits)
+		}
+	}
+}
+
 func TestClient_Archive(t *testing.T) {
 	root, err := ioutil.TempDir("", t.Name())
 	if err != nil {
@@ -75,19 +290,27 @@ func TestClient_Archive(t *testing.T) {
 		return []string{u.Host}
 	}
 
+	simple := createSimpleGitRepo(t, root)
+
 	tests := map[api.RepoName]struct {
 		remote string
+		paths  []string
 		want   map[string]string
 		err    error
 	}{
 		"simple": {
-			remote: createSimpleGitRepo(t, root),
+			remote: simple,
 			want: map[string]string{
 				"dir1/":      "",
 				"dir1/file1": "infile1",
 				"file 2":     "infile2",
 			},
 		},
+		"simple-paths": {
+			remote: simple,
+			paths:  []string{"file 2"},
+			want:   map[string]string{"file 2": "infile2"},
+		},
 		"repo-with-dotgit-dir": {
 			remote: createRepoWithDotGitDir(t, root),
 			want:   map[string]string{"file1": "hello\n", ".git/mydir/file2": "milton\n", ".git/mydir/": "", ".git/": ""},
@@ -106,7 +329,7 @@ func TestClient_Archive(t *testing.T) {
 				}
 			}
 
-			rc, err := cli.Archive(ctx, gitserver.Repo{Name: name}, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "zip"})
+			rc, err := cli.Archive(ctx, gitserver.Repo{Name: name}, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "zip", Paths: test.paths})
 			if have, want := fmt.Sprint(err), fmt.Sprint(test.err); have != want {
 				t.Errorf("archive: have err %v, want %v", have, want)
 			}
@@ -19,11 +19,21 @@ type ExecRequest struct {
 	// cloned on the gitserver, the request will fail.
 	URL string `json:"url,omitempty"`
 
+	// PartialCloneBlobLimit is the blob size limit used if the request causes
+	// the repository to be cloned. See RepoUpdateRequest.
+	PartialCloneBlobLimit string `json:"partialCloneBlobLimit,omitempty"`
+
 	EnsureRevision string      `json:"ensureRevision"`
 	Args           []string    `json:"args"`
 	Opt            *RemoteOpts `json:"opt"`
 }
 
+// ArchiveRequest is the optional body of a POST request to /archive. It lists
+// the paths to archive, which can be too many to fit into the URL.
+type ArchiveRequest struct {
+	Paths []string `json:"paths"`
+}
+
 // RemoteOpts configures interactions with a remote repository.
 type RemoteOpts struct {
 	SSH   *SSHConfig   `json:"ssh"`   // SSH configuration for communication with the remote
@@ -48,6 +58,12 @@ type RepoUpdateRequest struct {
 	Repo  api.RepoName  `json:"repo"`  // identifying URL for repo
 	URL   string        `json:"url"`   // repo's remote URL
 	Since time.Duration `json:"since"` // debounce interval for queries, used only with request-repo-update
+
+	// PartialCloneBlobLimit is only used if the repository isn't cloned yet.
+	// If set, the repository is cloned as a partial clone without the blobs
+	// larger than this size (git clone --filter=blob:limit=<size>). Missing
+	// blobs are fetched lazily when commands need them.
+	PartialCloneBlobLimit string `json:"partialCloneBlobLimit,omitempty"`
 }
 
 // RepoUpdateResponse returns meta information of the repo enqueued for
@@ -96,6 +112,19 @@ type IsRepoClonedRequest struct {
 	Repo api.RepoName
 }
 
+// ReposClonedRequest is a request to determine which of the given repos
+// currently exist on gitserver.
+type ReposClonedRequest struct {
+	// Repos are the repositories to check.
+	Repos []api.RepoName
+}
+
+// ReposClonedResponse is the response to a ReposClonedRequest.
+type ReposClonedResponse struct {
+	// Cloned are the requested repositories which exist on gitserver.
+	Cloned []api.RepoName
+}
+
 // RepoInfoRequest is a request for information about multiple repositories on gitserver.
 type RepoInfoRequest struct {
 	// Repos are the repositories to get information about.
@@ -122,6 +151,13 @@ type RepoInfo struct {
 	// recloned automatically, so this time is likely to move forward
 	// periodically.
 	CloneTime *time.Time
+
+	// PartialCloneFilter is the object filter of a partial clone (such as
+	// "blob:limit=1m"). It is empty for full clones.
+	PartialCloneFilter string `json:",omitempty"`
+	// PromisorPackBytes is the size of the packs fetched from the remote of a
+	// partial clone, including the blobs fetched lazily after cloning.
+	PromisorPackBytes int64 `json:",omitempty"`
 }
 
 // RepoInfoResponse is the response to a repository information request
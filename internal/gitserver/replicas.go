@@ -0,0 +1,249 @@
+package gitserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// Each repository is cloned on the gitserver it is placed on (the primary)
+// and on the next replicas-1 gitservers on the consistent hash ring. All of
+// them are updated by the repo-updater scheduler. Read-only requests (exec of
+// read-only git commands and archive) are spread across the replicas, so that
+// a very popular repository doesn't saturate a single gitserver. All other
+// requests go to the primary.
+
+// unhealthyAddrTTL is how long a gitserver which could not be reached is only
+// tried as a last resort by read-only requests.
+const unhealthyAddrTTL = 10 * time.Second
+
+// RepoAddrs returns the addresses in addrs that repo is cloned on when each
+// repository is cloned on replicas gitservers. The first address is the one
+// RepoAddr returns.
+func RepoAddrs(addrs []string, repo api.RepoName, replicas int) []string {
+	if len(addrs) == 0 {
+		return nil
+	}
+	if replicas < 1 {
+		replicas = 1
+	}
+	if replicas > len(addrs) {
+		replicas = len(addrs)
+	}
+
+	key := string(protocol.NormalizeRepo(repo))
+	m := addrsMap(addrs)
+	replicaAddrs := make([]string, 0, replicas)
+	exclude := make(map[string]bool, replicas)
+	for len(replicaAddrs) < replicas {
+		addr, _ := m.Get(key, exclude) // static maps never return an error
+		if addr == "" {
+			break
+		}
+		replicaAddrs = append(replicaAddrs, addr)
+		exclude[addr] = true
+	}
+	return replicaAddrs
+}
+
+// ReplicaAddrsForRepo returns the addresses of the gitservers repo is cloned
+// on. The first address is the one AddrForRepo returns.
+func (c *Client) ReplicaAddrsForRepo(ctx context.Context, repo api.RepoName) []string {
+	addrs := c.Addrs(ctx)
+	if len(addrs) == 0 {
+		panic("unexpected state: no gitserver addresses")
+	}
+	return RepoAddrs(addrs, repo, c.replicas(ctx))
+}
+
+func (c *Client) replicas(ctx context.Context) int {
+	if c.Replicas == nil {
+		return 1
+	}
+	return c.Replicas(ctx)
+}
+
+// readAddrs returns the addresses of the gitservers repo is cloned on in the
+// order a read-only request should try them. The healthy gitservers come
+// first, starting at a random one to spread the load. Gitservers which
+// recently could not be reached come last.
+func (c *Client) readAddrs(ctx context.Context, repo api.RepoName) []string {
+	replicaAddrs := c.ReplicaAddrsForRepo(ctx, repo)
+	if len(replicaAddrs) == 1 {
+		return replicaAddrs
+	}
+
+	healthy := make([]string, 0, len(replicaAddrs))
+	var unhealthy []string
+	start := rand.Intn(len(replicaAddrs))
+	for i := range replicaAddrs {
+		addr := replicaAddrs[(start+i)%len(replicaAddrs)]
+		if c.unhealthy.has(addr) {
+			unhealthy = append(unhealthy, addr)
+		} else {
+			healthy = append(healthy, addr)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// archiveAddr returns the address of the gitserver an archive URL for repo
+// points to. Since the client following the URL can't fail over, this is the
+// primary unless it recently could not be reached.
+func (c *Client) archiveAddr(ctx context.Context, repo api.RepoName) string {
+	replicaAddrs := c.ReplicaAddrsForRepo(ctx, repo)
+	for _, addr := range replicaAddrs {
+		if !c.unhealthy.has(addr) {
+			return addr
+		}
+	}
+	return replicaAddrs[0]
+}
+
+// doRead performs a read-only request for repo on one of the gitservers it is
+// cloned on. If a gitserver can't be reached, or doesn't have the repo (yet),
+// the request is retried on the next one. The primary is sent primaryPayload
+// instead of payload, e.g. an exec request which may fetch a missing
+// revision.
+//
+// Replicas can lag behind the primary. If the request is served by a replica
+// which doesn't have the requested revision yet, reading the response body
+// transparently reruns the request on the primary.
+func (c *Client) doRead(ctx context.Context, repo api.RepoName, method, op string, payload, primaryPayload interface{}) (*http.Response, error) {
+	primary := c.AddrForRepo(ctx, repo)
+	addrs := c.readAddrs(ctx, repo)
+	for i, addr := range addrs {
+		last := i == len(addrs)-1
+		p := payload
+		if addr == primary {
+			p = primaryPayload
+		}
+		resp, err := c.do(ctx, repo, method, "http://"+addr+"/"+op, p)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			c.unhealthy.mark(addr)
+			if last {
+				return nil, err
+			}
+			continue
+		}
+		if resp.StatusCode == http.StatusNotFound && !last {
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode == http.StatusOK && addr != primary {
+			resp.Body = &primaryFallbackReader{
+				rc:      resp.Body,
+				trailer: resp.Trailer,
+				retry: func() (*http.Response, error) {
+					return c.do(ctx, repo, method, "http://"+primary+"/"+op, primaryPayload)
+				},
+			}
+		}
+		return resp, nil
+	}
+	panic("unreachable: a repo is always cloned on at least one gitserver")
+}
+
+// primaryFallbackReader is the body of an exec or archive response from a
+// replica. If the command failed without any output because the replica
+// doesn't have the revision yet, it reruns the command on the primary and
+// reads its output and trailer instead.
+type primaryFallbackReader struct {
+	rc      io.ReadCloser
+	trailer http.Header // the trailer the caller reads, updated on retry
+	read    bool        // whether any output was read
+	retry   func() (*http.Response, error)
+	retried *http.Response
+}
+
+func (r *primaryFallbackReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	if n > 0 {
+		r.read = true
+	}
+	if err != io.EOF {
+		return n, err
+	}
+
+	if r.retried != nil {
+		// The trailer of the rerun is only known at its EOF.
+		for k := range r.trailer {
+			delete(r.trailer, k)
+		}
+		for k, v := range r.retried.Trailer {
+			r.trailer[k] = v
+		}
+		return n, err
+	}
+
+	if r.read || r.retry == nil || r.trailer.Get("X-Exec-Exit-Status") == "0" || !isRevisionNotFoundStderr(r.trailer.Get("X-Exec-Stderr")) {
+		return n, err
+	}
+	retry := r.retry
+	r.retry = nil
+	resp, rerr := retry()
+	if rerr != nil {
+		return 0, rerr
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return 0, fmt.Errorf("unexpected status code from primary: %d", resp.StatusCode)
+	}
+	r.rc.Close()
+	r.rc = resp.Body
+	r.retried = resp
+	return r.Read(p)
+}
+
+func (r *primaryFallbackReader) Close() error {
+	return r.rc.Close()
+}
+
+// isRevisionNotFoundStderr reports whether stderr of a failed git command
+// indicates that a revision doesn't exist in the repository.
+func isRevisionNotFoundStderr(stderr string) bool {
+	for _, msg := range []string{"unknown revision", "bad revision", "bad object", "invalid object name", "Not a valid object"} {
+		if strings.Contains(stderr, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// unhealthyAddrs tracks the gitservers which recently could not be reached.
+// The zero value is ready to use.
+type unhealthyAddrs struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func (u *unhealthyAddrs) mark(addr string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.until == nil {
+		u.until = make(map[string]time.Time)
+	}
+	u.until[addr] = time.Now().Add(unhealthyAddrTTL)
+}
+
+func (u *unhealthyAddrs) has(addr string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	until, ok := u.until[addr]
+	if ok && time.Now().After(until) {
+		delete(u.until, addr)
+		return false
+	}
+	return ok
+}
@@ -64,6 +64,7 @@ func NewExternalHTTPClientFactory() *Factory {
 		// TODO(tsenart): Use middle for Prometheus instrumentation later.
 		NewMiddleware(
 			ContextErrorMiddleware,
+			NewRetryMiddleware(ExternalRetryPolicy),
 		),
 		// ExternalTransportOpt needs to be before TracedTransportOpt and
 		// NewCachedTransportOpt since it wants to extract a http.Transport,
@@ -0,0 +1,243 @@
+package httpcli
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+)
+
+// A RetryPolicy configures the retries of NewRetryMiddleware.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times a request is retried.
+	MaxRetries int
+
+	// MinDelay is the delay before the first retry. It doubles for each
+	// following retry, up to MaxDelay. A random jitter of up to half the
+	// delay is subtracted, so that clients don't retry in lockstep.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// MaxRetryAfter is the longest Retry-After a request is retried after.
+	// If a response asks us to wait longer, it is returned to the caller.
+	MaxRetryAfter time.Duration
+}
+
+// ExternalRetryPolicy is the RetryPolicy used for requests to external
+// services.
+var ExternalRetryPolicy = RetryPolicy{
+	MaxRetries:    3,
+	MinDelay:      200 * time.Millisecond,
+	MaxDelay:      5 * time.Second,
+	MaxRetryAfter: 30 * time.Second,
+}
+
+var (
+	retriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "httpcli",
+		Name:      "retries_total",
+		Help:      "Number of retried HTTP requests, by the reason of the retry (an HTTP status code or error).",
+	}, []string{"reason"})
+	retriesExhaustedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "httpcli",
+		Name:      "retries_exhausted_total",
+		Help:      "Number of HTTP requests which still failed after the maximum number of retries.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(retriesCounter)
+	prometheus.MustRegister(retriesExhaustedCounter)
+}
+
+// NewRetryMiddleware returns a middleware that retries idempotent requests
+// which failed with a transient error: a connection error, or a 429, 500,
+// 502, 503 or 504 response. Retries are spaced out with exponential backoff
+// and jitter, unless the response has a Retry-After header, which is honored.
+//
+// GitHub responds with 403 instead of 429 when a rate limit is exceeded. Such
+// a 403 (with X-RateLimit-Remaining: 0 or a Retry-After header) is retried
+// too, once the rate limit resets.
+//
+// Requests with other methods than GET, HEAD, OPTIONS, PUT and DELETE are
+// only retried if they have an Idempotency-Key header, which the server can
+// use to deduplicate them. Requests with a body that can't be replayed are
+// never retried.
+//
+// If the request context carries a ratelimit.Monitor (see
+// ratelimit.WithMonitor), it is updated with the responses that are retried.
+// The response that is returned is left to the caller.
+func NewRetryMiddleware(p RetryPolicy) Middleware {
+	return func(cli Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if !isRetryableRequest(req) {
+				return cli.Do(req)
+			}
+
+			ctx := req.Context()
+			for attempt := 0; ; attempt++ {
+				if attempt > 0 && req.GetBody != nil {
+					body, err := req.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					req.Body = body
+				}
+
+				resp, err := cli.Do(req)
+				if ctx.Err() != nil {
+					return resp, err
+				}
+
+				reason, retry := retryReason(resp, err)
+				if !retry {
+					return resp, err
+				}
+				if attempt >= p.MaxRetries {
+					retriesExhaustedCounter.Inc()
+					return resp, err
+				}
+
+				delay := p.backoff(attempt)
+				if resp != nil {
+					if m := ratelimit.MonitorFromContext(ctx); m != nil {
+						m.Update(resp.Header)
+					}
+					if retryAfter, ok := retryAfter(resp.Header, time.Now()); ok {
+						if retryAfter > p.MaxRetryAfter {
+							return resp, err
+						}
+						delay = retryAfter
+					}
+					// Drain the body, so that the connection can be reused.
+					_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+					resp.Body.Close()
+				}
+
+				retriesCounter.WithLabelValues(reason).Inc()
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		})
+	}
+}
+
+// backoff returns the delay before the retry after the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.MinDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// isRetryableRequest reports whether req can safely be sent again.
+func isRetryableRequest(req *http.Request) bool {
+	switch req.Method {
+	case "", "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// retryReason reports whether a request which returned resp and err should
+// be retried, and why.
+func retryReason(resp *http.Response, err error) (reason string, retry bool) {
+	if err != nil {
+		return "error", isTransientError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return strconv.Itoa(resp.StatusCode), true
+	case http.StatusForbidden:
+		// A 403 is usually final, unless it's GitHub telling us we
+		// exceeded a rate limit.
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "" {
+			return strconv.Itoa(resp.StatusCode), true
+		}
+	}
+	return "", false
+}
+
+// retryAfter returns how long to wait before retrying a response with the
+// headers h: the Retry-After, or else the time until the rate limit resets
+// if it is exhausted.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if d, ok := parseRetryAfter(h.Get("Retry-After"), now); ok {
+		return d, true
+	}
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	if d := time.Unix(reset, 0).Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// isTransientError reports whether err is a connection error which may not
+// happen again, such as a reset connection or a timeout.
+func isTransientError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return netErr.Timeout() || netErr.Temporary()
+	}
+	return false
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
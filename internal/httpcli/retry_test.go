@@ -0,0 +1,255 @@
+package httpcli
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+)
+
+func TestRetryMiddleware(t *testing.T) {
+	policy := RetryPolicy{
+		MaxRetries:    2,
+		MinDelay:      time.Millisecond,
+		MaxDelay:      10 * time.Millisecond,
+		MaxRetryAfter: time.Second,
+	}
+
+	response := func(code int, headers ...string) *http.Response {
+		resp := &http.Response{
+			StatusCode: code,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader(http.StatusText(code))),
+		}
+		for i := 0; i < len(headers); i += 2 {
+			resp.Header.Set(headers[i], headers[i+1])
+		}
+		return resp
+	}
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+
+	for _, tc := range []struct {
+		name     string
+		method   string
+		header   http.Header
+		body     string
+		results  []result
+		attempts int
+		status   int
+		err      bool
+	}{
+		{
+			name:     "success is not retried",
+			results:  []result{{resp: response(200)}},
+			attempts: 1,
+			status:   200,
+		},
+		{
+			name:     "client errors are not retried",
+			results:  []result{{resp: response(404)}},
+			attempts: 1,
+			status:   404,
+		},
+		{
+			name:     "transient 5xx is retried",
+			results:  []result{{resp: response(503)}, {resp: response(502)}, {resp: response(200)}},
+			attempts: 3,
+			status:   200,
+		},
+		{
+			name:     "retries are bounded",
+			results:  []result{{resp: response(500)}, {resp: response(500)}, {resp: response(500)}, {resp: response(200)}},
+			attempts: 3,
+			status:   500,
+		},
+		{
+			name:     "connection resets are retried",
+			results:  []result{{err: syscall.ECONNRESET}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+		{
+			name:     "other errors are not retried",
+			results:  []result{{err: syscall.EACCES}, {resp: response(200)}},
+			attempts: 1,
+			err:      true,
+		},
+		{
+			name:     "429 with a short Retry-After is retried",
+			results:  []result{{resp: response(429, "Retry-After", "0")}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+		{
+			name:     "429 with a long Retry-After is returned",
+			results:  []result{{resp: response(429, "Retry-After", "3600")}, {resp: response(200)}},
+			attempts: 1,
+			status:   429,
+		},
+		{
+			name:     "403 is not retried",
+			results:  []result{{resp: response(403)}, {resp: response(200)}},
+			attempts: 1,
+			status:   403,
+		},
+		{
+			name:     "403 with an exhausted rate limit is retried once it resets",
+			results:  []result{{resp: response(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", "0")}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+		{
+			name:     "403 with a Retry-After is retried",
+			results:  []result{{resp: response(403, "Retry-After", "0")}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+		{
+			name:     "403 with a rate limit resetting later than MaxRetryAfter is returned",
+			results:  []result{{resp: response(403, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))}, {resp: response(200)}},
+			attempts: 1,
+			status:   403,
+		},
+		{
+			name:     "POST is not retried",
+			method:   "POST",
+			results:  []result{{resp: response(503)}, {resp: response(200)}},
+			attempts: 1,
+			status:   503,
+		},
+		{
+			name:     "POST with an Idempotency-Key is retried",
+			method:   "POST",
+			header:   http.Header{"Idempotency-Key": {"1234"}},
+			body:     "payload",
+			results:  []result{{resp: response(503)}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+		{
+			name:     "PUT body is replayed",
+			method:   "PUT",
+			body:     "payload",
+			results:  []result{{resp: response(503)}, {resp: response(200)}},
+			attempts: 2,
+			status:   200,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			cli := NewRetryMiddleware(policy)(DoerFunc(func(r *http.Request) (*http.Response, error) {
+				if tc.body != "" {
+					body, _ := ioutil.ReadAll(r.Body)
+					if string(body) != tc.body {
+						t.Errorf("attempt %d: have body %q, want %q", attempts, body, tc.body)
+					}
+				}
+				res := tc.results[attempts]
+				attempts++
+				return res.resp, res.err
+			}))
+
+			method := tc.method
+			if method == "" {
+				method = "GET"
+			}
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req, _ := http.NewRequest(method, "http://dev/null", body)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			resp, err := cli.Do(req)
+			if have, want := attempts, tc.attempts; have != want {
+				t.Errorf("have %d attempts, want %d", have, want)
+			}
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have, want := resp.StatusCode, tc.status; have != want {
+				t.Errorf("have status %d, want %d", have, want)
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_contextCanceled(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, MinDelay: time.Hour, MaxDelay: time.Hour}
+	cli := NewRetryMiddleware(policy)(DoerFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 503, Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest("GET", "http://dev/null", nil)
+	if _, err := cli.Do(req.WithContext(ctx)); err != context.DeadlineExceeded {
+		t.Fatalf("have error %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryMiddleware_rateLimitMonitor(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 1, MaxRetryAfter: time.Minute}
+	attempts := 0
+	cli := NewRetryMiddleware(policy)(DoerFunc(func(r *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return &http.Response{
+				StatusCode: 429,
+				Header:     http.Header{"Retry-After": {"0"}, "Ratelimit-Limit": {"100"}, "Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"1"}},
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+			}, nil
+		}
+		return &http.Response{StatusCode: 200, Header: make(http.Header), Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+	}))
+
+	m := &ratelimit.Monitor{}
+	req, _ := http.NewRequest("GET", "http://dev/null", nil)
+	if _, err := cli.Do(req.WithContext(ratelimit.WithMonitor(context.Background(), m))); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, _, _, known := m.Get(); !known || remaining != 0 {
+		t.Errorf("monitor was not updated with the retried response: remaining=%d known=%v", remaining, known)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{value: "", ok: false},
+		{value: "120", want: 2 * time.Minute, ok: true},
+		{value: "-1", ok: false},
+		{value: "Wed, 01 Apr 2020 12:00:30 GMT", want: 30 * time.Second, ok: true},
+		{value: "Wed, 01 Apr 2020 11:00:00 GMT", want: 0, ok: true},
+		{value: "soon", ok: false},
+	} {
+		have, ok := parseRetryAfter(tc.value, now)
+		if have != tc.want || ok != tc.ok {
+			t.Errorf("parseRetryAfter(%q): have (%v, %v), want (%v, %v)", tc.value, have, ok, tc.want, tc.ok)
+		}
+	}
+}
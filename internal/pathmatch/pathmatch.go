@@ -111,6 +111,12 @@ func (pm pathMatcherAnd) String() string {
 	return b.String()
 }
 
+// And returns a PathMatcher that matches a path iff all of the given matchers
+// match the path.
+func And(matchers ...PathMatcher) PathMatcher {
+	return pathMatcherAnd(matchers)
+}
+
 // CompilePatterns compiles the patterns into a PathMatcher func that matches
 // a path iff all patterns match the path.
 func CompilePatterns(patterns []string, options CompileOptions) (PathMatcher, error) {
@@ -55,3 +55,26 @@ func TestCompilePathPatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestAnd(t *testing.T) {
+	a, err := CompilePattern(`\.go$`, CompileOptions{RegExp: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := CompilePattern(`^cmd/`, CompileOptions{RegExp: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := And(a, b)
+
+	want := map[string]bool{
+		"cmd/main.go": true,
+		"main.go":     false,
+		"cmd/README":  false,
+	}
+	for path, want := range want {
+		if got := match.MatchPath(path); got != want {
+			t.Errorf("path %q: got %v, want %v", path, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"context"
 	"net/http"
 	"strconv"
 	"sync"
@@ -145,3 +146,18 @@ func (c *Monitor) now() time.Time {
 	}
 	return time.Now()
 }
+
+type monitorKey struct{}
+
+// WithMonitor returns a copy of ctx which carries m. HTTP middleware which
+// handles responses on behalf of the API client, such as retries, updates m
+// with the rate limit information of these responses.
+func WithMonitor(ctx context.Context, m *Monitor) context.Context {
+	return context.WithValue(ctx, monitorKey{}, m)
+}
+
+// MonitorFromContext returns the Monitor carried by ctx, or nil.
+func MonitorFromContext(ctx context.Context) *Monitor {
+	m, _ := ctx.Value(monitorKey{}).(*Monitor)
+	return m
+}
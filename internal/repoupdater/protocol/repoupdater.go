@@ -128,6 +128,10 @@ func (r *RepoInfo) String() string {
 // VCSInfo describes how to access an external repository's Git data (to clone or update it).
 type VCSInfo struct {
 	URL string // the Git remote URL
+
+	// PartialCloneBlobLimit is the blob size limit gitserver uses to create a
+	// partial clone of the repository. It is empty for full clones.
+	PartialCloneBlobLimit string `json:",omitempty"`
 }
 
 // RepoLinks contains URLs and URL patterns for objects in this repository.
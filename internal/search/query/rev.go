@@ -0,0 +1,55 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RevScope is the parsed value of a rev: field.
+type RevScope struct {
+	// Head is the revision to search. The empty string is the default
+	// branch.
+	Head string
+
+	// Base is only set for diff scopes (rev:base...head). The search is then
+	// restricted to the files changed on Head since it diverged from Base.
+	Base string
+}
+
+// IsDiff reports whether s restricts the search to the files changed between
+// two revisions.
+func (s RevScope) IsDiff() bool {
+	return s.Base != ""
+}
+
+// ParseRevScope parses the value of a rev: field, which is either a revision
+// (rev:v1.2.3) or a diff scope (rev:main...my-branch). As in git, an omitted
+// head of a diff scope means HEAD, which is searched as the default branch.
+func ParseRevScope(value string) (RevScope, error) {
+	if value == "" {
+		return RevScope{}, errors.New("rev: requires a revision, or base...head to search the files changed between two revisions")
+	}
+
+	var s RevScope
+	if i := strings.Index(value, "..."); i >= 0 {
+		s.Base, s.Head = value[:i], value[i+len("..."):]
+		if s.Base == "" {
+			return RevScope{}, fmt.Errorf("invalid rev:%s (the base revision of a diff scope must not be empty)", value)
+		}
+	} else if strings.Contains(value, "..") {
+		return RevScope{}, fmt.Errorf("invalid rev:%s (use rev:base...head to search the files changed between two revisions)", value)
+	} else {
+		s.Head = value
+	}
+
+	for _, rev := range []string{s.Base, s.Head} {
+		if strings.HasPrefix(rev, "-") || strings.Contains(rev, "..") {
+			return RevScope{}, fmt.Errorf("invalid rev:%s (invalid revision %q)", value, rev)
+		}
+	}
+	if s.Head == "HEAD" {
+		s.Head = ""
+	}
+	return s, nil
+}
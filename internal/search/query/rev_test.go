@@ -0,0 +1,46 @@
+package query
+
+import (
+	"testing"
+)
+
+func TestParseRevScope(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    RevScope
+		wantErr bool
+	}{
+		{input: "v1.2.3", want: RevScope{Head: "v1.2.3"}},
+		{input: "HEAD", want: RevScope{}},
+		{input: "main...my-branch", want: RevScope{Base: "main", Head: "my-branch"}},
+		{input: "main...HEAD", want: RevScope{Base: "main"}},
+		{input: "main...", want: RevScope{Base: "main"}},
+		{input: "refs/tags/v1...refs/heads/feature/x", want: RevScope{Base: "refs/tags/v1", Head: "refs/heads/feature/x"}},
+
+		{input: "", wantErr: true},
+		{input: "...my-branch", wantErr: true},
+		{input: "main..my-branch", wantErr: true},
+		{input: "a...b...c", wantErr: true},
+		{input: "-main...my-branch", wantErr: true},
+		{input: "main...--output=x", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseRevScope(test.input)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParseRevScope(%q): expected an error, got %+v", test.input, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRevScope(%q): %s", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseRevScope(%q): got %+v, want %+v", test.input, got, test.want)
+		}
+		if got.IsDiff() != (test.want.Base != "") {
+			t.Errorf("ParseRevScope(%q).IsDiff(): got %v", test.input, got.IsDiff())
+		}
+	}
+}
@@ -26,6 +26,12 @@ const (
 	FieldPatternType        = "patterntype"
 	FieldContent            = "content"
 	FieldVisibility         = "visibility"
+	FieldSelect             = "select"
+	FieldRev                = "rev"
+
+	// For symbol search only:
+	FieldSymbolKind   = "symbolkind"
+	FieldSymbolParent = "symbolparent"
 
 	// For diff and commit search only:
 	FieldBefore    = "before"
@@ -61,6 +67,11 @@ var (
 			FieldPatternType: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldContent:     {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldVisibility:  {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldSelect:      {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldRev:         {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+
+			FieldSymbolKind:   stringFieldType,
+			FieldSymbolParent: {Literal: types.RegexpType, Quoted: types.RegexpType},
 
 			FieldRepoHasFile:        regexpNegatableFieldType,
 			FieldRepoHasCommitAfter: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
@@ -210,9 +210,15 @@ func valueToTypedValue(field, value string) []*types.Value {
 		FieldLang, "l", "language",
 		FieldType,
 		FieldPatternType,
-		FieldContent:
+		FieldContent,
+		FieldSelect,
+		FieldRev,
+		FieldSymbolKind:
 		return []*types.Value{{String: &value}}
 
+	case FieldSymbolParent:
+		return []*types.Value{{Regexp: parseRegexpOrPanic(field, value)}}
+
 	case FieldRepoHasFile:
 		return []*types.Value{{Regexp: parseRegexpOrPanic(field, value)}}
 
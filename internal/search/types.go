@@ -64,6 +64,16 @@ type SymbolsParameters struct {
 	// need to match to get included in the result
 	ExcludePattern string
 
+	// Kinds is an optional list of ctags kinds (e.g. "function" or
+	// "struct"). If it is non-empty, only symbols of one of these kinds are
+	// returned. Kinds are matched case-insensitively.
+	Kinds []string
+
+	// ParentPatterns is a list of regexes that the symbol's parent (e.g. the
+	// enclosing class or struct) needs to match to get included in the
+	// result. The patterns are ANDed together.
+	ParentPatterns []string
+
 	// First indicates that only the first n symbols should be returned.
 	First int
 }
@@ -89,6 +99,11 @@ type TextParameters struct {
 	// to true if the user requests a specific timeout or maximum result size.
 	UseFullDeadline bool
 
+	// ChangedPaths, if not nil, restricts the search of each repository to
+	// the listed paths. It is set for diff scoped searches (rev:base...head),
+	// which leave out repositories without changed paths.
+	ChangedPaths map[api.RepoName][]string
+
 	Zoekt        *searchbackend.Zoekt
 	SearcherURLs *endpoint.Map
 }
@@ -129,6 +144,11 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// FilePaths, if non-empty, restricts the search to exactly these files. For
+	// structural search it is the list of candidate files found by indexed
+	// search, so that searcher only fetches and runs comby on those files.
+	FilePaths []string
 }
 
 func (p *TextPatternInfo) String() string {
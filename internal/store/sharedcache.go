@@ -0,0 +1,211 @@
+package store
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SharedCache is a second-tier cache of the zip archives built by Store. It is
+// shared by all replicas of a service, so an archive only has to be fetched
+// from gitserver once, rather than once per replica.
+//
+// Keys are hex encoded hashes, so they are safe to use as file names and
+// object keys.
+type SharedCache interface {
+	// Get returns the zip archive stored for key. If there is none, it
+	// returns an error satisfying os.IsNotExist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Put stores the zip archive read from r for key.
+	Put(ctx context.Context, key string, r io.ReadSeeker) error
+}
+
+// DirSharedCache is a SharedCache backed by a directory, usually on a volume
+// which is mounted by all replicas. It does not evict archives.
+type DirSharedCache struct {
+	Dir string
+}
+
+func (c *DirSharedCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".zip")
+}
+
+// Get implements SharedCache.
+func (c *DirSharedCache) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(c.path(key))
+}
+
+// Put implements SharedCache.
+func (c *DirSharedCache) Put(ctx context.Context, key string, r io.ReadSeeker) error {
+	path := c.path(key)
+	if _, err := os.Stat(path); err == nil {
+		// Another replica published it first.
+		return nil
+	}
+
+	// We write to a temporary file and rename it, so other replicas never
+	// read a partially written archive.
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(c.Dir, key+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// S3SharedCache is a SharedCache backed by a bucket of an S3 compatible blob
+// store, such as MinIO. It does not evict archives, which is best done with a
+// lifecycle rule on the bucket.
+type S3SharedCache struct {
+	Client *s3.Client
+	Bucket string
+	// Prefix is prepended to the object keys.
+	Prefix string
+}
+
+// NewS3SharedCache returns an S3SharedCache for bucket. If endpoint is not
+// empty, it is used instead of the AWS endpoint of region, with path-style
+// addressing as required by most S3 compatible stores.
+func NewS3SharedCache(config aws.Config, endpoint, bucket, prefix string) *S3SharedCache {
+	if endpoint != "" {
+		config.EndpointResolver = aws.ResolveWithEndpointURL(endpoint)
+	}
+	client := s3.New(config)
+	client.ForcePathStyle = endpoint != ""
+	return &S3SharedCache{Client: client, Bucket: bucket, Prefix: prefix}
+}
+
+// Get implements SharedCache.
+func (c *S3SharedCache) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req := c.Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(c.Bucket),
+		Key:    aws.String(c.Prefix + key + ".zip"),
+	})
+	resp, err := req.Send(ctx)
+	if err != nil {
+		if e, ok := err.(awserr.Error); ok && e.Code() == s3.ErrCodeNoSuchKey {
+			return nil, &os.PathError{Op: "get", Path: key, Err: os.ErrNotExist}
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put implements SharedCache.
+func (c *S3SharedCache) Put(ctx context.Context, key string, r io.ReadSeeker) error {
+	req := c.Client.PutObjectRequest(&s3.PutObjectInput{
+		Bucket:      aws.String(c.Bucket),
+		Key:         aws.String(c.Prefix + key + ".zip"),
+		Body:        r,
+		ContentType: aws.String("application/zip"),
+	})
+	_, err := req.Send(ctx)
+	return err
+}
+
+// getShared writes the archive for key from the shared cache to path. It
+// returns an error satisfying os.IsNotExist if the shared cache doesn't have
+// it.
+func (s *Store) getShared(ctx context.Context, key, path string) error {
+	rc, err := s.SharedCache.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to open temporary archive cache item")
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to copy archive from shared cache")
+	}
+	return f.Close()
+}
+
+// putShared publishes the archive at path to the shared cache in the
+// background, so that requests don't wait for the upload.
+func (s *Store) putShared(key, path string) {
+	// Open the file now, the disk cache renames it once we return.
+	f, err := os.Open(path)
+	if err != nil {
+		sharedCachePutFailed.Inc()
+		return
+	}
+
+	s.publishing.Add(1)
+	go func() {
+		defer s.publishing.Done()
+		defer f.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), sharedCachePutTimeout)
+		defer cancel()
+		if err := s.SharedCache.Put(ctx, key, f); err != nil {
+			sharedCachePutFailed.Inc()
+			return
+		}
+		sharedCachePuts.Inc()
+	}()
+}
+
+var (
+	sharedCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "searcher",
+		Subsystem: "store",
+		Name:      "shared_cache_hits",
+		Help:      "The total number of archives read from the shared cache instead of fetched from gitserver.",
+	})
+	sharedCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "searcher",
+		Subsystem: "store",
+		Name:      "shared_cache_misses",
+		Help:      "The total number of archives missing from the shared cache.",
+	})
+	sharedCacheGetFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "searcher",
+		Subsystem: "store",
+		Name:      "shared_cache_get_failed",
+		Help:      "The total number of archives that failed to be read from the shared cache.",
+	})
+	sharedCachePuts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "searcher",
+		Subsystem: "store",
+		Name:      "shared_cache_puts",
+		Help:      "The total number of archives published to the shared cache.",
+	})
+	sharedCachePutFailed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "searcher",
+		Subsystem: "store",
+		Name:      "shared_cache_put_failed",
+		Help:      "The total number of archives that failed to be published to the shared cache.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(sharedCacheHits)
+	prometheus.MustRegister(sharedCacheMisses)
+	prometheus.MustRegister(sharedCacheGetFailed)
+	prometheus.MustRegister(sharedCachePuts)
+	prometheus.MustRegister(sharedCachePutFailed)
+}
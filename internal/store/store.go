@@ -12,6 +12,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,6 +35,10 @@ import (
 // than this are searched.
 const maxFileSize = 1 << 20 // 1MB; match https://sourcegraph.com/search?q=repo:%5Egithub%5C.com/sourcegraph/zoekt%24+%22-file_limit%22
 
+// sharedCachePutTimeout is how long we wait for an archive to be published to
+// the shared cache.
+const sharedCachePutTimeout = 5 * time.Minute
+
 // Store manages the fetching and storing of git archives. Its main purpose is
 // keeping a local disk cache of the fetched archives to help speed up future
 // requests for the same archive. As a performance optimization, it is also
@@ -56,6 +61,11 @@ type Store struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains the given
+	// paths. It is optional; if it is nil, PrepareZipPaths fetches the whole
+	// archive with FetchTar.
+	FetchTarPaths func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error)
+
 	// Path is the directory to store the cache
 	Path string
 
@@ -76,6 +86,13 @@ type Store struct {
 
 	// ZipCache provides efficient access to repo zip files.
 	ZipCache ZipCache
+
+	// SharedCache, if set, is consulted before fetching an archive from
+	// gitserver. Archives fetched from gitserver are published to it.
+	SharedCache SharedCache
+
+	// publishing tracks archives being published to SharedCache.
+	publishing sync.WaitGroup
 }
 
 // SetMaxConcurrentFetchTar sets the maximum number of concurrent calls allowed
@@ -114,8 +131,26 @@ func (s *Store) Start() {
 // PrepareZip returns the path to a local zip archive of repo at commit.
 // It will first consult the local cache, otherwise will fetch from the network.
 func (s *Store) PrepareZip(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (path string, err error) {
+	return s.prepareZip(ctx, repo, commit, nil)
+}
+
+// PrepareZipPaths is like PrepareZip, but the zip archive only contains the
+// given paths. This is much cheaper than fetching the whole archive when only a
+// few files of a large repository need to be searched. Archives of different
+// sets of paths are cached separately, and are not published to SharedCache.
+func (s *Store) PrepareZipPaths(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (path string, err error) {
+	if len(paths) == 0 || s.FetchTarPaths == nil {
+		return s.prepareZip(ctx, repo, commit, nil)
+	}
+	paths = append([]string{}, paths...)
+	sort.Strings(paths)
+	return s.prepareZip(ctx, repo, commit, paths)
+}
+
+func (s *Store) prepareZip(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (path string, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Store.prepareZip")
 	ext.Component.Set(span, "store")
+	span.SetTag("paths", len(paths))
 	defer func() {
 		if err != nil {
 			ext.Error.Set(span, true)
@@ -136,7 +171,11 @@ func (s *Store) PrepareZip(ctx context.Context, repo gitserver.Repo, commit api.
 	largeFilePatterns := conf.Get().SearchLargeFiles
 
 	// key is a sha256 hash since we want to use it for the disk name
-	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %q", repo.Name, commit, largeFilePatterns)))
+	keyData := fmt.Sprintf("%q %q %q", repo.Name, commit, largeFilePatterns)
+	if len(paths) > 0 {
+		keyData += fmt.Sprintf(" %q", paths)
+	}
+	h := sha256.Sum256([]byte(keyData))
 	key := hex.EncodeToString(h[:])
 	span.LogKV("key", key)
 
@@ -151,8 +190,8 @@ func (s *Store) PrepareZip(ctx context.Context, repo gitserver.Repo, commit api.
 		// TODO: consider adding a cache method that doesn't actually bother opening the file,
 		// since we're just going to close it again immediately.
 		bgctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(ctx))
-		f, err := s.cache.Open(bgctx, key, func(ctx context.Context) (io.ReadCloser, error) {
-			return s.fetch(ctx, repo, commit, largeFilePatterns)
+		f, err := s.cache.OpenWithPath(bgctx, key, func(ctx context.Context, path string) error {
+			return s.fetchZip(ctx, path, key, repo, commit, paths, largeFilePatterns)
 		})
 		var path string
 		if f != nil {
@@ -176,10 +215,54 @@ func (s *Store) PrepareZip(ctx context.Context, repo gitserver.Repo, commit api.
 	}
 }
 
+// fetchZip writes the zip archive of repo at commit to path. If a shared
+// cache is configured, the archive is read from it if possible. Otherwise it
+// is fetched from gitserver and published to the shared cache. Archives of a
+// subset of paths don't use the shared cache, since they are rarely reused.
+func (s *Store) fetchZip(ctx context.Context, path, key string, repo gitserver.Repo, commit api.CommitID, paths, largeFilePatterns []string) error {
+	useSharedCache := s.SharedCache != nil && len(paths) == 0
+	if useSharedCache {
+		err := s.getShared(ctx, key, path)
+		if err == nil {
+			sharedCacheHits.Inc()
+			return nil
+		}
+		if os.IsNotExist(err) {
+			sharedCacheMisses.Inc()
+		} else {
+			sharedCacheGetFailed.Inc()
+			log.Printf("failed to read %s@%s from shared cache: %s", repo.Name, commit, err)
+		}
+	}
+
+	rc, err := s.fetch(ctx, repo, commit, paths, largeFilePatterns)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		rc.Close()
+		return errors.Wrap(err, "failed to open temporary archive cache item")
+	}
+	_, err = io.Copy(f, rc)
+	rc.Close()
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+
+	if useSharedCache {
+		s.putShared(key, path)
+	}
+	return nil
+}
+
 // fetch fetches an archive from the network and stores it on disk. It does
 // not populate the in-memory cache. You should probably be calling
 // prepareZip.
-func (s *Store) fetch(ctx context.Context, repo gitserver.Repo, commit api.CommitID, largeFilePatterns []string) (rc io.ReadCloser, err error) {
+func (s *Store) fetch(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths, largeFilePatterns []string) (rc io.ReadCloser, err error) {
 	fetchQueueSize.Inc()
 	ctx, releaseFetchLimiter, err := s.fetchLimiter.Acquire(ctx) // Acquire concurrent fetches semaphore
 	if err != nil {
@@ -223,7 +306,12 @@ func (s *Store) fetch(ctx context.Context, repo gitserver.Repo, commit api.Commi
 		}
 	}()
 
-	r, err := s.FetchTar(ctx, repo, commit)
+	var r io.ReadCloser
+	if len(paths) > 0 {
+		r, err = s.FetchTarPaths(ctx, repo, commit, paths)
+	} else {
+		r, err = s.FetchTar(ctx, repo, commit)
+	}
 	if err != nil {
 		return nil, err
 	}
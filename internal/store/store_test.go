@@ -2,11 +2,13 @@ package store
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"context"
 	"io"
 	"io/ioutil"
 	"os"
+	"reflect"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -117,6 +119,101 @@ func TestPrepareZip_errHeader(t *testing.T) {
 	}
 }
 
+func TestPrepareZip_sharedCache(t *testing.T) {
+	shared, err := ioutil.TempDir("", "store_test_shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(shared)
+
+	var fetchTarCalled int64
+	fetchTar := func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+		atomic.AddInt64(&fetchTarCalled, 1)
+		return emptyTar(t), nil
+	}
+
+	// Two replicas with their own disk cache, sharing a second-tier cache.
+	var replicas []*Store
+	for i := 0; i < 2; i++ {
+		s, cleanup := tmpStore(t)
+		defer cleanup()
+		s.FetchTar = fetchTar
+		s.SharedCache = &DirSharedCache{Dir: shared}
+		replicas = append(replicas, s)
+	}
+
+	repo := gitserver.Repo{Name: "foo"}
+	commit := api.CommitID("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+
+	if _, err := replicas[0].PrepareZip(context.Background(), repo, commit); err != nil {
+		t.Fatal("expected PrepareZip to succeed:", err)
+	}
+	replicas[0].publishing.Wait()
+	if files, _ := ioutil.ReadDir(shared); len(files) != 1 {
+		t.Fatalf("expected the archive to be published to the shared cache, found %d files", len(files))
+	}
+
+	path, err := replicas[1].PrepareZip(context.Background(), repo, commit)
+	if err != nil {
+		t.Fatal("expected PrepareZip to succeed:", err)
+	}
+	if got := atomic.LoadInt64(&fetchTarCalled); got != 1 {
+		t.Fatalf("expected FetchTar to be called once, was called %d times", got)
+	}
+	if _, err := zip.OpenReader(path); err != nil {
+		t.Fatalf("expected a valid zip from the shared cache: %s", err)
+	}
+}
+
+func TestPrepareZipPaths(t *testing.T) {
+	shared, err := ioutil.TempDir("", "store_test_shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(shared)
+
+	s, cleanup := tmpStore(t)
+	defer cleanup()
+	s.SharedCache = &DirSharedCache{Dir: shared}
+	s.FetchTar = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+		t.Fatal("expected FetchTarPaths to be called instead of FetchTar")
+		return nil, nil
+	}
+	var gotPaths [][]string
+	s.FetchTarPaths = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+		gotPaths = append(gotPaths, paths)
+		return emptyTar(t), nil
+	}
+
+	repo := gitserver.Repo{Name: "foo"}
+	commit := api.CommitID("deadbeefdeadbeefdeadbeefdeadbeefdeadbeef")
+
+	pathA, err := s.PrepareZipPaths(context.Background(), repo, commit, []string{"b.go", "a.go"})
+	if err != nil {
+		t.Fatal("expected PrepareZipPaths to succeed:", err)
+	}
+	// The same paths in a different order use the cached archive.
+	if path, err := s.PrepareZipPaths(context.Background(), repo, commit, []string{"a.go", "b.go"}); err != nil {
+		t.Fatal("expected PrepareZipPaths to succeed:", err)
+	} else if path != pathA {
+		t.Errorf("expected the cached archive %s, got %s", pathA, path)
+	}
+	// Other paths are fetched separately.
+	if path, err := s.PrepareZipPaths(context.Background(), repo, commit, []string{"a.go"}); err != nil {
+		t.Fatal("expected PrepareZipPaths to succeed:", err)
+	} else if path == pathA {
+		t.Error("expected archives of different paths to be cached separately")
+	}
+
+	if want := [][]string{{"a.go", "b.go"}, {"a.go"}}; !reflect.DeepEqual(gotPaths, want) {
+		t.Errorf("fetched wrong paths. got=%v want=%v", gotPaths, want)
+	}
+	s.publishing.Wait()
+	if files, _ := ioutil.ReadDir(shared); len(files) != 0 {
+		t.Errorf("expected archives of paths not to be published to the shared cache, found %d files", len(files))
+	}
+}
+
 func TestIngoreSizeMax(t *testing.T) {
 	patterns := []string{
 		"foo",
@@ -33,6 +33,16 @@ type SearchArgs struct {
 	// need to match to get included in the result
 	ExcludePattern string
 
+	// Kinds is an optional list of ctags kinds (e.g. "function" or
+	// "struct"). If it is non-empty, only symbols of one of these kinds are
+	// returned. Kinds are matched case-insensitively.
+	Kinds []string
+
+	// ParentPatterns is a list of regexes that the symbol's parent (e.g. the
+	// enclosing class or struct) needs to match to get included in the
+	// result. The patterns are ANDed together.
+	ParentPatterns []string
+
 	// First indicates that only the first n symbols should be returned.
 	First int
 }
@@ -47,6 +57,7 @@ type Symbol struct {
 	Name       string
 	Path       string
 	Line       int
+	EndLine    int // the last line of the definition, or 0 if unknown
 	Kind       string
 	Language   string
 	Parent     string
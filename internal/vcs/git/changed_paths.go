@@ -0,0 +1,59 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+// ChangedPaths returns the paths of the files that were added or modified on
+// head since it diverged from base, i.e. the files listed by
+// `git diff base...head`. Deleted files are not included, because they don't
+// exist in head.
+func ChangedPaths(ctx context.Context, repo gitserver.Repo, base, head string) ([]string, error) {
+	if Mocks.ChangedPaths != nil {
+		return Mocks.ChangedPaths(base, head)
+	}
+
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: ChangedPaths")
+	span.SetTag("Base", base)
+	span.SetTag("Head", head)
+	defer span.Finish()
+
+	if err := checkSpecArgSafety(base); err != nil {
+		return nil, err
+	}
+	if err := checkSpecArgSafety(head); err != nil {
+		return nil, err
+	}
+
+	spec := base + "..." + head
+	cmd := gitserver.DefaultClient.Command("git", "diff", "-z", "--name-only", "--no-renames", "--diff-filter=d", spec, "--")
+	cmd.Repo = repo
+	stdout, stderr, err := cmd.DividedOutput(ctx)
+	if err != nil {
+		if vcs.IsRepoNotExist(err) {
+			return nil, err
+		}
+		if bytes.Contains(stderr, []byte("bad revision")) || bytes.Contains(stderr, []byte("no merge base")) {
+			return nil, &gitserver.RevisionNotFoundError{Repo: repo.Name, Spec: spec}
+		}
+		return nil, errors.WithMessage(err, fmt.Sprintf("git command %v failed (stderr: %q)", cmd.Args, stderr))
+	}
+
+	stdout = bytes.TrimSuffix(stdout, []byte{0})
+	if len(stdout) == 0 {
+		return nil, nil
+	}
+	parts := bytes.Split(stdout, []byte{0})
+	paths := make([]string, len(parts))
+	for i, p := range parts {
+		paths[i] = string(p)
+	}
+	return paths, nil
+}
@@ -0,0 +1,68 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+func TestChangedPaths(t *testing.T) {
+	t.Parallel()
+
+	repo := MakeGitRepository(t,
+		"echo line1 > f",
+		"echo line1 > g",
+		"git add f g",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m foo --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"git checkout -b b2",
+		"echo line2 >> f",
+		"git rm g",
+		"mkdir dir",
+		"echo line1 > 'dir/with space'",
+		"git add f 'dir/with space'",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m bar --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+		"git checkout master",
+		"echo line3 > h",
+		"git add h",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit -m qux --author='a <a@a.com>' --date 2006-01-02T15:04:05Z",
+	)
+
+	tests := map[string]struct {
+		base, head string
+		want       []string
+	}{
+		"branch since master": {
+			base: "master", head: "b2",
+			// h was changed on master after b2 diverged and g was deleted, so
+			// neither is included.
+			want: []string{"dir/with space", "f"},
+		},
+		"master since branch": {
+			base: "b2", head: "master",
+			want: []string{"h"},
+		},
+		"no changes": {
+			base: "master", head: "master",
+			want: nil,
+		},
+	}
+
+	for label, test := range tests {
+		paths, err := ChangedPaths(ctx, repo, test.base, test.head)
+		if err != nil {
+			t.Errorf("%s: ChangedPaths: %s", label, err)
+			continue
+		}
+		if !reflect.DeepEqual(paths, test.want) {
+			t.Errorf("%s: got %q, want %q", label, paths, test.want)
+		}
+	}
+
+	if _, err := ChangedPaths(ctx, repo, "doesnotexist", "master"); !gitserver.IsRevisionNotFound(err) {
+		t.Errorf("expected a RevisionNotFoundError for an unknown base, got %v", err)
+	}
+	if _, err := ChangedPaths(ctx, repo, "--output=x", "master"); err == nil {
+		t.Error("expected an error for a revision beginning with '-'")
+	}
+}
@@ -13,10 +13,14 @@ import (
 
 // compilePathMatcher compiles the path options into a PathMatcher.
 func compilePathMatcher(options PathOptions) (pathmatch.PathMatcher, error) {
-	return pathmatch.CompilePathPatterns(
+	m, err := pathmatch.CompilePathPatterns(
 		options.IncludePatterns, options.ExcludePattern,
 		pathmatch.CompileOptions{CaseSensitive: options.IsCaseSensitive, RegExp: options.IsRegExp},
 	)
+	if err != nil || options.Matcher == nil {
+		return m, err
+	}
+	return pathmatch.And(m, options.Matcher), nil
 }
 
 // filterAndHighlightDiff returns the raw diff with query matches highlighted
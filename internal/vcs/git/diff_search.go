@@ -30,14 +30,15 @@ type PathOptions struct {
 	ExcludePattern  string   // exclude paths matching any of these patterns
 	IsRegExp        bool     // whether the pattern is a regexp (if false, treated as exact string)
 	IsCaseSensitive bool     // whether the pattern should be matched case-sensitively
+
+	// Matcher, if set, additionally restricts the paths to those it matches (e.g., the paths
+	// that the user may read due to sub-repository permissions).
+	Matcher pathmatch.PathMatcher
 }
 
 // CompilePathMatcher compiles the path options into a PathMatcher.
 func CompilePathMatcher(options PathOptions) (pathmatch.PathMatcher, error) {
-	return pathmatch.CompilePathPatterns(
-		options.IncludePatterns, options.ExcludePattern,
-		pathmatch.CompileOptions{CaseSensitive: options.IsCaseSensitive, RegExp: options.IsRegExp},
-	)
+	return compilePathMatcher(options)
 }
 
 // RawLogDiffSearchOptions specifies options to (Repository).RawLogDiffSearch.
@@ -203,7 +204,7 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 		// TODO(sqs): use git pathspec %(...) extensions to reduce the number of cases where this is
 		// necessary; see https://git-scm.com/docs/gitglossary.html#def_pathspec.
 		var addMaxCount500 bool
-		if opt.Paths.ExcludePattern != "" {
+		if opt.Paths.ExcludePattern != "" || opt.Paths.Matcher != nil {
 			addMaxCount500 = true
 		}
 
@@ -327,7 +328,7 @@ func RawLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt RawLogDiffSe
 	// Need --patch (TODO(sqs): or just --raw, which is smaller) if we are filtering by file paths,
 	// because we post-filter by path since we need to support regexps. Just the commit message
 	// alone would be insufficient for our post-filtering.
-	hasPathFilters := opt.Paths.ExcludePattern != "" || len(opt.Paths.IncludePatterns) > 0
+	hasPathFilters := opt.Paths.ExcludePattern != "" || len(opt.Paths.IncludePatterns) > 0 || opt.Paths.Matcher != nil
 	if hasPathFilters {
 		showArgs = append(showArgs, "--patch")
 	}
@@ -21,6 +21,7 @@ var Mocks, emptyMocks struct {
 	ResolveRevision  func(spec string, opt *ResolveRevisionOptions) (api.CommitID, error)
 	Stat             func(commit api.CommitID, name string) (os.FileInfo, error)
 	GetObject        func(objectName string) (OID, ObjectType, error)
+	ChangedPaths     func(base, head string) ([]string, error)
 }
 
 // ResetMocks clears the mock functions set on Mocks (so that subsequent tests don't inadvertently
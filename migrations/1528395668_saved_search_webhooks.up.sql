@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS notify_webhook boolean NOT NULL DEFAULT false;
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS webhook_url text;
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS webhook_secret text;
+
+COMMIT;
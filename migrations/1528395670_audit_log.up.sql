@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigserial PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    actor_user_id integer,
+    actor_ip text NOT NULL DEFAULT '',
+    action text NOT NULL CHECK (action <> ''),
+    target_type text NOT NULL DEFAULT '',
+    target text NOT NULL DEFAULT '',
+    diff text NOT NULL DEFAULT '',
+    prev_hash text NOT NULL,
+    hash text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS audit_log_actor_user_id ON audit_log(actor_user_id);
+CREATE INDEX IF NOT EXISTS audit_log_action ON audit_log(action);
+
+-- Entries are never changed or removed by the application. This does not stop
+-- someone with direct database access, but the hash chain makes such changes
+-- detectable.
+CREATE OR REPLACE FUNCTION audit_log_immutable() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+    BEGIN
+        RAISE EXCEPTION 'audit_log entries may not be modified or deleted';
+    END;
+$$;
+
+DROP TRIGGER IF EXISTS trig_audit_log_immutable ON audit_log;
+CREATE TRIGGER trig_audit_log_immutable BEFORE UPDATE OR DELETE ON audit_log FOR EACH ROW EXECUTE PROCEDURE audit_log_immutable();
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS invalidated_sessions_at;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS invalidated_sessions_at timestamp with time zone;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS audit_log_prev_hash;
+
+COMMIT;
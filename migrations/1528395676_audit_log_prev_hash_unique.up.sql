@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Each entry is chained to exactly one entry before it. Concurrent inserts
+-- which chain to the same entry conflict, and the loser retries.
+CREATE UNIQUE INDEX IF NOT EXISTS audit_log_prev_hash ON audit_log(prev_hash);
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS config_sync_status (
+    repo_name text PRIMARY KEY,
+    path text NOT NULL DEFAULT '',
+    commit_id text NOT NULL DEFAULT '',
+    synced_at timestamp with time zone NOT NULL,
+    error text,
+    changes jsonb NOT NULL DEFAULT '[]',
+    drift jsonb NOT NULL DEFAULT '[]'
+);
+
+COMMIT;
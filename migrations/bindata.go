@@ -102,6 +102,26 @@
 // 1528395666_lsif_filename.up.sql (289B)
 // 1528395667_index_boolean_fields_on_repo.down.sql (120B)
 // 1528395667_index_boolean_fields_on_repo.up.sql (187B)
+// 1528395668_saved_search_webhooks.down.sql (209B)
+// 1528395668_saved_search_webhooks.up.sql (259B)
+// 1528395669_query_runner_state_result_fingerprints.down.sql (91B)
+// 1528395669_query_runner_state_result_fingerprints.up.sql (101B)
+// 1528395670_audit_log.down.sql (96B)
+// 1528395670_audit_log.up.sql (1.215kB)
+// 1528395671_critical_and_site_config_author.down.sql (92B)
+// 1528395671_critical_and_site_config_author.up.sql (143B)
+// 1528395672_sub_repo_permissions.down.sql (60B)
+// 1528395672_sub_repo_permissions.up.sql (537B)
+// 1528395673_access_tokens_expires_at.down.sql (77B)
+// 1528395673_access_tokens_expires_at.up.sql (105B)
+// 1528395674_users_invalidated_sessions_at.down.sql (82B)
+// 1528395674_users_invalidated_sessions_at.up.sql (110B)
+// 1528395675_users_suspended_at.down.sql (71B)
+// 1528395675_users_suspended_at.up.sql (99B)
+// 1528395676_audit_log_prev_hash_unique.down.sql (59B)
+// 1528395676_audit_log_prev_hash_unique.up.sql (238B)
+// 1528395677_config_sync_status.down.sql (58B)
+// 1528395677_config_sync_status.up.sql (319B)
 
 package migrations
 
@@ -2210,6 +2230,406 @@ func _1528395667_index_boolean_fields_on_repoUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1528395668_saved_search_webhooksDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x4e\x2c\x4b\x4d\x89\x2f\x4e\x4d\x2c\x4a\xce\x48\x2d\x56\x70\x09\xf2\x0f\x50\x70\xf6\xf7\x09\xf5\xf5\x53\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\xc8\xcb\x2f\xc9\x4c\xab\x8c\x2f\x4f\x4d\xca\xc8\xcf\xcf\xb6\x26\xdd\x00\xa8\xce\xf8\xd2\xa2\x1c\x0a\x74\x17\xa7\x26\x17\xa5\x96\x58\x73\x71\x39\xfb\xfb\xfa\x7a\x86\x58\x73\x01\x06\x00\x27\x78\xc6\x79\xd1\x00\x00\x00")
+
+func _1528395668_saved_search_webhooksDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395668_saved_search_webhooksDownSql,
+		"1528395668_saved_search_webhooks.down.sql",
+	)
+}
+
+func _1528395668_saved_search_webhooksDownSql() (*asset, error) {
+	bytes, err := _1528395668_saved_search_webhooksDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395668_saved_search_webhooks.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x84, 0xfd, 0xf8, 0xb0, 0x73, 0x7e, 0x20, 0xb2, 0xc3, 0xbf, 0xe2, 0x53, 0x3b, 0x33, 0x2d, 0xb4, 0x77, 0x7, 0x16, 0xb2, 0x56, 0x28, 0xda, 0xb, 0xa3, 0xef, 0xc6, 0x8e, 0x68, 0x29, 0x44, 0x5a}}
+	return a, nil
+}
+
+var __1528395668_saved_search_webhooksUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\xcd\x4d\xaa\x83\x30\x14\x47\xf1\x79\x56\xf1\xdf\x87\xa3\xa8\xf1\x11\xb8\x46\x78\x5e\xa1\x33\x89\xf6\x8a\xa5\x62\x20\x49\xbf\x76\x5f\x28\xae\xa0\x9d\x1f\x7e\xa7\x34\x7f\xd6\x15\x4a\x69\x62\xf3\x0f\xd6\x25\x19\x24\x7f\x97\xf3\x98\xc4\xc7\x79\x95\x04\x5d\xd7\xa8\x3a\x1a\x5a\x07\xdb\xc0\x75\x0c\x73\xb2\x3d\xf7\xd8\x43\xbe\x2c\xaf\xf1\x21\xd3\x1a\xc2\x15\x53\x08\x9b\xf8\xfd\x53\xb8\x81\x08\xb5\x69\xf4\x40\x8c\xc5\x6f\x49\x8a\xaf\x16\x87\x3d\xde\xe2\x86\x2c\xcf\xfc\x9b\x92\x64\x8e\x92\x0f\x48\x55\x5d\xdb\x5a\x2e\xd4\x7b\x00\xb2\xf6\x72\x49\x03\x01\x00\x00")
+
+func _1528395668_saved_search_webhooksUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395668_saved_search_webhooksUpSql,
+		"1528395668_saved_search_webhooks.up.sql",
+	)
+}
+
+func _1528395668_saved_search_webhooksUpSql() (*asset, error) {
+	bytes, err := _1528395668_saved_search_webhooksUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395668_saved_search_webhooks.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x13, 0xb7, 0xbf, 0x37, 0x3e, 0xfb, 0xf1, 0x9d, 0x94, 0xd, 0x3e, 0xb2, 0xcb, 0xf7, 0x91, 0xaf, 0x74, 0x9e, 0x4f, 0x51, 0xd5, 0x1f, 0xa3, 0xb2, 0x7b, 0xd9, 0x9d, 0xc2, 0x37, 0x71, 0xa8, 0xd1}}
+	return a, nil
+}
+
+var __1528395669_query_runner_state_result_fingerprintsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5b\x00\xa4\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x71\x75\x65\x72\x79\x5f\x72\x75\x6e\x6e\x65\x72\x5f\x73\x74\x61\x74\x65\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x73\x75\x6c\x74\x5f\x66\x69\x6e\x67\x65\x72\x70\x72\x69\x6e\x74\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x3f\xc1\x05\x0b\x5b\x00\x00\x00")
+
+func _1528395669_query_runner_state_result_fingerprintsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395669_query_runner_state_result_fingerprintsDownSql,
+		"1528395669_query_runner_state_result_fingerprints.down.sql",
+	)
+}
+
+func _1528395669_query_runner_state_result_fingerprintsDownSql() (*asset, error) {
+	bytes, err := _1528395669_query_runner_state_result_fingerprintsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395669_query_runner_state_result_fingerprints.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x70, 0x42, 0x71, 0x6d, 0xf2, 0xd1, 0x4, 0x22, 0xfa, 0x99, 0x80, 0xbd, 0x20, 0xc2, 0xcc, 0x67, 0x9a, 0xcc, 0x49, 0xfb, 0x73, 0x75, 0x8a, 0x8a, 0x90, 0xda, 0xc5, 0xd, 0xb5, 0xf, 0x84, 0xc}}
+	return a, nil
+}
+
+var __1528395669_query_runner_state_result_fingerprintsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x65\x00\x9a\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x71\x75\x65\x72\x79\x5f\x72\x75\x6e\x6e\x65\x72\x5f\x73\x74\x61\x74\x65\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x72\x65\x73\x75\x6c\x74\x5f\x66\x69\x6e\x67\x65\x72\x70\x72\x69\x6e\x74\x73\x20\x74\x65\x78\x74\x5b\x5d\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x5e\x70\x0d\xd1\x65\x00\x00\x00")
+
+func _1528395669_query_runner_state_result_fingerprintsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395669_query_runner_state_result_fingerprintsUpSql,
+		"1528395669_query_runner_state_result_fingerprints.up.sql",
+	)
+}
+
+func _1528395669_query_runner_state_result_fingerprintsUpSql() (*asset, error) {
+	bytes, err := _1528395669_query_runner_state_result_fingerprintsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395669_query_runner_state_result_fingerprints.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xac, 0xc4, 0x2b, 0x5f, 0xe7, 0x17, 0x12, 0x99, 0x6e, 0x4c, 0x70, 0x6d, 0x9c, 0xc5, 0x1a, 0x38, 0x1c, 0x81, 0x9d, 0xd6, 0x14, 0x16, 0x38, 0xb2, 0x33, 0x43, 0x3f, 0xdd, 0x56, 0xa2, 0x37, 0xfe}}
+	return a, nil
+}
+
+var __1528395670_audit_logDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x60\x00\x9f\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x3b\x0a\x44\x52\x4f\x50\x20\x46\x55\x4e\x43\x54\x49\x4f\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x5f\x69\x6d\x6d\x75\x74\x61\x62\x6c\x65\x28\x29\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xf3\xb0\x63\xeb\x60\x00\x00\x00")
+
+func _1528395670_audit_logDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_audit_logDownSql,
+		"1528395670_audit_log.down.sql",
+	)
+}
+
+func _1528395670_audit_logDownSql() (*asset, error) {
+	bytes, err := _1528395670_audit_logDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_audit_log.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x4d, 0x53, 0x4b, 0xdb, 0x74, 0xf9, 0x15, 0x68, 0xd5, 0xad, 0x22, 0xc6, 0xde, 0x6, 0xa5, 0x10, 0x7c, 0xde, 0x33, 0x37, 0xc, 0xb8, 0xba, 0xd6, 0xe1, 0x5a, 0xff, 0x3, 0xd0, 0x6d, 0x3d, 0x22}}
+	return a, nil
+}
+
+var __1528395670_audit_logUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x93\x5d\x6f\xe2\x3a\x10\x86\xef\xf3\x2b\xde\x0b\x24\x40\x2a\xfd\x03\x1c\x1d\x29\x0d\x03\x8d\x4a\x13\x64\x12\x1d\x7a\x15\x99\x64\x9a\x58\x27\x5f\x6b\x9b\x76\xbb\xbf\x7e\x15\x43\x3f\xe8\x87\xba\x2b\x71\x61\x66\x5e\x3f\xe3\x99\x77\x72\x45\xab\x30\x9a\x7b\x5e\x20\xc8\x4f\x08\x89\x7f\xb5\x26\x84\x4b\x44\x71\x02\xda\x85\xdb\x64\x0b\x79\x28\x94\xcd\xea\xae\xc4\xc4\x03\x00\x55\x60\xaf\x4a\xc3\x5a\xc9\x1a\x1b\x11\xde\xfa\xe2\x0e\x37\x74\x77\xe1\xb2\xb9\x66\x69\xb9\xc8\xa4\x85\x55\x0d\x1b\x2b\x9b\x1e\x8f\xca\x56\xee\x2f\x7e\x75\x2d\x3b\x78\x94\xae\xd7\x58\xd0\xd2\x4f\xd7\x09\xda\xee\x71\x32\x3d\xde\x97\xb9\xed\x74\x76\x30\xac\x33\x55\x40\xb5\x96\x4b\xd6\x6f\x53\xaa\x87\xe5\x9f\xf6\x23\x64\x3c\x7e\x91\xa9\xae\x7d\x27\x0a\xae\x29\xb8\xc1\xe4\x94\xfb\xe7\x5f\x8c\xc7\xa7\x82\x56\xea\x92\x6d\x66\x9f\x7a\xfe\x06\x7c\x54\x7e\x23\x2a\xd4\xfd\xfd\x37\x92\x5e\xf3\x43\x56\x49\x53\x9d\xeb\x8e\xcf\xf9\x18\xf7\xa6\xaf\xfe\x84\xd1\x82\x76\x5f\xf9\x93\xbd\x99\x7d\x1c\xbd\xc6\x27\xaf\xf1\xe9\xfc\xcf\x48\xe7\x2e\x9c\xc1\xce\x52\x7f\xc1\x1b\x3c\x79\x0f\x52\x5d\x3b\x34\x37\x9b\x81\x5a\xab\x15\x1b\x48\xcd\x68\xf9\x81\x35\xf2\x4a\xb6\x25\x17\xe8\x34\x34\x37\xdd\x03\x17\xd8\x3f\xc1\x56\x0c\xd9\xf7\xb5\xca\xe5\xe0\xe4\x25\x92\x4a\x19\x14\x1d\x1b\xb4\x9d\x85\xb1\x5d\xef\xcd\x66\x30\x5d\xc3\xc3\xa6\xb9\xc5\x2b\x94\xe6\xdc\xa2\x90\x56\xee\xa5\x61\xc8\x3c\x67\x63\x2e\xb0\x3f\x58\xc7\x73\x23\xcf\x2b\xa9\x5a\x34\xf2\x7f\x36\x30\x87\xbc\x3a\xd5\x37\x03\xad\x60\xcb\xb9\x95\xfb\x9a\x2f\x9f\xdb\x8d\x05\x04\x6d\xd6\x7e\x40\x58\xa6\x51\x90\x84\x6f\x5b\xcb\x54\xd3\x1c\x9c\x7e\x32\x85\xa0\x24\x15\xd1\x16\x56\xab\xb2\x64\xed\x4c\x5e\xfb\xd1\x2a\xf5\x57\x84\xbe\xee\x4b\xf3\xa3\x76\x41\x7f\x8b\xd1\xc8\x9d\xdc\x47\xe9\x4e\xc3\x4f\xf8\xe1\x96\x40\xbb\x80\x36\xae\xcc\xf8\xa5\x0e\xf8\x34\xb5\x46\x3e\xb9\xf6\xf7\x8c\xa6\x2b\xd4\xbd\x3a\xce\xad\xe0\x9a\x2d\x17\xe3\xb9\x63\x51\xb4\x98\x7b\xa3\xd1\xdc\xf3\x16\x22\xde\x20\x11\xe1\x6a\x45\x62\x58\xa6\x93\x5d\xc3\x0b\xb3\x4f\x9a\x38\xb3\xed\xc5\xf0\xe7\xfb\x5f\xde\xba\xa2\x65\x2c\x08\xe9\x66\x71\x1a\xd8\x82\xd6\x94\xd0\x19\x0d\xcb\x58\x80\xfc\xe0\x1a\x22\xfe\x0f\xb4\xa3\x20\x4d\x08\x1b\x11\x07\xb4\x48\x05\xe1\x13\xee\x64\xd8\x98\x20\xbe\xbd\x0d\x93\xb9\xf7\x7b\x00\x08\x9e\xe1\xb2\xbf\x04\x00\x00")
+
+func _1528395670_audit_logUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_audit_logUpSql,
+		"1528395670_audit_log.up.sql",
+	)
+}
+
+func _1528395670_audit_logUpSql() (*asset, error) {
+	bytes, err := _1528395670_audit_logUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_audit_log.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x57, 0x73, 0x37, 0xd8, 0xdd, 0x31, 0x5d, 0x6f, 0xe9, 0x2b, 0xe8, 0x3, 0x1d, 0xb9, 0xa0, 0x13, 0xe8, 0x3, 0x6b, 0xd7, 0x10, 0xbd, 0x69, 0x87, 0xe8, 0x90, 0x36, 0xcb, 0xd, 0x49, 0x7c, 0x99}}
+	return a, nil
+}
+
+var __1528395671_critical_and_site_config_authorDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5c\x00\xa3\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x63\x72\x69\x74\x69\x63\x61\x6c\x5f\x61\x6e\x64\x5f\x73\x69\x74\x65\x5f\x63\x6f\x6e\x66\x69\x67\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x74\x68\x6f\x72\x5f\x75\x73\x65\x72\x5f\x69\x64\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x6c\x4d\xa7\xb5\x5c\x00\x00\x00")
+
+func _1528395671_critical_and_site_config_authorDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_critical_and_site_config_authorDownSql,
+		"1528395671_critical_and_site_config_author.down.sql",
+	)
+}
+
+func _1528395671_critical_and_site_config_authorDownSql() (*asset, error) {
+	bytes, err := _1528395671_critical_and_site_config_authorDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_critical_and_site_config_author.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2a, 0x17, 0x7b, 0x60, 0xa7, 0xc0, 0xf4, 0xfc, 0xe7, 0xf0, 0x71, 0xf7, 0xc3, 0x44, 0xa9, 0xf9, 0x90, 0x58, 0x88, 0x3e, 0xa9, 0xb5, 0x83, 0x4e, 0x63, 0xbe, 0x6a, 0x89, 0x45, 0x45, 0x8b, 0x1e}}
+	return a, nil
+}
+
+var __1528395671_critical_and_site_config_authorUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x8f\x00\x70\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x63\x72\x69\x74\x69\x63\x61\x6c\x5f\x61\x6e\x64\x5f\x73\x69\x74\x65\x5f\x63\x6f\x6e\x66\x69\x67\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x74\x68\x6f\x72\x5f\x75\x73\x65\x72\x5f\x69\x64\x20\x69\x6e\x74\x65\x67\x65\x72\x20\x52\x45\x46\x45\x52\x45\x4e\x43\x45\x53\x20\x75\x73\x65\x72\x73\x28\x69\x64\x29\x20\x4f\x4e\x20\x44\x45\x4c\x45\x54\x45\x20\x53\x45\x54\x20\x4e\x55\x4c\x4c\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x6e\x91\xd8\x47\x8f\x00\x00\x00")
+
+func _1528395671_critical_and_site_config_authorUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_critical_and_site_config_authorUpSql,
+		"1528395671_critical_and_site_config_author.up.sql",
+	)
+}
+
+func _1528395671_critical_and_site_config_authorUpSql() (*asset, error) {
+	bytes, err := _1528395671_critical_and_site_config_authorUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_critical_and_site_config_author.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x93, 0xa3, 0xc7, 0x68, 0x49, 0x1a, 0xff, 0x79, 0xc5, 0xfe, 0x4d, 0x28, 0xd5, 0x43, 0x20, 0x34, 0xe1, 0x68, 0xed, 0x50, 0x11, 0x86, 0xea, 0xcb, 0x70, 0x2c, 0x63, 0x4c, 0xf6, 0x54, 0xee, 0xe2}}
+	return a, nil
+}
+
+var __1528395672_sub_repo_permissionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x3c\x00\xc3\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x75\x62\x5f\x72\x65\x70\x6f\x5f\x70\x65\x72\x6d\x69\x73\x73\x69\x6f\x6e\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x18\x3d\x94\xd1\x3c\x00\x00\x00")
+
+func _1528395672_sub_repo_permissionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_sub_repo_permissionsDownSql,
+		"1528395672_sub_repo_permissions.down.sql",
+	)
+}
+
+func _1528395672_sub_repo_permissionsDownSql() (*asset, error) {
+	bytes, err := _1528395672_sub_repo_permissionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_sub_repo_permissions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x41, 0x58, 0x33, 0x7d, 0xc6, 0xd8, 0x2, 0xa8, 0x6f, 0x3b, 0x7e, 0xc1, 0xe2, 0x1c, 0xaa, 0xe7, 0x84, 0xda, 0x2, 0x4f, 0x53, 0x75, 0x3f, 0xaf, 0xb2, 0xf9, 0xe6, 0x5b, 0x49, 0xc6, 0xac, 0xeb}}
+	return a, nil
+}
+
+var __1528395672_sub_repo_permissionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x91\xd1\x4a\xc3\x30\x14\x40\xdf\xf3\x15\xf7\x6d\x2d\xec\x0f\xf6\x94\xa5\x77\x12\xe8\x52\x6c\x53\x18\x88\x84\x6a\x2f\x2e\x60\xd3\xda\x24\x6c\x28\xfe\xbb\xac\xb3\x4c\x70\xe8\x1e\x93\x9c\x7b\x02\xe7\xae\xf1\x4e\xaa\x15\x63\xa2\x44\xae\x11\x34\x5f\xe7\x08\x72\x03\xaa\xd0\x80\x3b\x59\xe9\x0a\x7c\x7c\x32\x23\x0d\xbd\x19\x68\xec\xac\xf7\xb6\x77\x1e\x12\x06\x00\x10\x3d\x8d\xc6\xb6\x60\x5d\xa0\x17\x1a\xa7\x29\x55\xe7\x39\x94\xb8\xc1\x12\x95\xc0\x6a\x62\x7c\x62\xdb\x14\x0a\x05\x19\xe6\xa8\x11\x04\xaf\x04\xcf\x70\x39\x49\x26\xf7\x3f\x92\x13\xf3\x97\x63\x68\xc2\xde\x58\xf7\xfc\x1a\x5b\xf2\x10\xe8\x18\x1e\x1e\x2f\xa2\x0c\x37\xbc\xce\x35\x2c\x3e\x3e\x17\x3f\x78\x3a\xde\xcc\xc7\xa1\x6d\x02\xb5\xa6\x09\x10\x6c\x47\x3e\x34\xdd\x00\x07\x1b\xf6\xd3\x11\xde\x7b\x47\xbf\xc7\x5d\x7f\x48\xd2\xf3\x7f\xa2\x50\x95\x2e\xb9\x54\xfa\x6a\x4e\x73\x8a\x74\xbe\x8d\xce\xbe\x45\x82\x5a\xc9\xfb\x1a\x21\xf9\x2e\xbc\x9c\x2b\xa5\x2c\xbd\x6c\x4b\xaa\x0c\x77\x37\x6c\xcb\xcc\x89\x0b\x75\xf5\x3d\x99\xe5\x2b\xc6\x44\xb1\xdd\x4a\xbd\x62\x5f\x03\x00\x11\x1b\x85\xc5\x19\x02\x00\x00")
+
+func _1528395672_sub_repo_permissionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_sub_repo_permissionsUpSql,
+		"1528395672_sub_repo_permissions.up.sql",
+	)
+}
+
+func _1528395672_sub_repo_permissionsUpSql() (*asset, error) {
+	bytes, err := _1528395672_sub_repo_permissionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_sub_repo_permissions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa7, 0x6b, 0x92, 0x67, 0x1, 0x6, 0x16, 0x7d, 0x6f, 0x1b, 0xf8, 0xeb, 0x34, 0x9, 0x89, 0x78, 0x40, 0xf7, 0x72, 0x1a, 0xa5, 0x54, 0xe8, 0x52, 0x83, 0xce, 0xa4, 0x1e, 0xff, 0x81, 0x4a, 0xd6}}
+	return a, nil
+}
+
+var __1528395673_access_tokens_expires_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x4d\x00\xb2\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x61\x63\x63\x65\x73\x73\x5f\x74\x6f\x6b\x65\x6e\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x65\x78\x70\x69\x72\x65\x73\x5f\x61\x74\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xfa\xc7\x84\x27\x4d\x00\x00\x00")
+
+func _1528395673_access_tokens_expires_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_access_tokens_expires_atDownSql,
+		"1528395673_access_tokens_expires_at.down.sql",
+	)
+}
+
+func _1528395673_access_tokens_expires_atDownSql() (*asset, error) {
+	bytes, err := _1528395673_access_tokens_expires_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_access_tokens_expires_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe9, 0xf4, 0xa, 0x25, 0x55, 0xaa, 0xae, 0x58, 0x3c, 0x51, 0x71, 0x39, 0x6b, 0x80, 0xd2, 0xe4, 0xa4, 0xa0, 0xf3, 0xca, 0xd3, 0x94, 0x7b, 0xf5, 0xb3, 0x32, 0xd6, 0x27, 0xad, 0x2a, 0x5c, 0x23}}
+	return a, nil
+}
+
+var __1528395673_access_tokens_expires_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x69\x00\x96\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x61\x63\x63\x65\x73\x73\x5f\x74\x6f\x6b\x65\x6e\x73\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x65\x78\x70\x69\x72\x65\x73\x5f\x61\x74\x20\x74\x69\x6d\x65\x73\x74\x61\x6d\x70\x20\x77\x69\x74\x68\x20\x74\x69\x6d\x65\x20\x7a\x6f\x6e\x65\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xb3\xf3\x05\x50\x69\x00\x00\x00")
+
+func _1528395673_access_tokens_expires_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_access_tokens_expires_atUpSql,
+		"1528395673_access_tokens_expires_at.up.sql",
+	)
+}
+
+func _1528395673_access_tokens_expires_atUpSql() (*asset, error) {
+	bytes, err := _1528395673_access_tokens_expires_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_access_tokens_expires_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x81, 0x60, 0x5d, 0xce, 0x98, 0x11, 0xf9, 0xc2, 0xb0, 0x2b, 0x1a, 0x7e, 0xe9, 0xc9, 0xd7, 0xb3, 0x8a, 0x5b, 0x2b, 0x5d, 0xeb, 0x76, 0xf1, 0xb3, 0x80, 0x8e, 0xc4, 0xc0, 0x46, 0x80, 0x17, 0xc4}}
+	return a, nil
+}
+
+var __1528395674_users_invalidated_sessions_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x52\x00\xad\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x69\x6e\x76\x61\x6c\x69\x64\x61\x74\x65\x64\x5f\x73\x65\x73\x73\x69\x6f\x6e\x73\x5f\x61\x74\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x1b\xfc\x15\x19\x52\x00\x00\x00")
+
+func _1528395674_users_invalidated_sessions_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_users_invalidated_sessions_atDownSql,
+		"1528395674_users_invalidated_sessions_at.down.sql",
+	)
+}
+
+func _1528395674_users_invalidated_sessions_atDownSql() (*asset, error) {
+	bytes, err := _1528395674_users_invalidated_sessions_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_users_invalidated_sessions_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x0, 0x54, 0x3b, 0xee, 0x20, 0xd1, 0xef, 0x62, 0x21, 0x1e, 0xbc, 0x7c, 0x10, 0x55, 0x98, 0xe3, 0xc1, 0x0, 0xfc, 0xdb, 0x57, 0xb6, 0xc2, 0x72, 0x6b, 0xa2, 0x9c, 0x40, 0xf6, 0xf3, 0x95, 0x90}}
+	return a, nil
+}
+
+var __1528395674_users_invalidated_sessions_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x6e\x00\x91\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x69\x6e\x76\x61\x6c\x69\x64\x61\x74\x65\x64\x5f\x73\x65\x73\x73\x69\x6f\x6e\x73\x5f\x61\x74\x20\x74\x69\x6d\x65\x73\x74\x61\x6d\x70\x20\x77\x69\x74\x68\x20\x74\x69\x6d\x65\x20\x7a\x6f\x6e\x65\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x70\x32\x56\x75\x6e\x00\x00\x00")
+
+func _1528395674_users_invalidated_sessions_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_users_invalidated_sessions_atUpSql,
+		"1528395674_users_invalidated_sessions_at.up.sql",
+	)
+}
+
+func _1528395674_users_invalidated_sessions_atUpSql() (*asset, error) {
+	bytes, err := _1528395674_users_invalidated_sessions_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_users_invalidated_sessions_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x8f, 0x17, 0x94, 0x44, 0x45, 0x28, 0x5d, 0x13, 0x4, 0xbc, 0x12, 0x21, 0x70, 0xf9, 0x4a, 0xba, 0xb, 0x2, 0xe1, 0x88, 0xca, 0xd5, 0x23, 0x71, 0x4d, 0xce, 0xc1, 0x15, 0xd6, 0x4a, 0x24, 0x1a}}
+	return a, nil
+}
+
+var __1528395675_users_suspended_atDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x47\x00\xb8\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x75\x73\x70\x65\x6e\x64\x65\x64\x5f\x61\x74\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x9e\xa6\x0e\xc7\x47\x00\x00\x00")
+
+func _1528395675_users_suspended_atDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_users_suspended_atDownSql,
+		"1528395675_users_suspended_at.down.sql",
+	)
+}
+
+func _1528395675_users_suspended_atDownSql() (*asset, error) {
+	bytes, err := _1528395675_users_suspended_atDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_users_suspended_at.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa9, 0x3c, 0x49, 0x65, 0xdf, 0x87, 0x52, 0x9e, 0x21, 0x3f, 0x5d, 0x89, 0xa0, 0x90, 0x59, 0x95, 0x71, 0x33, 0xf9, 0x58, 0x69, 0x96, 0x71, 0xb3, 0xdc, 0xc, 0x93, 0x4d, 0x63, 0x22, 0x99, 0x8c}}
+	return a, nil
+}
+
+var __1528395675_users_suspended_atUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x63\x00\x9c\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x75\x73\x65\x72\x73\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x4e\x4f\x54\x20\x45\x58\x49\x53\x54\x53\x20\x73\x75\x73\x70\x65\x6e\x64\x65\x64\x5f\x61\x74\x20\x74\x69\x6d\x65\x73\x74\x61\x6d\x70\x20\x77\x69\x74\x68\x20\x74\x69\x6d\x65\x20\x7a\x6f\x6e\x65\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x2e\xf5\xee\x31\x63\x00\x00\x00")
+
+func _1528395675_users_suspended_atUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_users_suspended_atUpSql,
+		"1528395675_users_suspended_at.up.sql",
+	)
+}
+
+func _1528395675_users_suspended_atUpSql() (*asset, error) {
+	bytes, err := _1528395675_users_suspended_atUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_users_suspended_at.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xcf, 0xbe, 0xa1, 0x91, 0x4f, 0xf0, 0x4f, 0xa4, 0x7f, 0x6e, 0x4f, 0x4f, 0x75, 0x18, 0xf0, 0xda, 0x5d, 0xba, 0x88, 0xf1, 0xd6, 0x64, 0xf2, 0x6d, 0x85, 0xb8, 0xaf, 0xd3, 0xb9, 0x18, 0xc0, 0x6b}}
+	return a, nil
+}
+
+var __1528395676_audit_log_prev_hash_uniqueDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x3b\x00\xc4\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x49\x4e\x44\x45\x58\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x5f\x70\x72\x65\x76\x5f\x68\x61\x73\x68\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x62\xc1\x47\xcb\x3b\x00\x00\x00")
+
+func _1528395676_audit_log_prev_hash_uniqueDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_audit_log_prev_hash_uniqueDownSql,
+		"1528395676_audit_log_prev_hash_unique.down.sql",
+	)
+}
+
+func _1528395676_audit_log_prev_hash_uniqueDownSql() (*asset, error) {
+	bytes, err := _1528395676_audit_log_prev_hash_uniqueDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_audit_log_prev_hash_unique.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x6a, 0xab, 0xde, 0xdc, 0x42, 0x76, 0xda, 0xd2, 0xf2, 0xa1, 0x2, 0x34, 0x5e, 0x43, 0x69, 0x8a, 0xfa, 0x9a, 0xc0, 0x33, 0x3f, 0x85, 0x5a, 0x7, 0xbc, 0x65, 0x2c, 0x4d, 0x84, 0x4b, 0xa2, 0x73}}
+	return a, nil
+}
+
+var __1528395676_audit_log_prev_hash_uniqueUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x44\xcc\x4d\x4e\xc3\x30\x10\xc5\xf1\xbd\x4f\xf1\x96\x20\xd1\x5e\x20\x2b\x08\x06\x79\x51\x47\xd0\x54\xea\x2e\x32\xce\x14\x8f\x14\xc6\x68\x3c\x05\x7a\x7b\x54\x3e\xb7\xef\xaf\xf7\xbb\xf1\xf7\x21\x76\xce\xad\x56\xf0\x29\x17\x90\x98\x9e\xc0\x0d\xb9\x24\x16\x9a\x61\x15\xf4\x91\xb2\x2d\x27\x54\xa1\x9f\xfe\x44\x87\xaa\x04\xb6\x35\xfa\x2a\xf9\xa8\x4a\x62\x60\x69\xa4\xd6\xce\xd6\x7b\xe1\x5c\xbe\x8d\xb3\x60\x85\xd0\xd2\xcb\xef\x3d\x57\x39\x2c\x9c\xed\x0a\x49\xe6\xaf\xb8\xd4\x46\x0a\x25\x53\xa6\xb6\x76\xfd\xa3\xbf\x1e\x3d\x76\x31\x3c\xec\x3c\x42\xbc\xf5\x7b\x84\x3b\xc4\x61\x84\xdf\x87\xed\xb8\x45\x3a\xce\x6c\xd3\x52\x9f\xa7\x57\xa5\xb7\xa9\xa4\x56\x30\xc4\xff\xf9\xe2\x6f\xbe\xec\x9c\xeb\x87\xcd\x26\x8c\x9d\xfb\x1c\x00\x21\xaa\x6d\xe2\xee\x00\x00\x00")
+
+func _1528395676_audit_log_prev_hash_uniqueUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_audit_log_prev_hash_uniqueUpSql,
+		"1528395676_audit_log_prev_hash_unique.up.sql",
+	)
+}
+
+func _1528395676_audit_log_prev_hash_uniqueUpSql() (*asset, error) {
+	bytes, err := _1528395676_audit_log_prev_hash_uniqueUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_audit_log_prev_hash_unique.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xdc, 0x84, 0x45, 0x7, 0x6b, 0x10, 0x3b, 0xaa, 0x39, 0x1b, 0x9a, 0xb4, 0x10, 0x66, 0x4b, 0x8d, 0x5b, 0x91, 0xd, 0xd2, 0xcd, 0x5e, 0x69, 0xf, 0xe0, 0x59, 0x37, 0xbc, 0xad, 0x18, 0xe, 0xc2}}
+	return a, nil
+}
+
+var __1528395677_config_sync_statusDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x3a\x00\xc5\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x63\x6f\x6e\x66\x69\x67\x5f\x73\x79\x6e\x63\x5f\x73\x74\x61\x74\x75\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xee\x76\x90\xbc\x3a\x00\x00\x00")
+
+func _1528395677_config_sync_statusDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_config_sync_statusDownSql,
+		"1528395677_config_sync_status.down.sql",
+	)
+}
+
+func _1528395677_config_sync_statusDownSql() (*asset, error) {
+	bytes, err := _1528395677_config_sync_statusDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_config_sync_status.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1e, 0xdc, 0xa9, 0xda, 0xef, 0xc1, 0x4d, 0x68, 0x8d, 0xca, 0x71, 0x26, 0xef, 0xfa, 0xec, 0x5b, 0xfc, 0x80, 0x90, 0xd0, 0x51, 0x74, 0xdd, 0xd2, 0x75, 0x26, 0x4a, 0x72, 0x23, 0xe2, 0xb2, 0xe9}}
+	return a, nil
+}
+
+var __1528395677_config_sync_statusUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x84\x8d\xcd\x4a\x03\x31\x18\x45\xf7\x79\x8a\xbb\xab\x82\x6f\x30\xab\x69\x4d\x25\x98\x99\xca\x34\x05\x8b\x48\x88\x33\x5f\xdb\x08\x49\x86\xe4\x13\x7f\x9e\x5e\xa6\x53\x5c\x89\xae\xef\xb9\xe7\x2c\xe5\x9d\x6a\x2b\x21\x56\x9d\xac\x8d\x84\xa9\x97\x5a\x42\xad\xd1\x6e\x0c\xe4\xa3\xda\x9a\x2d\xfa\x14\x0f\xfe\x68\xcb\x67\xec\x6d\x61\xc7\x6f\x05\x57\x02\x00\x32\x8d\xc9\x46\x17\x08\x4c\x1f\x8c\x87\x4e\x35\x75\xb7\xc7\xbd\xdc\xdf\x9c\xf7\xd1\xf1\x69\x9e\x26\x5b\xbb\xd3\x1a\xb7\x72\x5d\xef\xb4\xc1\x62\x31\x23\x7d\x0a\xc1\xb3\xf5\xc3\x3f\xdc\x14\xa7\xc1\x3a\x06\xfb\x40\x85\x5d\x18\xf1\xee\x27\xbd\x0f\x84\xaf\x14\xe9\xe7\x3b\x8b\x29\xe7\x94\xcf\xd2\x4b\xe8\xe4\xe2\x91\x0a\x5e\x4b\x8a\x2f\xbf\x74\x9e\x9e\x2f\xa5\x21\xfb\x03\xff\x85\x89\xeb\x4a\x88\xd5\xa6\x69\x94\xa9\xc4\xf7\x00\x29\xeb\x3e\x1b\x3f\x01\x00\x00")
+
+func _1528395677_config_sync_statusUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_config_sync_statusUpSql,
+		"1528395677_config_sync_status.up.sql",
+	)
+}
+
+func _1528395677_config_sync_statusUpSql() (*asset, error) {
+	bytes, err := _1528395677_config_sync_statusUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_config_sync_status.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x76, 0xdb, 0xa7, 0xe0, 0x4f, 0x3d, 0x5, 0x7f, 0x72, 0xc9, 0x5b, 0x24, 0xbf, 0x1f, 0x80, 0x45, 0x85, 0x90, 0x54, 0xb2, 0x6d, 0x75, 0x16, 0x33, 0xc7, 0xa2, 0x85, 0xaf, 0x5a, 0xc4, 0x2, 0xac}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -2403,6 +2823,26 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395666_lsif_filename.up.sql":                                         _1528395666_lsif_filenameUpSql,
 	"1528395667_index_boolean_fields_on_repo.down.sql":                        _1528395667_index_boolean_fields_on_repoDownSql,
 	"1528395667_index_boolean_fields_on_repo.up.sql":                          _1528395667_index_boolean_fields_on_repoUpSql,
+	"1528395668_saved_search_webhooks.down.sql":                               _1528395668_saved_search_webhooksDownSql,
+	"1528395668_saved_search_webhooks.up.sql":                                 _1528395668_saved_search_webhooksUpSql,
+	"1528395669_query_runner_state_result_fingerprints.down.sql":              _1528395669_query_runner_state_result_fingerprintsDownSql,
+	"1528395669_query_runner_state_result_fingerprints.up.sql":                _1528395669_query_runner_state_result_fingerprintsUpSql,
+	"1528395670_audit_log.down.sql":                                           _1528395670_audit_logDownSql,
+	"1528395670_audit_log.up.sql":                                             _1528395670_audit_logUpSql,
+	"1528395671_critical_and_site_config_author.down.sql":                     _1528395671_critical_and_site_config_authorDownSql,
+	"1528395671_critical_and_site_config_author.up.sql":                       _1528395671_critical_and_site_config_authorUpSql,
+	"1528395672_sub_repo_permissions.down.sql":                                _1528395672_sub_repo_permissionsDownSql,
+	"1528395672_sub_repo_permissions.up.sql":                                  _1528395672_sub_repo_permissionsUpSql,
+	"1528395673_access_tokens_expires_at.down.sql":                            _1528395673_access_tokens_expires_atDownSql,
+	"1528395673_access_tokens_expires_at.up.sql":                              _1528395673_access_tokens_expires_atUpSql,
+	"1528395674_users_invalidated_sessions_at.down.sql":                       _1528395674_users_invalidated_sessions_atDownSql,
+	"1528395674_users_invalidated_sessions_at.up.sql":                         _1528395674_users_invalidated_sessions_atUpSql,
+	"1528395675_users_suspended_at.down.sql":                                  _1528395675_users_suspended_atDownSql,
+	"1528395675_users_suspended_at.up.sql":                                    _1528395675_users_suspended_atUpSql,
+	"1528395676_audit_log_prev_hash_unique.down.sql":                          _1528395676_audit_log_prev_hash_uniqueDownSql,
+	"1528395676_audit_log_prev_hash_unique.up.sql":                            _1528395676_audit_log_prev_hash_uniqueUpSql,
+	"1528395677_config_sync_status.down.sql":                                  _1528395677_config_sync_statusDownSql,
+	"1528395677_config_sync_status.up.sql":                                    _1528395677_config_sync_statusUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -2548,6 +2988,26 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395666_lsif_filename.up.sql":                                         {_1528395666_lsif_filenameUpSql, map[string]*bintree{}},
 	"1528395667_index_boolean_fields_on_repo.down.sql":                        {_1528395667_index_boolean_fields_on_repoDownSql, map[string]*bintree{}},
 	"1528395667_index_boolean_fields_on_repo.up.sql":                          {_1528395667_index_boolean_fields_on_repoUpSql, map[string]*bintree{}},
+	"1528395668_saved_search_webhooks.down.sql":                               {_1528395668_saved_search_webhooksDownSql, map[string]*bintree{}},
+	"1528395668_saved_search_webhooks.up.sql":                                 {_1528395668_saved_search_webhooksUpSql, map[string]*bintree{}},
+	"1528395669_query_runner_state_result_fingerprints.down.sql":              {_1528395669_query_runner_state_result_fingerprintsDownSql, map[string]*bintree{}},
+	"1528395669_query_runner_state_result_fingerprints.up.sql":                {_1528395669_query_runner_state_result_fingerprintsUpSql, map[string]*bintree{}},
+	"1528395670_audit_log.down.sql":                                           {_1528395670_audit_logDownSql, map[string]*bintree{}},
+	"1528395670_audit_log.up.sql":                                             {_1528395670_audit_logUpSql, map[string]*bintree{}},
+	"1528395671_critical_and_site_config_author.down.sql":                     {_1528395671_critical_and_site_config_authorDownSql, map[string]*bintree{}},
+	"1528395671_critical_and_site_config_author.up.sql":                       {_1528395671_critical_and_site_config_authorUpSql, map[string]*bintree{}},
+	"1528395672_sub_repo_permissions.down.sql":                                {_1528395672_sub_repo_permissionsDownSql, map[string]*bintree{}},
+	"1528395672_sub_repo_permissions.up.sql":                                  {_1528395672_sub_repo_permissionsUpSql, map[string]*bintree{}},
+	"1528395673_access_tokens_expires_at.down.sql":                            {_1528395673_access_tokens_expires_atDownSql, map[string]*bintree{}},
+	"1528395673_access_tokens_expires_at.up.sql":                              {_1528395673_access_tokens_expires_atUpSql, map[string]*bintree{}},
+	"1528395674_users_invalidated_sessions_at.down.sql":                       {_1528395674_users_invalidated_sessions_atDownSql, map[string]*bintree{}},
+	"1528395674_users_invalidated_sessions_at.up.sql":                         {_1528395674_users_invalidated_sessions_atUpSql, map[string]*bintree{}},
+	"1528395675_users_suspended_at.down.sql":                                  {_1528395675_users_suspended_atDownSql, map[string]*bintree{}},
+	"1528395675_users_suspended_at.up.sql":                                    {_1528395675_users_suspended_atUpSql, map[string]*bintree{}},
+	"1528395676_audit_log_prev_hash_unique.down.sql":                          {_1528395676_audit_log_prev_hash_uniqueDownSql, map[string]*bintree{}},
+	"1528395676_audit_log_prev_hash_unique.up.sql":                            {_1528395676_audit_log_prev_hash_uniqueUpSql, map[string]*bintree{}},
+	"1528395677_config_sync_status.down.sql":                                  {_1528395677_config_sync_statusDownSql, map[string]*bintree{}},
+	"1528395677_config_sync_status.up.sql":                                    {_1528395677_config_sync_statusUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -114,6 +114,50 @@ const BitbucketCloudSchemaJSON = `{
         [{ "name": "myorg/myrepo" }, { "uuid": "{fceb73c7-cef6-4abe-956d-e471281126bc}" }],
         [{ "name": "myorg/myrepo" }, { "name": "myorg/myotherrepo" }, { "pattern": "^topsecretproject/.*" }]
       ]
+    },
+    "authorization": {
+      "title": "BitbucketCloudAuthorization",
+      "description": "If non-null, enforces Bitbucket Cloud repository permissions. Permissions are computed from the repository permissions of the workspaces in \"teams\" and of the workspace of \"username\", which requires the app password to have the \"Workspace membership: Read\" and \"Repositories: Admin\" permissions.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["identityProvider"],
+      "properties": {
+        "identityProvider": {
+          "description": "The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud (where the username is the nickname of the Bitbucket Cloud account) and ` + "`" + `auth.enableUsernameChanges` + "`" + ` must be set to false for security reasons.",
+          "title": "BitbucketCloudIdentityProvider",
+          "type": "object",
+          "required": ["type"],
+          "properties": {
+            "type": {
+              "type": "string",
+              "enum": ["username"]
+            }
+          },
+          "oneOf": [{ "$ref": "#/definitions/UsernameIdentity" }],
+          "!go": {
+            "taggedUnionType": true
+          }
+        },
+        "ttl": {
+          "description": "Duration after which a user's cached permissions will be updated (during which time the previously cached permissions will be used). This is 3 hours by default. It does not apply when permissions are synced in the background (` + "`" + `permissions.backgroundSync` + "`" + `).",
+          "type": "string",
+          "default": "3h"
+        }
+      }
+    }
+  },
+  "definitions": {
+    "UsernameIdentity": {
+      "title": "BitbucketCloudUsernameIdentity",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "username"
+        }
+      }
     }
   }
 }
@@ -81,6 +81,12 @@ const BitbucketServerSchemaJSON = `{
       "default": "http",
       "examples": ["ssh"]
     },
+    "partialCloneBlobLimit": {
+      "description": "If set, gitserver creates partial clones of the repositories on this Bitbucket Server instance, omitting blobs larger than the given size (` + "`" + `git clone --filter=blob:limit=<size>` + "`" + `). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.",
+      "type": "string",
+      "pattern": "^[0-9]+[kmg]?$",
+      "examples": ["1m", "512k"]
+    },
     "certificate": {
       "description": "TLS certificate of the Bitbucket Server instance. This is only necessary if the certificate is self-signed or signed by an internal CA. To get the certificate run ` + "`" + `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM` + "`" + `. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.",
       "type": "string",
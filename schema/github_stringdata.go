@@ -30,6 +30,12 @@ const GitHubSchemaJSON = `{
       "enum": ["http", "ssh"],
       "default": "http"
     },
+    "partialCloneBlobLimit": {
+      "description": "If set, gitserver creates partial clones of the repositories on this GitHub instance, omitting blobs larger than the given size (` + "`" + `git clone --filter=blob:limit=<size>` + "`" + `). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.",
+      "type": "string",
+      "pattern": "^[0-9]+[kmg]?$",
+      "examples": ["1m", "512k"]
+    },
     "token": {
       "description": "A GitHub personal access token. Create one for GitHub.com at https://github.com/settings/tokens/new?description=Sourcegraph (for GitHub Enterprise, replace github.com with your instance's hostname). See https://docs.sourcegraph.com/admin/external_service/github#github-api-token-and-access for which scopes are required for which use cases.",
       "type": "string",
@@ -58,6 +58,12 @@ const GitLabSchemaJSON = `{
       "enum": ["http", "ssh"],
       "default": "http"
     },
+    "partialCloneBlobLimit": {
+      "description": "If set, gitserver creates partial clones of the repositories on this GitLab instance, omitting blobs larger than the given size (` + "`" + `git clone --filter=blob:limit=<size>` + "`" + `). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.",
+      "type": "string",
+      "pattern": "^[0-9]+[kmg]?$",
+      "examples": ["1m", "512k"]
+    },
     "certificate": {
       "description": "TLS certificate of the GitLab instance. This is only necessary if the certificate is self-signed or signed by an internal CA. To get the certificate run ` + "`" + `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM` + "`" + `. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.",
       "type": "string",
@@ -150,6 +156,23 @@ const GitLabSchemaJSON = `{
         ]
       ]
     },
+    "webhooks": {
+      "description": "An array of configurations defining existing GitLab webhooks that send updates back to Sourcegraph.",
+      "type": "array",
+      "items": {
+        "type": "object",
+        "title": "GitLabWebhook",
+        "required": ["secret"],
+        "properties": {
+          "secret": {
+            "description": "The secret token used when creating the webhook",
+            "type": "string",
+            "minLength": 1
+          }
+        }
+      },
+      "examples": [[{ "secret": "webhook-secret" }]]
+    },
     "initialRepositoryEnablement": {
       "description": "Defines whether repositories from this GitLab instance should be enabled and cloned when they are first seen by Sourcegraph. If false, the site admin must explicitly enable GitLab repositories (in the site admin area) to clone them and make them searchable on Sourcegraph. If true, they will be enabled and cloned immediately (subject to rate limiting by GitLab); site admins can still disable them explicitly, and they'll remain disabled.",
       "type": "boolean"
@@ -39,6 +39,12 @@ const OtherExternalServiceSchemaJSON = `{
       "type": "string",
       "default": "{base}/{repo}",
       "examples": ["pretty-host-name/{repo}"]
+    },
+    "partialCloneBlobLimit": {
+      "description": "If set, gitserver creates partial clones of the repositories on this code host, omitting blobs larger than the given size (` + "`" + `git clone --filter=blob:limit=<size>` + "`" + `). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.",
+      "type": "string",
+      "pattern": "^[0-9]+[kmg]?$",
+      "examples": ["1m", "512k"]
     }
   }
 }
@@ -111,12 +111,28 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
 }
 
+// AuthScim description: Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which lets an identity provider (such as Okta or Azure AD) create, update and deactivate users and organizations. The API is disabled unless a bearer token is set.
+type AuthScim struct {
+	// BearerToken description: The secret token that the identity provider must send in the "Authorization: Bearer TOKEN" HTTP header of SCIM requests.
+	BearerToken string `json:"bearerToken"`
+}
+
+// BitbucketCloudAuthorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions are computed from the repository permissions of the workspaces in "teams" and of the workspace of "username", which requires the app password to have the "Workspace membership: Read" and "Repositories: Admin" permissions.
+type BitbucketCloudAuthorization struct {
+	// IdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud (where the username is the nickname of the Bitbucket Cloud account) and `auth.enableUsernameChanges` must be set to false for security reasons.
+	IdentityProvider BitbucketCloudIdentityProvider `json:"identityProvider"`
+	// Ttl description: Duration after which a user's cached permissions will be updated (during which time the previously cached permissions will be used). This is 3 hours by default. It does not apply when permissions are synced in the background (`permissions.backgroundSync`).
+	Ttl string `json:"ttl,omitempty"`
+}
+
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
 type BitbucketCloudConnection struct {
 	// ApiURL description: The API URL of Bitbucket Cloud, such as https://api.bitbucket.org. Generally, admin should not modify the value of this option because Bitbucket Cloud is a public hosting platform.
 	ApiURL string `json:"apiURL,omitempty"`
 	// AppPassword description: The app password to use when authenticating to the Bitbucket Cloud. Also set the corresponding "username" field.
 	AppPassword string `json:"appPassword"`
+	// Authorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions are computed from the repository permissions of the workspaces in "teams" and of the workspace of "username", which requires the app password to have the "Workspace membership: Read" and "Repositories: Admin" permissions.
+	Authorization *BitbucketCloudAuthorization `json:"authorization,omitempty"`
 	// Exclude description: A list of repositories to never mirror from Bitbucket Cloud. Takes precedence over "teams" configuration.
 	//
 	// Supports excluding by name ({"name": "myorg/myrepo"}) or by UUID ({"uuid": "{fceb73c7-cef6-4abe-956d-e471281126bd}"}).
@@ -145,6 +161,31 @@ type BitbucketCloudConnection struct {
 	Username string `json:"username"`
 }
 
+// BitbucketCloudIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud (where the username is the nickname of the Bitbucket Cloud account) and `auth.enableUsernameChanges` must be set to false for security reasons.
+type BitbucketCloudIdentityProvider struct {
+	Username *BitbucketCloudUsernameIdentity
+}
+
+func (v BitbucketCloudIdentityProvider) MarshalJSON() ([]byte, error) {
+	if v.Username != nil {
+		return json.Marshal(v.Username)
+	}
+	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
+}
+func (v *BitbucketCloudIdentityProvider) UnmarshalJSON(data []byte) error {
+	var d struct {
+		DiscriminantProperty string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	switch d.DiscriminantProperty {
+	case "username":
+		return json.Unmarshal(data, &v.Username)
+	}
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"username"})
+}
+
 // BitbucketCloudRateLimit description: Rate limit applied when making background API requests to Bitbucket Cloud.
 type BitbucketCloudRateLimit struct {
 	// Enabled description: true if rate limiting is enabled.
@@ -152,6 +193,9 @@ type BitbucketCloudRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+type BitbucketCloudUsernameIdentity struct {
+	Type string `json:"type"`
+}
 
 // BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions.
 type BitbucketServerAuthorization struct {
@@ -189,6 +233,8 @@ type BitbucketServerConnection struct {
 	GitURLType string `json:"gitURLType,omitempty"`
 	// InitialRepositoryEnablement description: Defines whether repositories from this Bitbucket Server instance should be enabled and cloned when they are first seen by Sourcegraph. If false, the site admin must explicitly enable Bitbucket Server repositories (in the site admin area) to clone them and make them searchable on Sourcegraph. If true, they will be enabled and cloned immediately (subject to rate limiting by Bitbucket Server); site admins can still disable them explicitly, and they'll remain disabled.
 	InitialRepositoryEnablement bool `json:"initialRepositoryEnablement,omitempty"`
+	// PartialCloneBlobLimit description: If set, gitserver creates partial clones of the repositories on this Bitbucket Server instance, omitting blobs larger than the given size (`git clone --filter=blob:limit=<size>`). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.
+	PartialCloneBlobLimit string `json:"partialCloneBlobLimit,omitempty"`
 	// Password description: The password to use when authenticating to the Bitbucket Server instance. Also set the corresponding "username" field.
 	//
 	// For Bitbucket Server instances that support personal access tokens (Bitbucket Server version 5.5 and newer), it is recommended to provide a token instead (in the "token" field).
@@ -378,6 +424,18 @@ type ExcludedGitLabProject struct {
 	// Name description: The name of a GitLab project ("group/name") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
 }
+type ExcludedGiteaRepo struct {
+	// Archived description: If set to true, archived repositories will be excluded.
+	Archived bool `json:"archived,omitempty"`
+	// Forks description: If set to true, forks will be excluded.
+	Forks bool `json:"forks,omitempty"`
+	// Id description: The ID of a Gitea repository (as returned by the Gitea instance's API) to exclude from mirroring. Use this to exclude the repository, even if renamed.
+	Id int `json:"id,omitempty"`
+	// Name description: The name of a Gitea repository ("owner/name") to exclude from mirroring.
+	Name string `json:"name,omitempty"`
+	// Pattern description: Regular expression which matches against the name of a Gitea repository ("owner/name").
+	Pattern string `json:"pattern,omitempty"`
+}
 type ExcludedGitoliteRepo struct {
 	// Name description: The name of a Gitolite repo ("my-repo") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
@@ -478,6 +536,8 @@ type GitHubConnection struct {
 	InitialRepositoryEnablement bool `json:"initialRepositoryEnablement,omitempty"`
 	// Orgs description: An array of organization names identifying GitHub organizations whose repositories should be mirrored on Sourcegraph.
 	Orgs []string `json:"orgs,omitempty"`
+	// PartialCloneBlobLimit description: If set, gitserver creates partial clones of the repositories on this GitHub instance, omitting blobs larger than the given size (`git clone --filter=blob:limit=<size>`). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.
+	PartialCloneBlobLimit string `json:"partialCloneBlobLimit,omitempty"`
 	// RateLimit description: Rate limit applied when making background API requests to GitHub.
 	RateLimit *GitHubRateLimit `json:"rateLimit,omitempty"`
 	// Repos description: An array of repository "owner/name" strings specifying which GitHub or GitHub Enterprise repositories to mirror on Sourcegraph.
@@ -571,6 +631,8 @@ type GitLabConnection struct {
 	InitialRepositoryEnablement bool `json:"initialRepositoryEnablement,omitempty"`
 	// NameTransformations description: An array of transformations will apply to the repository name. Currently, only regex replacement is supported. All transformations happen after "repositoryPathPattern" is processed.
 	NameTransformations []*GitLabNameTransformation `json:"nameTransformations,omitempty"`
+	// PartialCloneBlobLimit description: If set, gitserver creates partial clones of the repositories on this GitLab instance, omitting blobs larger than the given size (`git clone --filter=blob:limit=<size>`). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.
+	PartialCloneBlobLimit string `json:"partialCloneBlobLimit,omitempty"`
 	// ProjectQuery description: An array of strings specifying which GitLab projects to mirror on Sourcegraph. Each string is a URL path and query that targets a GitLab API endpoint returning a list of projects. If the string only contains a query, then "projects" is used as the path. Examples: "?membership=true&search=foo", "groups/mygroup/projects".
 	//
 	// The special string "none" can be used as the only element to disable this feature. Projects matched by multiple query strings are only imported once. Here are a few endpoints that return a list of projects: https://docs.gitlab.com/ee/api/projects.html#list-all-projects, https://docs.gitlab.com/ee/api/groups.html#list-a-groups-projects, https://docs.gitlab.com/ee/api/search.html#scope-projects.
@@ -589,6 +651,8 @@ type GitLabConnection struct {
 	Token string `json:"token"`
 	// Url description: URL of a GitLab instance, such as https://gitlab.example.com or (for GitLab.com) https://gitlab.com.
 	Url string `json:"url"`
+	// Webhooks description: An array of configurations defining existing GitLab webhooks that send updates back to Sourcegraph.
+	Webhooks []*GitLabWebhook `json:"webhooks,omitempty"`
 }
 type GitLabNameTransformation struct {
 	// Regex description: The regex to match for the occurrences of its replacement.
@@ -610,6 +674,58 @@ type GitLabRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+type GitLabWebhook struct {
+	// Secret description: The secret token used when creating the webhook
+	Secret string `json:"secret"`
+}
+
+// GiteaConnection description: Configuration for a connection to a Gitea instance.
+type GiteaConnection struct {
+	// Certificate description: TLS certificate of the Gitea instance. This is only necessary if the certificate is self-signed or signed by an internal CA. To get the certificate run `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM`. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.
+	Certificate string `json:"certificate,omitempty"`
+	// Exclude description: A list of repositories to never mirror from this Gitea instance. Takes precedence over "orgs", "repos", and "repositoryQuery" configuration.
+	//
+	// Supports excluding by name ({"name": "owner/name"}) or by ID ({"id": 42}).
+	Exclude []*ExcludedGiteaRepo `json:"exclude,omitempty"`
+	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Gitea instance.
+	//
+	// If "http", Sourcegraph will access Gitea repositories using Git URLs of the form http(s)://gitea.example.com/myorg/myrepo.git (using https: if the Gitea instance uses HTTPS).
+	//
+	// If "ssh", Sourcegraph will access Gitea repositories using Git URLs of the form git@gitea.example.com:myorg/myrepo.git. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.
+	GitURLType string `json:"gitURLType,omitempty"`
+	// Orgs description: An array of organization names identifying Gitea organizations whose repositories should be mirrored on Sourcegraph.
+	Orgs []string `json:"orgs,omitempty"`
+	// RateLimit description: Rate limit applied when making background API requests to Gitea.
+	RateLimit *GiteaRateLimit `json:"rateLimit,omitempty"`
+	// Repos description: An array of repository "owner/name" strings specifying which Gitea repositories to mirror on Sourcegraph.
+	Repos []string `json:"repos,omitempty"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for a Gitea repository. In the pattern, the variable "{host}" is replaced with the Gitea URL's host (such as gitea.example.com), and "{nameWithOwner}" is replaced with the Gitea repository's "owner/name" (such as "myorg/myrepo").
+	//
+	// For example, if your Gitea is https://gitea.example.com and your Sourcegraph is https://src.example.com, then a repositoryPathPattern of "{host}/{nameWithOwner}" would mean that a Gitea repository at https://gitea.example.com/myorg/myrepo is available on Sourcegraph at https://src.example.com/gitea.example.com/myorg/myrepo.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// RepositoryQuery description: An array of strings specifying which Gitea repositories to mirror on Sourcegraph. The valid values are:
+	//
+	// - `affiliated` mirrors all repositories the configured token's user owns, collaborates on or has access to through organization membership
+	//
+	// - `none` mirrors no repositories (except those specified in the `repos` and `orgs` configuration properties)
+	//
+	// If multiple values are provided, their results are unioned.
+	RepositoryQuery []string `json:"repositoryQuery,omitempty"`
+	// Token description: A Gitea access token. It is used to list repositories through the Gitea API and to clone them over HTTP(S), so it must have access to all repositories that should be mirrored.
+	Token string `json:"token"`
+	// Url description: URL of a Gitea instance, such as https://gitea.example.com.
+	Url string `json:"url"`
+}
+
+// GiteaRateLimit description: Rate limit applied when making background API requests to Gitea.
+type GiteaRateLimit struct {
+	// Enabled description: true if rate limiting is enabled.
+	Enabled bool `json:"enabled"`
+	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
+	RequestsPerHour float64 `json:"requestsPerHour"`
+}
 
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
@@ -740,7 +856,9 @@ type OpenIDConnectAuthProvider struct {
 
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
 type OtherExternalServiceConnection struct {
-	Repos []string `json:"repos"`
+	// PartialCloneBlobLimit description: If set, gitserver creates partial clones of the repositories on this code host, omitting blobs larger than the given size (`git clone --filter=blob:limit=<size>`). Omitted blobs are fetched on demand when they are needed, e.g. for search or when viewing a file. Use this for very large repositories with large binary files in their history. The size is in bytes and may be suffixed with k, m or g. The setting applies to repositories cloned or recloned after it is changed.
+	PartialCloneBlobLimit string   `json:"partialCloneBlobLimit,omitempty"`
+	Repos                 []string `json:"repos"`
 	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for the repositories. In the pattern, the variable "{base}" is replaced with the Git clone base URL host and path, and "{repo}" is replaced with the repository path taken from the `repos` field.
 	//
 	// For example, if your Git clone base URL is https://git.example.com/repos and `repos` contains the value "my/repo", then a repositoryPathPattern of "{base}/{repo}" would mean that a repository at https://git.example.com/repos/my/repo is available on Sourcegraph at https://sourcegraph.example.com/git.example.com/repos/my/repo.
@@ -937,6 +1055,8 @@ type SiteConfiguration struct {
 	AuthProviders []AuthProviders `json:"auth.providers,omitempty"`
 	// AuthPublic description: WARNING: This option has been removed as of 3.8.
 	AuthPublic bool `json:"auth.public,omitempty"`
+	// AuthScim description: Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which lets an identity provider (such as Okta or Azure AD) create, update and deactivate users and organizations. The API is disabled unless a bearer token is set.
+	AuthScim *AuthScim `json:"auth.scim,omitempty"`
 	// AuthSessionExpiry description: The duration of a user session, after which it expires and the user is required to re-authenticate. The default is 90 days. There is typically no need to set this, but some users may have specific internal security requirements.
 	//
 	// The string format is that of the Duration type in the Go time package (https://golang.org/pkg/time/#ParseDuration). E.g., "720h", "43200m", "2592000s" all indicate a timespan of 30 days.
@@ -951,6 +1071,8 @@ type SiteConfiguration struct {
 	//   ```
 	//
 	AuthSessionExpiry string `json:"auth.sessionExpiry,omitempty"`
+	// AuthSuspendInactiveUsersAfterDays description: Automatically suspend users (other than site admins) who haven't used Sourcegraph for this many days, based on the user event logs. Because event logs are deleted after 93 days, this can be at most 93. Suspended users can't sign in or use their access tokens, and they don't count toward the licensed user count. A site admin can unsuspend them. Users are never suspended automatically unless this is set.
+	AuthSuspendInactiveUsersAfterDays int `json:"auth.suspendInactiveUsersAfterDays,omitempty"`
 	// AuthUserOrgMap description: Ensure that matching users are members of the specified orgs (auto-joining users to the orgs if they are not already a member). Provide a JSON object of the form `{"*": ["org1", "org2"]}`, where org1 and org2 are orgs that all users are automatically joined to. Currently the only supported key is `"*"`.
 	AuthUserOrgMap map[string][]string `json:"auth.userOrgMap,omitempty"`
 	// AutomationReadAccessEnabled description: DEPRECATED: The automation feature was renamed to campaigns. Use `campaigns.readAccess.enabled` instead.
@@ -993,6 +1115,8 @@ type SiteConfiguration struct {
 	GitCloneURLToRepositoryName []*CloneURLToRepositoryName `json:"git.cloneURLToRepositoryName,omitempty"`
 	// GitMaxConcurrentClones description: Maximum number of git clone processes that will be run concurrently to update repositories.
 	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
+	// GitServerReplicas description: Number of gitservers each repository is cloned on. Read requests for a repository are spread across these gitservers, and fail over to another one if a gitserver is down. Increase this if a few very popular repositories overload a single gitserver. Each replica uses as much disk space as the primary clone.
+	GitServerReplicas int `json:"gitServerReplicas,omitempty"`
 	// GithubClientID description: Client ID for GitHub. (DEPRECATED)
 	GithubClientID string `json:"githubClientID,omitempty"`
 	// GithubClientSecret description: Client secret for GitHub. (DEPRECATED)
@@ -262,6 +262,13 @@ const SiteSchemaJSON = `{
       "default": 5,
       "group": "External services"
     },
+    "gitServerReplicas": {
+      "description": "Number of gitservers each repository is cloned on. Read requests for a repository are spread across these gitservers, and fail over to another one if a gitserver is down. Increase this if a few very popular repositories overload a single gitserver. Each replica uses as much disk space as the primary clone.",
+      "type": "integer",
+      "minimum": 1,
+      "default": 1,
+      "group": "External services"
+    },
     "repoListUpdateInterval": {
       "description": "Interval (in minutes) for checking code hosts (such as GitHub, Gitolite, etc.) for new repositories.",
       "type": "integer",
@@ -309,6 +316,29 @@ const SiteSchemaJSON = `{
       ],
       "group": "Security"
     },
+    "auth.scim": {
+      "description": "Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which lets an identity provider (such as Okta or Azure AD) create, update and deactivate users and organizations. The API is disabled unless a bearer token is set.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["bearerToken"],
+      "properties": {
+        "bearerToken": {
+          "description": "The secret token that the identity provider must send in the \"Authorization: Bearer TOKEN\" HTTP header of SCIM requests.",
+          "type": "string",
+          "minLength": 32
+        }
+      },
+      "examples": [{ "bearerToken": "0123456789abcdef0123456789abcdef" }],
+      "group": "Security"
+    },
+    "auth.suspendInactiveUsersAfterDays": {
+      "description": "Automatically suspend users (other than site admins) who haven't used Sourcegraph for this many days, based on the user event logs. Because event logs are deleted after 93 days, this can be at most 93. Suspended users can't sign in or use their access tokens, and they don't count toward the licensed user count. A site admin can unsuspend them. Users are never suspended automatically unless this is set.",
+      "type": "integer",
+      "minimum": 1,
+      "maximum": 93,
+      "examples": [90],
+      "group": "Security"
+    },
     "permissions.userMapping": {
       "description": "Settings for Sourcegraph permissions, which allow the site admin to explicitly manage repository permissions via the GraphQL API. This setting cannot be enabled if repository permissions for any specific external service are enabled (i.e., when the external service's ` + "`" + `authorization` + "`" + ` field is set).",
       "type": "object",
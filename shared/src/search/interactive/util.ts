@@ -39,6 +39,10 @@ export enum FilterType {
     content = 'content',
     patterntype = 'patterntype',
     index = 'index',
+    select = 'select',
+    symbolkind = 'symbolkind',
+    symbolparent = 'symbolparent',
+    rev = 'rev',
 }
 
 export const isFilterType = (filter: string): filter is FilterType => filter in FilterType
@@ -131,6 +131,20 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
         description: negated =>
             `${negated ? 'Exclude' : 'Include only'} results from repos that contain a matching file`,
     },
+    [FilterType.rev]: {
+        description: 'Search a revision instead of the default branch, or only the files changed in base...head.',
+        singular: true,
+    },
+    [FilterType.select]: {
+        description: 'Select the kind of result to return (symbol, or symbol.kind such as symbol.function).',
+        singular: true,
+    },
+    [FilterType.symbolkind]: {
+        description: 'Include only symbols of the given kind (e.g. struct, method).',
+    },
+    [FilterType.symbolparent]: {
+        description: 'Include only symbols whose parent (e.g. class or struct) matches the given regex pattern.',
+    },
     [FilterType.timeout]: {
         description: 'Duration before timeout',
         singular: true,
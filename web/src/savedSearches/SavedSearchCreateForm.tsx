@@ -42,7 +42,10 @@ export class SavedSearchCreateForm extends React.Component<Props, State> {
                                 fields.notify,
                                 fields.notifySlack,
                                 this.props.namespace.__typename === 'User' ? this.props.namespace.id : null,
-                                this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null
+                                this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null,
+                                fields.notifyWebhook,
+                                fields.webhookURL,
+                                fields.webhookSecret
                             ).pipe(
                                 map(() => true),
                                 catchError(error => [error])
@@ -14,6 +14,11 @@ export interface SavedQueryFields {
     notify: boolean
     notifySlack: boolean
     slackWebhookURL: string | null
+    notifyWebhook: boolean
+    webhookURL: string | null
+    /** Write-only: the current secret is never returned, only whether there is one. */
+    webhookSecret: string | null
+    hasWebhookSecret: boolean
 }
 
 interface Props extends RouteComponentProps<{}>, NamespaceProps {
@@ -36,8 +41,16 @@ export class SavedSearchForm extends React.Component<Props, State> {
     constructor(props: Props) {
         super(props)
 
-        const { description = '', query = '', notify = false, notifySlack = false, slackWebhookURL = '' } =
-            props.defaultValues || {}
+        const {
+            description = '',
+            query = '',
+            notify = false,
+            notifySlack = false,
+            slackWebhookURL = '',
+            notifyWebhook = false,
+            webhookURL = '',
+            hasWebhookSecret = false,
+        } = props.defaultValues || {}
 
         this.state = {
             values: {
@@ -46,6 +59,10 @@ export class SavedSearchForm extends React.Component<Props, State> {
                 notify,
                 notifySlack,
                 slackWebhookURL,
+                notifyWebhook,
+                webhookURL,
+                webhookSecret: '',
+                hasWebhookSecret,
             },
         }
     }
@@ -74,7 +91,17 @@ export class SavedSearchForm extends React.Component<Props, State> {
 
     public render(): JSX.Element | null {
         const {
-            values: { query, description, notify, notifySlack, slackWebhookURL },
+            values: {
+                query,
+                description,
+                notify,
+                notifySlack,
+                slackWebhookURL,
+                notifyWebhook,
+                webhookURL,
+                webhookSecret,
+                hasWebhookSecret,
+            },
         } = this.state
 
         return (
@@ -129,6 +156,47 @@ export class SavedSearchForm extends React.Component<Props, State> {
                             </label>
                         </div>
                     </div>
+                    <div className="saved-search-form__input">
+                        <label className="saved-search-form__label">Webhook notifications:</label>
+                        <div>
+                            <label>
+                                <input
+                                    type="checkbox"
+                                    name="Notify webhook"
+                                    className="saved-search-form__checkbox"
+                                    defaultChecked={notifyWebhook}
+                                    onChange={this.createInputChangeHandler('notifyWebhook')}
+                                />{' '}
+                                <span>POST new results to a webhook URL</span>
+                            </label>
+                        </div>
+                        {notifyWebhook && (
+                            <>
+                                <input
+                                    type="url"
+                                    name="Webhook URL"
+                                    className="form-control mb-2"
+                                    placeholder="https://example.com/hooks/sourcegraph"
+                                    required={true}
+                                    value={webhookURL || ''}
+                                    onChange={this.createInputChangeHandler('webhookURL')}
+                                />
+                                <input
+                                    type="password"
+                                    name="Webhook secret"
+                                    className="form-control"
+                                    autoComplete="new-password"
+                                    placeholder={
+                                        hasWebhookSecret
+                                            ? 'Leave blank to keep the current secret'
+                                            : 'Secret to sign the payloads with (optional)'
+                                    }
+                                    value={webhookSecret || ''}
+                                    onChange={this.createInputChangeHandler('webhookSecret')}
+                                />
+                            </>
+                        )}
+                    </div>
                     {notifySlack && slackWebhookURL && (
                         <div className="saved-search-form__input">
                             <label className="saved-search-form__label">Slack notifications:</label>
@@ -147,13 +215,7 @@ export class SavedSearchForm extends React.Component<Props, State> {
                             </label>
                         </div>
                     )}
-                    {this.isUnsupportedNotifyQuery(this.state.values) && (
-                        <div className="alert alert-warning mb-3">
-                            <strong>Warning:</strong> non-commit searches do not currently support notifications.
-                            Consider adding <code>type:diff</code> or <code>type:commit</code> to your query.
-                        </div>
-                    )}
-                    {notify && !window.context.emailEnabled && !this.isUnsupportedNotifyQuery(this.state.values) && (
+                    {notify && !window.context.emailEnabled && (
                         <div className="alert alert-warning mb-3">
                             <strong>Warning:</strong> Sending emails is not currently configured on this Sourcegraph
                             server.{' '}
@@ -176,11 +238,4 @@ export class SavedSearchForm extends React.Component<Props, State> {
             </div>
         )
     }
-    /**
-     * Tells if the query is unsupported for sending notifications.
-     */
-    private isUnsupportedNotifyQuery(v: Omit<SavedQueryFields, 'id'>): boolean {
-        const notifying = v.notify || v.notifySlack
-        return notifying && !v.query.includes('type:diff') && !v.query.includes('type:commit')
-    }
 }
@@ -63,7 +63,10 @@ export class SavedSearchUpdateForm extends React.Component<Props, State> {
                                 input.notify,
                                 input.notifySlack,
                                 this.props.namespace.__typename === 'User' ? this.props.namespace.id : null,
-                                this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null
+                                this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null,
+                                input.notifyWebhook,
+                                input.webhookURL,
+                                input.webhookSecret
                             ).pipe(
                                 mapTo(null),
                                 mergeMap(() =>
@@ -108,6 +111,9 @@ export class SavedSearchUpdateForm extends React.Component<Props, State> {
                             notify: savedSearch.notify,
                             notifySlack: savedSearch.notifySlack,
                             slackWebhookURL: savedSearch.slackWebhookURL,
+                            notifyWebhook: savedSearch.notifyWebhook,
+                            webhookURL: savedSearch.webhookURL,
+                            hasWebhookSecret: savedSearch.hasWebhookSecret,
                         }}
                         loading={this.state.updatedOrError === LOADING}
                         onSubmit={(fields: Pick<SavedQueryFields, Exclude<keyof SavedQueryFields, 'id'>>): void =>
@@ -239,6 +239,9 @@ const savedSearchFragment = gql`
         userID
         orgID
         slackWebhookURL
+        notifyWebhook
+        webhookURL
+        hasWebhookSecret
     }
 `
 
@@ -291,7 +294,10 @@ export function createSavedSearch(
     notify: boolean,
     notifySlack: boolean,
     userId: GQL.ID | null,
-    orgId: GQL.ID | null
+    orgId: GQL.ID | null,
+    notifyWebhook: boolean,
+    webhookURL: string | null,
+    webhookSecret: string | null
 ): Observable<void> {
     return mutateGraphQL(
         gql`
@@ -302,6 +308,9 @@ export function createSavedSearch(
                 $notifySlack: Boolean!
                 $userID: ID
                 $orgID: ID
+                $notifyWebhook: Boolean
+                $webhookURL: String
+                $webhookSecret: String
             ) {
                 createSavedSearch(
                     description: $description
@@ -310,6 +319,9 @@ export function createSavedSearch(
                     notifySlack: $notifySlack
                     userID: $userID
                     orgID: $orgID
+                    notifyWebhook: $notifyWebhook
+                    webhookURL: $webhookURL
+                    webhookSecret: $webhookSecret
                 ) {
                     ...SavedSearchFields
                 }
@@ -323,6 +335,10 @@ export function createSavedSearch(
             notifySlack,
             userID: userId,
             orgID: orgId,
+            notifyWebhook,
+            webhookURL: webhookURL || null,
+            // A blank secret keeps the existing one.
+            webhookSecret: webhookSecret || null,
         }
     ).pipe(
         map(dataOrThrowErrors),
@@ -337,7 +353,10 @@ export function updateSavedSearch(
     notify: boolean,
     notifySlack: boolean,
     userId: GQL.ID | null,
-    orgId: GQL.ID | null
+    orgId: GQL.ID | null,
+    notifyWebhook: boolean,
+    webhookURL: string | null,
+    webhookSecret: string | null
 ): Observable<void> {
     return mutateGraphQL(
         gql`
@@ -349,6 +368,9 @@ export function updateSavedSearch(
                 $notifySlack: Boolean!
                 $userID: ID
                 $orgID: ID
+                $notifyWebhook: Boolean
+                $webhookURL: String
+                $webhookSecret: String
             ) {
                 updateSavedSearch(
                     id: $id
@@ -358,6 +380,9 @@ export function updateSavedSearch(
                     notifySlack: $notifySlack
                     userID: $userID
                     orgID: $orgID
+                    notifyWebhook: $notifyWebhook
+                    webhookURL: $webhookURL
+                    webhookSecret: $webhookSecret
                 ) {
                     ...SavedSearchFields
                 }
@@ -372,6 +397,10 @@ export function updateSavedSearch(
             notifySlack,
             userID: userId,
             orgID: orgId,
+            notifyWebhook,
+            webhookURL: webhookURL || null,
+            // A blank secret keeps the existing one.
+            webhookSecret: webhookSecret || null,
         }
     ).pipe(
         map(dataOrThrowErrors),
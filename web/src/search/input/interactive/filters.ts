@@ -94,4 +94,8 @@ export const FilterTypeToProseNames: Record<FilterType, string> = {
     patterntype: 'Pattern type',
     index: 'Indexed repos',
     visibility: 'Repository visiblity',
+    select: 'Select',
+    symbolkind: 'Symbol kind',
+    symbolparent: 'Symbol parent',
+    rev: 'Revision',
 }
@@ -5,6 +5,7 @@ import { DynamicallyImportedMonacoSettingsEditor } from '../settings/Dynamically
 import awsCodeCommitJSON from '../../../schema/aws_codecommit.schema.json'
 import bitbucketCloudSchemaJSON from '../../../schema/bitbucket_cloud.schema.json'
 import bitbucketServerSchemaJSON from '../../../schema/bitbucket_server.schema.json'
+import giteaSchemaJSON from '../../../schema/gitea.schema.json'
 import githubSchemaJSON from '../../../schema/github.schema.json'
 import gitlabSchemaJSON from '../../../schema/gitlab.schema.json'
 import gitoliteSchemaJSON from '../../../schema/gitolite.schema.json'
@@ -30,6 +31,7 @@ const externalServices: Record<ExternalServiceKind, JSONSchema> = {
     AWSCODECOMMIT: awsCodeCommitJSON,
     BITBUCKETCLOUD: bitbucketCloudSchemaJSON,
     BITBUCKETSERVER: bitbucketServerSchemaJSON,
+    GITEA: giteaSchemaJSON,
     GITHUB: githubSchemaJSON,
     GITLAB: gitlabSchemaJSON,
     GITOLITE: gitoliteSchemaJSON,
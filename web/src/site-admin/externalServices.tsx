@@ -9,6 +9,7 @@ import React from 'react'
 import awsCodeCommitSchemaJSON from '../../../schema/aws_codecommit.schema.json'
 import bitbucketCloudSchemaJSON from '../../../schema/bitbucket_cloud.schema.json'
 import bitbucketServerSchemaJSON from '../../../schema/bitbucket_server.schema.json'
+import giteaSchemaJSON from '../../../schema/gitea.schema.json'
 import githubSchemaJSON from '../../../schema/github.schema.json'
 import gitlabSchemaJSON from '../../../schema/gitlab.schema.json'
 import gitoliteSchemaJSON from '../../../schema/gitolite.schema.json'
@@ -941,6 +942,95 @@ const GITOLITE: AddExternalServiceOptions = {
         },
     ],
 }
+const GITEA: AddExternalServiceOptions = {
+    kind: GQL.ExternalServiceKind.GITEA,
+    title: 'Gitea',
+    icon: GitIcon,
+    jsonSchema: giteaSchemaJSON,
+    defaultDisplayName: 'Gitea',
+    defaultConfig: `{
+  "url": "https://gitea.example.com",
+  "token": "<access token>",
+  "repositoryQuery": [
+    "affiliated"
+  ]
+}`,
+    instructions: (
+        <div>
+            <ol>
+                <li>
+                    In the configuration below, set <Field>url</Field> to the URL of your Gitea instance.
+                </li>
+                <li>
+                    Create a Gitea access token under <strong>Settings &gt; Applications</strong> and set it as the{' '}
+                    <Field>token</Field> field.
+                </li>
+                <li>
+                    Use the <Field>repositoryQuery</Field>, <Field>orgs</Field> and <Field>repos</Field> fields to
+                    select the repositories to sync.
+                </li>
+            </ol>
+            <p>
+                See{' '}
+                <a
+                    rel="noopener noreferrer"
+                    target="_blank"
+                    href="https://docs.sourcegraph.com/admin/external_service/gitea#configuration"
+                >
+                    the docs for more advanced options
+                </a>
+                , or try one of the buttons below.
+            </p>
+        </div>
+    ),
+    editorActions: [
+        {
+            id: 'setAccessToken',
+            label: 'Set access token',
+            run: config => {
+                const value = '<access token>'
+                const edits = setProperty(config, ['token'], value, defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+        {
+            id: 'addOrgRepos',
+            label: 'Add repositories in an organization',
+            run: config => {
+                const value = '<organization name>'
+                const edits = setProperty(config, ['orgs', -1], value, defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+        {
+            id: 'addRepo',
+            label: 'Add individual repository',
+            run: config => {
+                const value = '<owner>/<repository>'
+                const edits = setProperty(config, ['repos', -1], value, defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+        {
+            id: 'excludeRepo',
+            label: 'Exclude a repository',
+            run: config => {
+                const value = { name: '<owner>/<repository>' }
+                const edits = setProperty(config, ['exclude', -1], value, defaultFormattingOptions)
+                return { edits, selectText: '{"name": "<owner>/<repository>"}' }
+            },
+        },
+        {
+            id: 'setSelfSignedCert',
+            label: 'Set internal or self-signed certificate',
+            run: config => {
+                const value = '<certificate>'
+                const edits = setProperty(config, ['certificate'], value, defaultFormattingOptions)
+                return { edits, selectText: value }
+            },
+        },
+    ],
+}
 const PHABRICATOR_SERVICE: AddExternalServiceOptions = {
     kind: GQL.ExternalServiceKind.PHABRICATOR,
     title: 'Phabricator connection',
@@ -1056,6 +1146,7 @@ export const codeHostExternalServices: Record<string, AddExternalServiceOptions>
     bitbucketserver: BITBUCKET_SERVER,
     aws_codecommit: AWS_CODE_COMMIT,
     gitolite: GITOLITE,
+    gitea: GITEA,
     git: GENERIC_GIT,
 }
 
@@ -1074,6 +1165,7 @@ export const defaultExternalServices: Record<GQL.ExternalServiceKind, AddExterna
     [GQL.ExternalServiceKind.BITBUCKETSERVER]: BITBUCKET_SERVER,
     [GQL.ExternalServiceKind.GITLAB]: GITLAB_DOTCOM,
     [GQL.ExternalServiceKind.GITOLITE]: GITOLITE,
+    [GQL.ExternalServiceKind.GITEA]: GITEA,
     [GQL.ExternalServiceKind.PHABRICATOR]: PHABRICATOR_SERVICE,
     [GQL.ExternalServiceKind.OTHER]: GENERIC_GIT,
     [GQL.ExternalServiceKind.AWSCODECOMMIT]: AWS_CODE_COMMIT,